@@ -6,6 +6,12 @@
 // database connections, API client setup, and external service configurations.
 package kessoku
 
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
 // name represents an identifier for injectors and arguments.
 // It's used to specify the name of generated injector functions
 // and to identify function parameters in dependency injection.
@@ -39,6 +45,12 @@ func (p fnProvider[T]) Fn() T {
 	return p.fn
 }
 
+// providerOption is a marker interface for options that configure a single provider.
+// Options are analyzed at compile time by the code generator and have no runtime effect.
+type providerOption interface {
+	providerOption()
+}
+
 // Provide wraps a function to be used as a dependency provider.
 //
 // Use this for any function that creates dependencies like databases, services, or configs.
@@ -48,10 +60,171 @@ func (p fnProvider[T]) Fn() T {
 //
 //	kessoku.Provide(NewDatabase)  // func NewDatabase() (*sql.DB, error)
 //	kessoku.Provide(NewLogger)    // func NewLogger() *log.Logger
-func Provide[T any](fn T) fnProvider[T] {
+//
+// Options such as Deprecated can be passed after the function.
+func Provide[T any](fn T, opts ...providerOption) fnProvider[T] {
 	return fnProvider[T]{fn: fn}
 }
 
+// deprecatedOption marks a provider as deprecated.
+type deprecatedOption struct {
+	message string
+}
+
+// providerOption implements the providerOption interface.
+func (d deprecatedOption) providerOption() {}
+
+// Deprecated marks a provider as deprecated so that code generation warns whenever it is used.
+//
+// Use this to phase out old constructors during migrations: the warning is printed only
+// when an injector actually needs the provider, not merely when it is listed in a Set.
+//
+// Example:
+//
+//	kessoku.Provide(NewLegacyClient, kessoku.Deprecated("use NewClient"))
+func Deprecated(message string) deprecatedOption {
+	return deprecatedOption{message: message}
+}
+
+// Tracer starts a span named name derived from ctx and returns the span context
+// together with a function that ends the span.
+//
+// It matches the shape of most tracing libraries, e.g. for OpenTelemetry:
+//
+//	func(ctx context.Context, name string) (context.Context, func()) {
+//		ctx, span := otel.Tracer("app").Start(ctx, name)
+//		return ctx, func() { span.End() }
+//	}
+type Tracer func(ctx context.Context, name string) (context.Context, func())
+
+// spanOption wraps a provider call in a tracing span.
+type spanOption struct {
+	name string
+}
+
+// providerOption implements the providerOption interface.
+func (s spanOption) providerOption() {}
+
+// Span wraps the provider call in a span started by the injector's Tracer argument.
+//
+// Injectors using a provider with Span take a Tracer and a context.Context argument.
+// Providers that accept a context.Context receive the span context, so their own spans nest under it.
+//
+// Example:
+//
+//	kessoku.Async(kessoku.Provide(NewDatabase, kessoku.Span("init-database")))
+func Span(name string) spanOption {
+	return spanOption{name: name}
+}
+
+// tagOption labels a provider with a tag that kessoku.Use selects.
+type tagOption struct {
+	tag string
+}
+
+// providerOption implements the providerOption interface.
+func (t tagOption) providerOption() {}
+
+// Tag labels a provider so that an injector can select it with Use.
+//
+// Tagged providers may provide the same type: an injector listing several of them picks
+// one with Use, and the others are left unused. Without Use, tagged providers of the
+// same type conflict like any other providers.
+//
+// Example:
+//
+//	kessoku.Provide(NewS3Storage, kessoku.Tag("s3")),
+//	kessoku.Provide(NewGCSStorage, kessoku.Tag("gcs")),
+func Tag(tag string) tagOption {
+	return tagOption{tag: tag}
+}
+
+// costOption estimates how long a provider takes to run.
+type costOption struct {
+	ms int
+}
+
+// providerOption implements the providerOption interface.
+func (c costOption) providerOption() {}
+
+// Cost estimates the time in milliseconds a provider takes to run, such as connecting to a
+// database. It only weights the critical path printed by --report: the dependency chain with
+// the highest total cost, which bounds the startup latency no matter how many providers run
+// in parallel. Providers without Cost weigh 1.
+//
+// Example:
+//
+//	kessoku.Async(kessoku.Provide(NewDatabase, kessoku.Cost(200))),
+func Cost(ms int) costOption {
+	return costOption{ms: ms}
+}
+
+// readyCheckOption re-validates a provided value for the injector's ready check.
+type readyCheckOption[T any] struct {
+	check func(T, context.Context) error
+}
+
+// providerOption implements the providerOption interface.
+func (r readyCheckOption[T]) providerOption() {}
+
+// Check runs the ready check against v. It is called by generated injectors.
+func (r readyCheckOption[T]) Check(ctx context.Context, v T) error {
+	return r.check(v, ctx)
+}
+
+// WithReadyCheck declares a check that re-validates a value of type T created by the provider,
+// such as pinging a database, to build a readiness probe from the dependency graph.
+// The value comes first so that method expressions like (*sql.DB).PingContext can be passed.
+//
+// An injector using providers with WithReadyCheck also returns a func(context.Context) error
+// after the value, the cancel function of WithCancel, and the closer of CleanupCloser. It runs
+// the checks of every used provider and joins their errors. Each WithReadyCheck call is
+// evaluated once by the injector, which passes it to both the provider and the probe. T must be
+// a type the provider provides. Such injectors cannot be combined with LazyInjector, MustInject, Implements, or
+// Populate, and are not added to the registry.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Provide(NewDB, kessoku.WithReadyCheck((*sql.DB).PingContext)),
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp() (*App, func(context.Context) error, error)
+func WithReadyCheck[T any](check func(v T, ctx context.Context) error) readyCheckOption[T] {
+	return readyCheckOption[T]{check: check}
+}
+
+// groupOption labels providers with a section of the generated injector.
+type groupOption struct {
+	name string
+}
+
+// providerOption implements the providerOption interface.
+func (g groupOption) providerOption() {}
+
+// provide implements the provider interface.
+func (g groupOption) provide() {}
+
+// Group labels providers with a logical group such as "infra" or "services".
+//
+// The generated injector starts each run of calls to providers of the same group with a
+// "// --- name ---" comment, which makes large injectors easier to review. Providers without a
+// group do not end the run of the group before them. Pass Group to
+// Provide to label a single provider, or to Set to label every provider of the set that has
+// no group of its own. Groups only affect comments, never the call order.
+//
+// Example:
+//
+//	var InfraSet = kessoku.Set(
+//	    kessoku.Group("infra"),
+//	    kessoku.Provide(NewDatabase),
+//	    kessoku.Provide(NewCache, kessoku.Group("cache")),
+//	)
+func Group(name string) groupOption {
+	return groupOption{name: name}
+}
+
 type asyncProvider[T any, F funcProvider[T]] struct {
 	fn F
 }
@@ -106,6 +279,57 @@ func Bind[S, T any, F funcProvider[T]](fn F) bindProvider[S, T, F] {
 	return bindProvider[S, T, F]{fn: fn}
 }
 
+// adaptProvider represents a provider whose result is converted to T by an adapter function.
+// X is the type returned by the wrapped provider and C is its function type.
+type adaptProvider[T, X, C any, F funcProvider[C]] struct {
+	fn      F
+	adapter func(X) T
+}
+
+// provide implements the provider interface for adaptProvider.
+func (p adaptProvider[_, _, _, _]) provide() {}
+
+// Adapt converts the result of a third-party constructor to the type your code depends on.
+//
+// Use this when a library constructor returns a type that does not match your interfaces.
+// The adapter is called with the constructor's result at the wiring layer, so domain
+// packages do not need adapter constructors of their own.
+//
+// Example:
+//
+//	kessoku.Adapt[Storage](kessoku.Provide(s3.NewClient), NewS3Storage)
+//	// func NewS3Storage(client *s3.Client) Storage
+func Adapt[T, X, C any, F funcProvider[C]](fn F, adapter func(X) T) adaptProvider[T, X, C, F] {
+	return adaptProvider[T, X, C, F]{fn: fn, adapter: adapter}
+}
+
+// decorateProvider represents a provider whose result of type T is wrapped by decorators in order.
+// C is the function type of the wrapped provider.
+type decorateProvider[T, C any, F funcProvider[C]] struct {
+	fn         F
+	decorators []any
+}
+
+// provide implements the provider interface for decorateProvider.
+func (p decorateProvider[_, _, _]) provide() {}
+
+// Decorate wraps the T provided by a base provider with decorators, applied in order.
+//
+// Use this to compose middleware declaratively. Each decorator is a func(T) T, or a
+// func(T, deps...) T whose other parameters are injected like the parameters of a provider.
+// The base provider must provide only T, so it cannot be a Bind.
+//
+// Example:
+//
+//	kessoku.Decorate[http.Handler](kessoku.Provide(NewRouter), WithRecovery, WithLogging)
+//	// func NewRouter() http.Handler
+//	// func WithRecovery(next http.Handler) http.Handler
+//	// func WithLogging(next http.Handler, logger *slog.Logger) http.Handler
+//	// Provides the http.Handler WithLogging(WithRecovery(NewRouter()), logger)
+func Decorate[T, C any, F funcProvider[C]](fn F, decorators ...any) decorateProvider[T, C, F] {
+	return decorateProvider[T, C, F]{fn: fn, decorators: decorators}
+}
+
 // Value injects constant values like config settings, feature flags, or static data.
 //
 // Use this for any constant that your services need - no function creation required!
@@ -124,6 +348,273 @@ func Value[T any](v T) fnProvider[func() T] {
 	}
 }
 
+// ValueE injects a value computed by a fallible expression.
+//
+// Pass a call returning (T, error) directly, such as a parse function. The generated
+// injector returns the error, so there is no need for a named constructor just to
+// handle it. Like Value, the expression is evaluated every time the injector runs.
+//
+// Example:
+//
+//	kessoku.ValueE(url.Parse("https://api.example.com")), // Inject *url.URL
+//	kessoku.ValueE(time.ParseDuration("30s")),            // Inject time.Duration
+func ValueE[T any](v T, err error) fnProvider[func() (T, error)] {
+	return fnProvider[func() (T, error)]{
+		fn: func() (T, error) { return v, err },
+	}
+}
+
+// Const injects a typed constant, such as a value of an iota enum, as its named type T.
+//
+// Unlike Value, the argument must be a constant of a named type, so the generated injector
+// refers to it by name, e.g. DebugLevel rather than 0, and a misspelled or untyped value is
+// rejected at generation time. Like Value, the value is owned by the caller.
+//
+// Example:
+//
+//	type Level int
+//
+//	const (
+//	    DebugLevel Level = iota
+//	    InfoLevel
+//	)
+//
+//	kessoku.Const[Level](DebugLevel), // Inject Level
+func Const[T any](v T) fnProvider[func() T] {
+	return fnProvider[func() T]{
+		fn: func() T { return v },
+	}
+}
+
+// Call injects the result of a function call, such as an existing initializer.
+//
+// Unlike Provide, which takes the function itself and resolves its parameters, Call takes
+// the call expression, so the function is used as is without a wrapper. The generated
+// injector assigns the call result directly and evaluates it every time the injector runs.
+// Like Value, the result is owned by the caller and is not closed by CleanupCloser.
+//
+// Example:
+//
+//	kessoku.Call(config.Default()), // Inject *config.Config
+//	kessoku.Call(slog.Default()),   // Inject *slog.Logger
+func Call[T any](v T) fnProvider[func() T] {
+	return fnProvider[func() T]{
+		fn: func() T { return v },
+	}
+}
+
+// appendValue is a value contributed to a []T dependency by AppendValue.
+type appendValue[T any] struct {
+	v T
+}
+
+// provide implements the provider interface.
+func (a appendValue[T]) provide() {}
+
+// AppendValue contributes v to a []T dependency.
+//
+// All AppendValue contributions of the same type T in an injector, including those from sets,
+// are collected into a single []T in declaration order. Use this for config lists and
+// middleware stacks defined as values. Only values are aggregated: an element built by a
+// provider needs a provider that takes it and returns the slice instead. A []T cannot
+// be supplied both by AppendValue and by another provider.
+//
+// Example:
+//
+//	kessoku.AppendValue[Middleware](Logging),
+//	kessoku.AppendValue[Middleware](Recovery),
+//	kessoku.Provide(NewRouter), // func NewRouter(middlewares []Middleware) *Router
+func AppendValue[T any](v T) appendValue[T] {
+	return appendValue[T]{v: v}
+}
+
+// AppendValues collects the contributions of AppendValue into a slice.
+// This function is used internally by the code generator.
+func AppendValues[T any](values ...appendValue[T]) fnProvider[func() []T] {
+	return fnProvider[func() []T]{
+		fn: func() []T {
+			result := make([]T, 0, len(values))
+			for _, value := range values {
+				result = append(result, value.v)
+			}
+			return result
+		},
+	}
+}
+
+// nilProvider provides a nil value of the interface T.
+type nilProvider[T any] struct{}
+
+// provide implements the provider interface.
+func (n nilProvider[T]) provide() {}
+
+// Nil provides a nil value of the interface type T.
+//
+// Use this when a dependent accepts an optional collaborator and nil is a valid choice,
+// such as a logger or metrics recorder that is checked for nil before use. Unlike
+// leaving the dependency out, which turns it into an injector argument, Nil states
+// explicitly that no implementation is wanted. T must be an interface type.
+//
+// Example:
+//
+//	kessoku.Nil[Metrics](),          // func NewServer(metrics Metrics) *Server
+//	kessoku.Provide(NewServer),
+//	// Generates: var metrics Metrics = nil
+func Nil[T any]() nilProvider[T] {
+	return nilProvider[T]{}
+}
+
+// collectImplementing provides a []T of the provided values implementing T.
+type collectImplementing[T any] struct{}
+
+// provide implements the provider interface.
+func (c collectImplementing[T]) provide() {}
+
+// collectOption is a marker interface for options that configure CollectImplementing.
+type collectOption interface {
+	collectOption()
+}
+
+// allowEmptyOption lets CollectImplementing provide an empty slice.
+type allowEmptyOption struct{}
+
+// collectOption implements the collectOption interface.
+func (a allowEmptyOption) collectOption() {}
+
+// AllowEmpty lets CollectImplementing provide an empty slice when no provided type implements
+// its interface, instead of failing.
+func AllowEmpty() allowEmptyOption {
+	return allowEmptyOption{}
+}
+
+// CollectImplementing provides a []T holding the value of every provider whose concrete
+// type implements the interface T, without listing the providers individually.
+//
+// Use this to collect plugins, handlers, or health checks: adding a provider of a new
+// implementation is enough to add it to the slice. The values are in the order the
+// providers are declared in, with sets expanded in place, and each provider is called once
+// even if its value is also used elsewhere. Values provided as T itself, arguments, and
+// struct fields are not collected. It is an error if no provided type implements T, unless
+// AllowEmpty is passed. T must be an interface with methods.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Provide(NewAuthPlugin),  // func NewAuthPlugin() *AuthPlugin, implements Plugin
+//	    kessoku.Provide(NewCachePlugin), // func NewCachePlugin() *CachePlugin, implements Plugin
+//	    kessoku.Provide(NewConfig),      // func NewConfig() *Config, not collected
+//	    kessoku.CollectImplementing[Plugin](),
+//	    kessoku.Provide(NewApp), // func NewApp(plugins []Plugin, config *Config) *App
+//	)
+//	// Generates: plugins := []Plugin{authPlugin, cachePlugin}
+func CollectImplementing[T any](opts ...collectOption) collectImplementing[T] {
+	return collectImplementing[T]{}
+}
+
+// Now returns the current time.
+//
+// Providers that depend on Now instead of calling time.Now directly can be tested
+// with a fixed or fake clock.
+type Now func() time.Time
+
+// Clock provides Now backed by time.Now.
+//
+// Include Clock in production injectors. In tests, leave it out so that Now becomes an
+// argument of the generated injector (or declare it with Arg) and pass a fake clock.
+//
+// Example:
+//
+//	kessoku.Clock(),                       // func NewTokenIssuer(now kessoku.Now) *TokenIssuer
+//	kessoku.Provide(NewTokenIssuer),
+func Clock() fnProvider[func() Now] {
+	return fnProvider[func() Now]{
+		fn: func() Now { return time.Now },
+	}
+}
+
+// httpClientOption sets a field of the *http.Client generated for HTTPClient.
+type httpClientOption struct{}
+
+// HTTPTimeout sets the time limit for requests made by the client.
+func HTTPTimeout(timeout time.Duration) httpClientOption {
+	return httpClientOption{}
+}
+
+// HTTPTransport sets the transport used by the client instead of the shared http.DefaultTransport.
+//
+// The argument is evaluated by every call of the injector, as a field of the client literal, so an
+// expression like &http.Transport{} creates a new transport, with its own connection pool, for each
+// client. Pass a package-level variable to share one transport across calls and injectors.
+//
+// The transport must implement http.RoundTripper, which kessoku checks when generating the
+// injector; it is typed as any so that this package does not depend on net/http.
+func HTTPTransport(transport any) httpClientOption {
+	return httpClientOption{}
+}
+
+// httpClientProvider provides a *http.Client constructed by the generated code.
+type httpClientProvider struct{}
+
+// provide implements the provider interface.
+func (h httpClientProvider) provide() {}
+
+// HTTPClient provides a *http.Client configured by opts.
+//
+// The client is constructed by the generated injector, so the options must be HTTPTimeout
+// and HTTPTransport calls passed directly:
+//
+//	client := &http.Client{Timeout: 10 * time.Second}
+//
+// Unless HTTPTransport is given, every client uses http.DefaultTransport, so connections
+// are pooled across all injectors. Like Clock, it is only used when listed in an injector;
+// declare Arg[*http.Client]() or your own provider instead to supply a different client.
+//
+// Example:
+//
+//	kessoku.HTTPClient(kessoku.HTTPTimeout(10*time.Second)), // func NewAPIClient(client *http.Client) *APIClient
+//	kessoku.Provide(NewAPIClient),
+func HTTPClient(opts ...httpClientOption) httpClientProvider {
+	return httpClientProvider{}
+}
+
+// envValue is the set of types an environment variable can be converted to.
+type envValue interface {
+	~string | ~int | ~bool
+}
+
+// envProvider provides the value of an environment variable converted to T.
+type envProvider[T envValue] struct {
+	key string
+}
+
+// provide implements the provider interface.
+func (e envProvider[T]) provide() {}
+
+// Env injects the value of a required environment variable, converted to T.
+//
+// Use this for bootstrapping settings like ports, feature flags, and connection strings
+// without writing a provider per variable. T may be a string, int, or bool type, including
+// named types such as `type DatabaseURL string`, which keeps several variables distinguishable.
+//
+// The generated injector reads the variable with os.Getenv, parses int and bool types with
+// strconv.Atoi and strconv.ParseBool, and returns an error when the variable is unset or empty,
+// or when its value cannot be parsed as T.
+//
+// Example:
+//
+//	kessoku.Env[DatabaseURL]("DATABASE_URL"),  // type DatabaseURL string
+//	kessoku.Env[int]("PORT"),
+//	// Generates:
+//	// databaseURL := DatabaseURL(os.Getenv("DATABASE_URL"))
+//	// if databaseURL == "" { ... }
+//	// numEnv := os.Getenv("PORT")
+//	// if numEnv == "" { ... }
+//	// num, err := strconv.Atoi(numEnv)
+func Env[T envValue](key string) envProvider[T] {
+	return envProvider[T]{key: key}
+}
+
 // Inject creates a dependency injection build directive that generates fast initialization code.
 //
 // Declare your dependencies once, get blazing-fast startup automatically! Kessoku analyzes
@@ -143,6 +634,9 @@ func Value[T any](v T) fnProvider[func() T] {
 // • Handles dependency order and error propagation
 // • Includes context.Context for cancellation when async providers are used
 //
+// Use Inject[any] to infer the return type when the injector has a single provider
+// with a single result.
+//
 // Trigger code generation:
 //
 //	//go:generate go tool kessoku $GOFILE
@@ -152,54 +646,906 @@ func Inject[T any](name name, providers ...provider) struct{} {
 	return struct{}{}
 }
 
-type set struct{}
+// Populate generates a function that fills the exported fields of an existing struct
+// instead of constructing it, for objects whose lifecycle is owned by a framework.
+//
+// T must be a pointer to a struct. The generated function takes the target as an argument,
+// following context.Context when async providers are used, and assigns every exported
+// field from the providers, like dig's Populate.
+//
+// Fields of string, boolean, or numeric types with a `default:"..."` tag are assigned the tag
+// value when no provider supplies them, instead of becoming arguments, e.g. for config defaults:
+//
+//	type Config struct {
+//	    Addr    string `default:":8080"`
+//	    Workers int    `default:"4"`
+//	}
+//
+// Example - creates PopulateHandler(handler *Handler) error:
+//
+//	var _ = kessoku.Populate[*Handler](
+//	    "PopulateHandler",
+//	    kessoku.Provide(NewDatabase),
+//	    kessoku.Provide(NewLogger),
+//	)
+//
+//	handler := framework.NewHandler()
+//	err := PopulateHandler(handler) // Sets handler.DB and handler.Logger
+func Populate[T any](name name, providers ...provider) struct{} {
+	// This function is analyzed at compile time by the kessoku code generator.
+	// The actual implementation is generated and written to *_band.go files.
+	return struct{}{}
+}
 
-func (s set) provide() {}
+// lazyInjector marks an injector for lazy, once-guarded initialization.
+type lazyInjector struct{}
 
-// Set groups related providers into reusable bundles for better organization.
+// provide implements the provider interface.
+func (l lazyInjector) provide() {}
+
+// LazyInjector makes the generated injector build its result on the first call and cache it.
 //
-// Perfect for organizing providers by domain (database, auth, monitoring) and reusing
-// them across multiple injectors. Keeps your dependency declarations clean and DRY.
+// Use this for libraries and plugin-style packages that should not wire at import time.
+// Construction is guarded by sync.Once, so concurrent callers share a single result,
+// and a construction error is cached and returned on every subsequent call.
 //
 // Example:
 //
-//	var DatabaseSet = kessoku.Set(
-//	    kessoku.Provide(NewConfig),
-//	    kessoku.Async(kessoku.Provide(NewDatabase)),
-//	    kessoku.Provide(NewMigrator),
+//	var _ = kessoku.Inject[*Service](
+//	    "GetService",
+//	    kessoku.LazyInjector(),
+//	    kessoku.Provide(NewService),
 //	)
 //
-//	// Reuse the set in multiple injectors
-//	var _ = kessoku.Inject[*App]("InitApp", DatabaseSet, kessoku.Provide(NewApp))
-func Set(providers ...provider) set {
-	return set{}
+// The getter takes no arguments, since only its first call would use them, so it cannot be
+// combined with Arg, Named, or Args, and every type it needs must be provided.
+//
+// When async providers are used, the generated getter still takes context.Context, and the
+// context of the call constructing the result is the one given to the providers. A failure
+// while that context is done is not cached, so the next call retries with its own context.
+func LazyInjector() lazyInjector {
+	return lazyInjector{}
 }
 
-// structProvider marks a struct type for field expansion.
-// When used in an Inject declaration, all exported fields of T
-// become available as individual dependencies.
-type structProvider[T any] struct{}
+// sharedOption names the package-level variable caching the result of a lazy injector.
+type sharedOption struct {
+	name string
+}
 
 // provide implements the provider interface.
-func (s structProvider[T]) provide() {}
+func (s sharedOption) provide() {}
 
-// Fn returns a dummy function for type compatibility with funcProvider.
-// This allows structProvider to be wrapped with Async and Bind.
-// The returned function is never actually called - the code generator
-// recognizes structProvider and handles field expansion at compile time.
-func (s structProvider[T]) Fn() func() T {
-	return func() T {
-		var zero T
-		return zero
-	}
+// Shared names the package-level variable that caches the result of a LazyInjector.
+//
+// By default the variable is named after the injector and is not meant to be referenced.
+// With Shared, hand-written code in the package can use the shared resource by the given
+// name once the injector has run. Capitalize the name to export it. The name must not be
+// declared elsewhere in the package.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*sql.DB](
+//	    "GetDB",
+//	    kessoku.LazyInjector(),
+//	    kessoku.Shared("dbPool"),
+//	    kessoku.Provide(OpenDB),
+//	)
+func Shared(name string) sharedOption {
+	return sharedOption{name: name}
 }
 
-// Struct returns a provider that expands all exported fields of T
-// as individual dependencies for injection.
+// mustInject requests a panicking variant of an injector.
+type mustInject struct{}
+
+// provide implements the provider interface.
+func (m mustInject) provide() {}
+
+// MustInject additionally generates a variant of the injector, named with a "Must" suffix,
+// that panics instead of returning an error.
 //
-// T must be a struct type or pointer to struct type.
-// All exported fields of T become available as injectable dependencies.
-// Unexported fields are ignored.
+// Use this in main() where an initialization failure should crash the program. The
+// error-returning injector is still generated and the Must variant simply calls it, so
+// the injector must return an error.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.MustInject(),
+//	    kessoku.Provide(NewDatabase), // func NewDatabase() (*Database, error)
+//	    kessoku.Provide(NewApp),
+//	)
+//
+// This generates, next to InitializeApp:
+//
+//	func InitializeAppMust() *App {
+//	    app, err := InitializeApp()
+//	    if err != nil {
+//	        panic(err)
+//	    }
+//	    return app
+//	}
+func MustInject() mustInject {
+	return mustInject{}
+}
+
+// panicMsgOption formats the panic of a Must injector.
+type panicMsgOption struct {
+	format string
+}
+
+// provide implements the provider interface.
+func (p panicMsgOption) provide() {}
+
+// PanicMsg sets the message the Must variant of an injector panics with.
+//
+// The format takes the error returned by the injector through a single %w or %v verb, and the
+// variant panics with fmt.Errorf(format, err), so crash logs say what failed to initialize.
+// It requires MustInject.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.MustInject(),
+//	    kessoku.PanicMsg("failed to init app: %w"),
+//	    kessoku.Provide(NewDatabase),
+//	    kessoku.Provide(NewApp),
+//	)
+//
+// This makes InitializeAppMust panic with fmt.Errorf("failed to init app: %w", err).
+func PanicMsg(format string) panicMsgOption {
+	return panicMsgOption{format: format}
+}
+
+// blankImport imports a package for its side effects into the generated file.
+type blankImport struct {
+	path string
+}
+
+// provide implements the provider interface.
+func (b blankImport) provide() {}
+
+// BlankImport adds a blank import of the package path to the file generated for the injector,
+// for providers that only work once a package has registered itself in init, such as a database driver.
+//
+// It keeps the import next to the injector relying on it rather than in an unrelated file.
+// The blank import is left out if the generated file imports the package anyway.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*sql.DB](
+//	    "InitializeDB",
+//	    kessoku.BlankImport("github.com/lib/pq"),
+//	    kessoku.Provide(OpenDB), // func OpenDB() (*sql.DB, error) opens a "postgres" database
+//	)
+//
+// This adds import _ "github.com/lib/pq" to the generated file.
+func BlankImport(path string) blankImport {
+	return blankImport{path: path}
+}
+
+// dynamicOverrides requests a variant of an injector taking values by type.
+type dynamicOverrides struct{}
+
+// provide implements the provider interface.
+func (d dynamicOverrides) provide() {}
+
+// DynamicOverrides additionally generates a variant of the injector, named with a "WithOverrides"
+// suffix, taking a last argument that maps types to values used in place of their providers.
+//
+// Before each provider call, the variant looks up the provided type in the map, e.g.
+// reflect.TypeFor[*Database](), and uses the value found instead of calling the provider.
+// Values of the wrong type panic on the type assertion. Providers of several values are called
+// and their overridden values replaced afterwards. The injector itself calls the variant with a
+// nil map. It cannot be combined with LazyInjector.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.DynamicOverrides(),
+//	    kessoku.Provide(NewDatabase),
+//	    kessoku.Provide(NewApp),
+//	)
+//
+// This generates, next to InitializeApp:
+//
+//	func InitializeAppWithOverrides(overrides map[reflect.Type]any) *App {
+//	    var database *Database
+//	    if override, ok := overrides[reflect.TypeFor[*Database]()]; ok {
+//	        database = override.(*Database)
+//	    } else {
+//	        database = kessoku.Provide(NewDatabase).Fn()()
+//	    }
+//	    ...
+//	}
+func DynamicOverrides() dynamicOverrides {
+	return dynamicOverrides{}
+}
+
+// ChannelTraceFunc receives the channel events of an injector generated with WithChannelTrace.
+// event is "wait" before waiting for a dependency channel, "ready" once it is closed,
+// and "close" before a provider closes the channel of its result.
+type ChannelTraceFunc func(injector, event, channel string)
+
+// channelTrace requests channel tracing for an injector.
+type channelTrace struct {
+	tracers []ChannelTraceFunc
+}
+
+// provide implements the provider interface.
+func (c channelTrace) provide() {}
+
+// Trace reports a channel event to the tracers, or to slog.Debug if there are none.
+// This method is called by the generated code.
+func (c channelTrace) Trace(injector, event, channel string) {
+	if len(c.tracers) == 0 {
+		slog.Debug("kessoku channel trace", "injector", injector, "event", event, "channel", channel)
+		return
+	}
+
+	for _, tracer := range c.tracers {
+		tracer(injector, event, channel)
+	}
+}
+
+// WithChannelTrace makes the generated injector report every wait on and close of the channels
+// that hand results between async providers, so that a hanging injector can be diagnosed.
+//
+// Use this while debugging: the last "wait" without a matching "ready" names the channel that
+// is never closed. Events are logged with slog.Debug unless tracers are given. Injectors
+// without async providers use no channels, so nothing is reported for them.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.WithChannelTrace(func(injector, event, channel string) {
+//	        log.Printf("%s: %s %s", injector, event, channel)
+//	    }),
+//	    kessoku.Async(kessoku.Provide(NewDatabase)),
+//	    kessoku.Async(kessoku.Provide(NewCache)),
+//	    kessoku.Provide(NewApp),
+//	)
+func WithChannelTrace(tracers ...ChannelTraceFunc) channelTrace {
+	return channelTrace{tracers: tracers}
+}
+
+// withCancel makes an injector return the cancel function of the context given to its providers.
+type withCancel struct{}
+
+// provide implements the provider interface.
+func (w withCancel) provide() {}
+
+// WithCancel derives a cancelable context from the injector's context.Context argument,
+// passes it to the providers, and returns its context.CancelFunc to the caller.
+//
+// Use this for services that start background work during initialization: providers can
+// tie goroutines to the context they receive, and the caller stops them by calling cancel
+// on shutdown. The injector takes a context.Context even if no provider needs one, and it
+// cancels the context itself before returning an error. WithCancel cannot be combined with
+// LazyInjector, MustInject, Implements, or Populate.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.WithCancel(),
+//	    kessoku.Provide(NewWorker), // func NewWorker(ctx context.Context) *Worker starts a goroutine
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp(ctx context.Context) (*App, context.CancelFunc)
+func WithCancel() withCancel {
+	return withCancel{}
+}
+
+// omitContext drops the context argument that async injectors take for their errgroup.
+type omitContext struct{}
+
+// provide implements the provider interface.
+func (o omitContext) provide() {}
+
+// OmitContext stops the injector from taking a context.Context argument only to derive the
+// context of the errgroup running its async providers.
+//
+// Without it, an injector with async providers always takes a context, which cancels the
+// pending providers once one of them fails. With OmitContext, the providers run in a plain
+// errgroup.Group when none of them requires a context.Context or returns an error; otherwise
+// the injector keeps the context argument. OmitContext cannot be combined with WithCancel.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.OmitContext(),
+//	    kessoku.Async(kessoku.Provide(NewCache)), // func NewCache() *Cache
+//	    kessoku.Async(kessoku.Provide(NewQueue)), // func NewQueue() *Queue
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp() *App
+func OmitContext() omitContext {
+	return omitContext{}
+}
+
+// lastWins lets later providers replace earlier ones of the same type.
+type lastWins struct{}
+
+// provide implements the provider interface.
+func (l lastWins) provide() {}
+
+// LastWins resolves providers of the same type by keeping the last one in argument order,
+// with sets expanded in place, instead of failing with "multiple providers provide".
+//
+// It eases overriding a provider of a shared set without editing the set. Every replaced
+// provider is logged as a warning so that overrides are never silent. A replaced provider
+// is still called if the injector needs another type it provides.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.LastWins(),
+//	    DefaultSet,                     // provides *Config with NewDefaultConfig
+//	    kessoku.Provide(NewTestConfig), // replaces NewDefaultConfig
+//	    kessoku.Provide(NewApp),
+//	)
+func LastWins() lastWins {
+	return lastWins{}
+}
+
+// strictTypeIdentity makes an injector match types by identity rather than by name.
+type strictTypeIdentity struct{}
+
+// provide implements the provider interface.
+func (s strictTypeIdentity) provide() {}
+
+// StrictTypeIdentity matches provided and required types with types.Identical rather than by
+// their printed form, which distinct types can share.
+//
+// Use this when an injector wires types that print alike but are not the same type, such as
+// anonymous structs with unexported fields from different packages, or types of the same name
+// declared in different functions. Without it, such types are taken for one type and conflict
+// or satisfy each other. Error messages tell the types apart with a #1, #2, ... suffix.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.StrictTypeIdentity(),
+//	    kessoku.Provide(a.NewOptions), // func NewOptions() struct{ debug bool }
+//	    kessoku.Provide(a.NewClient),  // func NewClient(opts struct{ debug bool }) *a.Client
+//	    kessoku.Provide(b.NewOptions), // func NewOptions() struct{ debug bool }
+//	    kessoku.Provide(b.NewClient),  // func NewClient(opts struct{ debug bool }) *b.Client
+//	    kessoku.Provide(NewApp),
+//	)
+func StrictTypeIdentity() strictTypeIdentity {
+	return strictTypeIdentity{}
+}
+
+// cleanupCloser makes an injector return a closer for the values it creates.
+type cleanupCloser struct{}
+
+// provide implements the provider interface.
+func (c cleanupCloser) provide() {}
+
+// CleanupCloser makes the injector also return a closer whose Close() error method closes
+// every value created by the injector's providers that implements io.Closer.
+//
+// The closer type, named after the injector with a Closer suffix, is generated next to the
+// injector. Close closes the values in reverse creation order and joins their errors, so the
+// closer can be added to io.Closer-based shutdown code. Values of kessoku.Value and injector
+// arguments are owned by the caller and are not closed. If the injector fails, the context of
+// the async providers is canceled and they are waited for, then the values created so far are
+// closed before the error is returned.
+//
+// WithCancel returns its own cancel function, so CleanupCloser cannot be combined with it,
+// nor with LazyInjector, MustInject, Implements, or Populate.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.CleanupCloser(),
+//	    kessoku.Provide(NewDB), // func NewDB() (*sql.DB, error)
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp() (*App, *InitializeAppCloser, error)
+func CleanupCloser() cleanupCloser {
+	return cleanupCloser{}
+}
+
+// runWrapper makes an injector also generated as a function running a callback with its result.
+type runWrapper struct{}
+
+// provide implements the provider interface.
+func (r runWrapper) provide() {}
+
+// WithRunWrapper additionally generates Run<Name>, which builds the result with the injector,
+// passes it to a callback, and tears the created values down once the callback returns.
+//
+// It removes the build, use, and teardown boilerplate around the lifecycle of resources in
+// main. The wrapper takes the arguments of the injector followed by the callback. The cancel
+// function of WithCancel is deferred, and so is the closer of CleanupCloser, whose error is
+// joined to the error of the callback. An error of the injector is returned without calling
+// the callback. WithRunWrapper is not supported for Populate.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.WithRunWrapper(),
+//	    kessoku.CleanupCloser(),
+//	    kessoku.Provide(NewDB), // func NewDB() (*sql.DB, error)
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func RunInitializeApp(fn func(*App) error) error
+func WithRunWrapper() runWrapper {
+	return runWrapper{}
+}
+
+// forTest makes an injector generated into a test file.
+type forTest struct{}
+
+// provide implements the provider interface.
+func (f forTest) provide() {}
+
+// ForTest generates the injector into a _test.go file next to the regular generated file,
+// e.g. kessoku_band_test.go for kessoku.go, instead of kessoku_band.go.
+//
+// Use this for wiring that only tests need, such as injectors assembling fakes, so that it
+// stays out of the production binary. The test file declares the same package as the file
+// containing the Inject call, so the injector can use its unexported providers. Injectors
+// generated for tests are not added to the registry of --emit-registry.
+//
+// To wire providers declared in _test.go files, such as unexported fakes, put the Inject call
+// in a _test.go file too. Every injector of a test file is generated for tests, into e.g.
+// wire_test_band_test.go for wire_test.go, without ForTest.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeTestApp",
+//	    kessoku.ForTest(),
+//	    kessoku.Bind[Clock](kessoku.Provide(NewFakeClock)),
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates into kessoku_band_test.go: func InitializeTestApp() *App
+func ForTest() forTest {
+	return forTest{}
+}
+
+// errorWrapper converts the errors returned by an injector to E.
+type errorWrapper[E error] struct {
+	wrap func(error) E
+}
+
+// provide implements the provider interface.
+func (e errorWrapper[E]) provide() {}
+
+// WrapError makes the generated injector return errors of type E, converted by wrap,
+// instead of the builtin error.
+//
+// Use this in codebases with structured initialization errors, so that callers get the
+// richer type without a type assertion. Every error of a provider, of an async provider,
+// and of the context is passed to wrap before it is returned. Injectors whose providers
+// cannot fail return no error and are not affected.
+//
+// Example:
+//
+//	func NewInitError(err error) *InitError { return &InitError{Err: err} }
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.WrapError(NewInitError),
+//	    kessoku.Provide(NewDatabase), // func NewDatabase() (*Database, error)
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp() (*App, *InitError)
+func WrapError[E error](wrap func(error) E) errorWrapper[E] {
+	return errorWrapper[E]{wrap: wrap}
+}
+
+// autoConvert enables assignability-based wiring for an injector.
+type autoConvert struct{}
+
+// provide implements the provider interface.
+func (a autoConvert) provide() {}
+
+// AutoConvert lets a required type without an exact provider be satisfied by the single
+// provided type that is assignable to it, such as *bytes.Buffer for io.Writer.
+//
+// Generation fails when several provided types are assignable, so the wiring never depends on
+// declaration order. Use Bind when the choice should be explicit.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*Logger](
+//	    "InitializeLogger",
+//	    kessoku.AutoConvert(),
+//	    kessoku.Provide(NewBuffer),  // func NewBuffer() *bytes.Buffer
+//	    kessoku.Provide(NewLogger),  // func NewLogger(w io.Writer) *Logger
+//	)
+func AutoConvert() autoConvert {
+	return autoConvert{}
+}
+
+// autoRef enables pointer and value wiring of the same type for an injector.
+type autoRef struct{}
+
+// provide implements the provider interface.
+func (a autoRef) provide() {}
+
+// AutoRef lets a required *T without a provider be satisfied by the provided T, passing its
+// address, and a required T without a provider by the provided *T, passing a copy of the pointee.
+//
+// The address points to the injector's variable holding the value, so every provider requiring
+// *T shares it, while the providers requiring T get their own copy. Dereferencing panics if the
+// provider of *T returns nil, and copying a value holding a lock such as a sync.Mutex is flagged
+// by go vet, so use AutoRef only for plain data such as configuration structs.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*Server](
+//	    "InitializeServer",
+//	    kessoku.AutoRef(),
+//	    kessoku.Provide(LoadConfig), // func LoadConfig() Config
+//	    kessoku.Provide(NewServer),  // func NewServer(cfg *Config) *Server, called with &config
+//	)
+func AutoRef() autoRef {
+	return autoRef{}
+}
+
+// pooledProvider provides instances of T recycled through a sync.Pool.
+type pooledProvider[T any] struct {
+	fn func() T
+}
+
+// provide implements the provider interface.
+func (p pooledProvider[T]) provide() {}
+
+// Pooled provides a func() (T, func()) that acquires an instance of T from a package-level
+// sync.Pool, created with fn when the pool is empty, together with a function releasing it.
+//
+// Use it for short-lived objects on hot paths, such as buffers, to save an allocation per use.
+// The pool is declared next to the injector and shared by all of its calls. Acquiring and
+// releasing are safe for concurrent use. A released instance must not be used anymore, and an
+// acquired one keeps the state it was released with, so reset it before use. Like any
+// sync.Pool, the pool may drop idle instances at any time.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*Handler](
+//	    "InitializeHandler",
+//	    kessoku.Pooled[*bytes.Buffer](newBuffer), // func newBuffer() *bytes.Buffer
+//	    kessoku.Provide(NewHandler),              // func NewHandler(acquire func() (*bytes.Buffer, func())) *Handler
+//	)
+func Pooled[T any](fn func() T) pooledProvider[T] {
+	return pooledProvider[T]{fn: fn}
+}
+
+// ldFlag provides a package-level variable set with -ldflags.
+type ldFlag[T any] struct {
+	name string
+}
+
+// provide implements the provider interface.
+func (l ldFlag[T]) provide() {}
+
+// LDFlag provides the package-level variable name, given as "importpath.name" like the
+// -X flag of the Go linker, so that build metadata set with -ldflags can be injected.
+//
+// The generated injector reads the variable instead of inlining its value. The variable
+// must be of type T, which is string for variables set by the linker, and be declared in
+// the injector's package ("main" for package main) or exported by a package it imports.
+//
+// Example:
+//
+//	var version = "dev" // go build -ldflags "-X main.version=v1.2.3"
+//
+//	var _ = kessoku.Inject[*Server](
+//	    "InitializeServer",
+//	    kessoku.LDFlag[string]("main.version"),
+//	    kessoku.Provide(NewServer), // func NewServer(version string) *Server
+//	)
+func LDFlag[T any](name string) ldFlag[T] {
+	return ldFlag[T]{name: name}
+}
+
+// injectorNameProvider provides the name of the injector it is listed in.
+type injectorNameProvider struct{}
+
+// provide implements the provider interface.
+func (i injectorNameProvider) provide() {}
+
+// InjectorName provides the name of the generated injector as a string.
+//
+// The name is a constant emitted into each injector at generation time, so a set shared by
+// several injectors supplies each of them its own name. Use this to log which entrypoint built
+// a resource.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*Worker](
+//	    "InitializeWorker",
+//	    kessoku.InjectorName(),     // provides "InitializeWorker"
+//	    kessoku.Provide(NewLogger), // func NewLogger(injector string) *Logger
+//	    kessoku.Provide(NewWorker),
+//	)
+func InjectorName() injectorNameProvider {
+	return injectorNameProvider{}
+}
+
+// implementsOption makes the generated injector implement a method of the interface T.
+type implementsOption[T any] struct {
+	method string
+}
+
+// provide implements the provider interface.
+func (i implementsOption[T]) provide() {}
+
+// Implements makes kessoku also generate a type that implements the interface T by
+// calling the injector from its method named method.
+//
+// Use this to plug generated wiring into an existing factory abstraction. Generation fails
+// when the injector's signature does not match the method's.
+//
+// Example:
+//
+//	type AppFactory interface {
+//	    NewApp(ctx context.Context) (*App, error)
+//	}
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Implements[AppFactory]("NewApp"),
+//	    kessoku.Async(kessoku.Provide(NewDatabase)),
+//	    kessoku.Provide(NewApp),
+//	)
+//
+// This generates, next to InitializeApp:
+//
+//	type initializeAppImpl struct{}
+//
+//	func (initializeAppImpl) NewApp(ctx context.Context) (*App, error) {
+//	    return InitializeApp(ctx)
+//	}
+//
+//	var _ AppFactory = initializeAppImpl{}
+func Implements[T any](method string) implementsOption[T] {
+	return implementsOption[T]{method: method}
+}
+
+// argProvider declares an explicit injector argument of type T.
+type argProvider[T any] struct{}
+
+// provide implements the provider interface.
+func (a argProvider[T]) provide() {}
+
+// Arg declares that T is passed to the generated injector as a parameter.
+//
+// Missing dependencies already become parameters automatically; use Arg to document that
+// intent and to control the signature. Declared arguments come first, in declaration order,
+// followed by any auto-detected ones. A declared argument that no provider needs is kept
+// in the signature as an unnamed (_) parameter.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Arg[*Config](),
+//	    kessoku.Arg[*slog.Logger](),
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp(config *Config, logger *slog.Logger) *App
+func Arg[T any]() argProvider[T] {
+	return argProvider[T]{}
+}
+
+// argsProvider declares the command-line arguments as an injector argument.
+type argsProvider struct{}
+
+// provide implements the provider interface.
+func (a argsProvider) provide() {}
+
+// Args declares an args []string parameter of the generated injector for CLI-style apps.
+//
+// Every provider that needs a []string receives it, so command-line parsing can be part of
+// the dependency graph. Pass os.Args, or the part of it to parse, to the injector. Declaring
+// Args several times, such as in a set and in the injector using it, adds a single parameter.
+//
+// Example:
+//
+//	func NewFlags(args []string) (*Flags, error)
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Args(),
+//	    kessoku.Provide(NewFlags),
+//	    kessoku.Provide(NewApp),
+//	)
+//	// Generates: func InitializeApp(args []string) (*App, error)
+//	// Called as:  app, err := InitializeApp(os.Args[1:])
+func Args() argsProvider {
+	return argsProvider{}
+}
+
+// namedArg declares a named injector argument of type T.
+type namedArg[T any] struct {
+	name string
+}
+
+// provide implements the provider interface.
+func (n namedArg[T]) provide() {}
+
+// Named declares an injector argument of type T that is identified by name as well as type.
+//
+// Use this when several providers need different values of the same type, such as a
+// request-scoped context and a background context. A provider receives the named argument
+// when one of its parameters has the same name and type; other parameters are wired as usual.
+// The argument is added to the generated injector, under the given name, once a provider requests it.
+//
+// Example:
+//
+//	func NewHandler(request context.Context) *Handler   // receives the "request" argument
+//	func NewWorker(ctx context.Context) *Worker         // receives the regular context
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Named[context.Context]("request"),
+//	    kessoku.Provide(NewHandler),
+//	    kessoku.Async(kessoku.Provide(NewWorker)),
+//	    kessoku.Provide(NewApp),
+//	)
+//
+// A named context.Context is passed through as-is; it is never replaced by the
+// errgroup context that async providers use.
+func Named[T any](name string) namedArg[T] {
+	return namedArg[T]{name: name}
+}
+
+// useProvider selects the tagged provider of type T.
+type useProvider[T any] struct {
+	tag string
+}
+
+// provide implements the provider interface.
+func (u useProvider[T]) provide() {}
+
+// Use selects the provider of type T labeled with Tag(tag) for an injector.
+//
+// The other tagged providers of T are ignored, so a shared set can declare every
+// implementation and each injector picks one, e.g. per environment.
+//
+// Example:
+//
+//	var StorageSet = kessoku.Set(
+//	    kessoku.Provide(NewS3Storage, kessoku.Tag("s3")),   // func NewS3Storage() *Storage
+//	    kessoku.Provide(NewGCSStorage, kessoku.Tag("gcs")), // func NewGCSStorage() *Storage
+//	)
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    StorageSet,
+//	    kessoku.Use[*Storage]("s3"),
+//	    kessoku.Provide(NewApp),
+//	)
+func Use[T any](tag string) useProvider[T] {
+	return useProvider[T]{tag: tag}
+}
+
+// after orders the provider of B after the provider of A.
+type after[A, B any] struct{}
+
+// provide implements the provider interface.
+func (a after[A, B]) provide() {}
+
+// After makes the provider of B run after the provider of A returns, although B does not
+// take A as an argument.
+//
+// Use this for providers with side effects that must happen in order, such as seeding a
+// database after migrating it. Async providers wait for the completion of A like for a
+// dependency, and the value of A is not passed to B. A is created even if nothing else
+// requires it. Orderings that form a cycle with the dependencies are an error.
+//
+// Example:
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    kessoku.Async(kessoku.Provide(NewMigration)), // func NewMigration(db *DB) (*Migration, error)
+//	    kessoku.Async(kessoku.Provide(NewSeed)),      // func NewSeed(db *DB) (*Seed, error)
+//	    kessoku.After[*Migration, *Seed](),
+//	    kessoku.Provide(NewApp),
+//	)
+func After[A, B any]() after[A, B] {
+	return after[A, B]{}
+}
+
+type set struct{}
+
+func (s set) provide() {}
+
+// Set groups related providers into reusable bundles for better organization.
+//
+// Perfect for organizing providers by domain (database, auth, monitoring) and reusing
+// them across multiple injectors. Keeps your dependency declarations clean and DRY.
+//
+// Example:
+//
+//	var DatabaseSet = kessoku.Set(
+//	    kessoku.Provide(NewConfig),
+//	    kessoku.Async(kessoku.Provide(NewDatabase)),
+//	    kessoku.Provide(NewMigrator),
+//	)
+//
+//	// Reuse the set in multiple injectors
+//	var _ = kessoku.Inject[*App]("InitApp", DatabaseSet, kessoku.Provide(NewApp))
+func Set(providers ...provider) set {
+	return set{}
+}
+
+// GenericSet is a provider set parameterized by T, returned by a generic function.
+// Reference it by instantiating the function for a type, and kessoku expands the
+// providers with the function's type parameters replaced by the type arguments.
+type GenericSet[T any] struct{}
+
+// provide implements the provider interface.
+func (s GenericSet[T]) provide() {}
+
+// NewGenericSet groups providers that are specialized per type into a GenericSet.
+//
+// The enclosing function must be generic and consist of a single return statement,
+// so the same wiring can be reused for parallel type hierarchies.
+//
+// Example:
+//
+//	func RepositorySet[T any]() kessoku.GenericSet[T] {
+//	    return kessoku.NewGenericSet[T](
+//	        kessoku.Provide(NewRepository[T]),
+//	        kessoku.Provide(NewService[T]),
+//	    )
+//	}
+//
+//	var _ = kessoku.Inject[*App](
+//	    "InitializeApp",
+//	    RepositorySet[User](),
+//	    RepositorySet[Post](),
+//	    kessoku.Provide(NewApp),
+//	)
+func NewGenericSet[T any](providers ...provider) GenericSet[T] {
+	return GenericSet[T]{}
+}
+
+// structProvider marks a struct type for field expansion.
+// When used in an Inject declaration, all exported fields of T
+// become available as individual dependencies.
+type structProvider[T any] struct{}
+
+// provide implements the provider interface.
+func (s structProvider[T]) provide() {}
+
+// Fn returns a dummy function for type compatibility with funcProvider.
+// This allows structProvider to be wrapped with Async and Bind.
+// The returned function is never actually called - the code generator
+// recognizes structProvider and handles field expansion at compile time.
+func (s structProvider[T]) Fn() func() T {
+	return func() T {
+		var zero T
+		return zero
+	}
+}
+
+// Struct returns a provider that expands all exported fields of T
+// as individual dependencies for injection.
+//
+// T must be a struct type or pointer to struct type.
+// All exported fields of T become available as injectable dependencies.
+// Unexported fields are ignored.
+//
+// Fields holding a struct by value are expanded recursively, so the fields of nested
+// configuration structs are injectable too. A nested field is skipped when its type is
+// already provided or is shared by several nested fields at the same depth. Pointer
+// fields are not expanded because they may be nil.
 //
 // The struct type T must be provided by another provider in the same
 // Inject call (e.g., via Provide). Struct only expands fields; it does
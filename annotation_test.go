@@ -2,6 +2,10 @@ package kessoku_test
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
 
 	"github.com/mazrean/kessoku"
 )
@@ -93,6 +97,22 @@ func ExampleValue() {
 	// Output: Generated InitializeServer function using Value
 }
 
+func TestValueE(t *testing.T) {
+	t.Parallel()
+
+	d, err := kessoku.ValueE(time.ParseDuration("30s")).Fn()()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d != 30*time.Second {
+		t.Errorf("Expected 30s, got %v", d)
+	}
+
+	if _, err := kessoku.ValueE(time.ParseDuration("invalid")).Fn()(); err == nil {
+		t.Error("Expected the error of the expression to be returned")
+	}
+}
+
 // ExampleSet demonstrates grouping providers into reusable sets.
 func ExampleSet() {
 	// Database-related providers
@@ -118,6 +138,96 @@ func ExampleSet() {
 	// Output: Generated InitializeUserService using DatabaseSet
 }
 
+// ExampleEnv demonstrates injecting values read from environment variables.
+func ExampleEnv() {
+	type DatabaseURL string
+
+	NewServer := func(url DatabaseURL, port int, debug bool) *Server {
+		return &Server{DatabaseURL: string(url), Port: port, Debug: debug}
+	}
+
+	var _ = kessoku.Inject[*Server](
+		"InitializeServer",
+		kessoku.Env[DatabaseURL]("DATABASE_URL"),
+		kessoku.Env[int]("PORT"),
+		kessoku.Env[bool]("DEBUG"),
+		kessoku.Provide(NewServer),
+	)
+
+	// This generates a function:
+	// func InitializeServer() (*Server, error)
+	fmt.Println("Generated InitializeServer reading environment variables")
+	// Output: Generated InitializeServer reading environment variables
+}
+
+func ExampleClock() {
+	type TokenIssuer struct {
+		now kessoku.Now
+	}
+
+	NewTokenIssuer := func(now kessoku.Now) *TokenIssuer {
+		return &TokenIssuer{now: now}
+	}
+
+	// Production injector uses time.Now
+	var _ = kessoku.Inject[*TokenIssuer](
+		"InitializeTokenIssuer",
+		kessoku.Clock(),
+		kessoku.Provide(NewTokenIssuer),
+	)
+
+	// Test injector takes the clock as an argument
+	var _ = kessoku.Inject[*TokenIssuer](
+		"InitializeTestTokenIssuer",
+		kessoku.Arg[kessoku.Now](),
+		kessoku.Provide(NewTokenIssuer),
+	)
+
+	// This generates functions:
+	// func InitializeTokenIssuer() *TokenIssuer
+	// func InitializeTestTokenIssuer(now kessoku.Now) *TokenIssuer
+	fmt.Println("Generated InitializeTokenIssuer with an injectable clock")
+	// Output: Generated InitializeTokenIssuer with an injectable clock
+}
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	now := kessoku.Clock().Fn()()
+	if now == nil {
+		t.Fatal("Expected Clock to provide a clock")
+	}
+
+	before := time.Now()
+	got := now()
+	after := time.Now()
+	if got.Before(before) || got.After(after) {
+		t.Errorf("Expected the current time between %v and %v, got %v", before, after, got)
+	}
+}
+
+// ExampleHTTPClient demonstrates injecting a configured *http.Client.
+func ExampleHTTPClient() {
+	type APIClient struct {
+		client *http.Client
+	}
+
+	NewAPIClient := func(client *http.Client) *APIClient {
+		return &APIClient{client: client}
+	}
+
+	var _ = kessoku.Inject[*APIClient](
+		"InitializeAPIClient",
+		kessoku.HTTPClient(kessoku.HTTPTimeout(10*time.Second)),
+		kessoku.Provide(NewAPIClient),
+	)
+
+	// This generates function:
+	// func InitializeAPIClient() *APIClient
+	fmt.Println("Generated InitializeAPIClient with a built-in HTTP client")
+	// Output: Generated InitializeAPIClient with a built-in HTTP client
+}
+
 // Example types for documentation
 type (
 	Config          struct{}
@@ -137,3 +247,23 @@ type (
 		Debug       bool
 	}
 )
+
+func TestWithChannelTrace(t *testing.T) {
+	t.Parallel()
+
+	var events []string
+	trace := kessoku.WithChannelTrace(func(injector, event, channel string) {
+		events = append(events, injector+" "+event+" "+channel)
+	})
+
+	trace.Trace("InitializeApp", "wait", "configCh")
+	trace.Trace("InitializeApp", "ready", "configCh")
+
+	expected := []string{"InitializeApp wait configCh", "InitializeApp ready configCh"}
+	if strings.Join(events, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+
+	// Without tracers, events go to slog.Debug
+	kessoku.WithChannelTrace().Trace("InitializeApp", "close", "configCh")
+}
@@ -4,10 +4,15 @@ go 1.25.0
 
 require (
 	github.com/alecthomas/kong v1.15.0
+	github.com/alecthomas/kong-yaml v0.2.0
+	golang.org/x/mod v0.36.0
 	golang.org/x/sync v0.22.0
 	golang.org/x/tools v0.45.0
 )
 
-require golang.org/x/mod v0.36.0 // indirect
+require (
+	github.com/kr/text v0.2.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
 
 tool github.com/mazrean/kessoku/cmd/kessoku
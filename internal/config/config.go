@@ -2,12 +2,17 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"go/token"
 	"log/slog"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/alecthomas/kong"
+	kongyaml "github.com/alecthomas/kong-yaml"
 	"github.com/mazrean/kessoku/internal/kessoku"
 	"github.com/mazrean/kessoku/internal/llmsetup"
 	"github.com/mazrean/kessoku/internal/migrate"
@@ -19,53 +24,330 @@ var (
 	date    = "unknown"
 )
 
+// configFileName is the project configuration file looked up at the module root.
+// It sets defaults for flags, which are still overridden by the command line.
+const configFileName = ".kessoku.yaml"
+
 // CLI is the root command configuration with subcommands.
 type CLI struct {
 	LogLevel string               `kong:"short='l',help='Log level',enum='debug,info,warn,error',default='info'"`
-	Generate GenerateCmd          `kong:"cmd,default='withargs',help='Generate DI code (default)'"`
+	Generate *GenerateCmd         `kong:"cmd,default='withargs',help='Generate DI code (default)'"`
+	Validate ValidateCmd          `kong:"cmd,help='Check injector wiring without generating code'"`
 	Migrate  MigrateCmd           `kong:"cmd,help='Migrate wire config to kessoku'"`
 	LLMSetup llmsetup.LLMSetupCmd `kong:"cmd,name='llm-setup',help='Setup coding agent skills'"`
 	Version  kong.VersionFlag     `kong:"short='v',help='Show version and exit.'"`
+	Quiet    bool                 `kong:"short='q',help='Only log errors, overriding --log-level'"`
 }
 
 // GenerateCmd is the default command for generating DI code.
 type GenerateCmd struct {
-	Files []string `kong:"arg,help='Go files to process'"`
+	VarNames       map[string]string `kong:"name='var-name',help='Override generated variable names by type (e.g. *database/sql.DB=db)'"`
+	ImportAliases  map[string]string `kong:"name='import-alias',help='Name the imports of the generated code by package path (e.g. database/sql=stdsql)'"`
+	LocalPrefix    string            `kong:"name='local-prefix',help='Import path prefix grouped as local imports (defaults to the module path)'"`
+	OutputSuffix   string            `kong:"name='output-suffix',default='_band',help='Suffix of the generated file names (e.g. kessoku_band.go for kessoku.go)'"`
+	Tags           []string          `kong:"name='tags',help='Build tags to load the packages with (e.g. integration)'"`
+	ReportFormat   string            `kong:"name='report-format',enum='table,json',default='table',help='Format of the report printed by --report'"`
+	Formatter      []string          `kong:"name='formatter',sep='none',help='Command the generated code is piped through before writing, followed by its arguments in repeated flags (e.g. --formatter=gofumpt --formatter=-extra)'"`
+	Files          []string          `kong:"arg,optional,help='Go files to process (defaults to $GOFILE or the files importing kessoku in the current directory)'"`
+	MaxNodes       int               `kong:"name='max-nodes',help='Fail if an injector graph has more than this many nodes (0 disables the check)'"`
+	AsyncThreshold int               `kong:"name='async-threshold',help='Generate injectors with fewer providers than this sequentially (0 disables the threshold)'"`
+	WarnUnusedArgs bool              `kong:"name='warn-unused-args',help='Warn about injector arguments that are not used by any provider'"`
+	EmitRegistry   bool              `kong:"name='emit-registry',help='Also generate a map of injector names to injector functions for each package'"`
+	EmitInspector  bool              `kong:"name='emit-inspector',help='Also generate a function returning every value constructed by each injector, for debugging'"`
+	NamedReturns   bool              `kong:"name='named-returns',help='Name the results of the generated injectors after their types'"`
+	StampVersion   bool              `kong:"name='stamp-version',help='Add a comment with the kessoku version and a hash of the generated code below the generated header'"`
+	StampTime      bool              `kong:"name='stamp-time',help='Stamp the generation time instead of the hash of the generated code (requires --stamp-version)'"`
+	Diff           bool              `kong:"name='diff',help='Print a diff against the generated files instead of writing them, failing if they differ'"`
+	NoAsync        bool              `kong:"name='no-async',help='Generate providers marked with kessoku.Async sequentially'"`
+	StrictTypes    bool              `kong:"name='strict-types',help='Match the types of every injector by identity, as if declared with kessoku.StrictTypeIdentity'"`
+	Report         bool              `kong:"name='report',help='Print complexity metrics of each injector graph'"`
+	Describe       bool              `kong:"name='describe',help='Print the arguments of each generated injector as JSON'"`
+	SkipBroken     bool              `kong:"name='skip-broken',help='Skip files with syntax errors instead of failing, listing them at the end'"`
+	Cache          bool              `kong:"name='cache',help='Skip generation when the files and the packages they depend on are unchanged since the last run'"`
+	Stdout         bool              `kong:"name='stdout',help='Write the generated code to stdout instead of a file (requires a single file)'"`
 }
 
 // Run executes the generate command.
 func (c *GenerateCmd) Run(cli *CLI) error {
-	setupLogger(cli.LogLevel)
+	setupLogger(cli.logLevel())
 
 	if len(c.Files) == 0 {
-		return fmt.Errorf("no files specified")
+		files, err := defaultFiles(".")
+		if err != nil {
+			return err
+		}
+		c.Files = files
+	}
+
+	if c.Stdout {
+		switch {
+		case len(c.Files) > 1:
+			return fmt.Errorf("--stdout requires a single file, got %d", len(c.Files))
+		case c.Diff:
+			return fmt.Errorf("--stdout cannot be used with --diff")
+		case c.EmitRegistry:
+			return fmt.Errorf("--stdout cannot be used with --emit-registry")
+		case c.Report:
+			return fmt.Errorf("--stdout cannot be used with --report")
+		case c.Describe:
+			return fmt.Errorf("--stdout cannot be used with --describe")
+		}
+	}
+	if c.Describe && c.Report {
+		return fmt.Errorf("--describe cannot be used with --report")
+	}
+	if c.StampTime && !c.StampVersion {
+		return fmt.Errorf("--stamp-time requires --stamp-version")
+	}
+
+	for typeName, varName := range c.VarNames {
+		if !token.IsIdentifier(varName) {
+			return fmt.Errorf("invalid variable name %q for type %s", varName, typeName)
+		}
+	}
+	for path, alias := range c.ImportAliases {
+		if !token.IsIdentifier(alias) {
+			return fmt.Errorf("invalid import alias %q for package %s", alias, path)
+		}
+	}
+	if c.OutputSuffix == "" || strings.ContainsAny(c.OutputSuffix, `/\`) || strings.HasSuffix(c.OutputSuffix, "_test") {
+		return fmt.Errorf("invalid output suffix %q", c.OutputSuffix)
 	}
 
 	slog.Info("Generating dependency injection code", "files", c.Files)
 
-	processor := kessoku.NewProcessor()
+	opts := []kessoku.ProcessorOption{kessoku.WithTypeVarNames(c.VarNames), kessoku.WithOutputSuffix(c.OutputSuffix)}
+	if len(c.Tags) > 0 {
+		opts = append(opts, kessoku.WithBuildTags(c.Tags...))
+	}
+	if len(c.ImportAliases) > 0 {
+		opts = append(opts, kessoku.WithImportAliases(c.ImportAliases))
+	}
+	if c.StrictTypes {
+		opts = append(opts, kessoku.WithStrictTypes())
+	}
+	if c.WarnUnusedArgs {
+		opts = append(opts, kessoku.WithUnusedArgWarnings())
+	}
+	if c.EmitRegistry {
+		opts = append(opts, kessoku.WithRegistry())
+	}
+	if c.EmitInspector {
+		opts = append(opts, kessoku.WithInspector())
+	}
+	if c.NamedReturns {
+		opts = append(opts, kessoku.WithNamedReturns())
+	}
+	if c.StampVersion {
+		opts = append(opts, kessoku.WithVersionStamp(version, c.StampTime))
+	}
+	if c.LocalPrefix != "" {
+		opts = append(opts, kessoku.WithLocalImportPrefix(c.LocalPrefix))
+	}
+	if c.SkipBroken {
+		opts = append(opts, kessoku.WithSkipBrokenFiles())
+	}
+	if c.NoAsync {
+		opts = append(opts, kessoku.WithoutAsync())
+	}
+	if c.Report {
+		opts = append(opts, kessoku.WithReport(os.Stdout, kessoku.ReportFormat(c.ReportFormat)))
+	}
+	if c.Describe {
+		opts = append(opts, kessoku.WithDescribe(os.Stdout))
+	}
+	if c.AsyncThreshold > 0 {
+		opts = append(opts, kessoku.WithAsyncThreshold(c.AsyncThreshold))
+	}
+	if c.MaxNodes > 0 {
+		opts = append(opts, kessoku.WithMaxNodes(c.MaxNodes))
+	}
+	if c.Diff {
+		opts = append(opts, kessoku.WithDiff(os.Stdout))
+	}
+	if c.Stdout {
+		opts = append(opts, kessoku.WithOutput(os.Stdout))
+	}
+	if len(c.Formatter) > 0 {
+		opts = append(opts, kessoku.WithFormatter(c.Formatter[0], c.Formatter[1:]...))
+	}
+	if c.Cache {
+		dir, err := goBuildCacheDir()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, kessoku.WithCache(dir, cacheVersion()))
+	}
+
+	processor := kessoku.NewProcessor(opts...)
 	return processor.ProcessFiles(c.Files)
 }
 
+// goBuildCacheDir returns the Go build cache directory, which the generation cache is stored in
+// so that go clean -cache clears it too.
+func goBuildCacheDir() (string, error) {
+	out, err := exec.Command("go", "env", "GOCACHE").Output()
+	if err != nil {
+		return "", fmt.Errorf("resolve Go build cache directory: %w", err)
+	}
+
+	dir := strings.TrimSpace(string(out))
+	if dir == "" || dir == "off" {
+		return "", fmt.Errorf("--cache requires the Go build cache, but GOCACHE is %q", dir)
+	}
+
+	return dir, nil
+}
+
+// cacheVersion identifies the kessoku build, invalidating the generation cache when it changes.
+// Development builds have no version, so the executable itself identifies them.
+func cacheVersion() string {
+	v := version + " " + commit
+	if version != "dev" {
+		return v
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		if info, err := os.Stat(exe); err == nil {
+			v += fmt.Sprintf(" %s %d %d", exe, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+
+	return v
+}
+
+// defaultFiles returns the files to process when none are given: the file running
+// the go:generate directive (GOFILE), or else the files in dir that import kessoku.
+func defaultFiles(dir string) ([]string, error) {
+	if gofile := os.Getenv("GOFILE"); gofile != "" {
+		return []string{filepath.Join(dir, gofile)}, nil
+	}
+
+	files, err := kessoku.FindInjectorFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("find injector files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files specified and no files importing kessoku found in %s", dir)
+	}
+
+	return files, nil
+}
+
+// ValidateCmd is the command for checking injectors without generating code.
+type ValidateCmd struct {
+	Files []string `kong:"arg,optional,help='Go files to check (defaults to $GOFILE or the files importing kessoku in the current directory)'"`
+}
+
+// Run executes the validate command.
+func (c *ValidateCmd) Run(cli *CLI) error {
+	setupLogger(cli.logLevel())
+
+	if len(c.Files) == 0 {
+		files, err := defaultFiles(".")
+		if err != nil {
+			return err
+		}
+		c.Files = files
+	}
+
+	slog.Info("Validating injectors", "files", c.Files)
+
+	diagnostics := kessoku.NewProcessor().ValidateFiles(c.Files)
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(os.Stderr, withSuggestions(diagnostic))
+	}
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("found %d wiring errors", len(diagnostics))
+	}
+
+	return nil
+}
+
 // MigrateCmd is the command for migrating wire files to kessoku format.
 type MigrateCmd struct {
-	Output   string   `kong:"short='o',default='kessoku.go',help='Output file path'"`
+	Output   string   `kong:"short='o',default='kessoku.go',help='Output file path (the file name written into each package with --project)'"`
 	Patterns []string `kong:"arg,optional,help='Go package patterns to migrate',default='./'"`
+	Project  bool     `kong:"name='project',help='Migrate each package importing wire separately, writing the output file into its directory'"`
 }
 
 // Run executes the migrate command.
 func (c *MigrateCmd) Run(cli *CLI) error {
-	setupLogger(cli.LogLevel)
+	setupLogger(cli.logLevel())
 
 	slog.Info("Migrating wire configuration", "patterns", c.Patterns)
 
 	migrator := migrate.NewMigrator()
-	return migrator.MigrateFiles(c.Patterns, c.Output)
+	if !c.Project {
+		if err := migrator.MigrateFiles(c.Patterns, c.Output); err != nil {
+			return err
+		}
+		return migrate.WriteUnsupportedReport(os.Stderr, migrator.Unsupported())
+	}
+
+	migrations, err := migrator.MigrateProject(c.Patterns, c.Output)
+	if err != nil {
+		return err
+	}
+	if err := migrate.WriteUnsupportedReport(os.Stderr, migrator.Unsupported()); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, migration := range migrations {
+		switch {
+		case migration.Err != nil:
+			failed++
+			slog.Error("Failed to migrate package", "package", migration.PkgPath, "error", migration.Err)
+		case migration.Output == "":
+			slog.Info("No wire patterns found in package", "package", migration.PkgPath)
+		default:
+			slog.Info("Migrated package", "package", migration.PkgPath, "output", migration.Output)
+		}
+	}
+
+	slog.Info("Project migration finished", "packages", len(migrations), "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to migrate %d of %d packages", failed, len(migrations))
+	}
+
+	return nil
 }
 
 func Run() error {
 	var cli CLI
-	kongCtx := kong.Parse(&cli,
+	parser, err := newParser(&cli, filepath.Join(moduleRoot(), configFileName))
+	if err != nil {
+		return err
+	}
+
+	kongCtx, err := parser.Parse(os.Args[1:])
+	parser.FatalIfErrorf(err)
+
+	return withSuggestions(kongCtx.Run(&cli))
+}
+
+// withSuggestions appends the providers suggested for a missing provider reported by err to its message,
+// so that a wiring failure points at the constructor that was likely forgotten.
+func withSuggestions(err error) error {
+	var missing *kessoku.MissingProviderError
+	if !errors.As(err, &missing) || len(missing.Suggestions) == 0 {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, suggestion := range missing.Suggestions {
+		sb.WriteString("\n\tdid you forget ")
+		sb.WriteString(suggestion)
+		sb.WriteString("?")
+	}
+
+	return fmt.Errorf("%w%s", err, sb.String())
+}
+
+// newParser creates the command line parser, reading flag defaults from the configuration files that exist.
+func newParser(cli *CLI, configPaths ...string) (*kong.Kong, error) {
+	parser, err := kong.New(cli,
 		kong.Name("kessoku"),
 		kong.Description("A dependency injection code generator for Go, similar to google/wire"),
 		kong.UsageOnError(),
@@ -75,9 +357,43 @@ func Run() error {
 		kong.Vars{
 			"version": fmt.Sprintf("%s (%s) released on %s", version, commit, date),
 		},
+		kong.Configuration(kongyaml.Loader, configPaths...),
 	)
+	if err != nil {
+		return nil, fmt.Errorf("create command line parser: %w", err)
+	}
+
+	return parser, nil
+}
+
+// moduleRoot returns the nearest directory containing go.mod, starting from the working directory.
+// It falls back to the working directory outside a module.
+func moduleRoot() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+
+	for dir := wd; ; {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return wd
+		}
+		dir = parent
+	}
+}
+
+// logLevel returns the log level selected by the flags.
+func (c *CLI) logLevel() string {
+	if c.Quiet {
+		return "error"
+	}
 
-	return kongCtx.Run(&cli)
+	return c.LogLevel
 }
 
 func setupLogger(level string) {
@@ -0,0 +1,335 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mazrean/kessoku/internal/kessoku"
+)
+
+func TestLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cli      CLI
+		expected slog.Level
+	}{
+		{
+			name:     "default",
+			cli:      CLI{LogLevel: "info"},
+			expected: slog.LevelInfo,
+		},
+		{
+			name:     "log level flag",
+			cli:      CLI{LogLevel: "debug"},
+			expected: slog.LevelDebug,
+		},
+		{
+			name:     "quiet",
+			cli:      CLI{LogLevel: "info", Quiet: true},
+			expected: slog.LevelError,
+		},
+		{
+			name:     "quiet overrides log level",
+			cli:      CLI{LogLevel: "debug", Quiet: true},
+			expected: slog.LevelError,
+		},
+		{
+			name:     "unknown level",
+			cli:      CLI{LogLevel: "verbose"},
+			expected: slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if level := parseLogLevel(tt.cli.logLevel()); level != tt.expected {
+				t.Errorf("Expected log level %s, got %s", tt.expected, level)
+			}
+		})
+	}
+}
+
+func TestGenerateCmdStdoutFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		cmd           GenerateCmd
+		errorContains string
+	}{
+		{
+			name:          "multiple files",
+			cmd:           GenerateCmd{Files: []string{"a.go", "b.go"}, Stdout: true},
+			errorContains: "--stdout requires a single file, got 2",
+		},
+		{
+			name:          "with diff",
+			cmd:           GenerateCmd{Files: []string{"a.go"}, Stdout: true, Diff: true},
+			errorContains: "--stdout cannot be used with --diff",
+		},
+		{
+			name:          "with registry",
+			cmd:           GenerateCmd{Files: []string{"a.go"}, Stdout: true, EmitRegistry: true},
+			errorContains: "--stdout cannot be used with --emit-registry",
+		},
+		{
+			name:          "with report",
+			cmd:           GenerateCmd{Files: []string{"a.go"}, Stdout: true, Report: true},
+			errorContains: "--stdout cannot be used with --report",
+		},
+		{
+			name:          "with describe",
+			cmd:           GenerateCmd{Files: []string{"a.go"}, Stdout: true, Describe: true},
+			errorContains: "--stdout cannot be used with --describe",
+		},
+		{
+			name:          "stamp time without stamp version",
+			cmd:           GenerateCmd{Files: []string{"a.go"}, StampTime: true},
+			errorContains: "--stamp-time requires --stamp-version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.cmd.Run(&CLI{LogLevel: "error"})
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestNewParser_ConfigFile(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), configFileName)
+	config := `quiet: true
+generate:
+  local-prefix: example.com/config
+  no-async: true
+  max-nodes: 10
+  var-name:
+    "*database/sql.DB": db
+  import-alias:
+    database/sql: stdsql
+  output-suffix: _gen
+  strict-types: true
+  tags:
+    - integration
+    - e2e
+  formatter:
+    - gofumpt
+    - -extra
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		localPrefix  string
+		outputSuffix string
+		tags         []string
+		formatter    []string
+		maxNodes     int
+	}{
+		{
+			name:         "config defaults",
+			args:         []string{"main.go"},
+			localPrefix:  "example.com/config",
+			outputSuffix: "_gen",
+			tags:         []string{"integration", "e2e"},
+			formatter:    []string{"gofumpt", "-extra"},
+			maxNodes:     10,
+		},
+		{
+			name:         "flags override config",
+			args:         []string{"--local-prefix=example.com/flag", "--max-nodes=20", "--output-suffix=_wire", "--tags=unit", "--formatter=gofmt", "--formatter=-r=a,b", "main.go"},
+			localPrefix:  "example.com/flag",
+			outputSuffix: "_wire",
+			tags:         []string{"unit"},
+			formatter:    []string{"gofmt", "-r=a,b"},
+			maxNodes:     20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cli CLI
+			parser, err := newParser(&cli, configPath)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			if _, err := parser.Parse(tt.args); err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+
+			if !cli.Quiet {
+				t.Error("Expected quiet to be set from config file")
+			}
+			if cli.Generate == nil {
+				t.Fatal("Expected generate command to be selected")
+			}
+			if cli.Generate.LocalPrefix != tt.localPrefix {
+				t.Errorf("Expected local prefix %q, got %q", tt.localPrefix, cli.Generate.LocalPrefix)
+			}
+			if cli.Generate.MaxNodes != tt.maxNodes {
+				t.Errorf("Expected max nodes %d, got %d", tt.maxNodes, cli.Generate.MaxNodes)
+			}
+			if !cli.Generate.NoAsync {
+				t.Error("Expected no-async to be set from config file")
+			}
+			if got := cli.Generate.VarNames["*database/sql.DB"]; got != "db" {
+				t.Errorf("Expected var name %q, got %q", "db", got)
+			}
+			if got := cli.Generate.ImportAliases["database/sql"]; got != "stdsql" {
+				t.Errorf("Expected import alias %q, got %q", "stdsql", got)
+			}
+			if cli.Generate.OutputSuffix != tt.outputSuffix {
+				t.Errorf("Expected output suffix %q, got %q", tt.outputSuffix, cli.Generate.OutputSuffix)
+			}
+			if !slices.Equal(cli.Generate.Tags, tt.tags) {
+				t.Errorf("Expected tags %v, got %v", tt.tags, cli.Generate.Tags)
+			}
+			if !slices.Equal(cli.Generate.Formatter, tt.formatter) {
+				t.Errorf("Expected formatter %v, got %v", tt.formatter, cli.Generate.Formatter)
+			}
+			if !cli.Generate.StrictTypes {
+				t.Error("Expected strict-types to be set from config file")
+			}
+		})
+	}
+}
+
+func TestNewParser_MissingConfigFile(t *testing.T) {
+	t.Parallel()
+
+	var cli CLI
+	parser, err := newParser(&cli, filepath.Join(t.TempDir(), configFileName))
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	if _, err := parser.Parse([]string{"main.go"}); err != nil {
+		t.Fatalf("Failed to parse args: %v", err)
+	}
+
+	if cli.LogLevel != "info" {
+		t.Errorf("Expected default log level %q, got %q", "info", cli.LogLevel)
+	}
+	if cli.Generate == nil || cli.Generate.LocalPrefix != "" {
+		t.Errorf("Expected empty local prefix, got %+v", cli.Generate)
+	}
+	if cli.Generate != nil && cli.Generate.OutputSuffix != "_band" {
+		t.Errorf("Expected default output suffix %q, got %q", "_band", cli.Generate.OutputSuffix)
+	}
+}
+
+func TestDefaultFiles(t *testing.T) {
+	dir := t.TempDir()
+	injectorFile := filepath.Join(dir, "kessoku.go")
+	if err := os.WriteFile(injectorFile, []byte("package main\n\nimport \"github.com/mazrean/kessoku\"\n\nvar _ = kessoku.Provide\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write injector file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write main file: %v", err)
+	}
+
+	t.Run("go generate", func(t *testing.T) {
+		// go generate runs in the package directory with GOFILE set to the file containing the directive
+		t.Setenv("GOFILE", "main.go")
+
+		files, err := defaultFiles(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := filepath.Join(dir, "main.go"); len(files) != 1 || files[0] != expected {
+			t.Errorf("Expected files [%s], got %v", expected, files)
+		}
+	})
+
+	t.Run("scan directory", func(t *testing.T) {
+		t.Setenv("GOFILE", "")
+
+		files, err := defaultFiles(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(files) != 1 || files[0] != injectorFile {
+			t.Errorf("Expected files [%s], got %v", injectorFile, files)
+		}
+	})
+
+	t.Run("no injector files", func(t *testing.T) {
+		t.Setenv("GOFILE", "")
+
+		_, err := defaultFiles(t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), "no files importing kessoku found") {
+			t.Errorf("Expected no injector files error, got %v", err)
+		}
+	})
+}
+
+func TestWithSuggestions(t *testing.T) {
+	t.Parallel()
+
+	missing := &kessoku.MissingProviderError{
+		Type:        types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, "DB", nil), types.NewStruct(nil, nil), nil)),
+		Usage:       "ordered with After",
+		Suggestions: []string{"kessoku.Provide(NewDB)", "kessoku.Provide(OpenDB)"},
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "missing provider",
+			err:      fmt.Errorf("create injector: %w", missing),
+			expected: "create injector: no provider of *DB ordered with After\n\tdid you forget kessoku.Provide(NewDB)?\n\tdid you forget kessoku.Provide(OpenDB)?",
+		},
+		{
+			name:     "without suggestions",
+			err:      &kessoku.MissingProviderError{Type: missing.Type, Usage: "ordered with After"},
+			expected: "no provider of *DB ordered with After",
+		},
+		{
+			name:     "other error",
+			err:      errors.New("parse file"),
+			expected: "parse file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := withSuggestions(tt.err)
+			if err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %q", tt.expected, err.Error())
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected the error to wrap %v", tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package kessoku
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// generationCache records the hashes of the sources and outputs of previous runs, so that a run
+// whose sources are unchanged can skip generation as long as its outputs are intact.
+//
+// Variable names are allocated across all files of a run, so the files of a run are regenerated
+// together: a run is skipped only if none of its files changed. go generate runs kessoku for each
+// file separately, which makes this a per-file cache there.
+type generationCache struct {
+	dir     string
+	version string
+}
+
+// cacheEntry is the state of the sources and outputs after a run of a set of files.
+type cacheEntry struct {
+	Fingerprint string            `json:"fingerprint"` // kessoku version, Go version, and generation options
+	Sources     map[string]string `json:"sources"`     // Hashes of the files that the generated code depends on
+	Outputs     map[string]string `json:"outputs"`     // Hashes of the generated files
+}
+
+// cacheRun is a run of ProcessFiles looked up in the cache.
+type cacheRun struct {
+	file    string
+	key     string
+	entry   *cacheEntry
+	entries map[string]*cacheEntry
+}
+
+// lookup returns the run of files and whether its cached outputs are still up to date.
+// Files with the output suffix are generated, so they are not sources of the run.
+func (c *generationCache) lookup(files []string, fingerprint, outputSuffix string) (*cacheRun, bool, error) {
+	absFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		absFile, err := filepath.Abs(file)
+		if err != nil {
+			return nil, false, fmt.Errorf("resolve path of %s: %w", file, err)
+		}
+		absFiles = append(absFiles, absFile)
+	}
+	slices.Sort(absFiles)
+
+	sources, moduleRoot, err := collectSources(absFiles, outputSuffix)
+	if err != nil {
+		return nil, false, err
+	}
+
+	run := &cacheRun{
+		file: filepath.Join(c.dir, "kessoku", hashString(moduleRoot)[:16]+".json"),
+		key:  strings.Join(absFiles, "\n"),
+		entry: &cacheEntry{
+			Fingerprint: hashString(c.version + "\n" + runtime.Version() + "\n" + fingerprint),
+			Sources:     sources,
+		},
+		entries: make(map[string]*cacheEntry),
+	}
+
+	data, err := os.ReadFile(run.file)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return run, false, nil
+	case err != nil:
+		return nil, false, fmt.Errorf("read cache %s: %w", run.file, err)
+	}
+	if err := json.Unmarshal(data, &run.entries); err != nil {
+		// A corrupted cache only costs a regeneration
+		slog.Warn("Ignoring invalid generation cache", "file", run.file, "error", err)
+		run.entries = make(map[string]*cacheEntry)
+		return run, false, nil
+	}
+
+	cached, ok := run.entries[run.key]
+	if !ok || cached.Fingerprint != run.entry.Fingerprint || !maps.Equal(cached.Sources, run.entry.Sources) {
+		return run, false, nil
+	}
+	for output, hash := range cached.Outputs {
+		if current, err := hashFile(output); err != nil || current != hash {
+			return run, false, nil
+		}
+	}
+
+	return run, true, nil
+}
+
+// store records the hashes of the outputs of run that exist.
+func (c *generationCache) store(run *cacheRun, outputs []string) error {
+	run.entry.Outputs = make(map[string]string)
+	for _, output := range outputs {
+		absOutput, err := filepath.Abs(output)
+		if err != nil {
+			return fmt.Errorf("resolve path of %s: %w", output, err)
+		}
+
+		hash, err := hashFile(absOutput)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		run.entry.Outputs[absOutput] = hash
+	}
+	run.entries[run.key] = run.entry
+
+	data, err := json.Marshal(run.entries)
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(run.file), 0755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	// Concurrent go generate runs must never observe a partially written cache
+	tmp, err := os.CreateTemp(filepath.Dir(run.file), filepath.Base(run.file)+".*")
+	if err != nil {
+		return fmt.Errorf("create cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), run.file); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	return nil
+}
+
+// collectSources hashes the files that code generated from files depends on: the Go files of their
+// packages and of the packages of the same module they import, directly or not, and go.mod and
+// go.sum for the other packages. It also returns the module root, or the directory of the first
+// file outside of a module.
+func collectSources(files []string, outputSuffix string) (map[string]string, string, error) {
+	sources := make(map[string]string)
+
+	moduleRoot, modulePath := findModule(filepath.Dir(files[0]))
+	if moduleRoot == "" {
+		moduleRoot = filepath.Dir(files[0])
+	} else {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			filename := filepath.Join(moduleRoot, name)
+			hash, err := hashFile(filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			sources[filename] = hash
+		}
+	}
+
+	dirs := make([]string, 0, len(files))
+	for _, file := range files {
+		dirs = append(dirs, filepath.Dir(file))
+	}
+	visited := make(map[string]bool)
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+		if visited[dir] {
+			continue
+		}
+		visited[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, "", fmt.Errorf("read directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || filepath.Ext(name) != ".go" || isGeneratedOutput(name, outputSuffix) {
+				continue
+			}
+
+			filename := filepath.Join(dir, name)
+			hash, err := hashFile(filename)
+			if err != nil {
+				return nil, "", err
+			}
+			sources[filename] = hash
+
+			if modulePath == "" {
+				continue
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ImportsOnly)
+			if err != nil {
+				// The parser reports syntax errors once generation runs
+				continue
+			}
+			for _, imp := range file.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err != nil {
+					continue
+				}
+				if rel, ok := strings.CutPrefix(path, modulePath); ok && (rel == "" || strings.HasPrefix(rel, "/")) {
+					dirs = append(dirs, filepath.Join(moduleRoot, filepath.FromSlash(rel)))
+				}
+			}
+		}
+	}
+
+	return sources, moduleRoot, nil
+}
+
+// findModule returns the root directory and path of the module containing dir, if any.
+func findModule(dir string) (string, string) {
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return dir, modfile.ModulePath(data)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// isGeneratedOutput reports whether name is a file written by kessoku,
+// which changes on every regeneration and is not a source of the generated code.
+func isGeneratedOutput(name, outputSuffix string) bool {
+	return name == registryFileName(outputSuffix) || strings.HasSuffix(name, outputSuffix+".go") || strings.HasSuffix(name, outputSuffix+"_test.go")
+}
+
+func hashFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("read file %s: %w", filename, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,69 @@
+package kessoku
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCollectSources(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                    "module example.com/app\n\ngo 1.25\n",
+		"go.sum":                    "",
+		"cmd/app/kessoku.go":        "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/service\"\n)\n",
+		"cmd/app/kessoku_band.go":   "package main\n",
+		"cmd/app/app.go":            "package main\n",
+		"service/service.go":        "package service\n\nimport \"example.com/app/internal/db\"\n",
+		"service/service_test.go":   "package service\n",
+		"internal/db/db.go":         "package db\n",
+		"internal/db/db_band.go":    "package db\n",
+		"kessoku_registry_band.go":  "package app\n",
+		"unrelated/unrelated.go":    "package unrelated\n",
+		"cmd/app/testdata/input.go": "package testdata\n",
+	}
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	sources, moduleRoot, err := collectSources([]string{filepath.Join(root, "cmd", "app", "kessoku.go")}, defaultOutputSuffix)
+	if err != nil {
+		t.Fatalf("collectSources failed: %v", err)
+	}
+	if moduleRoot != root {
+		t.Errorf("Expected module root %s, got %s", root, moduleRoot)
+	}
+
+	// Generated files, packages that are not imported, and the standard library are left out
+	var got []string
+	for filename := range sources {
+		rel, err := filepath.Rel(root, filename)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %v", filename, err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+
+	expected := []string{
+		"cmd/app/app.go",
+		"cmd/app/kessoku.go",
+		"go.mod",
+		"go.sum",
+		"internal/db/db.go",
+		"service/service.go",
+		"service/service_test.go",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected sources %v, got %v", expected, got)
+	}
+}
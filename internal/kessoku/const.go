@@ -1,5 +1,7 @@
 package kessoku
 
+import "go/types"
+
 const (
 	kessokuPkgPath  = "github.com/mazrean/kessoku"
 	errgroupPkgPath = "golang.org/x/sync/errgroup"
@@ -7,6 +9,37 @@ const (
 	contextPkgPath  = "context"
 	contextPkgName  = "context"
 	contextTypeName = "Context"
+	syncPkgPath     = "sync"
+	syncPkgName     = "sync"
+	ioPkgPath       = "io"
+	ioPkgName       = "io"
+	errorsPkgPath   = "errors"
+	errorsPkgName   = "errors"
+	fmtPkgPath      = "fmt"
+	fmtPkgName      = "fmt"
+	reflectPkgPath  = "reflect"
+	reflectPkgName  = "reflect"
+	osPkgPath       = "os"
+	osPkgName       = "os"
+	strconvPkgPath  = "strconv"
+	strconvPkgName  = "strconv"
+	httpPkgPath     = "net/http"
+	httpPkgName     = "http"
+
+	// generatedHeader marks files written by the generator. It must match the
+	// ^// Code generated .* DO NOT EDIT\.$ convention so that go tools and
+	// coverage filters recognize the files as generated.
+	generatedHeader = "// Code generated by kessoku. DO NOT EDIT."
+
+	// injectCommentDirective declares a single-provider injector above a provider function.
+	injectCommentDirective = "//kessoku:inject"
+
+	// defaultOutputSuffix is appended to the name of an injector file to name its generated file.
+	defaultOutputSuffix = "_band"
+	// registryFilePrefix names the file that holds the injector registry of a package, followed by the output suffix.
+	registryFilePrefix = "kessoku_registry"
+	// registryVarName is the name of the generated injector registry variable.
+	registryVarName = "InjectorRegistry"
 )
 
 var (
@@ -34,4 +67,7 @@ var (
 		"const", "fallthrough", "if", "range", "type",
 		"continue", "for", "import", "return", "var",
 	}
+
+	// commandArgsType is the type of the argument declared with kessoku.Args.
+	commandArgsType = types.NewSlice(types.Typ[types.String])
 )
@@ -0,0 +1,61 @@
+package kessoku
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+)
+
+// InjectorDescription describes the signature of a generated injector, so that tools generating
+// its call sites do not have to parse the generated code.
+type InjectorDescription struct {
+	Injector string `json:"injector"`
+	Package  string `json:"package"`
+	// Args lists the parameters of the injector in the order of its signature.
+	Args []*ArgumentDescription `json:"args"`
+}
+
+// ArgumentDescription is a parameter of a generated injector.
+type ArgumentDescription struct {
+	Name string `json:"name"`
+	// Type is rendered as in the generated code, with the package names of the generated file.
+	Type string `json:"type"`
+}
+
+// describeInjector returns the description of injector. Parameters are named while
+// the injector is generated, so it must be called after generation.
+func describeInjector(metaData *MetaData, injector *Injector, varPool *VarPool) *InjectorDescription {
+	description := &InjectorDescription{
+		Injector: injector.Name,
+		Package:  metaData.Package.Path,
+		Args:     []*ArgumentDescription{},
+	}
+	for _, arg := range injector.Args {
+		if arg == nil || arg.ASTTypeExpr == nil || arg.Param == nil {
+			continue
+		}
+
+		description.Args = append(description.Args, &ArgumentDescription{
+			Name: arg.Param.Name(varPool),
+			Type: types.ExprString(arg.ASTTypeExpr),
+		})
+	}
+
+	return description
+}
+
+// writeDescriptions writes the descriptions of all injectors to w as a JSON array.
+func writeDescriptions(w io.Writer, descriptions []*InjectorDescription) error {
+	if descriptions == nil {
+		descriptions = []*InjectorDescription{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(descriptions); err != nil {
+		return fmt.Errorf("encode injector descriptions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package kessoku
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffLine is a single line of a line diff.
+type diffLine struct {
+	text string
+	op   byte // ' ' for unchanged, '-' for removed and '+' for added lines
+}
+
+// unifiedDiff returns a unified diff that turns oldText into newText.
+// It returns an empty string if the texts are identical.
+func unifiedDiff(oldName, newName string, oldText, newText []byte) string {
+	if string(oldText) == string(newText) {
+		return ""
+	}
+
+	lines := diffLines(splitLines(string(oldText)), splitLines(string(newText)))
+
+	var (
+		b        strings.Builder
+		oldCount int
+		newCount int
+	)
+	// oldPos and newPos hold the number of old and new lines preceding each diff line
+	oldPos := make([]int, len(lines)+1)
+	newPos := make([]int, len(lines)+1)
+	for i, line := range lines {
+		if line.op != '+' {
+			oldCount++
+		}
+		if line.op != '-' {
+			newCount++
+		}
+		oldPos[i+1], newPos[i+1] = oldCount, newCount
+	}
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+
+		// Merge changes separated by fewer unchanged lines than the context of two hunks
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].op != ' ' {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*diffContext {
+				break
+			}
+		}
+
+		start := max(i-diffContext, 0)
+		stop := min(end+diffContext, len(lines))
+
+		oldStart, oldLen := oldPos[start], oldPos[stop]-oldPos[start]
+		newStart, newLen := newPos[start], newPos[stop]-newPos[start]
+		if oldLen > 0 {
+			oldStart++
+		}
+		if newLen > 0 {
+			newStart++
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldLen, newStart, newLen)
+		for _, line := range lines[start:stop] {
+			b.WriteByte(line.op)
+			b.WriteString(line.text)
+			b.WriteByte('\n')
+		}
+
+		i = stop
+	}
+
+	return b.String()
+}
+
+// diffLines computes a line diff of oldLines and newLines from their longest common subsequence.
+func diffLines(oldLines, newLines []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := range slices.Backward(oldLines) {
+		for j := range slices.Backward(newLines) {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(oldLines)+len(newLines))
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{op: ' ', text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{op: '-', text: oldLines[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{op: '+', text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		lines = append(lines, diffLine{op: '-', text: oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		lines = append(lines, diffLine{op: '+', text: newLines[j]})
+	}
+
+	return lines
+}
+
+// splitLines splits text into lines without their line terminators.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
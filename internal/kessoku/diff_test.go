@@ -0,0 +1,91 @@
+package kessoku
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{
+			name:     "identical",
+			oldText:  "a\nb\nc\n",
+			newText:  "a\nb\nc\n",
+			expected: "",
+		},
+		{
+			name:    "changed line",
+			oldText: "a\nb\nc\n",
+			newText: "a\nx\nc\n",
+			expected: `--- old.go
++++ new.go
+@@ -1,3 +1,3 @@
+ a
+-b
++x
+ c
+`,
+		},
+		{
+			name:    "new file",
+			oldText: "",
+			newText: "a\nb\n",
+			expected: `--- old.go
++++ new.go
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name:    "distant changes in separate hunks",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			newText: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			expected: `--- old.go
++++ new.go
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -9,4 +10,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+		{
+			name:    "nearby changes merged into one hunk",
+			oldText: "1\n2\n3\n4\n5\n6\n",
+			newText: "x\n2\n3\n4\n5\ny\n",
+			expected: `--- old.go
++++ new.go
+@@ -1,6 +1,6 @@
+-1
++x
+ 2
+ 3
+ 4
+ 5
+-6
++y
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := unifiedDiff("old.go", "new.go", []byte(tt.oldText), []byte(tt.newText))
+			if got != tt.expected {
+				t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,67 +1,368 @@
 package kessoku
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"go/ast"
 	"go/format"
+	"go/parser"
 	"go/token"
 	"go/types"
 	"io"
 	"log/slog"
+	"maps"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	// maxInjectorReturnValues represents the maximum number of return values for an injector function
-	maxInjectorReturnValues = 2
+	maxInjectorReturnValues = 4
 )
 
 func Generate(w io.Writer, filename string, metaData *MetaData, injectors []*Injector, varPool *VarPool) error {
-	file := &ast.File{
-		Name: ast.NewIdent(metaData.Package.Name),
-	}
-
 	// Generate injector function declarations
 	var funcDecls []ast.Decl
 	for _, injector := range injectors {
-		funcDecl, err := generateInjectorDecl(metaData, injector, varPool)
+		decls, err := generateInjectorDecl(metaData, injector, varPool)
 		if err != nil {
 			slog.Error("Failed to generate injector declaration", "error", err)
 			continue
 		}
 
-		funcDecls = append(funcDecls, funcDecl)
+		funcDecls = append(funcDecls, decls...)
 	}
 
+	var src bytes.Buffer
+	src.WriteString(generatedHeader + "\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", metaData.Package.Name)
+
 	// Generate import declarations only for used imports
 	usedImports := GetUsedImports(metaData.Imports)
+	for _, injector := range injectors {
+		for _, path := range injector.BlankImports {
+			// Importing the package anyway runs its init as well
+			if _, ok := usedImports[path]; !ok {
+				usedImports[path] = &Import{Name: "_"}
+			}
+		}
+	}
 	importSpecs := make([]*ast.ImportSpec, 0, len(usedImports))
 	for path, imp := range usedImports {
 		importSpecs = append(importSpecs, importSpec(imp, path))
 	}
-	slices.SortFunc(importSpecs, func(a, b *ast.ImportSpec) int {
-		return strings.Compare(a.Path.Value, b.Path.Value)
-	})
-	importDecl := generateImportDecl(importSpecs)
-	if importDecl != nil {
-		file.Decls = append(file.Decls, importDecl)
+	if err := writeImportDecl(&src, groupImportSpecs(importSpecs, metaData.LocalPrefix)); err != nil {
+		return fmt.Errorf("write import declaration: %w", err)
 	}
 
-	// Add function declarations after imports
-	file.Decls = append(file.Decls, funcDecls...)
-
-	// Add DO NOT EDIT comment
-	_, err := w.Write([]byte("// Code generated by kessoku. DO NOT EDIT.\n\n"))
-	if err != nil {
-		return fmt.Errorf("write DO NOT EDIT comment: %w", err)
+	comments := make(map[ast.Stmt]string)
+	for _, injector := range injectors {
+		maps.Copy(comments, injector.comments)
+	}
+	for _, decl := range funcDecls {
+		if err := formatDecl(&src, decl, comments); err != nil {
+			return fmt.Errorf("format generated code: %w", err)
+		}
+		src.WriteString("\n\n")
 	}
 
 	// Format and write the generated code
-	err = format.Node(w, token.NewFileSet(), file)
+	formatted, err := format.Source(src.Bytes())
 	if err != nil {
 		return fmt.Errorf("format generated code: %w", err)
 	}
+	if metaData.Stamp != nil {
+		formatted = metaData.Stamp.apply(formatted)
+	}
+
+	if _, err := w.Write(formatted); err != nil {
+		return fmt.Errorf("write generated code: %w", err)
+	}
+
+	return nil
+}
+
+// formatDecl formats decl, writing the comments of its statements on their own line before them.
+// Generated nodes have no positions to attach comments to, so the formatted code is parsed again
+// to find the lines of the statements, which are visited in the same order as in decl.
+func formatDecl(w io.Writer, decl ast.Decl, comments map[ast.Stmt]string) error {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), decl); err != nil {
+		return err
+	}
+
+	// The printer leaves out empty statements, so they are not counted
+	stmtComments := make(map[int]string)
+	var count int
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if stmt, ok := n.(ast.Stmt); ok {
+			if _, empty := stmt.(*ast.EmptyStmt); !empty {
+				if comment, ok := comments[stmt]; ok {
+					stmtComments[count] = comment
+				}
+				count++
+			}
+		}
+		return true
+	})
+	if len(stmtComments) == 0 {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	const pkgClause = "package p\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", pkgClause+buf.String(), parser.SkipObjectResolution)
+	if err != nil {
+		return fmt.Errorf("parse formatted declaration: %w", err)
+	}
+
+	lineComments := make(map[int]string)
+	count = 0
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		stmt, ok := n.(ast.Stmt)
+		if !ok {
+			return true
+		}
+		if comment, ok := stmtComments[count]; ok {
+			// Lines are numbered from 1, after the package clause
+			lineComments[fset.Position(stmt.Pos()).Line-2] = comment
+		}
+		count++
+
+		// The cases of a select are generated as case clauses of expressions, so the
+		// communication statement parsed from them has no counterpart in decl
+		if clause, ok := stmt.(*ast.CommClause); ok {
+			for _, bodyStmt := range clause.Body {
+				ast.Inspect(bodyStmt, visit)
+			}
+			return false
+		}
+		return true
+	}
+	ast.Inspect(file.Decls[0], visit)
+
+	for i, line := range strings.SplitAfter(buf.String(), "\n") {
+		if comment, ok := lineComments[i]; ok {
+			indent := line[:len(line)-len(strings.TrimLeft(line, "\t"))]
+			if _, err := io.WriteString(w, indent+comment+"\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VersionStamp identifies the kessoku version that generated a file, to correlate generated code
+// with the tool that produced it.
+type VersionStamp struct {
+	Version string
+	// Time is the generation time, stamped in place of a hash of the generated code if set.
+	// The hash only changes with the code, so regenerating unchanged code produces no diff.
+	Time time.Time
+}
+
+// apply adds the stamp line below the generated header of src:
+//
+//	// Code generated by kessoku. DO NOT EDIT.
+//	// kessoku v1.2.0, content sha256:2c26b46b68ffc68f
+func (s *VersionStamp) apply(src []byte) []byte {
+	var line string
+	if s.Time.IsZero() {
+		sum := sha256.Sum256(src)
+		line = fmt.Sprintf("// kessoku %s, content sha256:%s\n", s.Version, hex.EncodeToString(sum[:])[:16])
+	} else {
+		line = fmt.Sprintf("// kessoku %s, generated at %s\n", s.Version, s.Time.UTC().Format(time.RFC3339))
+	}
+
+	header := generatedHeader + "\n"
+	rest, ok := bytes.CutPrefix(src, []byte(header))
+	if !ok {
+		return src
+	}
+	return slices.Concat([]byte(header), []byte(line), rest)
+}
+
+// GenerateRegistry writes a file declaring a map from injector names to wrappers with a uniform
+// func(context.Context) (any, error) signature. Injectors taking arguments other than the
+// errgroup context cannot be called uniformly and are left out.
+func GenerateRegistry(w io.Writer, pkgName string, injectors []*Injector) error {
+	ctxIdent := ast.NewIdent("ctx")
+	funcType := &ast.FuncType{
+		Params: &ast.FieldList{List: []*ast.Field{{
+			Names: []*ast.Ident{ctxIdent},
+			Type:  &ast.SelectorExpr{X: ast.NewIdent(contextPkgName), Sel: ast.NewIdent(contextTypeName)},
+		}}},
+		Results: &ast.FieldList{List: []*ast.Field{
+			{Type: ast.NewIdent("any")},
+			{Type: ast.NewIdent("error")},
+		}},
+	}
+
+	injectors = slices.SortedFunc(slices.Values(injectors), func(a, b *Injector) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	fset := token.NewFileSet()
+	var src bytes.Buffer
+	src.WriteString(generatedHeader + "\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkgName)
+	fmt.Fprintf(&src, "import %q\n\n", contextPkgPath)
+	src.WriteString("// " + registryVarName + " maps injector names to their generated functions.\n")
+	src.WriteString("var " + registryVarName + " = map[string]")
+	if err := format.Node(&src, fset, funcType); err != nil {
+		return fmt.Errorf("format registry type: %w", err)
+	}
+	src.WriteString("{\n")
+
+	for _, injector := range injectors {
+		if injector.WithCancel {
+			slog.Debug("Injector returning a cancel function is not added to the registry", "injector", injector.Name)
+			continue
+		}
+		if injector.CleanupCloser {
+			slog.Debug("Injector returning a closer is not added to the registry", "injector", injector.Name)
+			continue
+		}
+		if len(injector.ReadyChecks) > 0 {
+			slog.Debug("Injector returning a ready check is not added to the registry", "injector", injector.Name)
+			continue
+		}
+
+		var args []ast.Expr
+		switch {
+		case len(injector.Args) == 0:
+		case len(injector.Args) == 1 && injector.ContextArg() == injector.Args[0]:
+			args = append(args, ctxIdent)
+		default:
+			slog.Debug("Injector with arguments is not added to the registry", "injector", injector.Name)
+			continue
+		}
+
+		/*
+			ast of:
+			func(ctx context.Context) (any, error) {
+				return InitializeApp(ctx)
+			}
+		*/
+		call := &ast.CallExpr{Fun: ast.NewIdent(injector.Name), Args: args}
+		var body []ast.Stmt
+		switch {
+		case !injector.IsReturnError:
+			body = []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{call, ast.NewIdent("nil")}}}
+		case injector.ErrorWrapper != nil:
+			// A nil custom error must not be returned as a non-nil error interface
+			valIdent, errIdent := ast.NewIdent("v"), ast.NewIdent("err")
+			body = []ast.Stmt{
+				&ast.AssignStmt{Lhs: []ast.Expr{valIdent, errIdent}, Tok: token.DEFINE, Rhs: []ast.Expr{call}},
+				&ast.IfStmt{
+					Cond: &ast.BinaryExpr{X: errIdent, Op: token.NEQ, Y: ast.NewIdent("nil")},
+					Body: &ast.BlockStmt{List: []ast.Stmt{
+						&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("nil"), errIdent}},
+					}},
+				},
+				&ast.ReturnStmt{Results: []ast.Expr{valIdent, ast.NewIdent("nil")}},
+			}
+		default:
+			body = []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{call}}}
+		}
+		entry := &ast.FuncLit{
+			Type: funcType,
+			Body: &ast.BlockStmt{List: body},
+		}
+
+		fmt.Fprintf(&src, "%q: ", injector.Name)
+		if err := format.Node(&src, fset, entry); err != nil {
+			return fmt.Errorf("format registry entry %s: %w", injector.Name, err)
+		}
+		src.WriteString(",\n")
+	}
+	src.WriteString("}\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("format registry: %w", err)
+	}
+
+	if _, err := w.Write(formatted); err != nil {
+		return fmt.Errorf("write registry: %w", err)
+	}
+
+	return nil
+}
+
+// groupImportSpecs splits imports into goimports-style sections: standard library,
+// third-party, and imports under localPrefix. Each section is sorted by path and empty sections are dropped.
+func groupImportSpecs(importSpecs []*ast.ImportSpec, localPrefix string) [][]*ast.ImportSpec {
+	var std, thirdParty, local []*ast.ImportSpec
+	for _, spec := range importSpecs {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			path = spec.Path.Value
+		}
+
+		switch {
+		case localPrefix != "" && (path == localPrefix || strings.HasPrefix(path, strings.TrimSuffix(localPrefix, "/")+"/")):
+			local = append(local, spec)
+		case !strings.Contains(strings.SplitN(path, "/", 2)[0], "."):
+			std = append(std, spec)
+		default:
+			thirdParty = append(thirdParty, spec)
+		}
+	}
+
+	groups := make([][]*ast.ImportSpec, 0, 3)
+	for _, group := range [][]*ast.ImportSpec{std, thirdParty, local} {
+		if len(group) == 0 {
+			continue
+		}
+		slices.SortFunc(group, func(a, b *ast.ImportSpec) int {
+			return strings.Compare(a.Path.Value, b.Path.Value)
+		})
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// writeImportDecl writes a single import declaration with a blank line between groups.
+func writeImportDecl(w *bytes.Buffer, groups [][]*ast.ImportSpec) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	if len(groups) == 1 && len(groups[0]) == 1 {
+		w.WriteString("import ")
+		if err := format.Node(w, fset, groups[0][0]); err != nil {
+			return fmt.Errorf("format import %s: %w", groups[0][0].Path.Value, err)
+		}
+		w.WriteString("\n\n")
+		return nil
+	}
+
+	w.WriteString("import (\n")
+	for i, group := range groups {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		for _, spec := range group {
+			if err := format.Node(w, fset, spec); err != nil {
+				return fmt.Errorf("format import %s: %w", spec.Path.Value, err)
+			}
+			w.WriteString("\n")
+		}
+	}
+	w.WriteString(")\n\n")
 
 	return nil
 }
@@ -111,11 +412,8 @@ func generateAsyncInitialization(pkg string, injector *Injector, varPool *VarPoo
 
 	// Find context parameter name if available
 	var ctxParamName string
-	for _, arg := range injector.Args {
-		if isContextType(arg.Type) {
-			ctxParamName = arg.Param.Name(varPool)
-			break
-		}
+	if ctxArg := injector.ContextArg(); ctxArg != nil {
+		ctxParamName = ctxArg.Param.Name(varPool)
 	}
 
 	// Generate variable declarations for async access
@@ -124,6 +422,18 @@ func generateAsyncInitialization(pkg string, injector *Injector, varPool *VarPoo
 		return nil, fmt.Errorf("generate variable declarations: %w", err)
 	}
 
+	// Evaluate the kessoku.WithChannelTrace option once for all channel events
+	if injector.ChannelTrace != nil {
+		for _, imp := range injector.ChannelTrace.ReferencedImports {
+			imp.IsUsed = true
+		}
+		injector.ChannelTrace.varName = varPool.GetName("channelTrace")
+		varSpecs = append(varSpecs, &ast.ValueSpec{
+			Names:  []*ast.Ident{ast.NewIdent(injector.ChannelTrace.varName)},
+			Values: []ast.Expr{injector.ChannelTrace.ASTExpr},
+		})
+	}
+
 	stmts = append(stmts, &ast.DeclStmt{
 		Decl: &ast.GenDecl{
 			Tok:   token.VAR,
@@ -184,6 +494,10 @@ func generateVariableSpecs(pkg string, injector *Injector, varPool *VarPool, imp
 	for _, param := range injector.Vars {
 		paramName := param.Name(varPool)
 		if paramName == "_" {
+			if param.WithChannel() {
+				// Providers ordered with kessoku.After wait for the channel of a value they do not use
+				specs = append(specs, channelSpec(param.ChannelName(varPool)))
+			}
 			continue
 		}
 
@@ -206,27 +520,33 @@ func generateVariableSpecs(pkg string, injector *Injector, varPool *VarPool, imp
 			continue
 		}
 
-		specs = append(specs, &ast.ValueSpec{
-			Names: []*ast.Ident{ast.NewIdent(param.ChannelName(varPool))},
-			Values: []ast.Expr{
-				&ast.CallExpr{
-					Fun: ast.NewIdent("make"),
-					Args: []ast.Expr{
-						&ast.ChanType{
-							Dir:   ast.SEND | ast.RECV,
-							Value: ast.NewIdent("struct{}"),
-						},
+		specs = append(specs, channelSpec(param.ChannelName(varPool)))
+	}
+
+	return specs, nil
+}
+
+// channelSpec declares the channel closed when the value of a provider is ready.
+func channelSpec(name string) ast.Spec {
+	return &ast.ValueSpec{
+		Names: []*ast.Ident{ast.NewIdent(name)},
+		Values: []ast.Expr{
+			&ast.CallExpr{
+				Fun: ast.NewIdent("make"),
+				Args: []ast.Expr{
+					&ast.ChanType{
+						Dir:   ast.SEND | ast.RECV,
+						Value: ast.NewIdent("struct{}"),
 					},
 				},
 			},
-		})
+		},
 	}
-
-	return specs, nil
 }
 
-// generateAsyncWaitStatements creates errgroup wait statements
-func generateAsyncWaitStatements(injector *Injector) []ast.Stmt {
+// generateAsyncWaitStatements creates errgroup wait statements.
+// An error is returned with returnErrStmts, like the errors of the providers.
+func generateAsyncWaitStatements(injector *Injector, returnErrStmts func(ast.Expr) []ast.Stmt) []ast.Stmt {
 	if injector.IsReturnError {
 		errIdent := ast.NewIdent("err")
 
@@ -250,14 +570,7 @@ func generateAsyncWaitStatements(injector *Injector) []ast.Stmt {
 					Y:  ast.NewIdent("nil"),
 				},
 				Body: &ast.BlockStmt{
-					List: []ast.Stmt{
-						&ast.ReturnStmt{
-							Results: []ast.Expr{
-								ast.NewIdent("nil"),
-								errIdent,
-							},
-						},
-					},
+					List: returnErrStmts(errIdent),
 				},
 			},
 		}
@@ -279,22 +592,7 @@ func generateAsyncWaitStatements(injector *Injector) []ast.Stmt {
 	}
 }
 
-func generateImportDecl(imporSpecs []*ast.ImportSpec) *ast.GenDecl {
-	specs := make([]ast.Spec, 0, len(imporSpecs))
-	for _, importSpec := range imporSpecs {
-		specs = append(specs, importSpec)
-	}
-	if len(specs) == 0 {
-		return nil
-	}
-
-	return &ast.GenDecl{
-		Tok:   token.IMPORT,
-		Specs: specs,
-	}
-}
-
-func generateInjectorDecl(metaData *MetaData, injector *Injector, varPool *VarPool) (ast.Decl, error) {
+func generateInjectorDecl(metaData *MetaData, injector *Injector, varPool *VarPool) ([]ast.Decl, error) {
 	paramFields := make([]*ast.Field, 0, len(injector.Args)+1)
 
 	// Add parameters
@@ -324,9 +622,29 @@ func generateInjectorDecl(metaData *MetaData, injector *Injector, varPool *VarPo
 			Type: injector.Return.Return.ASTTypeExpr,
 		})
 	}
+	if injector.WithCancel {
+		resultsFields = append(resultsFields, &ast.Field{
+			Type: contextSelector(metaData.Imports, "CancelFunc"),
+		})
+	}
+	if injector.CleanupCloser {
+		injector.closerTypeName = varPool.GetName(injector.Name + "Closer")
+		resultsFields = append(resultsFields, &ast.Field{
+			Type: &ast.StarExpr{X: ast.NewIdent(injector.closerTypeName)},
+		})
+	}
+	if len(injector.ReadyChecks) > 0 {
+		resultsFields = append(resultsFields, &ast.Field{
+			Type: readyCheckFuncType(varPool, metaData.Imports),
+		})
+	}
 	if injector.IsReturnError {
+		errType, err := errorTypeExpr(metaData.Package.Path, injector, varPool, metaData.Imports)
+		if err != nil {
+			return nil, fmt.Errorf("create error type expression: %w", err)
+		}
 		resultsFields = append(resultsFields, &ast.Field{
-			Type: &ast.Ident{Name: "error"},
+			Type: errType,
 		})
 	}
 
@@ -367,28 +685,1130 @@ func generateInjectorDecl(metaData *MetaData, injector *Injector, varPool *VarPo
 		Results: results,
 	}
 
+	if injector.Overrides {
+		overrides, err := newInjectorOverrides(metaData.Package.Path, injector, varPool, metaData.Imports)
+		if err != nil {
+			return nil, fmt.Errorf("generate overrides of %s: %w", injector.Name, err)
+		}
+		injector.overrides = overrides
+	}
+
+	// Results are named before the body, so that they get the plain names rather than its variables
+	var resultNames []string
+	if injector.NamedReturns {
+		resultNames = injectorResultNames(injector, varPool)
+	}
+
+	poolDecls, err := generatePoolDecls(metaData.Package.Path, injector, varPool, metaData.Imports)
+	if err != nil {
+		return nil, fmt.Errorf("generate pools of %s: %w", injector.Name, err)
+	}
+	nameFactories(injector.Stmts, varPool)
+
 	stmts, err := generateStmts(varPool, metaData.Package.Path, injector, metaData.Imports)
 	if err != nil {
 		return nil, fmt.Errorf("generate statements: %w", err)
 	}
 
-	funcDecl := &ast.FuncDecl{
-		Name: ast.NewIdent(injector.Name),
-		Type: funcType,
-		Body: &ast.BlockStmt{
-			List: stmts,
+	decls := poolDecls
+	if injector.CleanupCloser {
+		decls = append(decls, generateCloserDecls(injector.closerTypeName, varPool, metaData.Imports)...)
+	}
+	switch {
+	case injector.IsLazy:
+		decls = append(decls, generateLazyInjectorDecls(injector, funcType, stmts, varPool, metaData.Imports)...)
+	case injector.overrides != nil:
+		decls = append(decls, generateOverridesInjectorDecls(injector, funcType, stmts, varPool)...)
+	default:
+		decls = append(decls, &ast.FuncDecl{
+			Name: ast.NewIdent(injector.Name),
+			Type: funcType,
+			Body: &ast.BlockStmt{
+				List: stmts,
+			},
+		})
+	}
+
+	if resultNames != nil {
+		nameInjectorResults(decls, injector.Name, resultNames)
+	}
+
+	if injector.IsMust {
+		mustDecl, err := generateMustInjectorDecl(injector, varPool, metaData.Imports)
+		if err != nil {
+			return nil, fmt.Errorf("generate Must variant of %s: %w", injector.Name, err)
+		}
+		decls = append(decls, mustDecl)
+	}
+
+	if injector.RunWrapper {
+		decls = append(decls, generateRunWrapperDecl(injector, varPool, metaData.Imports))
+	}
+
+	if injector.Inspector {
+		inspectorDecls, err := generateInspectorDecls(metaData, injector, funcType, varPool)
+		if err != nil {
+			return nil, fmt.Errorf("generate inspector of %s: %w", injector.Name, err)
+		}
+		decls = append(decls, inspectorDecls...)
+	}
+
+	if injector.Implements != nil {
+		implDecls, err := generateImplementationDecls(metaData, injector, funcType, varPool)
+		if err != nil {
+			return nil, fmt.Errorf("generate implementation of %s: %w", injector.Implements.Interface, err)
+		}
+		decls = append(decls, implDecls...)
+	}
+
+	return decls, nil
+}
+
+// injectorResultNames returns the names of the results of the injector after their types, e.g. app
+// and err for func InitializeApp() (app *App, err error), for NamedReturns. The names are taken from
+// varPool like the variables of the body, so they never collide with them.
+func injectorResultNames(injector *Injector, varPool *VarPool) []string {
+	// The results are in the order the injector returns them
+	var baseNames []string
+	if injector.Return != nil && injector.Return.Return != nil {
+		baseNames = append(baseNames, varPool.getBaseName(injector.Return.Return.Type))
+	}
+	if injector.WithCancel {
+		baseNames = append(baseNames, "cancel")
+	}
+	if injector.CleanupCloser {
+		baseNames = append(baseNames, "closer")
+	}
+	if len(injector.ReadyChecks) > 0 {
+		baseNames = append(baseNames, "ready")
+	}
+	if injector.IsReturnError {
+		baseNames = append(baseNames, "err")
+	}
+
+	names := make([]string, 0, len(baseNames))
+	for _, baseName := range baseNames {
+		names = append(names, varPool.GetName(baseName))
+	}
+
+	return names
+}
+
+// nameInjectorResults names the results of the injector function among decls. The results are
+// copied, since the variants of the injector share them.
+func nameInjectorResults(decls []ast.Decl, name string, names []string) {
+	idx := slices.IndexFunc(decls, func(decl ast.Decl) bool {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		return ok && funcDecl.Recv == nil && funcDecl.Name.Name == name
+	})
+	if idx == -1 {
+		return
+	}
+	decl := decls[idx].(*ast.FuncDecl)
+	if decl.Type.Results == nil || len(decl.Type.Results.List) != len(names) {
+		return
+	}
+
+	results := make([]*ast.Field, 0, len(names))
+	for i, field := range decl.Type.Results.List {
+		results = append(results, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(names[i])},
+			Type:  field.Type,
+		})
+	}
+	decl.Type = &ast.FuncType{
+		Params:  decl.Type.Params,
+		Results: &ast.FieldList{List: results},
+	}
+}
+
+// generatePoolDecls declares the sync.Pool of every kessoku.Pooled provider of the injector.
+// The pool is named after the injector, like its lazy variables, so that the injectors of other
+// files of the package pooling the same type do not declare it again:
+//
+//	var initializeRendererBufferPool = sync.Pool{New: func() any {
+//		return newBuffer()
+//	}}
+func generatePoolDecls(pkg string, injector *Injector, varPool *VarPool, imports map[string]*Import) ([]ast.Decl, error) {
+	var decls []ast.Decl
+
+	var addStmts func(stmts []InjectorStmt) error
+	addStmts = func(stmts []InjectorStmt) error {
+		for _, stmt := range stmts {
+			switch s := stmt.(type) {
+			case *InjectorChainStmt:
+				if err := addStmts(s.Statements); err != nil {
+					return err
+				}
+			case *InjectorProviderCallStmt:
+				if s.Provider.Type != ProviderTypePool {
+					continue
+				}
+
+				acquire := s.Returns[0]
+				pooledType := acquire.Type().(*types.Signature).Results().At(0).Type()
+				typeExpr, err := createASTTypeExpr(pkg, pooledType, varPool, imports)
+				if err != nil {
+					return fmt.Errorf("create AST type expression for %s: %w", pooledType, err)
+				}
+				// The pooled type is asserted even if the acquire function is never named
+				for _, imp := range acquire.ReferencedImports {
+					imp.IsUsed = true
+				}
+				for _, imp := range s.Provider.ReferencedImports {
+					imp.IsUsed = true
+				}
+
+				baseName := varPool.getBaseName(pooledType)
+				s.pool = &injectorPool{
+					name:     varPool.GetInjectorVarName(injector.Name, exportedFieldName(baseName)+"Pool"),
+					typeExpr: typeExpr,
+				}
+				// Function types have no name of their own, so the acquire function is named after the pooled type
+				if acquire.refCounter > 0 && acquire.name == "" {
+					acquire.name = varPool.GetName("acquire" + exportedFieldName(baseName))
+				}
+				decls = append(decls, &ast.GenDecl{
+					Tok: token.VAR,
+					Specs: []ast.Spec{&ast.ValueSpec{
+						Names: []*ast.Ident{ast.NewIdent(s.pool.name)},
+						Values: []ast.Expr{&ast.CompositeLit{
+							Type: &ast.SelectorExpr{
+								X:   ast.NewIdent(importName(syncPkgPath, syncPkgName, varPool, imports)),
+								Sel: ast.NewIdent("Pool"),
+							},
+							Elts: []ast.Expr{&ast.KeyValueExpr{
+								Key: ast.NewIdent("New"),
+								Value: &ast.FuncLit{
+									Type: &ast.FuncType{
+										Params:  &ast.FieldList{},
+										Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("any")}}},
+									},
+									Body: &ast.BlockStmt{List: []ast.Stmt{
+										&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{Fun: s.Provider.ASTExpr}}},
+									}},
+								},
+							}},
+						}},
+					}},
+				})
+			}
+		}
+		return nil
+	}
+	if err := addStmts(injector.Stmts); err != nil {
+		return nil, err
+	}
+
+	return decls, nil
+}
+
+// nameFactories names the functions of the factory providers in stmts after the values they
+// construct, e.g. newSession, as function types have no name of their own.
+func nameFactories(stmts []InjectorStmt, varPool *VarPool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *InjectorChainStmt:
+			nameFactories(s.Statements, varPool)
+		case *InjectorProviderCallStmt:
+			if s.Provider.Type != ProviderTypeFactory {
+				continue
+			}
+
+			factory := s.Returns[0]
+			if factory.refCounter > 0 && factory.name == "" {
+				valueType := factory.Type().(*types.Signature).Results().At(0).Type()
+				factory.name = varPool.GetName("new" + exportedFieldName(varPool.getBaseName(valueType)))
+			}
+		}
+	}
+}
+
+// buildPoolAcquire builds the function acquiring an instance from the pool of a kessoku.Pooled provider:
+//
+//	func() (*Buffer, func()) {
+//		v := initializeRendererBufferPool.Get().(*Buffer)
+//		return v, func() { bufferPool.Put(v) }
+//	}
+func (stmt *InjectorProviderCallStmt) buildPoolAcquire() ast.Expr {
+	poolIdent := ast.NewIdent(stmt.pool.name)
+	valueIdent := ast.NewIdent("v")
+	releaseType := &ast.FuncType{Params: &ast.FieldList{}}
+
+	return &ast.FuncLit{
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: stmt.pool.typeExpr},
+				{Type: releaseType},
+			}},
 		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{valueIdent},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.TypeAssertExpr{
+					X:    &ast.CallExpr{Fun: &ast.SelectorExpr{X: poolIdent, Sel: ast.NewIdent("Get")}},
+					Type: stmt.pool.typeExpr,
+				}},
+			},
+			&ast.ReturnStmt{Results: []ast.Expr{
+				valueIdent,
+				&ast.FuncLit{
+					Type: releaseType,
+					Body: &ast.BlockStmt{List: []ast.Stmt{
+						&ast.ExprStmt{X: &ast.CallExpr{
+							Fun:  &ast.SelectorExpr{X: poolIdent, Sel: ast.NewIdent("Put")},
+							Args: []ast.Expr{valueIdent},
+						}},
+					}},
+				},
+			}},
+		}},
+	}
+}
+
+// generateMustInjectorDecl generates the variant of an injector declared with
+// kessoku.MustInject, which calls the injector and panics if it returns an error.
+//
+//	func InitializeAppMust() *App {
+//		app, err := InitializeApp()
+//		if err != nil {
+//			panic(err)
+//		}
+//		return app
+//	}
+//
+// With kessoku.PanicMsg, the error is wrapped as panic(fmt.Errorf("format", err)).
+func generateMustInjectorDecl(injector *Injector, varPool *VarPool, imports map[string]*Import) (ast.Decl, error) {
+	if !injector.IsReturnError {
+		return nil, fmt.Errorf("injector %s does not return an error", injector.Name)
 	}
 
-	return funcDecl, nil
+	params, args := forwardedParams(injector, varPool)
+	resultName := injector.Return.Param.Name(varPool)
+	errIdent := ast.NewIdent("err")
+
+	var panicArg ast.Expr = errIdent
+	if injector.PanicFormat != "" {
+		panicArg = &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent(importName(fmtPkgPath, fmtPkgName, varPool, imports)),
+				Sel: ast.NewIdent("Errorf"),
+			},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(injector.PanicFormat)},
+				errIdent,
+			},
+		}
+	}
+
+	return &ast.FuncDecl{
+		Name: ast.NewIdent(injector.Name + "Must"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: params},
+			Results: &ast.FieldList{List: []*ast.Field{{
+				Type: injector.Return.Return.ASTTypeExpr,
+			}}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent(resultName), errIdent},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(injector.Name), Args: args}},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: errIdent, Op: token.NEQ, Y: ast.NewIdent("nil")},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent("panic"), Args: []ast.Expr{panicArg}}},
+				}},
+			},
+			&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent(resultName)}},
+		}},
+	}, nil
+}
+
+// generateRunWrapperDecl generates the wrapper of kessoku.WithRunWrapper, which defers the cleanups
+// returned by the injector around the callback:
+//
+//	func RunInitializeApp(fn func(*App) error) (runErr error) {
+//		app, closer, err := InitializeApp()
+//		if err != nil {
+//			return err
+//		}
+//		defer func() {
+//			runErr = errors.Join(runErr, closer.Close())
+//		}()
+//		return fn(app)
+//	}
+func generateRunWrapperDecl(injector *Injector, varPool *VarPool, imports map[string]*Import) ast.Decl {
+	params, args := forwardedParams(injector, varPool)
+	fnIdent := ast.NewIdent(wrapperLocalName("fn", params, varPool))
+	resultIdent := ast.NewIdent(injector.Return.Param.Name(varPool))
+	errIdent := ast.NewIdent(wrapperLocalName("err", params, varPool))
+	params = append(params, &ast.Field{
+		Names: []*ast.Ident{fnIdent},
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: []*ast.Field{{Type: injector.Return.Return.ASTTypeExpr}}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("error")}}},
+		},
+	})
+	result := &ast.Field{Type: ast.NewIdent("error")}
+
+	// The results are assigned in the order generateStmts returns them
+	lhs := []ast.Expr{resultIdent}
+	var deferStmts []ast.Stmt
+	if injector.WithCancel {
+		cancelIdent := ast.NewIdent(wrapperLocalName("cancel", params, varPool))
+		lhs = append(lhs, cancelIdent)
+		deferStmts = append(deferStmts, &ast.DeferStmt{Call: &ast.CallExpr{Fun: cancelIdent}})
+	}
+	if injector.CleanupCloser {
+		// The error of the closer is joined into the named result once the callback returned
+		closerIdent := ast.NewIdent(wrapperLocalName("closer", params, varPool))
+		runErrIdent := ast.NewIdent(wrapperLocalName("runErr", params, varPool))
+		result.Names = []*ast.Ident{runErrIdent}
+		lhs = append(lhs, closerIdent)
+		deferStmts = append(deferStmts, &ast.DeferStmt{Call: &ast.CallExpr{Fun: &ast.FuncLit{
+			Type: &ast.FuncType{Params: &ast.FieldList{}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.AssignStmt{
+				Lhs: []ast.Expr{runErrIdent},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{&ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: ast.NewIdent(importName(errorsPkgPath, errorsPkgName, varPool, imports)), Sel: ast.NewIdent("Join")},
+					Args: []ast.Expr{runErrIdent, &ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: closerIdent, Sel: ast.NewIdent("Close")},
+					}},
+				}},
+			}}},
+		}}})
+	}
+	if len(injector.ReadyChecks) > 0 {
+		// The wrapper has no caller to report readiness to
+		lhs = append(lhs, ast.NewIdent("_"))
+	}
+
+	var body []ast.Stmt
+	call := &ast.CallExpr{Fun: ast.NewIdent(injector.Name), Args: args}
+	if injector.IsReturnError {
+		body = append(body,
+			&ast.AssignStmt{Lhs: append(lhs, errIdent), Tok: token.DEFINE, Rhs: []ast.Expr{call}},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: errIdent, Op: token.NEQ, Y: ast.NewIdent("nil")},
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{errIdent}}}},
+			},
+		)
+	} else {
+		body = append(body, &ast.AssignStmt{Lhs: lhs, Tok: token.DEFINE, Rhs: []ast.Expr{call}})
+	}
+	body = append(body, deferStmts...)
+	body = append(body, &ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{Fun: fnIdent, Args: []ast.Expr{resultIdent}}}})
+
+	return &ast.FuncDecl{
+		Name: ast.NewIdent("Run" + injector.Name),
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: params},
+			Results: &ast.FieldList{List: []*ast.Field{result}},
+		},
+		Body: &ast.BlockStmt{List: body},
+	}
+}
+
+// wrapperLocalName returns baseName for a variable of a function wrapping the injector,
+// or a fresh name if one of the parameters of the wrapper already has it.
+func wrapperLocalName(baseName string, params []*ast.Field, varPool *VarPool) string {
+	name := baseName
+	for slices.ContainsFunc(params, func(param *ast.Field) bool { return param.Names[0].Name == name }) {
+		name = varPool.GetName(baseName)
+	}
+
+	return name
+}
+
+// forwardedParams returns the parameters of a function wrapping the injector and the arguments
+// passing them through to it.
+func forwardedParams(injector *Injector, varPool *VarPool) ([]*ast.Field, []ast.Expr) {
+	params := make([]*ast.Field, 0, len(injector.Args))
+	args := make([]ast.Expr, 0, len(injector.Args))
+	for _, arg := range injector.Args {
+		if arg == nil || arg.ASTTypeExpr == nil || arg.Param == nil {
+			continue
+		}
+		// Unused arguments are unnamed in the injector but still have to be passed through
+		name := arg.Param.Name(varPool)
+		if name == "_" {
+			name = varPool.Get(arg.Type)
+		}
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(name)},
+			Type:  arg.ASTTypeExpr,
+		})
+		args = append(args, ast.NewIdent(name))
+	}
+
+	return params, args
+}
+
+// newInjectorOverrides names the map argument of the variant generated by kessoku.DynamicOverrides
+// and creates the types of the values it can override.
+func newInjectorOverrides(pkg string, injector *Injector, varPool *VarPool, imports map[string]*Import) (*injectorOverrides, error) {
+	overrides := &injectorOverrides{
+		typeExprs:   make(map[*InjectorParam]ast.Expr),
+		mapName:     varPool.GetName("overrides"),
+		valueName:   varPool.GetName("override"),
+		reflectName: importName(reflectPkgPath, reflectPkgName, varPool, imports),
+	}
+
+	var addStmts func(stmts []InjectorStmt) error
+	addStmts = func(stmts []InjectorStmt) error {
+		for _, stmt := range stmts {
+			switch s := stmt.(type) {
+			case *InjectorChainStmt:
+				if err := addStmts(s.Statements); err != nil {
+					return err
+				}
+			case *InjectorProviderCallStmt:
+				for _, param := range s.overridableParams() {
+					typeExpr, err := createASTTypeExpr(pkg, param.Type(), varPool, imports)
+					if err != nil {
+						return fmt.Errorf("create AST type expression for %s: %w", param.Type(), err)
+					}
+					for _, imp := range param.ReferencedImports {
+						imp.IsUsed = true
+					}
+					overrides.typeExprs[param] = typeExpr
+				}
+			}
+		}
+		return nil
+	}
+	if err := addStmts(injector.Stmts); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+// generateOverridesInjectorDecls generates the injector body into a variant taking the values to use
+// in place of providers by type, and the injector calling it without overrides:
+//
+//	func InitializeAppWithOverrides(overrides map[reflect.Type]any) (*App, error) {
+//		...
+//	}
+//
+//	func InitializeApp() (*App, error) {
+//		return InitializeAppWithOverrides(nil)
+//	}
+//
+// The map follows the arguments of the injector.
+func generateOverridesInjectorDecls(injector *Injector, funcType *ast.FuncType, stmts []ast.Stmt, varPool *VarPool) []ast.Decl {
+	variantName := injector.Name + "WithOverrides"
+
+	// The map comes last so that a context argument stays first
+	var variantParams []*ast.Field
+	if funcType.Params != nil {
+		variantParams = slices.Clone(funcType.Params.List)
+	}
+	variantParams = append(variantParams, &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(injector.overrides.mapName)},
+		Type: &ast.MapType{
+			Key:   &ast.SelectorExpr{X: ast.NewIdent(injector.overrides.reflectName), Sel: ast.NewIdent("Type")},
+			Value: ast.NewIdent("any"),
+		},
+	})
+
+	params, args := forwardedParams(injector, varPool)
+	call := &ast.CallExpr{
+		Fun:  ast.NewIdent(variantName),
+		Args: append(args, ast.NewIdent("nil")),
+	}
+	var body ast.Stmt = &ast.ReturnStmt{Results: []ast.Expr{call}}
+	if funcType.Results == nil {
+		body = &ast.ExprStmt{X: call}
+	}
+
+	return []ast.Decl{
+		&ast.FuncDecl{
+			Name: ast.NewIdent(variantName),
+			Type: &ast.FuncType{
+				Params:  &ast.FieldList{List: variantParams},
+				Results: funcType.Results,
+			},
+			Body: &ast.BlockStmt{List: stmts},
+		},
+		&ast.FuncDecl{
+			Name: ast.NewIdent(injector.Name),
+			Type: &ast.FuncType{
+				Params:  &ast.FieldList{List: params},
+				Results: funcType.Results,
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{body}},
+		},
+	}
+}
+
+// generateInspectorDecls generates a struct of every value constructed by the injector and
+// a variant of the injector returning it in place of its result, for debugging:
+//
+//	type InitializeAppInspection struct {
+//		Config   *Config
+//		Database *Database
+//		App      *App
+//	}
+//
+//	func InitializeAppInspect() (*InitializeAppInspection, error) {
+//		...
+//		return &InitializeAppInspection{Config: config, Database: database, App: app}, nil
+//	}
+//
+// Values of types that cannot be named in the package of the injector are left out.
+// The variant always constructs new values, even for lazy injectors, and takes no overrides.
+func generateInspectorDecls(metaData *MetaData, injector *Injector, funcType *ast.FuncType, varPool *VarPool) ([]ast.Decl, error) {
+	pkg := metaData.Package.Path
+	typeName := varPool.GetName(injector.Name + "Inspection")
+
+	fields := make([]*ast.Field, 0, len(injector.Vars))
+	elts := make([]ast.Expr, 0, len(injector.Vars))
+	fieldNames := make(map[string]struct{}, len(injector.Vars))
+	for _, param := range injector.Vars {
+		if !isAccessibleType(pkg, param.Type()) {
+			slog.Debug("Leaving value out of inspection", "injector", injector.Name, "type", param.Type().String())
+			continue
+		}
+		typeExpr, err := createASTTypeExpr(pkg, param.Type(), varPool, metaData.Imports)
+		if err != nil {
+			return nil, fmt.Errorf("create AST type expression for %s: %w", param.Type(), err)
+		}
+		for _, imp := range param.ReferencedImports {
+			imp.IsUsed = true
+		}
+
+		// Values the injector discards are named so that the inspection can hold them
+		param.Ref(false)
+		varName := param.Name(varPool)
+
+		fieldName := exportedFieldName(varName)
+		for i := 1; ; i++ {
+			if _, ok := fieldNames[fieldName]; !ok {
+				break
+			}
+			fieldName = exportedFieldName(varName) + strconv.Itoa(i)
+		}
+		fieldNames[fieldName] = struct{}{}
+
+		fields = append(fields, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(fieldName)},
+			Type:  typeExpr,
+		})
+		elts = append(elts, &ast.KeyValueExpr{
+			Key:   ast.NewIdent(fieldName),
+			Value: ast.NewIdent(varName),
+		})
+	}
+
+	injector.inspection = &injectorInspection{
+		typeExpr: &ast.StarExpr{X: ast.NewIdent(typeName)},
+		lit: &ast.UnaryExpr{
+			Op: token.AND,
+			X:  &ast.CompositeLit{Type: ast.NewIdent(typeName), Elts: elts},
+		},
+	}
+	overrides := injector.overrides
+	injector.overrides = nil
+	defer func() {
+		injector.inspection = nil
+		injector.overrides = overrides
+	}()
+
+	stmts, err := generateStmts(varPool, pkg, injector, metaData.Imports)
+	if err != nil {
+		return nil, fmt.Errorf("generate statements: %w", err)
+	}
+
+	// The inspection takes the place of the result, or comes first if the injector returns nothing
+	results := []*ast.Field{{Type: injector.inspection.typeExpr}}
+	if funcType.Results != nil {
+		rest := funcType.Results.List
+		if injector.Return != nil && injector.Return.Return != nil && injector.Return.Return.ASTTypeExpr != nil {
+			rest = rest[1:]
+		}
+		results = append(results, rest...)
+	}
+
+	return []ast.Decl{
+		&ast.GenDecl{
+			Tok: token.TYPE,
+			Specs: []ast.Spec{&ast.TypeSpec{
+				Name: ast.NewIdent(typeName),
+				Type: &ast.StructType{Fields: &ast.FieldList{List: fields}},
+			}},
+		},
+		&ast.FuncDecl{
+			Name: ast.NewIdent(injector.Name + "Inspect"),
+			Type: &ast.FuncType{
+				Params:  funcType.Params,
+				Results: &ast.FieldList{List: results},
+			},
+			Body: &ast.BlockStmt{List: stmts},
+		},
+	}, nil
+}
+
+// exportedFieldName returns the name of the field of an inspection holding the variable name.
+func exportedFieldName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// isAccessibleType reports whether t can be named in the package pkg,
+// i.e. it refers to no unexported type or field of another package.
+func isAccessibleType(pkg string, t types.Type) bool {
+	accessible := func(obj types.Object) bool {
+		return obj.Exported() || obj.Pkg() == nil || obj.Pkg().Path() == pkg
+	}
+
+	switch typ := t.(type) {
+	case *types.Named:
+		if !accessible(typ.Obj()) {
+			return false
+		}
+		for typeArg := range typ.TypeArgs().Types() {
+			if !isAccessibleType(pkg, typeArg) {
+				return false
+			}
+		}
+	case *types.Alias:
+		return accessible(typ.Obj())
+	case *types.Pointer:
+		return isAccessibleType(pkg, typ.Elem())
+	case *types.Slice:
+		return isAccessibleType(pkg, typ.Elem())
+	case *types.Array:
+		return isAccessibleType(pkg, typ.Elem())
+	case *types.Chan:
+		return isAccessibleType(pkg, typ.Elem())
+	case *types.Map:
+		return isAccessibleType(pkg, typ.Key()) && isAccessibleType(pkg, typ.Elem())
+	case *types.Signature:
+		for v := range typ.Params().Variables() {
+			if !isAccessibleType(pkg, v.Type()) {
+				return false
+			}
+		}
+		for v := range typ.Results().Variables() {
+			if !isAccessibleType(pkg, v.Type()) {
+				return false
+			}
+		}
+	case *types.Struct:
+		for field := range typ.Fields() {
+			if !accessible(field) || !isAccessibleType(pkg, field.Type()) {
+				return false
+			}
+		}
+	case *types.Interface:
+		for method := range typ.Methods() {
+			if !accessible(method) || !isAccessibleType(pkg, method.Type()) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// generateImplementationDecls generates a type implementing the interface declared with
+// kessoku.Implements by calling the injector, and asserts that it satisfies the interface.
+//
+//	type initializeAppImpl struct{}
+//
+//	func (initializeAppImpl) NewApp(ctx context.Context) (*App, error) {
+//		return InitializeApp(ctx)
+//	}
+//
+//	var _ AppFactory = initializeAppImpl{}
+func generateImplementationDecls(metaData *MetaData, injector *Injector, funcType *ast.FuncType, varPool *VarPool) ([]ast.Decl, error) {
+	impl := injector.Implements
+
+	params := make([]*types.Var, 0, len(injector.Args))
+	args := make([]ast.Expr, 0, len(injector.Args))
+	for _, arg := range injector.Args {
+		if arg == nil || arg.Param == nil {
+			continue
+		}
+		params = append(params, types.NewParam(token.NoPos, nil, "", arg.Type))
+		args = append(args, ast.NewIdent(arg.Param.Name(varPool)))
+	}
+	var results []*types.Var
+	if injector.Return != nil && injector.Return.Return != nil {
+		results = append(results, types.NewParam(token.NoPos, nil, "", injector.Return.Return.Type))
+	}
+	if injector.IsReturnError {
+		errType := types.Universe.Lookup("error").Type()
+		if injector.ErrorWrapper != nil {
+			errType = injector.ErrorWrapper.Type
+		}
+		results = append(results, types.NewParam(token.NoPos, nil, "", errType))
+	}
+
+	// Types are compared by their string form like provider keys, since the context.Context
+	// of an injector argument may come from a separately loaded package
+	have := types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(results...), false)
+	if want := unnamedSignature(impl.Method.Signature()); have.String() != want.String() {
+		return nil, fmt.Errorf("injector %s does not match method %s: have %s, want %s", injector.Name, impl.Method.Name(), have, want)
+	}
+
+	ifaceExpr, err := createASTTypeExpr(metaData.Package.Path, impl.Interface, varPool, metaData.Imports)
+	if err != nil {
+		return nil, fmt.Errorf("create interface type expression: %w", err)
+	}
+	referencedImports := make(map[string]*Import)
+	collectImportsFromType(impl.Interface, metaData.Package.Path, metaData.Imports, referencedImports, varPool)
+	for _, imp := range referencedImports {
+		imp.IsUsed = true
+	}
+
+	implName := varPool.GetInjectorVarName(injector.Name, "Impl")
+
+	return []ast.Decl{
+		&ast.GenDecl{
+			Tok: token.TYPE,
+			Specs: []ast.Spec{&ast.TypeSpec{
+				Name: ast.NewIdent(implName),
+				// Valid brace positions on the same line make the printer emit struct{}
+				Type: &ast.StructType{Fields: &ast.FieldList{Opening: 1, Closing: 1}},
+			}},
+		},
+		&ast.FuncDecl{
+			Recv: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent(implName)}}},
+			Name: ast.NewIdent(impl.Method.Name()),
+			Type: funcType,
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{
+				Results: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(injector.Name), Args: args}},
+			}}},
+		},
+		&ast.GenDecl{
+			Tok: token.VAR,
+			Specs: []ast.Spec{&ast.ValueSpec{
+				Names:  []*ast.Ident{ast.NewIdent("_")},
+				Type:   ifaceExpr,
+				Values: []ast.Expr{&ast.CompositeLit{Type: ast.NewIdent(implName)}},
+			}},
+		},
+	}, nil
+}
+
+// unnamedSignature returns sig without its receiver and parameter names.
+func unnamedSignature(sig *types.Signature) *types.Signature {
+	unnamed := func(tuple *types.Tuple) *types.Tuple {
+		vars := make([]*types.Var, 0, tuple.Len())
+		for v := range tuple.Variables() {
+			vars = append(vars, types.NewParam(token.NoPos, nil, "", v.Type()))
+		}
+		return types.NewTuple(vars...)
+	}
+
+	return types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+}
+
+// generateLazyInjectorDecls wraps the injector body in a sync.Once-guarded closure whose
+// result (and error, if any) is cached in package-level variables.
+//
+//	var (
+//		getServiceOnce   sync.Once
+//		getServiceResult *Service
+//		getServiceErr    error
+//	)
+//
+//	func GetService() (*Service, error) {
+//		getServiceOnce.Do(func() {
+//			getServiceResult, getServiceErr = func() (*Service, error) {
+//				...
+//			}()
+//		})
+//		return getServiceResult, getServiceErr
+//	}
+//
+// A getter taking the context of async providers is guarded by a mutex instead, and a failure while
+// the context of the call is done is not cached, so that the next call with a live context retries:
+//
+//	func GetCache(ctx context.Context) (*Cache, error) {
+//		getCacheMu.Lock()
+//		defer getCacheMu.Unlock()
+//		if !getCacheDone {
+//			getCacheResult, getCacheErr = func() (*Cache, error) {
+//				...
+//			}()
+//			getCacheDone = getCacheErr == nil || ctx.Err() == nil
+//		}
+//		return getCacheResult, getCacheErr
+//	}
+func generateLazyInjectorDecls(injector *Injector, funcType *ast.FuncType, stmts []ast.Stmt, varPool *VarPool, imports map[string]*Import) []ast.Decl {
+	syncName := syncPkgName
+	if imp, exists := imports[syncPkgPath]; exists {
+		imp.IsUsed = true
+		syncName = imp.Name
+	} else {
+		name := varPool.GetName(syncPkgName)
+		imports[syncPkgPath] = &Import{
+			Name:          name,
+			IsDefaultName: syncPkgName == name,
+			IsUsed:        true,
+		}
+		syncName = name
+	}
+
+	// The name given to kessoku.Shared was reserved by the parser
+	resultName := injector.SharedName
+	if resultName == "" {
+		resultName = varPool.GetInjectorVarName(injector.Name, "Result")
+	}
+
+	resultSpec := &ast.ValueSpec{
+		Names: []*ast.Ident{ast.NewIdent(resultName)},
+		Type:  injector.Return.Return.ASTTypeExpr,
+	}
+	cached := []ast.Expr{ast.NewIdent(resultName)}
+
+	var errSpec *ast.ValueSpec
+	if injector.IsReturnError {
+		errName := varPool.GetInjectorVarName(injector.Name, "Err")
+		errSpec = &ast.ValueSpec{
+			Names: []*ast.Ident{ast.NewIdent(errName)},
+			Type:  funcType.Results.List[len(funcType.Results.List)-1].Type,
+		}
+		cached = append(cached, ast.NewIdent(errName))
+	}
+
+	initStmt := &ast.AssignStmt{
+		Lhs: cached,
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun: &ast.FuncLit{
+					Type: &ast.FuncType{
+						Params:  &ast.FieldList{},
+						Results: funcType.Results,
+					},
+					Body: &ast.BlockStmt{
+						List: stmts,
+					},
+				},
+			},
+		},
+	}
+
+	var (
+		varSpecs []ast.Spec
+		body     []ast.Stmt
+	)
+	if ctxArg := injector.ContextArg(); ctxArg != nil && errSpec != nil {
+		muName := varPool.GetInjectorVarName(injector.Name, "Mu")
+		doneName := varPool.GetInjectorVarName(injector.Name, "Done")
+		varSpecs = []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(muName)},
+				Type: &ast.SelectorExpr{
+					X:   ast.NewIdent(syncName),
+					Sel: ast.NewIdent("Mutex"),
+				},
+			},
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(doneName)},
+				Type:  ast.NewIdent("bool"),
+			},
+		}
+
+		muCall := func(method string) *ast.CallExpr {
+			return &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   ast.NewIdent(muName),
+					Sel: ast.NewIdent(method),
+				},
+			}
+		}
+		body = []ast.Stmt{
+			&ast.ExprStmt{X: muCall("Lock")},
+			&ast.DeferStmt{Call: muCall("Unlock")},
+			&ast.IfStmt{
+				Cond: &ast.UnaryExpr{
+					Op: token.NOT,
+					X:  ast.NewIdent(doneName),
+				},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						initStmt,
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{ast.NewIdent(doneName)},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{
+								&ast.BinaryExpr{
+									X: &ast.BinaryExpr{
+										X:  ast.NewIdent(errSpec.Names[0].Name),
+										Op: token.EQL,
+										Y:  ast.NewIdent("nil"),
+									},
+									Op: token.LOR,
+									Y: &ast.BinaryExpr{
+										X: &ast.CallExpr{
+											Fun: &ast.SelectorExpr{
+												X:   ast.NewIdent(ctxArg.Param.Name(varPool)),
+												Sel: ast.NewIdent("Err"),
+											},
+										},
+										Op: token.EQL,
+										Y:  ast.NewIdent("nil"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	} else {
+		onceName := varPool.GetInjectorVarName(injector.Name, "Once")
+		varSpecs = []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(onceName)},
+				Type: &ast.SelectorExpr{
+					X:   ast.NewIdent(syncName),
+					Sel: ast.NewIdent("Once"),
+				},
+			},
+		}
+		body = []ast.Stmt{
+			&ast.ExprStmt{
+				X: &ast.CallExpr{
+					Fun: &ast.SelectorExpr{
+						X:   ast.NewIdent(onceName),
+						Sel: ast.NewIdent("Do"),
+					},
+					Args: []ast.Expr{
+						&ast.FuncLit{
+							Type: &ast.FuncType{
+								Params: &ast.FieldList{},
+							},
+							Body: &ast.BlockStmt{
+								List: []ast.Stmt{initStmt},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	varSpecs = append(varSpecs, resultSpec)
+	if errSpec != nil {
+		varSpecs = append(varSpecs, errSpec)
+	}
+	body = append(body, &ast.ReturnStmt{
+		Results: cached,
+	})
+
+	return []ast.Decl{
+		&ast.GenDecl{
+			Tok:    token.VAR,
+			Lparen: 1,
+			Specs:  varSpecs,
+		},
+		&ast.FuncDecl{
+			Name: ast.NewIdent(injector.Name),
+			Type: funcType,
+			Body: &ast.BlockStmt{
+				List: body,
+			},
+		},
+	}
+}
+
+// errorTypeExpr returns the type expression of the error returned by the injector,
+// which is the builtin error unless kessoku.WrapError is declared.
+func errorTypeExpr(pkg string, injector *Injector, varPool *VarPool, imports map[string]*Import) (ast.Expr, error) {
+	if injector.ErrorWrapper == nil {
+		return ast.NewIdent("error"), nil
+	}
+
+	errType, err := createASTTypeExpr(pkg, injector.ErrorWrapper.Type, varPool, imports)
+	if err != nil {
+		return nil, err
+	}
+	referencedImports := make(map[string]*Import)
+	collectImportsFromType(injector.ErrorWrapper.Type, pkg, imports, referencedImports, varPool)
+	for _, imp := range referencedImports {
+		imp.IsUsed = true
+	}
+
+	return errType, nil
+}
+
+// wrapErrExpr converts errExpr with the function declared by kessoku.WrapError, if any.
+func wrapErrExpr(injector *Injector, errExpr ast.Expr) ast.Expr {
+	if injector.ErrorWrapper == nil {
+		return errExpr
+	}
+
+	for _, imp := range injector.ErrorWrapper.ReferencedImports {
+		imp.IsUsed = true
+	}
+
+	return &ast.CallExpr{
+		Fun:  injector.ErrorWrapper.ASTExpr,
+		Args: []ast.Expr{errExpr},
+	}
 }
 
 // generateStmts generates statements with parallel execution support using errgroup
 func generateStmts(varPool *VarPool, pkg string, injector *Injector, imports map[string]*Import) ([]ast.Stmt, error) {
 	var stmts []ast.Stmt
 
+	// Derive the context given to the providers before errgroup derives its own from it
+	var cancelIdent *ast.Ident
+	if injector.WithCancel {
+		var cancelStmt ast.Stmt
+		cancelIdent, cancelStmt = generateCancelContext(injector, varPool, imports)
+		stmts = append(stmts, cancelStmt)
+	}
+
+	// The closer is created first so that providers, including async ones, can add their values
+	var closerIdent *ast.Ident
+	if injector.CleanupCloser {
+		injector.closerVarName = varPool.GetName("closer")
+		closerIdent = ast.NewIdent(injector.closerVarName)
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{closerIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.UnaryExpr{
+				Op: token.AND,
+				X:  &ast.CompositeLit{Type: ast.NewIdent(injector.closerTypeName)},
+			}},
+		})
+	}
+
+	// Each ready check is evaluated once, for both its provider and the returned ready check
+	for _, readyCheck := range injector.ReadyChecks {
+		for _, imp := range readyCheck.Check.ReferencedImports {
+			imp.IsUsed = true
+		}
+		readyCheck.Check.ident.Name = varPool.GetName(readyCheck.Param.Name(varPool) + "Check")
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(readyCheck.Check.ident.Name)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{readyCheck.Check.ASTExpr},
+		})
+	}
+
 	hasChains := hasChainStmts(injector)
 
+	// On errors, the async providers still running are canceled and waited for before the values are
+	// closed, so that none is created after the closer is closed or left blocked on a value that never comes
+	var egCancelIdent *ast.Ident
+	if closerIdent != nil && hasChains && injector.ContextArg() != nil {
+		var cancelStmt ast.Stmt
+		egCancelIdent, cancelStmt = generateCancelContext(injector, varPool, imports)
+		stmts = append(stmts, cancelStmt, &ast.DeferStmt{Call: &ast.CallExpr{Fun: egCancelIdent}})
+	}
+
 	// Initialize async components if needed
 	if hasChains {
 		asyncStmts, err := generateAsyncInitialization(pkg, injector, varPool, imports)
@@ -399,40 +1819,73 @@ func generateStmts(varPool *VarPool, pkg string, injector *Injector, imports map
 		stmts = append(stmts, asyncStmts...)
 	}
 
-	var returnErrStmts func(ast.Expr) []ast.Stmt
-	switch {
-	case !injector.IsReturnError:
-		returnErrStmts = nil
-	case injector.Return != nil && injector.Return.Return != nil && injector.Return.Return.ASTTypeExpr != nil:
-		returnErrStmts = func(errExpr ast.Expr) []ast.Stmt {
-			return []ast.Stmt{
-				&ast.DeclStmt{
+	// waitErrStmts returns the error of the errgroup, whose providers need no waiting anymore
+	var returnErrStmts, waitErrStmts func(ast.Expr) []ast.Stmt
+	if injector.IsReturnError {
+		returnErr := func(errExpr ast.Expr, waited bool) []ast.Stmt {
+			var stmts []ast.Stmt
+			results := make([]ast.Expr, 0, maxInjectorReturnValues)
+			var zeroType ast.Expr
+			if injector.Return != nil && injector.Return.Return != nil {
+				zeroType = injector.Return.Return.ASTTypeExpr
+			}
+			if injector.inspection != nil {
+				zeroType = injector.inspection.typeExpr
+			}
+			if zeroType != nil {
+				stmts = append(stmts, &ast.DeclStmt{
 					Decl: &ast.GenDecl{
 						Tok: token.VAR,
 						Specs: []ast.Spec{
 							&ast.ValueSpec{
 								Names: []*ast.Ident{ast.NewIdent("zero")},
-								Type:  injector.Return.Return.ASTTypeExpr,
+								Type:  zeroType,
 							},
 						},
 					},
-				},
-				&ast.ReturnStmt{
-					Results: []ast.Expr{ast.NewIdent("zero"), errExpr},
-				},
+				})
+				results = append(results, ast.NewIdent("zero"))
 			}
-		}
-	default:
-		returnErrStmts = func(errExpr ast.Expr) []ast.Stmt {
-			return []ast.Stmt{
-				&ast.ReturnStmt{
-					Results: []ast.Expr{errExpr},
-				},
+			if cancelIdent != nil {
+				// The caller gets no cancel function on error, so the context is canceled here
+				stmts = append(stmts, &ast.ExprStmt{X: &ast.CallExpr{Fun: cancelIdent}})
+				results = append(results, ast.NewIdent("nil"))
+			}
+			if egCancelIdent != nil && !waited {
+				stmts = append(stmts,
+					&ast.ExprStmt{X: &ast.CallExpr{Fun: egCancelIdent}},
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{ast.NewIdent("_")},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent("eg"), Sel: ast.NewIdent("Wait")}}},
+					},
+				)
+			}
+			if closerIdent != nil {
+				// The caller gets no closer on error, so the values created so far are closed here
+				stmts = append(stmts, &ast.AssignStmt{
+					Lhs: []ast.Expr{ast.NewIdent("_")},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{&ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: closerIdent, Sel: ast.NewIdent("Close")},
+					}},
+				})
+				results = append(results, ast.NewIdent("nil"))
+			}
+			if len(injector.ReadyChecks) > 0 {
+				results = append(results, ast.NewIdent("nil"))
 			}
+
+			return append(stmts, &ast.ReturnStmt{
+				Results: append(results, wrapErrExpr(injector, errExpr)),
+			})
 		}
+		returnErrStmts = func(errExpr ast.Expr) []ast.Stmt { return returnErr(errExpr, false) }
+		waitErrStmts = func(errExpr ast.Expr) []ast.Stmt { return returnErr(errExpr, true) }
 	}
 
 	// Process statements and collect completion channels
+	markGroupSections(injector.Stmts, "")
 	for _, stmt := range injector.Stmts {
 		newStmts, _ := stmt.Stmt(varPool, injector, returnErrStmts)
 		stmts = append(stmts, newStmts...)
@@ -440,15 +1893,27 @@ func generateStmts(varPool *VarPool, pkg string, injector *Injector, imports map
 
 	// Add async completion handling
 	if hasChains {
-		waitStmts := generateAsyncWaitStatements(injector)
+		waitStmts := generateAsyncWaitStatements(injector, waitErrStmts)
 		stmts = append(stmts, waitStmts...)
 	}
 
 	// Add return statement
 	returnExprs := make([]ast.Expr, 0, maxInjectorReturnValues)
-	if injector.Return != nil && injector.Return.Param != nil {
+	switch {
+	case injector.inspection != nil:
+		returnExprs = append(returnExprs, injector.inspection.lit)
+	case injector.Return != nil && injector.Return.Param != nil:
 		returnExprs = append(returnExprs, ast.NewIdent(injector.Return.Param.Name(varPool)))
 	}
+	if cancelIdent != nil {
+		returnExprs = append(returnExprs, cancelIdent)
+	}
+	if closerIdent != nil {
+		returnExprs = append(returnExprs, closerIdent)
+	}
+	if len(injector.ReadyChecks) > 0 {
+		returnExprs = append(returnExprs, generateReadyCheckFunc(injector, varPool, imports))
+	}
 	if injector.IsReturnError {
 		returnExprs = append(returnExprs, ast.NewIdent("nil"))
 	}
@@ -461,24 +1926,316 @@ func generateStmts(varPool *VarPool, pkg string, injector *Injector, imports map
 	return stmts, nil
 }
 
+// readyCheckFuncType returns the type of the ready check returned by an injector: func(context.Context) error
+func readyCheckFuncType(varPool *VarPool, imports map[string]*Import) *ast.FuncType {
+	return &ast.FuncType{
+		Params: &ast.FieldList{List: []*ast.Field{{
+			Type: &ast.SelectorExpr{X: ast.NewIdent(importName(contextPkgPath, contextPkgName, varPool, imports)), Sel: ast.NewIdent(contextTypeName)},
+		}}},
+		Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("error")}}},
+	}
+}
+
+// generateReadyCheckFunc generates the ready check returned by an injector whose providers
+// declare kessoku.WithReadyCheck, joining the errors of every check bound by generateStmts:
+//
+//	func(ctx context.Context) error {
+//		return errors.Join(
+//			dbCheck.Check(ctx, db),
+//			cacheCheck.Check(ctx, cache),
+//		)
+//	}
+func generateReadyCheckFunc(injector *Injector, varPool *VarPool, imports map[string]*Import) ast.Expr {
+	ctxIdent := ast.NewIdent("ctx")
+
+	checks := make([]ast.Expr, 0, len(injector.ReadyChecks))
+	for _, readyCheck := range injector.ReadyChecks {
+		checks = append(checks, &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: ast.NewIdent(readyCheck.Check.ident.Name), Sel: ast.NewIdent("Check")},
+			Args: []ast.Expr{ctxIdent, ast.NewIdent(readyCheck.Param.Name(varPool))},
+		})
+	}
+
+	funcType := readyCheckFuncType(varPool, imports)
+	funcType.Params.List[0].Names = []*ast.Ident{ctxIdent}
+
+	return &ast.FuncLit{
+		Type: funcType,
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   ast.NewIdent(importName(errorsPkgPath, errorsPkgName, varPool, imports)),
+					Sel: ast.NewIdent("Join"),
+				},
+				Args: checks,
+			}}},
+		}},
+	}
+}
+
+// generateCloserDecls generates the closer type returned by an injector declared with kessoku.CleanupCloser:
+//
+//	type InitializeAppCloser struct {
+//		mu      sync.Mutex
+//		closers []io.Closer
+//		closed  bool
+//	}
+//
+//	func (c *InitializeAppCloser) add(closer io.Closer) {
+//		c.mu.Lock()
+//		defer c.mu.Unlock()
+//		if c.closed {
+//			_ = closer.Close()
+//			return
+//		}
+//		c.closers = append(c.closers, closer)
+//	}
+//
+//	func (c *InitializeAppCloser) Close() error {
+//		c.mu.Lock()
+//		closers := c.closers
+//		c.closers = nil
+//		c.closed = true
+//		c.mu.Unlock()
+//
+//		errs := make([]error, 0, len(closers))
+//		for i := len(closers) - 1; i >= 0; i-- {
+//			errs = append(errs, closers[i].Close())
+//		}
+//		return errors.Join(errs...)
+//	}
+//
+// The mutex guards the values added by async providers, and Close closes each value only once.
+// A value added after Close, by an async provider finishing after the injector failed, is closed
+// right away since nobody else holds it.
+func generateCloserDecls(typeName string, varPool *VarPool, imports map[string]*Import) []ast.Decl {
+	ioCloser := &ast.SelectorExpr{X: ast.NewIdent(importName(ioPkgPath, ioPkgName, varPool, imports)), Sel: ast.NewIdent("Closer")}
+	syncName := importName(syncPkgPath, syncPkgName, varPool, imports)
+	errorsName := importName(errorsPkgPath, errorsPkgName, varPool, imports)
+
+	recv := func() *ast.FieldList {
+		return &ast.FieldList{List: []*ast.Field{{
+			Names: []*ast.Ident{ast.NewIdent("c")},
+			Type:  &ast.StarExpr{X: ast.NewIdent(typeName)},
+		}}}
+	}
+	field := func(name string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{X: ast.NewIdent("c"), Sel: ast.NewIdent(name)}
+	}
+	call := func(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+		return &ast.CallExpr{Fun: fun, Args: args}
+	}
+	mu := func(method string) *ast.CallExpr {
+		return call(&ast.SelectorExpr{X: field("mu"), Sel: ast.NewIdent(method)})
+	}
+	closersIdent, errsIdent, iIdent := ast.NewIdent("closers"), ast.NewIdent("errs"), ast.NewIdent("i")
+
+	return []ast.Decl{
+		&ast.GenDecl{
+			Tok: token.TYPE,
+			Specs: []ast.Spec{&ast.TypeSpec{
+				Name: ast.NewIdent(typeName),
+				Type: &ast.StructType{Fields: &ast.FieldList{List: []*ast.Field{
+					{
+						Names: []*ast.Ident{ast.NewIdent("mu")},
+						Type:  &ast.SelectorExpr{X: ast.NewIdent(syncName), Sel: ast.NewIdent("Mutex")},
+					},
+					{
+						Names: []*ast.Ident{closersIdent},
+						Type:  &ast.ArrayType{Elt: ioCloser},
+					},
+					{
+						Names: []*ast.Ident{ast.NewIdent("closed")},
+						Type:  ast.NewIdent("bool"),
+					},
+				}}},
+			}},
+		},
+		&ast.FuncDecl{
+			Recv: recv(),
+			Name: ast.NewIdent("add"),
+			Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{{
+				Names: []*ast.Ident{ast.NewIdent("closer")},
+				Type:  ioCloser,
+			}}}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ExprStmt{X: mu("Lock")},
+				&ast.DeferStmt{Call: mu("Unlock")},
+				&ast.IfStmt{
+					Cond: field("closed"),
+					Body: &ast.BlockStmt{List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{ast.NewIdent("_")},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{call(&ast.SelectorExpr{X: ast.NewIdent("closer"), Sel: ast.NewIdent("Close")})},
+						},
+						&ast.ReturnStmt{},
+					}},
+				},
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{field("closers")},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{call(ast.NewIdent("append"), field("closers"), ast.NewIdent("closer"))},
+				},
+			}},
+		},
+		&ast.FuncDecl{
+			Recv: recv(),
+			Name: ast.NewIdent("Close"),
+			Type: &ast.FuncType{
+				Params:  &ast.FieldList{},
+				Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("error")}}},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ExprStmt{X: mu("Lock")},
+				&ast.AssignStmt{Lhs: []ast.Expr{closersIdent}, Tok: token.DEFINE, Rhs: []ast.Expr{field("closers")}},
+				&ast.AssignStmt{Lhs: []ast.Expr{field("closers")}, Tok: token.ASSIGN, Rhs: []ast.Expr{ast.NewIdent("nil")}},
+				&ast.AssignStmt{Lhs: []ast.Expr{field("closed")}, Tok: token.ASSIGN, Rhs: []ast.Expr{ast.NewIdent("true")}},
+				&ast.ExprStmt{X: mu("Unlock")},
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{errsIdent},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{call(ast.NewIdent("make"),
+						&ast.ArrayType{Elt: ast.NewIdent("error")},
+						&ast.BasicLit{Kind: token.INT, Value: "0"},
+						call(ast.NewIdent("len"), closersIdent),
+					)},
+				},
+				&ast.ForStmt{
+					Init: &ast.AssignStmt{
+						Lhs: []ast.Expr{iIdent},
+						Tok: token.DEFINE,
+						Rhs: []ast.Expr{&ast.BinaryExpr{
+							X:  call(ast.NewIdent("len"), closersIdent),
+							Op: token.SUB,
+							Y:  &ast.BasicLit{Kind: token.INT, Value: "1"},
+						}},
+					},
+					Cond: &ast.BinaryExpr{X: iIdent, Op: token.GEQ, Y: &ast.BasicLit{Kind: token.INT, Value: "0"}},
+					Post: &ast.IncDecStmt{X: iIdent, Tok: token.DEC},
+					Body: &ast.BlockStmt{List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{errsIdent},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{call(ast.NewIdent("append"), errsIdent, call(&ast.SelectorExpr{
+								X:   &ast.IndexExpr{X: closersIdent, Index: iIdent},
+								Sel: ast.NewIdent("Close"),
+							}))},
+						},
+					}},
+				},
+				&ast.ReturnStmt{Results: []ast.Expr{&ast.CallExpr{
+					Fun:      &ast.SelectorExpr{X: ast.NewIdent(errorsName), Sel: ast.NewIdent("Join")},
+					Args:     []ast.Expr{errsIdent},
+					Ellipsis: 1, // Any valid position makes the printer emit errs...
+				}}},
+			}},
+		},
+	}
+}
+
+// importName returns the name of the import of path, adding it with the default name
+// when it is missing, and marks the import used.
+func importName(path, defaultName string, varPool *VarPool, imports map[string]*Import) string {
+	if imp, exists := imports[path]; exists {
+		imp.IsUsed = true
+		return imp.Name
+	}
+
+	name := varPool.GetName(defaultName)
+	imports[path] = &Import{
+		Name:          name,
+		IsDefaultName: defaultName == name,
+		IsUsed:        true,
+	}
+
+	return name
+}
+
+// generateCancelContext derives the cancelable context of an injector declared with kessoku.WithCancel,
+// shadowing the context argument so that the providers receive it:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+func generateCancelContext(injector *Injector, varPool *VarPool, imports map[string]*Import) (*ast.Ident, ast.Stmt) {
+	ctxName := injector.ContextArg().Param.Name(varPool)
+	cancelIdent := ast.NewIdent(varPool.GetName("cancel"))
+
+	return cancelIdent, &ast.AssignStmt{
+		Lhs: []ast.Expr{ast.NewIdent(ctxName), cancelIdent},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun:  contextSelector(imports, "WithCancel"),
+				Args: []ast.Expr{ast.NewIdent(ctxName)},
+			},
+		},
+	}
+}
+
+// contextSelector returns name of the context package through its import, marking the import used.
+func contextSelector(imports map[string]*Import, name string) ast.Expr {
+	pkgName := contextPkgName
+	if imp, ok := imports[contextPkgPath]; ok {
+		imp.IsUsed = true
+		pkgName = imp.Name
+	}
+
+	return &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: ast.NewIdent(name)}
+}
+
 func (stmt *InjectorProviderCallStmt) Stmt(varPool *VarPool, injector *Injector, returnErrStmts func(ast.Expr) []ast.Stmt) ([]ast.Stmt, []string) {
 	var stmts []ast.Stmt
 
 	// Add channel synchronization for async scenarios
 	hasChains := hasChainStmts(injector)
 	if hasChains {
-		waitStmt := stmt.generateChannelWaitStatement(varPool, injector, returnErrStmts)
-		if waitStmt != nil {
-			stmts = append(stmts, waitStmt)
-		}
+		stmts = append(stmts, stmt.generateChannelWaitStatements(varPool, injector, returnErrStmts)...)
 	}
 
 	// Generate provider function call
 	args := stmt.buildArguments(varPool)
+
+	if stmt.Provider.Type == ProviderTypePopulate {
+		return append(stmts, stmt.buildPopulateStatements(args)...), nil
+	}
+
+	// Async scenarios have the variable declared already and assign nil below instead
+	if stmt.Provider.Type == ProviderTypeNil && !hasChains {
+		for _, reference := range stmt.Provider.ReferencedImports {
+			reference.IsUsed = true
+		}
+		return append(stmts, stmt.buildNilDeclaration(varPool)), nil
+	}
+
+	// The variant of kessoku.DynamicOverrides looks up the value of a single-value provider
+	// before calling it, so the call is generated into the else branch of the lookup
+	overrideParam := stmt.overrideParam(injector, varPool)
+	outerStmts := stmts
+	if overrideParam != nil {
+		stmts = nil
+	}
+
+	if stmt.Provider.Type == ProviderTypeEnv {
+		stmts = append(stmts, stmt.buildEnvStatements(varPool, returnErrStmts, hasChains || overrideParam != nil)...)
+		return stmt.finishStatements(varPool, injector, hasChains, overrideParam, outerStmts, stmts), nil
+	}
+
+	var spanEndStmt ast.Stmt
+	if stmt.Provider.SpanName != "" {
+		var spanStmt ast.Stmt
+		spanStmt, spanEndStmt = stmt.buildSpanStatements(varPool, args)
+		stmts = append(stmts, spanStmt)
+	}
+
 	rhs := stmt.buildProviderCall(args)
 
 	// Generate assignment statement
 	lhs := stmt.buildLhsExpressions(varPool)
+	// Every value is discarded when the provider is only waited for by kessoku.After, leaving nothing to declare
+	declaresVars := slices.ContainsFunc(lhs, func(expr ast.Expr) bool {
+		ident, ok := expr.(*ast.Ident)
+		return !ok || ident.Name != "_"
+	})
 
 	var errorHandleStmt ast.Stmt
 	if stmt.Provider.IsReturnError {
@@ -501,38 +2258,170 @@ func (stmt *InjectorProviderCallStmt) Stmt(varPool *VarPool, injector *Injector,
 		errorHandleStmt = stmt.buildErrorHandlingStatement(errIdent, returnErrStmts)
 	}
 
-	assignStmt := stmt.buildAssignmentStatement(lhs, rhs, hasChains)
+	assignStmt := stmt.buildAssignmentStatement(lhs, rhs, hasChains || !declaresVars || overrideParam != nil)
 	stmts = append(stmts, assignStmt)
 
+	// End the span right after the call so that it covers only this provider
+	if spanEndStmt != nil {
+		stmts = append(stmts, spanEndStmt)
+	}
+
 	if errorHandleStmt != nil {
 		stmts = append(stmts, errorHandleStmt)
 	}
 
+	// Values of failing providers are decorated once the error is checked; discarded values are not
+	if len(stmt.Provider.Decorators) > 0 && stmt.Provider.IsReturnError && declaresVars {
+		decorated := lhs[0]
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{decorated},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{stmt.buildDecoratorCalls(decorated, args[len(args)-stmt.Provider.decoratorRequires():])},
+		})
+	}
+
+	return stmt.finishStatements(varPool, injector, hasChains, overrideParam, outerStmts, stmts), nil
+}
+
+// finishStatements completes the statements of the provider call with the handling of its values:
+// their closing, their lookup in the variant of kessoku.DynamicOverrides, wrapping the call when
+// overrideParam is set, and the signaling of async consumers. outerStmts precede the lookup.
+func (stmt *InjectorProviderCallStmt) finishStatements(varPool *VarPool, injector *Injector, hasChains bool, overrideParam *InjectorParam, outerStmts, stmts []ast.Stmt) []ast.Stmt {
+	// Values are added to the closer only once the provider has succeeded
+	for _, param := range stmt.Returns {
+		if param.closes {
+			stmts = append(stmts, &ast.ExprStmt{X: &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: ast.NewIdent(injector.closerVarName), Sel: ast.NewIdent("add")},
+				Args: []ast.Expr{ast.NewIdent(param.Name(varPool))},
+			}})
+		}
+	}
+
+	switch {
+	case overrideParam != nil:
+		stmts = append(outerStmts, stmt.buildOverrideLookup(injector, varPool, overrideParam, !hasChains, stmts)...)
+	case injector.overrides != nil:
+		// Providers of several values are called, and the values found in the map replace their results
+		for _, param := range stmt.overridableParams() {
+			stmts = append(stmts, stmt.buildOverrideLookup(injector, varPool, param, false, nil)...)
+		}
+	}
+
 	// Add channel cleanup for async scenarios
 	if hasChains {
-		closeStmt := stmt.generateChannelCloseStatement(varPool)
-		if closeStmt != nil {
-			stmts = append(stmts, closeStmt)
-		}
+		stmts = append(stmts, stmt.generateChannelCloseStatements(varPool, injector)...)
 	}
 
 	for _, reference := range stmt.Provider.ReferencedImports {
 		reference.IsUsed = true // Mark imports used by this provider as used
 	}
 
-	return stmts, nil
+	if stmt.groupComment != "" && len(stmts) > 0 {
+		if injector.comments == nil {
+			injector.comments = make(map[ast.Stmt]string)
+		}
+		injector.comments[stmts[0]] = stmt.groupComment
+	}
+
+	return stmts
 }
 
-func (stmt *InjectorProviderCallStmt) channelsWait(channels []ast.Expr, injector *Injector, returnErrStmts func(ast.Expr) []ast.Stmt) ast.Stmt {
-	// Check if context is available
-	hasCtx := false
-	for _, arg := range injector.Args {
-		if isContextType(arg.Type) {
-			hasCtx = true
-			break
+// buildEnvStatements reads the environment variable of a kessoku.Env provider into its value,
+// declaring the value unless predeclared, and returns an error when the variable is empty or
+// cannot be parsed:
+//
+//	numEnv := os.Getenv("PORT")
+//	if numEnv == "" {
+//		var zero *Server
+//		return zero, errors.New("environment variable PORT is not set")
+//	}
+//	num, err := strconv.Atoi(numEnv)
+//	if err != nil {
+//		var zero *Server
+//		return zero, fmt.Errorf("parse environment variable PORT as int: %w", err)
+//	}
+//
+// String values are checked after they are read, with no variable in between.
+func (stmt *InjectorProviderCallStmt) buildEnvStatements(varPool *VarPool, returnErrStmts func(ast.Expr) []ast.Stmt, predeclared bool) []ast.Stmt {
+	env := stmt.Provider.Env
+	valueName := stmt.Returns[0].Name(varPool)
+	valueIdent := ast.NewIdent(valueName)
+	valueTok := token.DEFINE
+	if predeclared {
+		valueTok = token.ASSIGN
+	}
+
+	call := func(pkgName, name string, args ...ast.Expr) ast.Expr {
+		return &ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: ast.NewIdent(name)}, Args: args}
+	}
+	convert := func(expr ast.Expr) ast.Expr {
+		if !env.Convert {
+			return expr
+		}
+		return &ast.CallExpr{Fun: stmt.Provider.ASTExpr, Args: []ast.Expr{expr}}
+	}
+	returnErrIf := func(cond, errExpr ast.Expr) ast.Stmt {
+		return &ast.IfStmt{Cond: cond, Body: &ast.BlockStmt{List: returnErrStmts(errExpr)}}
+	}
+	isEmpty := func(expr ast.Expr) ast.Expr {
+		return &ast.BinaryExpr{X: expr, Op: token.EQL, Y: &ast.BasicLit{Kind: token.STRING, Value: `""`}}
+	}
+
+	getenv := call(env.osName, "Getenv", &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(env.Key)})
+	notSetErr := call(env.errorsName, "New", &ast.BasicLit{
+		Kind:  token.STRING,
+		Value: strconv.Quote("environment variable " + env.Key + " is not set"),
+	})
+
+	if env.Kind == types.String {
+		return []ast.Stmt{
+			&ast.AssignStmt{Lhs: []ast.Expr{valueIdent}, Tok: valueTok, Rhs: []ast.Expr{convert(getenv)}},
+			returnErrIf(isEmpty(valueIdent), notSetErr),
 		}
 	}
 
+	parseFunc, kindName := "Atoi", "int"
+	if env.Kind == types.Bool {
+		parseFunc, kindName = "ParseBool", "bool"
+	}
+
+	rawIdent := ast.NewIdent(varPool.GetName(valueName + "Env"))
+	errIdent := ast.NewIdent(varPool.GetName("err"))
+	stmts := []ast.Stmt{
+		&ast.AssignStmt{Lhs: []ast.Expr{rawIdent}, Tok: token.DEFINE, Rhs: []ast.Expr{getenv}},
+		returnErrIf(isEmpty(rawIdent), notSetErr),
+	}
+
+	// The parsed value is assigned through a variable of its own when it is converted or predeclared
+	parsedIdent := valueIdent
+	if env.Convert || predeclared {
+		parsedIdent = ast.NewIdent(varPool.GetName(kindName + "Value"))
+	}
+	stmts = append(stmts,
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{parsedIdent, errIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{call(env.strconvName, parseFunc, rawIdent)},
+		},
+		returnErrIf(
+			&ast.BinaryExpr{X: errIdent, Op: token.NEQ, Y: ast.NewIdent("nil")},
+			call(env.fmtName, "Errorf", &ast.BasicLit{
+				Kind:  token.STRING,
+				Value: strconv.Quote("parse environment variable " + strings.ReplaceAll(env.Key, "%", "%%") + " as " + kindName + ": %w"),
+			}, errIdent),
+		),
+	)
+	if parsedIdent != valueIdent {
+		stmts = append(stmts, &ast.AssignStmt{Lhs: []ast.Expr{valueIdent}, Tok: valueTok, Rhs: []ast.Expr{convert(parsedIdent)}})
+	}
+
+	return stmts
+}
+
+func (stmt *InjectorProviderCallStmt) channelsWait(channels []ast.Expr, injector *Injector, returnErrStmts func(ast.Expr) []ast.Stmt) ast.Stmt {
+	// Check if context is available
+	hasCtx := injector.ContextArg() != nil
+
 	if len(channels) == 1 {
 		// Single channel case
 		return stmt.buildWaitStatement(hasCtx, channels[0], returnErrStmts)
@@ -704,7 +2593,115 @@ func (stmt *InjectorChainStmt) Stmt(varPool *VarPool, injector *Injector, _ func
 	}, imports
 }
 
+// markGroupSections sets the section comment of the provider calls of stmts starting a kessoku.Group,
+// given the group of the section they continue. Providers without a group continue the current
+// section, so ungrouped providers in between do not repeat its comment. A chain continues the
+// section it is started in, and the section continues after it, so chains moved next to the
+// producer of their values do not repeat the comment either. It runs on the final order of the
+// statements, after chains were moved.
+func markGroupSections(stmts []InjectorStmt, group string) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *InjectorChainStmt:
+			markGroupSections(s.Statements, group)
+		case *InjectorProviderCallStmt:
+			s.groupComment = ""
+			if s.Provider.Group != "" && s.Provider.Group != group {
+				group = s.Provider.Group
+				s.groupComment = "// --- " + group + " ---"
+			}
+		}
+	}
+}
+
 // buildLhsExpressions builds the left-hand side expressions for assignment
+// overridableParams returns the values assigned by the provider call that the variant of
+// kessoku.DynamicOverrides can take from its map. Discarded values are never looked up.
+func (stmt *InjectorProviderCallStmt) overridableParams() []*InjectorParam {
+	if stmt.Provider.Type == ProviderTypeNil || stmt.Provider.Type == ProviderTypePopulate {
+		return nil
+	}
+
+	var params []*InjectorParam
+	for _, param := range stmt.Returns {
+		if param.refCounter == 0 || slices.Contains(params, param) {
+			continue
+		}
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// overrideParam returns the value looked up before calling the provider in the variant of
+// kessoku.DynamicOverrides, or nil if the provider assigns anything else or overrides are not generated.
+func (stmt *InjectorProviderCallStmt) overrideParam(injector *Injector, varPool *VarPool) *InjectorParam {
+	if injector.overrides == nil {
+		return nil
+	}
+
+	params := stmt.overridableParams()
+	if len(params) != 1 || len(stmt.buildLhsExpressions(varPool)) != 1 {
+		return nil
+	}
+
+	return params[0]
+}
+
+// buildOverrideLookup builds the lookup of a value in the map of the variant of kessoku.DynamicOverrides,
+// declaring its variable first if declare is set. elseStmts run when the map has no value of the type:
+//
+//	var database *Database
+//	if override, ok := overrides[reflect.TypeFor[*Database]()]; ok {
+//		database = override.(*Database)
+//	} else {
+//		...
+//	}
+func (stmt *InjectorProviderCallStmt) buildOverrideLookup(injector *Injector, varPool *VarPool, param *InjectorParam, declare bool, elseStmts []ast.Stmt) []ast.Stmt {
+	overrides := injector.overrides
+	typeExpr := overrides.typeExprs[param]
+	paramIdent := ast.NewIdent(param.Name(varPool))
+	valueIdent, okIdent := ast.NewIdent(overrides.valueName), ast.NewIdent("ok")
+
+	var stmts []ast.Stmt
+	if declare {
+		stmts = append(stmts, &ast.DeclStmt{
+			Decl: &ast.GenDecl{
+				Tok: token.VAR,
+				Specs: []ast.Spec{&ast.ValueSpec{
+					Names: []*ast.Ident{paramIdent},
+					Type:  typeExpr,
+				}},
+			},
+		})
+	}
+
+	lookup := &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{valueIdent, okIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.IndexExpr{
+				X: ast.NewIdent(overrides.mapName),
+				Index: &ast.CallExpr{Fun: &ast.IndexExpr{
+					X:     &ast.SelectorExpr{X: ast.NewIdent(overrides.reflectName), Sel: ast.NewIdent("TypeFor")},
+					Index: typeExpr,
+				}},
+			}},
+		},
+		Cond: okIdent,
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.AssignStmt{
+			Lhs: []ast.Expr{paramIdent},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.TypeAssertExpr{X: valueIdent, Type: typeExpr}},
+		}}},
+	}
+	if len(elseStmts) > 0 {
+		lookup.Else = &ast.BlockStmt{List: elseStmts}
+	}
+
+	return append(stmts, lookup)
+}
+
 func (stmt *InjectorProviderCallStmt) buildLhsExpressions(varPool *VarPool) []ast.Expr {
 	var lhs []ast.Expr
 
@@ -725,33 +2722,193 @@ func (stmt *InjectorProviderCallStmt) buildLhsExpressions(varPool *VarPool) []as
 func (stmt *InjectorProviderCallStmt) buildArguments(varPool *VarPool) []ast.Expr {
 	var args []ast.Expr
 
-	// Add input parameters
-	for _, arg := range stmt.Arguments {
-		args = append(args, ast.NewIdent(arg.Param.Name(varPool)))
+	// Add input parameters; trailing span dependencies are consumed by the tracer instead
+	for _, arg := range stmt.Arguments[:len(stmt.Provider.Requires)] {
+		var expr ast.Expr = ast.NewIdent(arg.Param.Name(varPool))
+		switch arg.Unary {
+		case token.AND:
+			expr = &ast.UnaryExpr{Op: token.AND, X: expr}
+		case token.MUL:
+			expr = &ast.StarExpr{X: expr}
+		}
+		args = append(args, expr)
 	}
 
 	return args
 }
 
+// buildSpanStatements builds the tracer call that starts the provider span and the call that ends it.
+// Context arguments of the provider in args are replaced with the span context.
+func (stmt *InjectorProviderCallStmt) buildSpanStatements(varPool *VarPool, args []ast.Expr) (ast.Stmt, ast.Stmt) {
+	tracerArg := stmt.Arguments[len(stmt.Provider.Requires)]
+	ctxArg := stmt.Arguments[len(stmt.Provider.Requires)+1]
+
+	spanCtxName := "_"
+	for i, arg := range stmt.Arguments[:len(stmt.Provider.Requires)] {
+		if arg.Param != ctxArg.Param {
+			continue
+		}
+		if spanCtxName == "_" {
+			spanCtxName = varPool.GetName("spanCtx")
+		}
+		args[i] = ast.NewIdent(spanCtxName)
+	}
+	endName := varPool.GetName("end")
+
+	/*
+		ast of:
+		spanCtx, end := tracer(ctx, "name")
+	*/
+	spanStmt := &ast.AssignStmt{
+		Lhs: []ast.Expr{ast.NewIdent(spanCtxName), ast.NewIdent(endName)},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun: ast.NewIdent(tracerArg.Param.Name(varPool)),
+				Args: []ast.Expr{
+					ast.NewIdent(ctxArg.Param.Name(varPool)),
+					&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(stmt.Provider.SpanName)},
+				},
+			},
+		},
+	}
+
+	endStmt := &ast.ExprStmt{
+		X: &ast.CallExpr{Fun: ast.NewIdent(endName)},
+	}
+
+	return spanStmt, endStmt
+}
+
 // buildProviderCall builds the provider function call expression
 func (stmt *InjectorProviderCallStmt) buildProviderCall(args []ast.Expr) []ast.Expr {
-	return []ast.Expr{
-		&ast.CallExpr{
-			Fun: &ast.CallExpr{
-				Fun: &ast.SelectorExpr{
-					X:   stmt.Provider.ASTExpr,
-					Sel: ast.NewIdent("Fn"),
+	if stmt.Provider.Type == ProviderTypeNil {
+		return []ast.Expr{ast.NewIdent("nil")}
+	}
+	if stmt.Provider.Type == ProviderTypePool {
+		return []ast.Expr{stmt.buildPoolAcquire()}
+	}
+	if stmt.Provider.Type == ProviderTypeCollect {
+		return []ast.Expr{&ast.CompositeLit{Type: &ast.ArrayType{Elt: stmt.Provider.ASTExpr}, Elts: args}}
+	}
+	if stmt.Provider.Type == ProviderTypeFactory {
+		return []ast.Expr{stmt.Provider.Factory.expr()}
+	}
+	if stmt.Provider.CallExpr != nil {
+		// kessoku.HTTPClient, kessoku.Call, and kessoku.Const take no dependencies, so their expression is assigned directly
+		return []ast.Expr{stmt.Provider.CallExpr}
+	}
+
+	decoratorArgs := len(args) - stmt.Provider.decoratorRequires()
+	var call ast.Expr = &ast.CallExpr{
+		Fun: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   stmt.Provider.ASTExpr,
+				Sel: ast.NewIdent("Fn"),
+			},
+		},
+		Args: args[:decoratorArgs],
+	}
+
+	// The error of the provider is checked before its value is decorated
+	if !stmt.Provider.IsReturnError {
+		call = stmt.buildDecoratorCalls(call, args[decoratorArgs:])
+	}
+
+	return []ast.Expr{call}
+}
+
+// expr returns the injector called by the factory, or a closure adding a nil error to its result:
+//
+//	func() (*Session, error) { return InitializeSession(), nil }
+func (f *Factory) expr() ast.Expr {
+	if !f.wrapError {
+		return ast.NewIdent(f.Injector)
+	}
+
+	for _, imp := range f.returnImports {
+		imp.IsUsed = true
+	}
+
+	return &ast.FuncLit{
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: f.ReturnType},
+				{Type: ast.NewIdent("error")},
+			}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.ReturnStmt{Results: []ast.Expr{
+				&ast.CallExpr{Fun: ast.NewIdent(f.Injector)},
+				ast.NewIdent("nil"),
+			}},
+		}},
+	}
+}
+
+// buildDecoratorCalls wraps value in the calls of the decorators of kessoku.Decorate, in order,
+// passing each its arguments from args:
+//
+//	WithLogging(WithRecovery(value), logger)
+func (stmt *InjectorProviderCallStmt) buildDecoratorCalls(value ast.Expr, args []ast.Expr) ast.Expr {
+	for _, decorator := range stmt.Provider.Decorators {
+		value = &ast.CallExpr{
+			Fun:  decorator.ASTExpr,
+			Args: append([]ast.Expr{value}, args[:len(decorator.Requires)]...),
+		}
+		args = args[len(decorator.Requires):]
+	}
+
+	return value
+}
+
+// buildPopulateStatements assigns the arguments following the target to the target's fields:
+//
+//	target.Field = arg
+func (stmt *InjectorProviderCallStmt) buildPopulateStatements(args []ast.Expr) []ast.Stmt {
+	stmts := make([]ast.Stmt, 0, len(stmt.Provider.StructFields))
+	fieldArgs := args[1:]
+	for _, field := range stmt.Provider.StructFields {
+		// Fields that no provider supplies take the literal of their default tag instead of an argument
+		value := field.Default
+		if !field.UseDefault {
+			value, fieldArgs = fieldArgs[0], fieldArgs[1:]
+		}
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.SelectorExpr{X: args[0], Sel: ast.NewIdent(field.Name)}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{value},
+		})
+	}
+
+	return stmts
+}
+
+// buildNilDeclaration declares the interface variable provided by kessoku.Nil:
+//
+//	var x Iface = nil
+func (stmt *InjectorProviderCallStmt) buildNilDeclaration(varPool *VarPool) ast.Stmt {
+	lhs := stmt.buildLhsExpressions(varPool)
+
+	return &ast.DeclStmt{
+		Decl: &ast.GenDecl{
+			Tok: token.VAR,
+			Specs: []ast.Spec{
+				&ast.ValueSpec{
+					Names:  []*ast.Ident{lhs[0].(*ast.Ident)},
+					Type:   stmt.Provider.ASTExpr,
+					Values: []ast.Expr{ast.NewIdent("nil")},
 				},
 			},
-			Args: args,
 		},
 	}
 }
 
 // buildAssignmentStatement builds the assignment statement
-func (stmt *InjectorProviderCallStmt) buildAssignmentStatement(lhs, rhs []ast.Expr, hasChains bool) ast.Stmt {
+func (stmt *InjectorProviderCallStmt) buildAssignmentStatement(lhs, rhs []ast.Expr, predeclared bool) ast.Stmt {
 	tokenType := token.DEFINE
-	if hasChains {
+	if predeclared {
 		tokenType = token.ASSIGN
 	}
 
@@ -780,8 +2937,8 @@ func (stmt *InjectorProviderCallStmt) buildErrorHandlingStatement(errIdent *ast.
 	}
 }
 
-// generateChannelWaitStatement generates channel wait statements for async coordination
-func (stmt *InjectorProviderCallStmt) generateChannelWaitStatement(varPool *VarPool, injector *Injector, returnErrStmts func(ast.Expr) []ast.Stmt) ast.Stmt {
+// generateChannelWaitStatements generates channel wait statements for async coordination
+func (stmt *InjectorProviderCallStmt) generateChannelWaitStatements(varPool *VarPool, injector *Injector, returnErrStmts func(ast.Expr) []ast.Stmt) []ast.Stmt {
 	var channels []ast.Expr
 
 	// Collect channels from dependencies
@@ -795,11 +2952,26 @@ func (stmt *InjectorProviderCallStmt) generateChannelWaitStatement(varPool *VarP
 		return nil
 	}
 
-	return stmt.channelsWait(channels, injector, returnErrStmts)
+	if injector.ChannelTrace == nil {
+		return []ast.Stmt{stmt.channelsWait(channels, injector, returnErrStmts)}
+	}
+
+	// Wait for each channel separately so that every event names its channel
+	hasCtx := injector.ContextArg() != nil
+	stmts := make([]ast.Stmt, 0, 3*len(channels))
+	for _, channel := range channels {
+		stmts = append(stmts,
+			traceChannelStmt(injector, "wait", channel),
+			stmt.buildWaitStatement(hasCtx, channel, returnErrStmts),
+			traceChannelStmt(injector, "ready", channel),
+		)
+	}
+
+	return stmts
 }
 
-// generateChannelCloseStatement generates channel close statements for async coordination
-func (stmt *InjectorProviderCallStmt) generateChannelCloseStatement(varPool *VarPool) ast.Stmt {
+// generateChannelCloseStatements generates channel close statements for async coordination
+func (stmt *InjectorProviderCallStmt) generateChannelCloseStatements(varPool *VarPool, injector *Injector) []ast.Stmt {
 	var channels []ast.Expr
 
 	// Collect channels from output parameters
@@ -813,5 +2985,36 @@ func (stmt *InjectorProviderCallStmt) generateChannelCloseStatement(varPool *Var
 		return nil
 	}
 
-	return stmt.channelsClose(channels)
+	if injector.ChannelTrace == nil {
+		return []ast.Stmt{stmt.channelsClose(channels)}
+	}
+
+	stmts := make([]ast.Stmt, 0, 2*len(channels))
+	for _, channel := range channels {
+		stmts = append(stmts,
+			traceChannelStmt(injector, "close", channel),
+			stmt.channelsClose([]ast.Expr{channel}),
+		)
+	}
+
+	return stmts
+}
+
+// traceChannelStmt reports a channel event to the kessoku.WithChannelTrace option of the injector:
+//
+//	channelTrace.Trace("InitializeApp", "wait", "configCh")
+func traceChannelStmt(injector *Injector, event string, channel ast.Expr) ast.Stmt {
+	return &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent(injector.ChannelTrace.varName),
+				Sel: ast.NewIdent("Trace"),
+			},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(injector.Name)},
+				&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(event)},
+				&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(types.ExprString(channel))},
+			},
+		},
+	}
 }
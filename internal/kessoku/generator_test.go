@@ -3,9 +3,17 @@ package kessoku
 import (
 	"bytes"
 	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
 	"go/types"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func createTestMetaData() *MetaData {
@@ -1471,3 +1479,2269 @@ func TestVarPool_GetChannel(t *testing.T) {
 		}
 	})
 }
+
+// createTestServiceInjector creates an injector that builds *Service from a single provider.
+func createTestServiceInjector(name string, isReturnError bool) *Injector {
+	_, serviceType, _ := createTestTypes()
+	serviceTypeExpr, _, _, serviceProviderExpr := createTestAST()
+
+	serviceParam := NewInjectorParam([]types.Type{serviceType}, false)
+	serviceParam.Ref(false)
+
+	return &Injector{
+		Name: name,
+		Stmts: []InjectorStmt{
+			&InjectorProviderCallStmt{
+				Provider: &ProviderSpec{
+					Type:              ProviderTypeFunction,
+					Provides:          [][]types.Type{{serviceType}},
+					Requires:          []types.Type{},
+					IsReturnError:     isReturnError,
+					ASTExpr:           serviceProviderExpr,
+					ReferencedImports: make(map[string]*Import),
+				},
+				Arguments: []*InjectorCallArgument{},
+				Returns:   []*InjectorParam{serviceParam},
+			},
+		},
+		Return: &InjectorReturn{
+			Param: serviceParam,
+			Return: &Return{
+				Type:        serviceType,
+				ASTTypeExpr: serviceTypeExpr,
+			},
+		},
+		IsReturnError: isReturnError,
+	}
+}
+
+func TestGenerate_Env(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("main", "main")
+	serverType := types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, "Server", nil), types.NewStruct(nil, nil), nil))
+	portType := types.NewNamed(types.NewTypeName(0, pkg, "Port", nil), types.Typ[types.Int], nil)
+
+	tests := []struct {
+		valueType types.Type
+		typeExpr  ast.Expr
+		name      string
+		contains  []string
+		kind      types.BasicKind
+		convert   bool
+	}{
+		{
+			name:      "string",
+			valueType: types.Typ[types.String],
+			typeExpr:  ast.NewIdent("string"),
+			kind:      types.String,
+			contains: []string{
+				"str := os.Getenv(\"APP_ENV\")\n",
+				"if str == \"\" {\n\t\tvar zero *Server\n\t\treturn zero, errors.New(\"environment variable APP_ENV is not set\")\n\t}",
+			},
+		},
+		{
+			name:      "int",
+			valueType: types.Typ[types.Int],
+			typeExpr:  ast.NewIdent("int"),
+			kind:      types.Int,
+			contains: []string{
+				"numEnv := os.Getenv(\"APP_ENV\")\n",
+				"if numEnv == \"\" {\n\t\tvar zero *Server\n\t\treturn zero, errors.New(\"environment variable APP_ENV is not set\")\n\t}",
+				"num, err := strconv.Atoi(numEnv)\n",
+				"return zero, fmt.Errorf(\"parse environment variable APP_ENV as int: %w\", err)",
+			},
+		},
+		{
+			name:      "bool",
+			valueType: types.Typ[types.Bool],
+			typeExpr:  ast.NewIdent("bool"),
+			kind:      types.Bool,
+			contains: []string{
+				"flagEnv := os.Getenv(\"APP_ENV\")\n",
+				"flag, err := strconv.ParseBool(flagEnv)\n",
+				"return zero, fmt.Errorf(\"parse environment variable APP_ENV as bool: %w\", err)",
+			},
+		},
+		{
+			name:      "named int",
+			valueType: portType,
+			typeExpr:  ast.NewIdent("Port"),
+			kind:      types.Int,
+			convert:   true,
+			contains: []string{
+				"portEnv := os.Getenv(\"APP_ENV\")\n",
+				"intValue, err := strconv.Atoi(portEnv)\n",
+				"port := Port(intValue)\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &EnvVar{Key: "APP_ENV", Kind: tt.kind, Convert: tt.convert, osName: "os", errorsName: "errors"}
+			if tt.kind != types.String {
+				env.fmtName, env.strconvName = "fmt", "strconv"
+			}
+
+			build := &BuildDirective{
+				InjectorName: "InitializeServer",
+				Return: &Return{
+					Type:        serverType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Server")},
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeEnv,
+						Provides:          [][]types.Type{{tt.valueType}},
+						IsReturnError:     true,
+						Env:               env,
+						ASTExpr:           tt.typeExpr,
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serverType}},
+						Requires:          []types.Type{tt.valueType},
+						ASTExpr:           ast.NewIdent("NewServer"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			// The variable is read and parsed by the generated code rather than through kessoku
+			generated := buf.String()
+			for _, expected := range tt.contains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			if strings.Contains(generated, "kessoku.Env") {
+				t.Errorf("Expected generated code not to call kessoku.Env, got:\n%s", generated)
+			}
+		})
+	}
+}
+
+func TestGenerate_LazyInjector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		expectedContains    []string
+		expectedNotContains []string
+		sharedName          string
+		isReturnError       bool
+	}{
+		{
+			name:          "lazy injector with error",
+			isReturnError: true,
+			expectedContains: []string{
+				`"sync"`,
+				"getServiceOnce   sync.Once",
+				"getServiceResult *Service",
+				"getServiceErr    error",
+				"func GetService() (*Service, error) {",
+				"getServiceOnce.Do(func() {",
+				"getServiceResult, getServiceErr = func() (*Service, error) {",
+				"return getServiceResult, getServiceErr",
+			},
+		},
+		{
+			name:          "lazy injector without error",
+			isReturnError: false,
+			expectedContains: []string{
+				"getServiceOnce   sync.Once",
+				"getServiceResult = func() *Service {",
+				"return getServiceResult\n",
+			},
+			expectedNotContains: []string{
+				"getServiceErr",
+			},
+		},
+		{
+			name:          "shared result",
+			isReturnError: true,
+			sharedName:    "SharedService",
+			expectedContains: []string{
+				"getServiceOnce sync.Once",
+				"SharedService  *Service",
+				"getServiceErr  error",
+				"SharedService, getServiceErr = func() (*Service, error) {",
+				"return SharedService, getServiceErr",
+			},
+			expectedNotContains: []string{
+				"getServiceResult",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			injector := createTestServiceInjector("GetService", tt.isReturnError)
+			injector.IsLazy = true
+			injector.SharedName = tt.sharedName
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", createTestMetaData(), []*Injector{injector}, NewVarPool()); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, notExpected := range tt.expectedNotContains {
+				if strings.Contains(generated, notExpected) {
+					t.Errorf("Expected generated code NOT to contain %q, got:\n%s", notExpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateInjectorDecl_MustInject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		errorContains    string
+		panicFormat      string
+		expectedContains []string
+		isReturnError    bool
+	}{
+		{
+			name:          "injector with error",
+			isReturnError: true,
+			expectedContains: []string{
+				"func InitializeService() (*Service, error) {",
+				"func InitializeServiceMust() *Service {",
+				"service, err := InitializeService()",
+				"panic(err)",
+				"return service\n",
+			},
+		},
+		{
+			name:          "custom panic message",
+			isReturnError: true,
+			panicFormat:   "failed to init service: %w",
+			expectedContains: []string{
+				"func InitializeServiceMust() *Service {",
+				`panic(fmt.Errorf("failed to init service: %w", err))`,
+			},
+		},
+		{
+			name:          "injector without error",
+			isReturnError: false,
+			errorContains: "injector InitializeService does not return an error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			injector := createTestServiceInjector("InitializeService", tt.isReturnError)
+			injector.IsMust = true
+			injector.PanicFormat = tt.panicFormat
+
+			decls, err := generateInjectorDecl(createTestMetaData(), injector, NewVarPool())
+			if tt.errorContains != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			for _, decl := range decls {
+				if err := format.Node(&buf, token.NewFileSet(), decl); err != nil {
+					t.Fatalf("Failed to format declaration: %v", err)
+				}
+				buf.WriteString("\n")
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_WrapError(t *testing.T) {
+	t.Parallel()
+
+	initErrorType := types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, "InitError", nil), types.NewStruct(nil, nil), nil))
+
+	tests := []struct {
+		name                string
+		expectedContains    []string
+		expectedNotContains []string
+		isLazy              bool
+	}{
+		{
+			name: "injector",
+			expectedContains: []string{
+				"func InitializeService() (*Service, *InitError) {",
+				"return zero, NewInitError(err)",
+				"return service, nil",
+			},
+		},
+		{
+			name:   "lazy injector",
+			isLazy: true,
+			expectedContains: []string{
+				"initializeServiceErr    *InitError",
+				"initializeServiceResult, initializeServiceErr = func() (*Service, *InitError) {",
+				"return zero, NewInitError(err)",
+			},
+			expectedNotContains: []string{
+				"initializeServiceErr    error",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			injector := createTestServiceInjector("InitializeService", true)
+			injector.IsLazy = tt.isLazy
+			injector.ErrorWrapper = &ErrorWrapper{
+				Type:              initErrorType,
+				ASTExpr:           ast.NewIdent("NewInitError"),
+				ReferencedImports: make(map[string]*Import),
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", createTestMetaData(), []*Injector{injector}, NewVarPool()); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, notExpected := range tt.expectedNotContains {
+				if strings.Contains(generated, notExpected) {
+					t.Errorf("Expected generated code NOT to contain %q, got:\n%s", notExpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateInjectorDecl_Implements(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, intType := createTestTypes()
+	errorType := types.Universe.Lookup("error").Type()
+
+	newFactory := func(params []types.Type, results ...types.Type) *Implementation {
+		newTuple := func(ts []types.Type) *types.Tuple {
+			vars := make([]*types.Var, 0, len(ts))
+			for _, t := range ts {
+				vars = append(vars, types.NewParam(token.NoPos, nil, "", t))
+			}
+			return types.NewTuple(vars...)
+		}
+		method := types.NewFunc(token.NoPos, nil, "NewService", types.NewSignatureType(nil, nil, nil, newTuple(params), newTuple(results), false))
+		iface := types.NewInterfaceType([]*types.Func{method}, nil).Complete()
+		return &Implementation{
+			Interface: types.NewNamed(types.NewTypeName(token.NoPos, nil, "ServiceFactory", nil), iface, nil),
+			Method:    method,
+		}
+	}
+
+	tests := []struct {
+		implements       *Implementation
+		name             string
+		errorContains    string
+		expectedContains []string
+	}{
+		{
+			name:       "matching signature",
+			implements: newFactory(nil, serviceType, errorType),
+			expectedContains: []string{
+				"type initializeServiceImpl struct{}",
+				"func (initializeServiceImpl) NewService() (*Service, error) {",
+				"return InitializeService()",
+				"var _ ServiceFactory = initializeServiceImpl{}",
+			},
+		},
+		{
+			name:          "missing error result",
+			implements:    newFactory(nil, serviceType),
+			errorContains: "injector InitializeService does not match method NewService: have func() (*Service, error), want func() *Service",
+		},
+		{
+			name:          "extra parameter",
+			implements:    newFactory([]types.Type{intType}, serviceType, errorType),
+			errorContains: "want func(int) (*Service, error)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			injector := createTestServiceInjector("InitializeService", true)
+			injector.Implements = tt.implements
+
+			decls, err := generateInjectorDecl(createTestMetaData(), injector, NewVarPool())
+			if tt.errorContains != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			for _, decl := range decls {
+				if err := format.Node(&buf, token.NewFileSet(), decl); err != nil {
+					t.Fatalf("Failed to format declaration: %v", err)
+				}
+				buf.WriteString("\n")
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_ImportGrouping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		localPrefix string
+		expected    string
+	}{
+		{
+			name:        "stdlib, third-party and local groups",
+			localPrefix: "example.com/app",
+			expected: `import (
+	"context"
+	"sync"
+
+	"github.com/mazrean/kessoku"
+	"golang.org/x/sync/errgroup"
+
+	"example.com/app/internal/config"
+	db "example.com/app/internal/database"
+)
+`,
+		},
+		{
+			name:        "no local prefix",
+			localPrefix: "",
+			expected: `import (
+	"context"
+	"sync"
+
+	"example.com/app/internal/config"
+	db "example.com/app/internal/database"
+	"github.com/mazrean/kessoku"
+	"golang.org/x/sync/errgroup"
+)
+`,
+		},
+		{
+			name:        "prefix matches on path boundaries only",
+			localPrefix: "example.com/app/internal/data",
+			expected: `import (
+	"context"
+	"sync"
+
+	"example.com/app/internal/config"
+	db "example.com/app/internal/database"
+	"github.com/mazrean/kessoku"
+	"golang.org/x/sync/errgroup"
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "example.com/app",
+				},
+				Imports: map[string]*Import{
+					"sync":                              {Name: "sync", IsDefaultName: true, IsUsed: true},
+					"example.com/app/internal/config":   {Name: "config", IsDefaultName: true, IsUsed: true},
+					"golang.org/x/sync/errgroup":        {Name: "errgroup", IsDefaultName: true, IsUsed: true},
+					"context":                           {Name: "context", IsDefaultName: true, IsUsed: true},
+					"example.com/app/internal/database": {Name: "db", IsUsed: true},
+					"github.com/mazrean/kessoku":        {Name: "kessoku", IsDefaultName: true, IsUsed: true},
+				},
+				LocalPrefix: tt.localPrefix,
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, nil, NewVarPool()); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("Expected generated code to contain:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestInjectorProviderCallStmt_Span(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+	ctxType := types.NewNamed(types.NewTypeName(0, types.NewPackage(contextPkgPath, contextPkgName), contextTypeName, nil), types.NewInterfaceType(nil, nil), nil)
+	tracerType := types.NewNamed(types.NewTypeName(0, types.NewPackage("github.com/mazrean/kessoku", "kessoku"), "Tracer", nil), types.NewSignatureType(nil, nil, nil, nil, nil, false), nil)
+
+	newParam := func(name string, t types.Type) *InjectorParam {
+		param := NewInjectorParam([]types.Type{t}, true)
+		param.name = name
+		return param
+	}
+
+	tests := []struct {
+		name     string
+		requires []types.Type
+		expected []string
+	}{
+		{
+			name:     "provider without context",
+			requires: []types.Type{configType},
+			expected: []string{
+				`_, end := tracer(ctx, "init-service")`,
+				`service := kessoku.Provide(NewService).Fn()(config)`,
+				`end()`,
+			},
+		},
+		{
+			name:     "provider receives span context",
+			requires: []types.Type{ctxType, configType},
+			expected: []string{
+				`spanCtx, end := tracer(ctx, "init-service")`,
+				`service := kessoku.Provide(NewService).Fn()(spanCtx, config)`,
+				`end()`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := map[types.Type]*InjectorParam{
+				configType: newParam("config", configType),
+				ctxType:    newParam("ctx", ctxType),
+				tracerType: newParam("tracer", tracerType),
+			}
+			provider := &ProviderSpec{
+				Type:              ProviderTypeFunction,
+				ASTExpr:           &ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent("kessoku"), Sel: ast.NewIdent("Provide")}, Args: []ast.Expr{ast.NewIdent("NewService")}},
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          tt.requires,
+				SpanName:          "init-service",
+				SpanRequires:      []types.Type{tracerType, ctxType},
+				ReferencedImports: make(map[string]*Import),
+			}
+
+			var arguments []*InjectorCallArgument
+			for _, dep := range provider.dependencies() {
+				arguments = append(arguments, &InjectorCallArgument{Param: params[dep]})
+			}
+
+			stmt := &InjectorProviderCallStmt{
+				Provider:  provider,
+				Arguments: arguments,
+				Returns:   []*InjectorParam{newParam("service", serviceType)},
+			}
+
+			stmts, _ := stmt.Stmt(NewVarPool(), &Injector{}, nil)
+			if len(stmts) != len(tt.expected) {
+				t.Fatalf("Expected %d statements, got %d", len(tt.expected), len(stmts))
+			}
+
+			for i, s := range stmts {
+				var buf bytes.Buffer
+				if err := format.Node(&buf, token.NewFileSet(), s); err != nil {
+					t.Fatalf("format statement: %v", err)
+				}
+				if buf.String() != tt.expected[i] {
+					t.Errorf("Statement %d: expected %q, got %q", i, tt.expected[i], buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Populate(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+	pkg := types.NewPackage("main", "main")
+	handlerType := types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, "Handler", nil), types.NewStruct(nil, nil), nil))
+	fields := []*StructFieldSpec{
+		{Name: "Config", Type: configType, Index: 0},
+		{Name: "Service", Type: serviceType, Index: 1},
+	}
+
+	tests := []struct {
+		name                string
+		serviceReturnsError bool
+		expectedContains    []string
+		expectedNotContains []string
+	}{
+		{
+			name: "without error",
+			expectedContains: []string{
+				"func PopulateHandler(handler *Handler) {",
+				"handler.Config = config",
+				"handler.Service = service",
+			},
+			expectedNotContains: []string{
+				"return",
+			},
+		},
+		{
+			name:                "with error",
+			serviceReturnsError: true,
+			expectedContains: []string{
+				"func PopulateHandler(handler *Handler) error {",
+				"if err != nil {\n\t\treturn err\n\t}",
+				"handler.Service = service\n\treturn nil",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "PopulateHandler",
+				Args:         []types.Type{handlerType},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						ASTExpr:           ast.NewIdent("NewConfig"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{configType},
+						IsReturnError:     tt.serviceReturnsError,
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:         ProviderTypePopulate,
+						StructType:   handlerType,
+						StructFields: fields,
+						Requires:     []types.Type{handlerType, configType, serviceType},
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+			if injector.Return != nil {
+				t.Errorf("Expected no return value, got %v", injector.Return)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, notExpected := range tt.expectedNotContains {
+				if strings.Contains(generated, notExpected) {
+					t.Errorf("Expected generated code NOT to contain %q, got:\n%s", notExpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Nil(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+	pkg := types.NewPackage("main", "main")
+	metricsType := types.NewNamed(types.NewTypeName(0, pkg, "Metrics", nil), types.NewInterfaceType(nil, nil), nil)
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypeNil,
+				Provides:          [][]types.Type{{metricsType}},
+				ASTExpr:           ast.NewIdent("Metrics"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{metricsType},
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	generated := buf.String()
+	for _, expected := range []string{
+		"var metrics Metrics = nil",
+		"NewService.Fn()(metrics)",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+}
+
+func TestGenerate_ChannelTrace(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("main", "main")
+	newType := func(name string) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, name, nil), types.NewStruct(nil, nil), nil))
+	}
+	configType, databaseType, cacheType, appType := newType("Config"), newType("Database"), newType("Cache"), newType("App")
+
+	tests := []struct {
+		name                string
+		channelTrace        bool
+		expectedContains    []string
+		expectedNotContains []string
+	}{
+		{
+			name:         "with channel trace",
+			channelTrace: true,
+			expectedContains: []string{
+				"channelTrace = kessoku.WithChannelTrace()",
+				`channelTrace.Trace("InitializeApp", "wait", "configCh")`,
+				`channelTrace.Trace("InitializeApp", "ready", "configCh")`,
+				`channelTrace.Trace("InitializeApp", "close", "configCh")` + "\n\tclose(configCh)",
+			},
+		},
+		{
+			name: "without channel trace",
+			expectedContains: []string{
+				"close(configCh)",
+			},
+			expectedNotContains: []string{
+				"Trace(",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			asyncProvider := func(name string, provides types.Type, requires ...types.Type) *ProviderSpec {
+				return &ProviderSpec{
+					Type:              ProviderTypeFunction,
+					Provides:          [][]types.Type{{provides}},
+					Requires:          requires,
+					ASTExpr:           ast.NewIdent(name),
+					ReferencedImports: make(map[string]*Import),
+					IsAsync:           true,
+				}
+			}
+
+			build := &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return: &Return{
+					Type:        appType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("App")},
+				},
+				Providers: []*ProviderSpec{
+					asyncProvider("NewConfig", configType),
+					asyncProvider("NewDatabase", databaseType, configType),
+					asyncProvider("NewCache", cacheType, configType),
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{appType}},
+						Requires:          []types.Type{databaseType, cacheType},
+						ASTExpr:           ast.NewIdent("NewApp"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+			if tt.channelTrace {
+				build.ChannelTrace = &ChannelTrace{
+					ASTExpr: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: ast.NewIdent("kessoku"), Sel: ast.NewIdent("WithChannelTrace")},
+					},
+					ReferencedImports: make(map[string]*Import),
+				}
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, notExpected := range tt.expectedNotContains {
+				if strings.Contains(generated, notExpected) {
+					t.Errorf("Expected generated code NOT to contain %q, got:\n%s", notExpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_ErrorZeroValues(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("main", "main")
+	configType := types.NewNamed(types.NewTypeName(0, pkg, "Config", nil), types.NewStruct(nil, nil), nil)
+	endpointType := types.Typ[types.String]
+	intType, boolType := types.Typ[types.Int], types.Typ[types.Bool]
+
+	tests := []struct {
+		name    string
+		isAsync bool
+	}{
+		{name: "sync"},
+		{name: "async", isAsync: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeConfig",
+				Return: &Return{
+					Type:        configType,
+					ASTTypeExpr: ast.NewIdent("Config"),
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{endpointType}},
+						IsReturnError:     true,
+						IsAsync:           tt.isAsync,
+						ASTExpr:           ast.NewIdent("NewEndpoint"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						// Multiple results followed by an error
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{intType}, {boolType}},
+						IsReturnError:     true,
+						IsAsync:           tt.isAsync,
+						ASTExpr:           ast.NewIdent("NewLimits"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						Requires:          []types.Type{endpointType, intType, boolType},
+						ASTExpr:           ast.NewIdent("NewConfig"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			// Every error path of the injector returns the zero Config, which cannot be nil
+			generated := buf.String()
+			injectorReturns := strings.Count(generated, "return zero, ")
+			zeroDecls := strings.Count(generated, "var zero Config\n")
+			if injectorReturns == 0 || injectorReturns != zeroDecls {
+				t.Errorf("Expected each error return to declare the zero Config, got %d returns and %d declarations:\n%s",
+					injectorReturns, zeroDecls, generated)
+			}
+			if strings.Contains(generated, "return nil, ") {
+				t.Errorf("Expected no nil return for a non-pointer result, got:\n%s", generated)
+			}
+			if tt.isAsync && !strings.Contains(generated, "if err := eg.Wait(); err != nil {\n\t\tvar zero Config\n\t\treturn zero, err\n\t}") {
+				t.Errorf("Expected the errgroup error to return the zero Config, got:\n%s", generated)
+			}
+		})
+	}
+}
+
+func TestGenerate_LazyInjectorContext(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	tests := []struct {
+		name                string
+		withConfigProvider  bool
+		expectedContains    []string
+		expectedNotContains []string
+		expectedError       string
+	}{
+		{
+			name:               "async provider",
+			withConfigProvider: true,
+			expectedContains: []string{
+				"getServiceMu     sync.Mutex",
+				"getServiceDone   bool",
+				"func GetService(ctx context.Context) (*Service, error) {\n\tgetServiceMu.Lock()\n\tdefer getServiceMu.Unlock()\n\tif !getServiceDone {",
+				"getServiceDone = getServiceErr == nil || ctx.Err() == nil\n\t}\n\treturn getServiceResult, getServiceErr\n",
+			},
+			expectedNotContains: []string{
+				"sync.Once",
+			},
+		},
+		{
+			name:          "unprovided argument",
+			expectedError: "LazyInjector cannot take an argument of type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "GetService",
+				IsLazy:       true,
+				Return: &Return{
+					Type:        serviceType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{configType},
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+			if tt.withConfigProvider {
+				build.Providers = append(build.Providers, &ProviderSpec{
+					Type:              ProviderTypeFunction,
+					Provides:          [][]types.Type{{configType}},
+					IsAsync:           true,
+					IsReturnError:     true,
+					ASTExpr:           ast.NewIdent("NewConfig"),
+					ReferencedImports: make(map[string]*Import),
+				})
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, notExpected := range tt.expectedNotContains {
+				if strings.Contains(generated, notExpected) {
+					t.Errorf("Expected generated code NOT to contain %q, got:\n%s", notExpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_WithCancel(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	tests := []struct {
+		name             string
+		isReturnError    bool
+		expectedContains []string
+	}{
+		{
+			name: "without error",
+			expectedContains: []string{
+				"func InitializeService(ctx context.Context) (*Service, context.CancelFunc) {",
+				"ctx, cancel := context.WithCancel(ctx)",
+				"return service, cancel\n",
+			},
+		},
+		{
+			name:          "with error",
+			isReturnError: true,
+			expectedContains: []string{
+				"func InitializeService(ctx context.Context) (*Service, context.CancelFunc, error) {",
+				"ctx, cancel := context.WithCancel(ctx)",
+				"if err != nil {\n\t\tvar zero *Service\n\t\tcancel()\n\t\treturn zero, nil, err\n\t}",
+				"return service, cancel, nil\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				WithCancel:   true,
+				Return: &Return{
+					Type:        serviceType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						IsReturnError:     tt.isReturnError,
+						ASTExpr:           ast.NewIdent("NewConfig"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{configType},
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_CleanupCloser(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+
+	// *Conn and *File have a Close() error method like io.Closer
+	newCloserType := func(name string) types.Type {
+		named := types.NewNamed(types.NewTypeName(0, nil, name, nil), types.NewStruct(nil, nil), nil)
+		ptr := types.NewPointer(named)
+		results := types.NewTuple(types.NewParam(0, nil, "", types.Universe.Lookup("error").Type()))
+		recv := types.NewParam(0, nil, "", ptr)
+		named.AddMethod(types.NewFunc(0, nil, "Close", types.NewSignatureType(recv, nil, nil, nil, results, false)))
+		return ptr
+	}
+	connType := newCloserType("Conn")
+	fileType := newCloserType("File")
+
+	tests := []struct {
+		name             string
+		isReturnError    bool
+		expectedContains []string
+	}{
+		{
+			name: "without error",
+			expectedContains: []string{
+				"type InitializeServiceCloser struct {\n\tmu      sync.Mutex\n\tclosers []io.Closer\n\tclosed  bool\n}",
+				"func (c *InitializeServiceCloser) add(closer io.Closer) {",
+				"if c.closed {\n\t\t_ = closer.Close()\n\t\treturn\n\t}",
+				"func (c *InitializeServiceCloser) Close() error {",
+				"for i := len(closers) - 1; i >= 0; i-- {",
+				"return errors.Join(errs...)",
+				"func InitializeService() (*Service, *InitializeServiceCloser) {",
+				"closer := &InitializeServiceCloser{}",
+				"conn := NewConn.Fn()(file)\n\tcloser.add(conn)\n",
+				"return service, closer\n",
+			},
+		},
+		{
+			name:          "with error",
+			isReturnError: true,
+			expectedContains: []string{
+				"func InitializeService() (*Service, *InitializeServiceCloser, error) {",
+				"if err != nil {\n\t\tvar zero *Service\n\t\t_ = closer.Close()\n\t\treturn zero, nil, err\n\t}\n\tcloser.add(conn)\n",
+				"return service, closer, nil\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName:  "InitializeService",
+				CleanupCloser: true,
+				Return: &Return{
+					Type:        serviceType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{fileType}},
+						IsValue:           true,
+						ASTExpr:           ast.NewIdent("NewFile"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{connType}},
+						Requires:          []types.Type{fileType},
+						IsReturnError:     tt.isReturnError,
+						ASTExpr:           ast.NewIdent("NewConn"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{connType},
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+
+			// Values of kessoku.Value belong to the caller
+			if strings.Contains(generated, "closer.add(file)") {
+				t.Errorf("Expected the value provider not to be closed, got:\n%s", generated)
+			}
+		})
+	}
+}
+
+func TestGenerate_CommandArgs(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Args:        []types.Type{commandArgsType},
+		CommandArgs: true,
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{types.NewSlice(types.Typ[types.String])},
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	generated := buf.String()
+	for _, expected := range []string{
+		"func InitializeService(args []string) *Service {",
+		"service := NewService.Fn()(args)\n",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+}
+
+func TestGenerate_DynamicOverrides(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Overrides: true,
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{configType}},
+				ASTExpr:           ast.NewIdent("NewConfig"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{configType},
+				IsReturnError:     true,
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// Each provider call moves to the else branch of a lookup of its type in the map
+	generated := buf.String()
+	for _, expected := range []string{
+		`"reflect"`,
+		"func InitializeServiceWithOverrides(overrides map[reflect.Type]any) (*Service, error) {",
+		"\tvar config *Config\n\tif override, ok := overrides[reflect.TypeFor[*Config]()]; ok {\n\t\tconfig = override.(*Config)\n\t} else {\n\t\tconfig = NewConfig.Fn()()\n\t}\n",
+		"if override, ok := overrides[reflect.TypeFor[*Service]()]; ok {\n\t\tservice = override.(*Service)\n\t} else {\n\t\tvar err error\n\t\tservice, err = NewService.Fn()(config)\n",
+		"func InitializeService() (*Service, error) {\n\treturn InitializeServiceWithOverrides(nil)\n}",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+}
+
+func TestGenerate_OmitContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		isReturnError    bool
+		expectedContains []string
+		expectedMissing  []string
+	}{
+		{
+			name: "async providers without context",
+			expectedContains: []string{
+				"func InitializeService() *Service {",
+				"eg := &errgroup.Group{}",
+				"\t<-numCh\n",
+				"_ = eg.Wait()",
+			},
+			expectedMissing: []string{"context", "ctx"},
+		},
+		{
+			name:          "failing provider keeps context",
+			isReturnError: true,
+			expectedContains: []string{
+				"func InitializeService(ctx context.Context) (*Service, error) {",
+				"eg, ctx := errgroup.WithContext(ctx)",
+				"case <-ctx.Done():",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			configType, serviceType, intType := createTestTypes()
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				Return: &Return{
+					Type:        serviceType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+				},
+				OmitContext: true,
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						IsAsync:           true,
+						IsReturnError:     tt.isReturnError,
+						ASTExpr:           ast.NewIdent("NewConfig"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{intType}},
+						IsAsync:           true,
+						ASTExpr:           ast.NewIdent("NewPort"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{configType, intType},
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, missing := range tt.expectedMissing {
+				if strings.Contains(generated, missing) {
+					t.Errorf("Expected generated code not to contain %q, got:\n%s", missing, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Inspector(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, intType := createTestTypes()
+	otherPkg := types.NewPackage("example.com/other", "other")
+	secretType := types.NewPointer(types.NewNamed(types.NewTypeName(0, otherPkg, "secret", nil), types.NewStruct(nil, nil), nil))
+	extraType := types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, "Extra", nil), types.NewStruct(nil, nil), nil))
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{configType}},
+				IsReturnError:     true,
+				ASTExpr:           ast.NewIdent("NewConfig"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{intType}, {secretType}, {extraType}},
+				ASTExpr:           ast.NewIdent("NewPort"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{configType, intType},
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+	injector.Inspector = true
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	generated := buf.String()
+	expectedContains := []string{
+		// The injector itself still discards the values nothing depends on
+		"func InitializeService() (*Service, error) {",
+		"num, _, _ := NewPort.Fn()()",
+		"type InitializeServiceInspection struct {",
+		"func InitializeServiceInspect() (*InitializeServiceInspection, error) {",
+		"num, _, extra := NewPort.Fn()()",
+		"var zero *InitializeServiceInspection",
+		"return &InitializeServiceInspection{Config: config, Num: num, Extra: extra, Service: service}, nil",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+
+	// The unexported type of another package cannot be named in the inspection
+	if strings.Contains(generated, "other.") {
+		t.Errorf("Expected the value of an unexported type to be left out, got:\n%s", generated)
+	}
+}
+
+func TestGenerate_Pooled(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+	acquireType := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(
+		types.NewParam(0, nil, "", configType),
+		types.NewParam(0, nil, "", types.NewSignatureType(nil, nil, nil, nil, nil, false)),
+	), false)
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypePool,
+				Provides:          [][]types.Type{{acquireType}},
+				ASTExpr:           ast.NewIdent("newConfig"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{acquireType},
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	generated := buf.String()
+	for _, expected := range []string{
+		`import "sync"`,
+		"var initializeServiceConfigPool = sync.Pool{New: func() any {\n\treturn newConfig()\n}}",
+		"acquireConfig := func() (*Config, func()) {",
+		"v := initializeServiceConfigPool.Get().(*Config)",
+		"initializeServiceConfigPool.Put(v)",
+		"service := NewService.Fn()(acquireConfig)",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+}
+
+func TestGenerate_BlankImport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		blankImports     []string
+		imports          map[string]*Import
+		expectedContains []string
+		expectedMissing  []string
+	}{
+		{
+			name:             "side-effect-only package",
+			blankImports:     []string{"github.com/lib/pq"},
+			expectedContains: []string{`import _ "github.com/lib/pq"`},
+		},
+		{
+			name:         "package imported anyway",
+			blankImports: []string{"github.com/lib/pq"},
+			imports: map[string]*Import{
+				"github.com/lib/pq": {Name: "pq", IsDefaultName: true, IsUsed: true},
+			},
+			expectedContains: []string{`import "github.com/lib/pq"`},
+			expectedMissing:  []string{`_ "github.com/lib/pq"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			configType, _, _ := createTestTypes()
+			build := &BuildDirective{
+				InjectorName: "InitializeConfig",
+				Return: &Return{
+					Type:        configType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Config")},
+				},
+				BlankImports: tt.blankImports,
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						ASTExpr:           ast.NewIdent("NewConfig"),
+						ReferencedImports: tt.imports,
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			maps.Copy(metaData.Imports, tt.imports)
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, missing := range tt.expectedMissing {
+				if strings.Contains(generated, missing) {
+					t.Errorf("Expected generated code not to contain %q, got:\n%s", missing, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_RunWrapper(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+
+	connNamed := types.NewNamed(types.NewTypeName(0, nil, "Conn", nil), types.NewStruct(nil, nil), nil)
+	connType := types.NewPointer(connNamed)
+	closeResults := types.NewTuple(types.NewParam(0, nil, "", types.Universe.Lookup("error").Type()))
+	connNamed.AddMethod(types.NewFunc(0, nil, "Close", types.NewSignatureType(types.NewParam(0, nil, "", connType), nil, nil, nil, closeResults, false)))
+
+	tests := []struct {
+		name             string
+		cleanupCloser    bool
+		isReturnError    bool
+		expectedContains []string
+	}{
+		{
+			name: "without cleanups",
+			expectedContains: []string{
+				"func RunInitializeService(fn func(*Service) error) error {\n\tservice := InitializeService()\n\treturn fn(service)\n}",
+			},
+		},
+		{
+			name:          "with closer",
+			cleanupCloser: true,
+			isReturnError: true,
+			expectedContains: []string{
+				"func RunInitializeService(fn func(*Service) error) (runErr error) {",
+				"service, closer, err := InitializeService()\n\tif err != nil {\n\t\treturn err\n\t}\n",
+				"defer func() {\n\t\trunErr = errors.Join(runErr, closer.Close())\n\t}()\n\treturn fn(service)\n}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName:  "InitializeService",
+				RunWrapper:    true,
+				CleanupCloser: tt.cleanupCloser,
+				Return: &Return{
+					Type:        serviceType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{connType}},
+						IsReturnError:     tt.isReturnError,
+						ASTExpr:           ast.NewIdent("NewConn"),
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{connType},
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_NamedReturns(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{configType}},
+				IsReturnError:     true,
+				ASTExpr:           ast.NewIdent("NewConfig"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{configType},
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+	injector.NamedReturns = true
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// The results take the plain names, so the variables of the body are numbered
+	generated := buf.String()
+	for _, expected := range []string{
+		"func InitializeService() (service *Service, err error) {",
+		"config, err0 := NewConfig.Fn()()",
+		"return service0, nil",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+}
+
+func TestGenerate_CallProvider(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Providers: []*ProviderSpec{
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{configType}},
+				IsValue:  true,
+				ASTExpr: &ast.CallExpr{
+					Fun:  &ast.SelectorExpr{X: ast.NewIdent("kessoku"), Sel: ast.NewIdent("Call")},
+					Args: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("defaultConfig")}},
+				},
+				CallExpr:          &ast.CallExpr{Fun: ast.NewIdent("defaultConfig")},
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{configType},
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// The call result is assigned directly instead of going through the provider
+	generated := buf.String()
+	if !strings.Contains(generated, "config := defaultConfig()\n") {
+		t.Errorf("Expected the call to be assigned directly, got:\n%s", generated)
+	}
+	if strings.Contains(generated, "kessoku.Call") {
+		t.Errorf("Expected kessoku.Call not to be generated, got:\n%s", generated)
+	}
+}
+
+func TestGenerate_ReadyCheck(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	tests := []struct {
+		name             string
+		isReturnError    bool
+		isLazy           bool
+		expectedContains []string
+		expectedError    string
+	}{
+		{
+			name: "without error",
+			expectedContains: []string{
+				"func InitializeService() (*Service, func(context.Context) error) {\n\tconfigCheck := checkConfig\n\tserviceCheck := checkService\n",
+				"return service, func(ctx context.Context) error {\n\t\treturn errors.Join(configCheck.Check(ctx, config), serviceCheck.Check(ctx, service))\n\t}\n",
+			},
+		},
+		{
+			name:          "with error",
+			isReturnError: true,
+			expectedContains: []string{
+				"func InitializeService() (*Service, func(context.Context) error, error) {",
+				"var zero *Service\n\t\treturn zero, nil, err\n",
+				"serviceCheck.Check(ctx, service))\n\t}, nil\n",
+			},
+		},
+		{
+			name:          "lazy injector",
+			isLazy:        true,
+			expectedError: "WithReadyCheck cannot be combined with LazyInjector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				IsLazy:       tt.isLazy,
+				Return: &Return{
+					Type:        serviceType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						IsReturnError:     tt.isReturnError,
+						ASTExpr:           ast.NewIdent("NewConfig"),
+						ReadyChecks:       []*ReadyCheck{{Type: configType, ASTExpr: ast.NewIdent("checkConfig"), ident: ast.NewIdent("_")}},
+						ReferencedImports: make(map[string]*Import),
+					},
+					{
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{serviceType}},
+						Requires:          []types.Type{configType},
+						ASTExpr:           ast.NewIdent("NewService"),
+						ReadyChecks:       []*ReadyCheck{{Type: serviceType, ASTExpr: ast.NewIdent("checkService"), ident: ast.NewIdent("_")}},
+						ReferencedImports: make(map[string]*Import),
+					},
+				},
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_GeneratedMarker(t *testing.T) {
+	t.Parallel()
+
+	// The convention of https://go.dev/s/generatedcode, which go vet, gopls, and coverage
+	// filters use to recognize generated files
+	generatedRegexp := regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+	tests := []struct {
+		generate func(w io.Writer) error
+		name     string
+	}{
+		{
+			name: "injectors",
+			generate: func(w io.Writer) error {
+				return Generate(w, "test.go", createTestMetaData(), nil, NewVarPool())
+			},
+		},
+		{
+			name: "registry",
+			generate: func(w io.Writer) error {
+				return GenerateRegistry(w, "main", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			if err := tt.generate(&buf); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			firstLine, _, _ := strings.Cut(buf.String(), "\n")
+			if !generatedRegexp.MatchString(firstLine) {
+				t.Errorf("Expected the first line to match %s, got %q", generatedRegexp, firstLine)
+			}
+
+			file, err := parser.ParseFile(token.NewFileSet(), "test.go", buf.Bytes(), parser.ParseComments|parser.PackageClauseOnly)
+			if err != nil {
+				t.Fatalf("Failed to parse generated code: %v", err)
+			}
+			if !ast.IsGenerated(file) {
+				t.Errorf("Expected generated code to be recognized by ast.IsGenerated, got:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestGenerate_ProviderGroups(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+	repoType := types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, "Repository", nil), types.NewStruct(nil, nil), nil))
+	cacheType := types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, "Cache", nil), types.NewStruct(nil, nil), nil))
+
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return: &Return{
+			Type:        serviceType,
+			ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("Service")},
+		},
+		Providers: []*ProviderSpec{
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{configType}},
+				Group:             "infra",
+				ASTExpr:           ast.NewIdent("NewConfig"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{cacheType}},
+				Requires:          []types.Type{configType},
+				ASTExpr:           ast.NewIdent("NewCache"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{repoType}},
+				Requires:          []types.Type{configType},
+				Group:             "infra",
+				IsReturnError:     true,
+				ASTExpr:           ast.NewIdent("NewRepository"),
+				ReferencedImports: make(map[string]*Import),
+			},
+			{
+				Type:              ProviderTypeFunction,
+				Provides:          [][]types.Type{{serviceType}},
+				Requires:          []types.Type{repoType, cacheType},
+				Group:             "services",
+				ASTExpr:           ast.NewIdent("NewService"),
+				ReferencedImports: make(map[string]*Import),
+			},
+		},
+	}
+
+	metaData := createTestMetaData()
+	varPool := NewVarPool()
+	injector, err := CreateInjector(metaData, build, varPool, false, 0)
+	if err != nil {
+		t.Fatalf("CreateInjector failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// Consecutive providers of a group share a single section comment, which error handling and
+	// ungrouped providers do not interrupt
+	generated := buf.String()
+	expected := []string{
+		"\t// --- infra ---\n\tconfig := NewConfig.Fn()()\n\tvar err error\n\trepository, err := NewRepository.Fn()(config)\n",
+		"\tcache := NewCache.Fn()(config)\n\t// --- services ---\n\tservice := NewService.Fn()(repository, cache)\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(generated, e) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", e, generated)
+		}
+	}
+	if count := strings.Count(generated, "// --- infra ---"); count != 1 {
+		t.Errorf("Expected a single infra section comment, got %d:\n%s", count, generated)
+	}
+
+	// The comments are real comments of the file rather than statements
+	file, err := parser.ParseFile(token.NewFileSet(), "test.go", generated, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse generated code: %v", err)
+	}
+	var comments []string
+	for _, group := range file.Comments {
+		comments = append(comments, group.Text())
+	}
+	if !slices.Contains(comments, "--- infra ---\n") || !slices.Contains(comments, "--- services ---\n") {
+		t.Errorf("Expected section comments in the comments of the file, got %q", comments)
+	}
+}
+
+func TestGenerate_SingleConsumerHandoff(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("main", "main")
+	newType := func(name string) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, name, nil), types.NewStruct(nil, nil), nil))
+	}
+	configType := newType("Config")
+	databaseType := newType("Database")
+	loggerType := newType("Logger")
+	logConfigType := newType("LogConfig")
+	appType := newType("App")
+
+	newProvider := func(name string, provides types.Type, isAsync bool, requires ...types.Type) *ProviderSpec {
+		return &ProviderSpec{
+			Type:              ProviderTypeFunction,
+			Provides:          [][]types.Type{{provides}},
+			Requires:          requires,
+			IsAsync:           isAsync,
+			ASTExpr:           ast.NewIdent(name),
+			ReferencedImports: make(map[string]*Import),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		providers   []*ProviderSpec
+		contains    []string
+		notContains []string
+	}{
+		{
+			// The pool of NewLogger starts once the log config is ready instead of waiting for a channel
+			name: "single consumer",
+			providers: []*ProviderSpec{
+				newProvider("NewConfig", configType, false),
+				newProvider("NewLogConfig", logConfigType, false),
+				newProvider("NewDatabase", databaseType, true, configType),
+				newProvider("NewLogger", loggerType, true, logConfigType),
+				newProvider("NewApp", appType, false, databaseType, loggerType),
+			},
+			contains: []string{
+				"logConfig = NewLogConfig.Fn()()\n\teg.Go(func() error {\n\t\tlogger = NewLogger.Fn()(logConfig)",
+			},
+			notContains: []string{"logConfigCh"},
+		},
+		{
+			// Values with several consumers are broadcast by closing their channel
+			name: "multiple consumers",
+			providers: []*ProviderSpec{
+				newProvider("NewConfig", configType, false),
+				newProvider("NewDatabase", databaseType, true, configType),
+				newProvider("NewLogger", loggerType, true, configType),
+				newProvider("NewApp", appType, false, databaseType, loggerType),
+			},
+			contains: []string{
+				"close(configCh)",
+				"case <-configCh:",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return: &Return{
+					Type:        appType,
+					ASTTypeExpr: &ast.StarExpr{X: ast.NewIdent("App")},
+				},
+				Providers: tt.providers,
+			}
+
+			metaData := createTestMetaData()
+			varPool := NewVarPool()
+			injector, err := CreateInjector(metaData, build, varPool, false, 0)
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Generate(&buf, "test.go", metaData, []*Injector{injector}, varPool); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			generated := buf.String()
+			for _, expected := range tt.contains {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(generated, unexpected) {
+					t.Errorf("Expected generated code not to contain %q, got:\n%s", unexpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_VersionStamp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		stamp    *VersionStamp
+		expected *regexp.Regexp
+		name     string
+	}{
+		{
+			name:     "content hash",
+			stamp:    &VersionStamp{Version: "v1.2.3"},
+			expected: regexp.MustCompile(`^// kessoku v1\.2\.3, content sha256:[0-9a-f]{16}$`),
+		},
+		{
+			name:     "generation time",
+			stamp:    &VersionStamp{Version: "v1.2.3", Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60))},
+			expected: regexp.MustCompile(`^// kessoku v1\.2\.3, generated at 2026-01-01T18:04:05Z$`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			generate := func() string {
+				metaData := createTestMetaData()
+				metaData.Stamp = tt.stamp
+
+				var buf bytes.Buffer
+				if err := Generate(&buf, "test.go", metaData, nil, NewVarPool()); err != nil {
+					t.Fatalf("Generate failed: %v", err)
+				}
+				return buf.String()
+			}
+
+			generated := generate()
+			lines := strings.SplitN(generated, "\n", 3)
+			if len(lines) < 3 {
+				t.Fatalf("Expected generated code to have a stamp line, got:\n%s", generated)
+			}
+			// The stamp must not push the generated marker off the first line
+			if lines[0] != generatedHeader {
+				t.Errorf("Expected the first line to be %q, got %q", generatedHeader, lines[0])
+			}
+			if !tt.expected.MatchString(lines[1]) {
+				t.Errorf("Expected the second line to match %s, got %q", tt.expected, lines[1])
+			}
+
+			if regenerated := generate(); regenerated != generated {
+				t.Errorf("Expected regeneration to produce the same stamp, got:\n%s\nand:\n%s", generated, regenerated)
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package kessoku
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // update flag for regenerating golden files
@@ -35,6 +39,8 @@ func TestGoldenGeneration(t *testing.T) {
 }
 
 // runGoldenTest runs a single golden test case.
+// Besides kessoku.go, a test case may declare injectors in other files named kessoku_*.go,
+// whose generated code is compared with expected_*.go.
 func runGoldenTest(t *testing.T, testdataDir, testName string) {
 	t.Helper()
 
@@ -46,49 +52,256 @@ func runGoldenTest(t *testing.T, testdataDir, testName string) {
 		t.Fatalf("test case %s: missing kessoku.go", testName)
 	}
 
-	// Generated file path (kessoku.go -> kessoku_band.go)
-	generatedPath := filepath.Join(srcDir, "kessoku_band.go")
+	extraPaths, err := filepath.Glob(filepath.Join(srcDir, "kessoku_*.go"))
+	if err != nil {
+		t.Fatalf("test case %s: failed to list source files: %v", testName, err)
+	}
+	sourcePaths := []string{kessokuPath}
+	for _, path := range extraPaths {
+		if !strings.HasSuffix(path, "_band.go") {
+			sourcePaths = append(sourcePaths, path)
+		}
+	}
+
+	for _, sourcePath := range sourcePaths {
+		// Generated file path (kessoku.go -> kessoku_band.go)
+		generatedPath := outputFileName(sourcePath, defaultOutputSuffix)
+
+		// Clean up generated file after test (unless updating)
+		if !*update {
+			defer func() {
+				_ = os.Remove(generatedPath)
+			}()
+		}
+
+		// Run processor directly on the testdata directory
+		// This works because testdata is within the main module.
+		// Each file is processed on its own, as go:generate does with $GOFILE.
+		processor := NewProcessor()
+		if err := processor.ProcessFiles([]string{sourcePath}); err != nil {
+			t.Fatalf("test case %s: generation of %s failed: %v", testName, filepath.Base(sourcePath), err)
+		}
+
+		// Read generated output
+		actual, err := os.ReadFile(generatedPath)
+		if err != nil {
+			t.Fatalf("test case %s: failed to read generated file: %v", testName, err)
+		}
 
-	// Clean up generated file after test (unless updating)
-	if !*update {
-		defer func() {
+		// Handle update mode
+		expectedPath := filepath.Join(srcDir, strings.Replace(filepath.Base(sourcePath), "kessoku", "expected", 1))
+		if *update {
+			if writeErr := os.WriteFile(expectedPath, actual, 0644); writeErr != nil {
+				t.Fatalf("failed to update golden file: %v", writeErr)
+			}
+			// Also remove the generated file in update mode
 			_ = os.Remove(generatedPath)
-		}()
+			t.Logf("updated golden file: %s", expectedPath)
+			continue
+		}
+
+		// Compare with expected.go
+		expected, readErr := os.ReadFile(expectedPath)
+		if readErr != nil {
+			t.Fatalf("test case %s: missing golden file: %s", testName, expectedPath)
+		}
+
+		if string(actual) != string(expected) {
+			t.Errorf("test case %s: output mismatch of %s:\n--- expected ---\n%s\n--- got ---\n%s",
+				testName, filepath.Base(sourcePath), string(expected), string(actual))
+		}
+	}
+}
+
+// TestGoldenGeneration_Deterministic generates async graphs twice and asserts identical output.
+func TestGoldenGeneration_Deterministic(t *testing.T) {
+	testdataDir := "testdata"
+
+	for _, testName := range []string{"async_parallel", "async_chain", "complex_async", "diamond_dependency"} {
+		t.Run(testName, func(t *testing.T) {
+			kessokuPath := filepath.Join(testdataDir, testName, "kessoku.go")
+			generatedPath := filepath.Join(testdataDir, testName, "kessoku_band.go")
+			defer func() {
+				_ = os.Remove(generatedPath)
+			}()
+
+			outputs := make([]string, 2)
+			for i := range outputs {
+				processor := NewProcessor()
+				if err := processor.ProcessFiles([]string{kessokuPath}); err != nil {
+					t.Fatalf("test case %s: generation %d failed: %v", testName, i, err)
+				}
+
+				actual, err := os.ReadFile(generatedPath)
+				if err != nil {
+					t.Fatalf("test case %s: failed to read generated file: %v", testName, err)
+				}
+				outputs[i] = string(actual)
+
+				_ = os.Remove(generatedPath)
+			}
+
+			if outputs[0] != outputs[1] {
+				t.Errorf("test case %s: output differs between runs:\n--- first ---\n%s\n--- second ---\n%s",
+					testName, outputs[0], outputs[1])
+			}
+		})
 	}
+}
+
+// TestGoldenGeneration_SharedProviderCalledOnce asserts that a provider needed by several
+// dependents is called exactly once by the generated injector.
+func TestGoldenGeneration_SharedProviderCalledOnce(t *testing.T) {
+	testdataDir := "testdata"
+	testName := "diamond_dependency"
+
+	kessokuPath := filepath.Join(testdataDir, testName, "kessoku.go")
+	generatedPath := filepath.Join(testdataDir, testName, "kessoku_band.go")
+	defer func() {
+		_ = os.Remove(generatedPath)
+	}()
 
-	// Run processor directly on the testdata directory
-	// This works because testdata is within the main module
-	processor := NewProcessor()
-	if err := processor.ProcessFiles([]string{kessokuPath}); err != nil {
+	if err := NewProcessor().ProcessFiles([]string{kessokuPath}); err != nil {
 		t.Fatalf("test case %s: generation failed: %v", testName, err)
 	}
 
-	// Read generated output
 	actual, err := os.ReadFile(generatedPath)
 	if err != nil {
 		t.Fatalf("test case %s: failed to read generated file: %v", testName, err)
 	}
 
-	// Handle update mode
-	expectedPath := filepath.Join(srcDir, "expected.go")
-	if *update {
-		if writeErr := os.WriteFile(expectedPath, actual, 0644); writeErr != nil {
-			t.Fatalf("failed to update golden file: %v", writeErr)
-		}
-		// Also remove the generated file in update mode
+	if count := strings.Count(string(actual), "kessoku.Provide(NewDatabase)"); count != 1 {
+		t.Errorf("test case %s: expected NewDatabase to be called once, got %d calls:\n%s", testName, count, actual)
+	}
+}
+
+// TestGoldenGeneration_AsyncFanOut asserts that the result of an async provider is handed to every
+// async consumer waiting for it: its channel is closed, which wakes all receivers, instead of sent on,
+// which would wake only one of them and leave the others blocked.
+func TestGoldenGeneration_AsyncFanOut(t *testing.T) {
+	testdataDir := "testdata"
+	testName := "async_fan_out"
+
+	kessokuPath := filepath.Join(testdataDir, testName, "kessoku.go")
+	generatedPath := filepath.Join(testdataDir, testName, "kessoku_band.go")
+	defer func() {
 		_ = os.Remove(generatedPath)
-		t.Logf("updated golden file: %s", expectedPath)
-		return
+	}()
+
+	if err := NewProcessor().ProcessFiles([]string{kessokuPath}); err != nil {
+		t.Fatalf("test case %s: generation failed: %v", testName, err)
+	}
+
+	actual, err := os.ReadFile(generatedPath)
+	if err != nil {
+		t.Fatalf("test case %s: failed to read generated file: %v", testName, err)
+	}
+	generated := string(actual)
+
+	if count := strings.Count(generated, "kessoku.Provide(NewDatabase)"); count != 1 {
+		t.Errorf("test case %s: expected NewDatabase to be called once, got %d calls:\n%s", testName, count, generated)
+	}
+	if count := strings.Count(generated, "close(databaseCh)"); count != 1 {
+		t.Errorf("test case %s: expected the database channel to be closed once, got %d closes:\n%s", testName, count, generated)
+	}
+	if strings.Contains(generated, "databaseCh <-") {
+		t.Errorf("test case %s: expected the database channel never to be sent on:\n%s", testName, generated)
+	}
+	if count := strings.Count(generated, "case <-databaseCh:"); count != 2 {
+		t.Errorf("test case %s: expected two goroutines to wait for the database, got %d:\n%s", testName, count, generated)
+	}
+
+	// The expected code is part of the test package, so running it checks that both consumers receive the value
+	if err := os.Remove(generatedPath); err != nil {
+		t.Fatalf("test case %s: failed to remove generated file: %v", testName, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", ".")
+	cmd.Dir = filepath.Join(testdataDir, testName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("test case %s: running the injector failed: %v\n%s", testName, err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "dials: 1" {
+		t.Errorf("test case %s: expected a single shared database, got %q", testName, got)
+	}
+}
+
+// TestGoldenGeneration_CleanupCloserAsyncError asserts that when a provider of an injector declared with
+// kessoku.CleanupCloser fails, the async providers still running are waited for and their values closed.
+func TestGoldenGeneration_CleanupCloserAsyncError(t *testing.T) {
+	testdataDir := "testdata"
+	testName := "cleanup_closer_async_error"
+
+	// The expected code is part of the test package, so running it checks that the slow cache is closed
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", ".")
+	cmd.Dir = filepath.Join(testdataDir, testName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("test case %s: running the injector failed: %v\n%s", testName, err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "true store unavailable true" {
+		t.Errorf("test case %s: expected the cache to be closed after the store failed, got %q", testName, got)
+	}
+}
+
+// TestGoldenGeneration_LazyInjectorRetry asserts that a lazy getter failing because the context of
+// its call is done does not cache the failure, so that the next call constructs the result.
+func TestGoldenGeneration_LazyInjectorRetry(t *testing.T) {
+	testdataDir := "testdata"
+	testName := "lazy_injector"
+
+	// The expected code is part of the test package, so running it calls the getter twice
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", ".")
+	cmd.Dir = filepath.Join(testdataDir, testName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("test case %s: running the injector failed: %v\n%s", testName, err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "postgres://localhost:5432/app context canceled true" {
+		t.Errorf("test case %s: expected the canceled call not to be cached, got %q", testName, got)
 	}
+}
+
+// TestGoldenGeneration_PooledMultiFile asserts that the pools of injectors generated from different
+// files of a package do not collide, by building the package with both expected files.
+func TestGoldenGeneration_PooledMultiFile(t *testing.T) {
+	testdataDir := "testdata"
+	testName := "pooled_multi_file"
 
-	// Compare with expected.go
-	expected, readErr := os.ReadFile(expectedPath)
-	if readErr != nil {
-		t.Fatalf("test case %s: missing golden file: %s", testName, expectedPath)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", ".")
+	cmd.Dir = filepath.Join(testdataDir, testName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("test case %s: running the injectors failed: %v\n%s", testName, err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "hello, pool\n1 rendered" {
+		t.Errorf("test case %s: unexpected output %q", testName, got)
 	}
+}
+
+// TestGoldenGeneration_HTTPClientSharedTransport asserts that the clients of two injectors given
+// the same package-level transport use the same transport.
+func TestGoldenGeneration_HTTPClientSharedTransport(t *testing.T) {
+	testdataDir := "testdata"
+	testName := "http_client"
 
-	if string(actual) != string(expected) {
-		t.Errorf("test case %s: output mismatch:\n--- expected ---\n%s\n--- got ---\n%s",
-			testName, string(expected), string(actual))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", ".")
+	cmd.Dir = filepath.Join(testdataDir, testName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("test case %s: running the injectors failed: %v\n%s", testName, err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "10s true true" {
+		t.Errorf("test case %s: expected both clients to share the transport, got %q", testName, got)
 	}
 }
@@ -15,6 +15,28 @@ import (
 	"github.com/mazrean/kessoku/internal/pkg/collection"
 )
 
+// instantiateASTTypeExpr appends the type arguments of a generic type instantiation, such as Repo[User], to expr.
+func instantiateASTTypeExpr(pkg string, expr ast.Expr, typeArgs *types.TypeList, varPool *VarPool, imports map[string]*Import) (ast.Expr, error) {
+	if typeArgs.Len() == 0 {
+		return expr, nil
+	}
+
+	indices := make([]ast.Expr, 0, typeArgs.Len())
+	for typeArg := range typeArgs.Types() {
+		indexExpr, err := createASTTypeExpr(pkg, typeArg, varPool, imports)
+		if err != nil {
+			return nil, fmt.Errorf("type argument: %w", err)
+		}
+		indices = append(indices, indexExpr)
+	}
+
+	if len(indices) == 1 {
+		return &ast.IndexExpr{X: expr, Index: indices[0]}, nil
+	}
+
+	return &ast.IndexListExpr{X: expr, Indices: indices}, nil
+}
+
 // createASTTypeExpr creates an AST type expression from a types.Type and updates existingImports
 func createASTTypeExpr(pkg string, t types.Type, varPool *VarPool, imports map[string]*Import) (ast.Expr, error) {
 	switch typ := t.(type) {
@@ -49,13 +71,13 @@ func createASTTypeExpr(pkg string, t types.Type, varPool *VarPool, imports map[s
 				}
 			}
 
-			return &ast.SelectorExpr{
+			return instantiateASTTypeExpr(pkg, &ast.SelectorExpr{
 				X:   ast.NewIdent(pkgName),
 				Sel: ast.NewIdent(name),
-			}, nil
+			}, typ.TypeArgs(), varPool, imports)
 		}
 
-		return ast.NewIdent(name), nil
+		return instantiateASTTypeExpr(pkg, ast.NewIdent(name), typ.TypeArgs(), varPool, imports)
 	case *types.Alias:
 		name := typ.Obj().Name()
 		if objPkg := typ.Obj().Pkg(); objPkg != nil && objPkg.Path() != pkg {
@@ -209,7 +231,10 @@ func createASTTypeExpr(pkg string, t types.Type, varPool *VarPool, imports map[s
 	}
 }
 
-func CreateInjector(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Injector, error) {
+// If disableAsync is true, providers marked with kessoku.Async are generated sequentially.
+// Injectors with fewer than asyncThreshold providers are also generated sequentially,
+// but keep the context.Context argument of their async providers.
+func CreateInjector(metaData *MetaData, build *BuildDirective, varPool *VarPool, disableAsync bool, asyncThreshold int) (*Injector, error) {
 	slog.Debug("CreateInjector", "build", build)
 	for _, provider := range build.Providers {
 		slog.Debug("provider", "provider", provider)
@@ -218,18 +243,68 @@ func CreateInjector(metaData *MetaData, build *BuildDirective, varPool *VarPool)
 	if err != nil {
 		return nil, fmt.Errorf("create graph: %w", err)
 	}
+	graph.disableAsync = disableAsync
+	graph.asyncThreshold = asyncThreshold
 
 	injector, err := graph.Build(metaData, varPool)
 	if err != nil {
 		return nil, fmt.Errorf("build injector: %w", err)
 	}
+	injector.Metrics = graph.metrics()
 
 	return injector, nil
 }
 
+// linkFactories checks the injectors called by the factory providers of build against the function
+// types they provide. injectors holds the injectors created from the file by name; factories calling
+// an injector that could not be created are left to the error of that injector.
+func linkFactories(build *BuildDirective, injectors map[string]*Injector) error {
+	for _, provider := range build.Providers {
+		if provider.Type != ProviderTypeFactory {
+			continue
+		}
+		factory := provider.Factory
+		target, ok := injectors[factory.Injector]
+		if !ok {
+			continue
+		}
+
+		factoryType := typeKey(provider.Provides[0][0])
+		switch {
+		case len(target.Args) > 0:
+			return fmt.Errorf("injector %s takes arguments, so it cannot provide %s", target.Name, factoryType)
+		case len(target.ReadyChecks) > 0:
+			return fmt.Errorf("injector %s returns a ready check, so it cannot provide %s", target.Name, factoryType)
+		case target.IsReturnError && !factory.ReturnsError:
+			return fmt.Errorf("injector %s returns an error, so it cannot provide %s", target.Name, factoryType)
+		}
+
+		// An injector returning no error is wrapped in a closure returning a nil error
+		factory.wrapError = factory.ReturnsError && !target.IsReturnError
+		if target.Return != nil && target.Return.Param != nil {
+			factory.returnImports = target.Return.Param.ReferencedImports
+		}
+	}
+
+	return nil
+}
+
+// MissingProviderError reports a type that the injector uses in a way requiring a provider,
+// such as an ordering or a tag selection, but that no provider provides.
+type MissingProviderError struct {
+	Type        types.Type
+	Usage       string   // How the injector uses Type, e.g. "ordered with After"
+	Suggestions []string // Provider declarations of package functions returning Type, filled by the Processor
+}
+
+func (e *MissingProviderError) Error() string {
+	return fmt.Sprintf("no provider of %s %s", typeKey(e.Type), e.Usage)
+}
+
 type argument struct {
 	Type        types.Type
 	ASTTypeExpr ast.Expr
+	Name        string
 }
 
 type node struct {
@@ -243,6 +318,8 @@ type edgeNode struct {
 	node          *node
 	provideArgSrc int
 	provideArgDst int
+	unary         token.Token // Conversion of the value for kessoku.AutoRef, see InjectorCallArgument
+	orderOnly     bool        // Ordering declared with kessoku.After, which waits without passing the value
 }
 
 type returnVal struct {
@@ -251,33 +328,96 @@ type returnVal struct {
 }
 
 type Graph struct {
-	edges        map[*node][]*edgeNode
-	reverseEdges map[*node][]*node
-	returnType   *Return
-	returnValue  *returnVal
-	injectorName string
-	nodes        []*node
+	edges          map[*node][]*edgeNode
+	reverseEdges   map[*node][]*node
+	returnType     *Return
+	returnValue    *returnVal
+	implements     *Implementation
+	errorWrapper   *ErrorWrapper
+	channelTrace   *ChannelTrace
+	injectorName   string
+	panicFormat    string
+	sharedName     string
+	nodes          []*node
+	blankImports   []string
+	asyncThreshold int
+	isLazy         bool
+	isMust         bool
+	overrides      bool
+	withCancel     bool
+	omitContext    bool
+	cleanupCloser  bool
+	runWrapper     bool
+	forTest        bool
+	disableAsync   bool
+}
+
+// fnProvider identifies the result of a provider that supplies a type.
+type fnProvider struct {
+	provider    *ProviderSpec
+	returnIndex int
 }
 
 func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Graph, error) {
 	graph := &Graph{
-		injectorName: build.InjectorName,
-		returnType:   build.Return,
-		edges:        make(map[*node][]*edgeNode),
-		reverseEdges: make(map[*node][]*node),
+		injectorName:  build.InjectorName,
+		returnType:    build.Return,
+		implements:    build.Implements,
+		errorWrapper:  build.ErrorWrapper,
+		channelTrace:  build.ChannelTrace,
+		isLazy:        build.IsLazy,
+		panicFormat:   build.PanicFormat,
+		sharedName:    build.SharedName,
+		isMust:        build.IsMust,
+		overrides:     build.Overrides,
+		withCancel:    build.WithCancel,
+		omitContext:   build.OmitContext,
+		blankImports:  build.BlankImports,
+		cleanupCloser: build.CleanupCloser,
+		runWrapper:    build.RunWrapper,
+		forTest:       build.ForTest,
+		edges:         make(map[*node][]*edgeNode),
+		reverseEdges:  make(map[*node][]*node),
+	}
+
+	var keys *typeKeys
+	if build.StrictTypes {
+		keys = newTypeKeys()
 	}
 
-	type fnProvider struct {
-		provider    *ProviderSpec
-		returnIndex int
+	providers, err := mergeAppendValues(keys, build.Providers)
+	if err != nil {
+		return nil, err
+	}
+	providers, err = selectTaggedProviders(keys, providers, build.Uses)
+	if err != nil {
+		return nil, err
+	}
+	build.Providers = providers
+	if err := collectImplementers(build.Providers); err != nil {
+		return nil, err
+	}
+	if err := verifyMethodSets(build.Providers); err != nil {
+		return nil, err
 	}
 
 	fnProviderMap := make(map[string]*fnProvider)
+	namedArgMap := make(map[string]*ProviderSpec)
 	declOrder := 0
 
 	// First pass: Process non-struct providers and assign DeclOrder
 	var structProviders []*ProviderSpec
 	for _, provider := range build.Providers {
+		// Named arguments are matched by parameter name, not by type alone
+		if provider.Type == ProviderTypeArg {
+			key := namedArgKey(provider.ArgName, keys.key(provider.Provides[0][0]))
+			if _, ok := namedArgMap[key]; ok {
+				return nil, fmt.Errorf("multiple named arguments %q of type %s", provider.ArgName, provider.Provides[0][0])
+			}
+			namedArgMap[key] = provider
+			continue
+		}
+
 		// Skip struct providers in first pass - they are processed in second pass
 		if provider.Type == ProviderTypeStruct {
 			structProviders = append(structProviders, provider)
@@ -292,17 +432,21 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 				if t == nil {
 					return nil, fmt.Errorf("provider has nil type at group %d, index %d", groupIndex, typeIndex)
 				}
-				key := t.String()
+				key := keys.key(t)
 
 				if existing, ok := fnProviderMap[key]; ok {
 					// Allow the same provider to provide multiple types (e.g., concrete and interface)
 					// but still error if different providers try to provide the same type
-					if existing.provider != provider {
-						return nil, fmt.Errorf("multiple providers provide %s", key)
+					if existing.provider == provider {
+						// If it's the same provider, just update the return index to the first occurrence
+						// This handles the case where bindProvider adds both concrete and interface types
+						continue
 					}
-					// If it's the same provider, just update the return index to the first occurrence
-					// This handles the case where bindProvider adds both concrete and interface types
-					continue
+					if !build.LastWins {
+						return nil, fmt.Errorf("multiple providers provide %s%s", key, conflictSource(existing.provider, provider))
+					}
+					// Overrides are allowed but never silent, since they may hide a mistake
+					slog.Warn("Provider overridden by a later provider", "injector", build.InjectorName, "type", key)
 				}
 
 				fnProviderMap[key] = &fnProvider{
@@ -314,15 +458,16 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 	}
 
 	// Second pass: Expand struct providers into synthetic field accessor providers
+	var nestedProviders []*ProviderSpec
 	for _, structProvider := range structProviders {
 		if structProvider.StructType == nil {
 			return nil, fmt.Errorf("struct provider has nil StructType")
 		}
 
 		// Find the provider that provides this struct type
-		structTypeKey := structProvider.StructType.String()
+		structTypeKey := keys.key(structProvider.StructType)
 		if _, ok := fnProviderMap[structTypeKey]; !ok {
-			return nil, fmt.Errorf("no provider for struct type %s", structTypeKey)
+			return nil, &MissingProviderError{Type: structProvider.StructType, Usage: "expanded with Struct"}
 		}
 
 		// Create synthetic field accessor providers for each exported field
@@ -337,9 +482,9 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 			}
 			declOrder++
 
-			fieldTypeKey := field.Type.String()
-			if _, ok := fnProviderMap[fieldTypeKey]; ok {
-				return nil, fmt.Errorf("multiple providers provide %s (field %s conflicts with existing provider)", fieldTypeKey, field.Name)
+			fieldTypeKey := keys.key(field.Type)
+			if existing, ok := fnProviderMap[fieldTypeKey]; ok {
+				return nil, fmt.Errorf("multiple providers provide %s (field %s conflicts with existing provider)%s", fieldTypeKey, field.Name, conflictSource(existing.provider, structProvider))
 			}
 
 			fnProviderMap[fieldTypeKey] = &fnProvider{
@@ -348,37 +493,113 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 			}
 			// Add to build.Providers so it's included in graph processing
 			build.Providers = append(build.Providers, fieldProvider)
+			nestedProviders = append(nestedProviders, fieldProvider)
 		}
 	}
 
-	if build.Return.Type == nil {
-		return nil, fmt.Errorf("return type is nil")
+	// Expand the fields of nested structs one level at a time
+	for len(nestedProviders) > 0 {
+		var fieldProviders []*ProviderSpec
+		fieldProviders, declOrder = expandNestedStructFields(keys, nestedProviders, fnProviderMap, declOrder)
+		build.Providers = append(build.Providers, fieldProviders...)
+		nestedProviders = fieldProviders
 	}
-	returnTypeKey := build.Return.Type.String()
 
-	returnProvider, ok := fnProviderMap[returnTypeKey]
-	if !ok {
-		n, err := graph.autoAddMissingDependencies(metaData, build.Return.Type, varPool)
+	// Declared arguments are added first so that they become parameters in declaration order
+	argNodeMap := make(map[string]*node)
+	for _, t := range build.Args {
+		key := keys.key(t)
+		if _, ok := fnProviderMap[key]; ok {
+			return nil, fmt.Errorf("argument %s is also provided by a provider", key)
+		}
+		if _, ok := argNodeMap[key]; ok {
+			return nil, fmt.Errorf("multiple arguments of type %s", key)
+		}
+
+		n, err := graph.newArgNode(metaData, t, varPool)
 		if err != nil {
-			return nil, fmt.Errorf("auto add missing return dependency: %w", err)
+			return nil, fmt.Errorf("add declared argument: %w", err)
 		}
-		graph.returnValue = &returnVal{
-			node:        n,
-			returnIndex: 0,
+		if build.CommandArgs && types.Identical(t, commandArgsType) {
+			n.arg.Name = "args"
 		}
+		argNodeMap[key] = n
 		graph.nodes = append(graph.nodes, n)
-		return graph, nil
 	}
 
+	var returnProvider *fnProvider
+	if build.Return == nil {
+		// kessoku.Populate returns nothing, so the graph is rooted at the provider filling the target
+		idx := slices.IndexFunc(build.Providers, func(provider *ProviderSpec) bool {
+			return provider.Type == ProviderTypePopulate
+		})
+		if idx == -1 {
+			return nil, fmt.Errorf("return type is nil")
+		}
+		returnProvider = &fnProvider{provider: build.Providers[idx], returnIndex: -1}
+
+		if err := applyPopulateDefaults(keys, build, fnProviderMap, argNodeMap, returnProvider.provider); err != nil {
+			return nil, err
+		}
+	} else {
+		if build.Return.Type == nil {
+			return nil, fmt.Errorf("return type is nil")
+		}
+		returnTypeKey := keys.key(build.Return.Type)
+
+		var ok bool
+		returnProvider, ok = fnProviderMap[returnTypeKey]
+		if !ok && types.IsInterface(build.Return.Type) {
+			if _, isArg := argNodeMap[returnTypeKey]; !isArg {
+				// An interface return type is satisfied by the only provided type implementing it,
+				// which the return statement converts implicitly
+				implementer, err := uniqueAssignableProvider(build, build.Return.Type)
+				if err != nil {
+					return nil, fmt.Errorf("resolve return type: %w", err)
+				}
+				returnProvider, ok = implementer, implementer != nil
+			}
+		}
+		if !ok {
+			n, ok := argNodeMap[returnTypeKey]
+			if !ok {
+				var err error
+				n, err = graph.autoAddMissingDependencies(metaData, build.Return.Type, varPool)
+				if err != nil {
+					return nil, fmt.Errorf("auto add missing return dependency: %w", err)
+				}
+				graph.nodes = append(graph.nodes, n)
+			}
+			graph.returnValue = &returnVal{
+				node:        n,
+				returnIndex: 0,
+			}
+			return graph, nil
+		}
+	}
+
+	for _, order := range build.Orders {
+		for _, t := range []types.Type{order.Before, order.After} {
+			if _, ok := fnProviderMap[keys.key(t)]; !ok {
+				return nil, &MissingProviderError{Type: t, Usage: "ordered with After"}
+			}
+		}
+		if fnProviderMap[keys.key(order.Before)].provider == fnProviderMap[keys.key(order.After)].provider {
+			return nil, fmt.Errorf("%s and %s ordered with After are provided by the same provider", order.Before, order.After)
+		}
+	}
+
+	// Every provider is represented by a single node, so it is called once per injector run
+	// no matter how many providers depend on its results
 	providerNodeMap := make(map[*ProviderSpec]*node)
-	argNodeMap := make(map[string]*node)
 	queue := collection.NewQueue[*node]()
 	visited := make(map[*node]bool)
 
 	returnNode := &node{
 		providerSpec: returnProvider.provider,
-		providerArgs: make([]*InjectorCallArgument, len(returnProvider.provider.Requires)),
+		providerArgs: make([]*InjectorCallArgument, len(returnProvider.provider.dependencies())),
 	}
+	providerNodeMap[returnProvider.provider] = returnNode
 	graph.returnValue = &returnVal{
 		node:        returnNode,
 		returnIndex: returnProvider.returnIndex,
@@ -398,21 +619,38 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 			continue
 		}
 
-		for i, t := range n1.providerSpec.Requires {
+		for i, t := range n1.providerSpec.dependencies() {
 			if t == nil {
 				return nil, fmt.Errorf("provider has nil required type at index %d", i)
 			}
-			key := t.String()
+			key := keys.key(t)
 			var (
 				n2       *node
 				srcIndex int
+				unary    token.Token
 			)
-			if provider, ok := fnProviderMap[key]; ok {
+			if namedArg, ok := namedArgMap[namedArgKey(n1.providerSpec.requireName(i), key)]; ok {
+				namedKey := namedArgKey(namedArg.ArgName, key)
+				n2, ok = argNodeMap[namedKey]
+				if !ok {
+					var err error
+					n2, err = graph.newArgNode(metaData, t, varPool)
+					if err != nil {
+						return nil, fmt.Errorf("add named argument %q: %w", namedArg.ArgName, err)
+					}
+					n2.arg.Name = namedArg.ArgName
+
+					argNodeMap[namedKey] = n2
+					queue.Push(n2)
+					graph.nodes = append(graph.nodes, n2)
+				}
+				srcIndex = 0
+			} else if provider, ok := fnProviderMap[key]; ok {
 				n2, ok = providerNodeMap[provider.provider]
 				if !ok {
 					n2 = &node{
 						providerSpec: provider.provider,
-						providerArgs: make([]*InjectorCallArgument, len(provider.provider.Requires)),
+						providerArgs: make([]*InjectorCallArgument, len(provider.provider.dependencies())),
 					}
 					providerNodeMap[provider.provider] = n2
 					queue.Push(n2)
@@ -422,6 +660,35 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 				srcIndex = provider.returnIndex
 			} else if n2, ok = argNodeMap[key]; ok {
 				srcIndex = 0
+			} else if assignable, findErr := findAssignableProvider(build, t); findErr != nil {
+				return nil, findErr
+			} else if assignable != nil {
+				n2, ok = providerNodeMap[assignable.provider]
+				if !ok {
+					n2 = &node{
+						providerSpec: assignable.provider,
+						providerArgs: make([]*InjectorCallArgument, len(assignable.provider.dependencies())),
+					}
+					providerNodeMap[assignable.provider] = n2
+					queue.Push(n2)
+					graph.nodes = append(graph.nodes, n2)
+				}
+
+				srcIndex = assignable.returnIndex
+			} else if ref, refUnary := findRefProvider(keys, build, fnProviderMap, t); ref != nil {
+				n2, ok = providerNodeMap[ref.provider]
+				if !ok {
+					n2 = &node{
+						providerSpec: ref.provider,
+						providerArgs: make([]*InjectorCallArgument, len(ref.provider.dependencies())),
+					}
+					providerNodeMap[ref.provider] = n2
+					queue.Push(n2)
+					graph.nodes = append(graph.nodes, n2)
+				}
+
+				srcIndex = ref.returnIndex
+				unary = refUnary
 			} else {
 				// Auto-detect missing dependency and create an argument for it
 				var err error
@@ -440,9 +707,38 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 				node:          n1,
 				provideArgSrc: srcIndex,
 				provideArgDst: i,
+				unary:         unary,
 			})
 			graph.reverseEdges[n1] = append(graph.reverseEdges[n1], n2)
 		}
+
+		// Orderings wait for the provider of the Before type through an extra argument slot that is never passed
+		for _, order := range build.Orders {
+			if fnProviderMap[keys.key(order.After)].provider != n1.providerSpec {
+				continue
+			}
+
+			before := fnProviderMap[keys.key(order.Before)]
+			n2, ok := providerNodeMap[before.provider]
+			if !ok {
+				n2 = &node{
+					providerSpec: before.provider,
+					providerArgs: make([]*InjectorCallArgument, len(before.provider.dependencies())),
+				}
+				providerNodeMap[before.provider] = n2
+				queue.Push(n2)
+				graph.nodes = append(graph.nodes, n2)
+			}
+
+			graph.edges[n2] = append(graph.edges[n2], &edgeNode{
+				node:          n1,
+				provideArgSrc: before.returnIndex,
+				provideArgDst: len(n1.providerArgs),
+				orderOnly:     true,
+			})
+			graph.reverseEdges[n1] = append(graph.reverseEdges[n1], n2)
+			n1.providerArgs = append(n1.providerArgs, nil)
+		}
 	}
 
 	// Check for cycles in the dependency graph
@@ -453,6 +749,181 @@ func NewGraph(metaData *MetaData, build *BuildDirective, varPool *VarPool) (*Gra
 	return graph, nil
 }
 
+// findAssignableProvider returns the provider result assignable to t when build enables
+// kessoku.AutoConvert, or nil if there is none. Several assignable results are an error.
+// A receive-only or send-only channel is always satisfied by a bidirectional channel of the same element type.
+// applyPopulateDefaults assigns the default tag of the kessoku.Populate fields that no provider
+// or argument supplies, instead of adding the fields as injector arguments.
+func applyPopulateDefaults(keys *typeKeys, build *BuildDirective, fnProviderMap map[string]*fnProvider, argNodeMap map[string]*node, populate *ProviderSpec) error {
+	requires := []types.Type{populate.StructType}
+	for _, field := range populate.StructFields {
+		field.UseDefault = false
+		if field.Default != nil {
+			key := keys.key(field.Type)
+			_, provided := fnProviderMap[key]
+			_, isArg := argNodeMap[key]
+			assignable, err := findAssignableProvider(build, field.Type)
+			if err != nil {
+				return err
+			}
+			ref, _ := findRefProvider(keys, build, fnProviderMap, field.Type)
+			field.UseDefault = !provided && !isArg && assignable == nil && ref == nil
+		}
+
+		if !field.UseDefault {
+			requires = append(requires, field.Type)
+		}
+	}
+	populate.Requires = requires
+
+	return nil
+}
+
+func findAssignableProvider(build *BuildDirective, t types.Type) (*fnProvider, error) {
+	if !build.AutoConvert && !isDirectionalChan(t) {
+		return nil, nil
+	}
+
+	return uniqueAssignableProvider(build, t)
+}
+
+// findRefProvider returns the provider of T for a required *T, with token.AND to take its address,
+// or the provider of *T for a required T, with token.MUL to dereference it, when build enables
+// kessoku.AutoRef. It returns nil if there is none.
+func findRefProvider(keys *typeKeys, build *BuildDirective, fnProviderMap map[string]*fnProvider, t types.Type) (*fnProvider, token.Token) {
+	if !build.AutoRef {
+		return nil, token.ILLEGAL
+	}
+
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		if provider, ok := fnProviderMap[keys.key(ptr.Elem())]; ok {
+			return provider, token.AND
+		}
+		return nil, token.ILLEGAL
+	}
+
+	if provider, ok := fnProviderMap[keys.key(types.NewPointer(t))]; ok {
+		return provider, token.MUL
+	}
+
+	return nil, token.ILLEGAL
+}
+
+// uniqueAssignableProvider returns the provider result assignable to t, or nil if there is none.
+// Several assignable results are an error.
+func uniqueAssignableProvider(build *BuildDirective, t types.Type) (*fnProvider, error) {
+	var (
+		found      *fnProvider
+		foundTypes []string
+	)
+	for _, provider := range build.Providers {
+		if provider.Type == ProviderTypeArg || provider.Type == ProviderTypeStruct {
+			continue
+		}
+
+		for groupIndex, typeGroup := range provider.Provides {
+			for _, provided := range typeGroup {
+				if !types.AssignableTo(provided, t) {
+					continue
+				}
+
+				// Types provided together (e.g. by Bind) are the same value
+				if found != nil && found.provider == provider && found.returnIndex == groupIndex {
+					continue
+				}
+
+				foundTypes = append(foundTypes, provided.String())
+				if found == nil {
+					found = &fnProvider{provider: provider, returnIndex: groupIndex}
+				}
+			}
+		}
+	}
+
+	if len(foundTypes) > 1 {
+		return nil, fmt.Errorf("multiple provided types are assignable to %s: %s", t, strings.Join(foundTypes, ", "))
+	}
+
+	return found, nil
+}
+
+// isDirectionalChan reports whether t is a receive-only or send-only channel type.
+func isDirectionalChan(t types.Type) bool {
+	ch, ok := t.Underlying().(*types.Chan)
+	return ok && ch.Dir() != types.SendRecv
+}
+
+// typeKey returns the key identifying t among provided and required types.
+// Aliases are resolved, also inside pointer, slice, array, map, and channel types,
+// so that a type alias and its target are the same type as in the type checker.
+//
+// Keys are the string form of types, which identical types always share. The string form
+// also matches a type loaded more than once, such as context.Context from a separately loaded
+// package, but it collides for distinct types printed alike: types of the same name declared
+// in different functions, and anonymous structs and interfaces whose unexported fields or
+// methods belong to different packages. typeKeys tells those apart.
+func typeKey(t types.Type) string {
+	return unaliasType(t).String()
+}
+
+// typeKeys keys types by their types.Identical equivalence classes instead of their string
+// form alone, declared with kessoku.StrictTypeIdentity. The first class of a string form is
+// keyed by typeKey, so keys and messages only change for the types that would collide.
+// A nil typeKeys keys every type by typeKey.
+type typeKeys struct {
+	classes map[string][]types.Type // Representatives of the classes sharing a string form
+}
+
+func newTypeKeys() *typeKeys {
+	return &typeKeys{classes: make(map[string][]types.Type)}
+}
+
+// key returns the key of t, numbering the classes after the first one sharing its string form.
+func (k *typeKeys) key(t types.Type) string {
+	key := typeKey(t)
+	if k == nil {
+		return key
+	}
+
+	t = unaliasType(t)
+	classes := k.classes[key]
+	i := slices.IndexFunc(classes, func(class types.Type) bool {
+		return types.Identical(class, t)
+	})
+	if i == -1 {
+		i = len(classes)
+		k.classes[key] = append(classes, t)
+	}
+	if i == 0 {
+		return key
+	}
+
+	return fmt.Sprintf("%s#%d", key, i)
+}
+
+// unaliasType returns t with the aliases it is built from replaced by their targets.
+func unaliasType(t types.Type) types.Type {
+	switch typ := types.Unalias(t).(type) {
+	case *types.Pointer:
+		return types.NewPointer(unaliasType(typ.Elem()))
+	case *types.Slice:
+		return types.NewSlice(unaliasType(typ.Elem()))
+	case *types.Array:
+		return types.NewArray(unaliasType(typ.Elem()), typ.Len())
+	case *types.Map:
+		return types.NewMap(unaliasType(typ.Key()), unaliasType(typ.Elem()))
+	case *types.Chan:
+		return types.NewChan(typ.Dir(), unaliasType(typ.Elem()))
+	default:
+		return typ
+	}
+}
+
+// namedArgKey identifies a kessoku.Named argument by its declared name and the key of its type.
+func namedArgKey(name, key string) string {
+	return name + " " + key
+}
+
 // nodeColor represents the color of a node during DFS for cycle detection
 type nodeColor int
 
@@ -575,23 +1046,36 @@ func (g *Graph) hasAsyncProviders() bool {
 	return false
 }
 
+// hasFallibleProviders checks if any providers in the graph return an error
+func (g *Graph) hasFallibleProviders() bool {
+	for _, n := range g.nodes {
+		if n.providerSpec != nil && n.providerSpec.IsReturnError {
+			return true
+		}
+	}
+	return false
+}
+
 // injectContextArg injects context.Context as the first argument when async providers exist
+// or the injector derives a cancelable context with kessoku.WithCancel
 func (g *Graph) injectContextArg(injector *Injector, metaData *MetaData, varPool *VarPool) error {
-	if !g.hasAsyncProviders() {
+	if !g.hasAsyncProviders() && !g.withCancel {
 		return nil
 	}
 
-	// Check if context.Context already exists in arguments
-	var existingContextArg *InjectorArgument
-	existingContextIdx := -1
-	for i, arg := range injector.Args {
-		if isContextType(arg.Type) {
-			existingContextArg = arg
-			existingContextIdx = i
-			break
-		}
+	// Providers requiring a context already made it an argument, so kessoku.OmitContext runs
+	// the async providers in a plain errgroup.Group only when no provider consumes it. Waits for
+	// the channel of a failed provider are released by the errgroup context, so it is also kept
+	// when a provider can fail.
+	if g.omitContext && !g.withCancel && injector.ContextArg() == nil && !g.hasFallibleProviders() {
+		return nil
 	}
 
+	// Check if an unnamed context.Context already exists in arguments.
+	// Named contexts are left untouched so providers requesting them never receive the errgroup ctx.
+	existingContextArg := injector.ContextArg()
+	existingContextIdx := slices.Index(injector.Args, existingContextArg)
+
 	// If context.Context already exists, move it to the first position
 	if existingContextArg != nil {
 		if existingContextIdx > 0 {
@@ -661,6 +1145,15 @@ func (g *Graph) injectContextArg(injector *Injector, metaData *MetaData, varPool
 
 func (g *Graph) autoAddMissingDependencies(metaData *MetaData, t types.Type, varPool *VarPool) (*node, error) {
 	// Auto-detect missing dependency and create an argument for it
+	if kind := unusualArgKind(t); kind != "" {
+		slog.Warn("Missing dependency of an unusual kind is added as an injector argument; a provider may be missing", "injector", g.injectorName, "type", t.String(), "kind", kind)
+	}
+
+	return g.newArgNode(metaData, t, varPool)
+}
+
+// newArgNode creates an argument node of type t.
+func (g *Graph) newArgNode(metaData *MetaData, t types.Type, varPool *VarPool) (*node, error) {
 	expr, err := createASTTypeExpr(metaData.Package.Path, t, varPool, metaData.Imports)
 	if err != nil {
 		return nil, fmt.Errorf("create AST type expr: %w", err)
@@ -674,13 +1167,271 @@ func (g *Graph) autoAddMissingDependencies(metaData *MetaData, t types.Type, var
 	}, nil
 }
 
+// expandNestedStructFields creates field accessor providers for the exported fields of the struct values
+// provided by fieldProviders, and returns them with the next declaration order.
+//
+// Only struct fields held by value are expanded: pointer fields may be nil, and skipping them also
+// rules out self-referential structs. Nested fields never override another provider, and a type
+// provided by several nested fields at the same depth is ambiguous and left unprovided.
+func expandNestedStructFields(keys *typeKeys, fieldProviders []*ProviderSpec, fnProviderMap map[string]*fnProvider, declOrder int) ([]*ProviderSpec, int) {
+	var (
+		fieldKeys  []string
+		candidates = make(map[string][]*ProviderSpec)
+	)
+	for _, parent := range fieldProviders {
+		parentType := parent.Provides[0][0]
+		if _, ok := parentType.Underlying().(*types.Struct); !ok {
+			continue
+		}
+
+		fields, err := extractExportedFields(parentType)
+		if err != nil {
+			continue
+		}
+
+		for _, field := range fields {
+			key := keys.key(field.Type)
+			if _, ok := fnProviderMap[key]; ok {
+				continue
+			}
+
+			if _, ok := candidates[key]; !ok {
+				fieldKeys = append(fieldKeys, key)
+			}
+			candidates[key] = append(candidates[key], &ProviderSpec{
+				Type:        ProviderTypeFieldAccess,
+				SourceField: field,
+				StructType:  parentType,
+				Provides:    [][]types.Type{{field.Type}},
+				Requires:    []types.Type{parentType},
+			})
+		}
+	}
+
+	var expanded []*ProviderSpec
+	for _, key := range fieldKeys {
+		if len(candidates[key]) > 1 {
+			slog.Debug("skip ambiguous nested struct field", "type", key)
+			continue
+		}
+
+		fieldProvider := candidates[key][0]
+		fieldProvider.DeclOrder = declOrder
+		declOrder++
+
+		fnProviderMap[key] = &fnProvider{
+			provider:    fieldProvider,
+			returnIndex: 0,
+		}
+		expanded = append(expanded, fieldProvider)
+	}
+
+	return expanded, declOrder
+}
+
+// mergeAppendValues replaces the kessoku.AppendValue contributions to each slice type with a
+// single provider calling kessoku.AppendValues, which keeps them in declaration order.
+func mergeAppendValues(keys *typeKeys, providers []*ProviderSpec) ([]*ProviderSpec, error) {
+	merged := make([]*ProviderSpec, 0, len(providers))
+	aggregates := make(map[string]*ProviderSpec)
+	for _, provider := range providers {
+		if provider.Type != ProviderTypeAppendValue {
+			merged = append(merged, provider)
+			continue
+		}
+
+		key := keys.key(provider.Provides[0][0])
+		aggregate, ok := aggregates[key]
+		if !ok {
+			// Call kessoku.AppendValues through the package name used by the contribution
+			call, isCall := provider.ASTExpr.(*ast.CallExpr)
+			if !isCall {
+				return nil, fmt.Errorf("invalid AppendValue expression for %s", key)
+			}
+			fun := ast.Unparen(call.Fun)
+			if index, isIndex := fun.(*ast.IndexExpr); isIndex {
+				fun = index.X
+			}
+			sel, isSel := fun.(*ast.SelectorExpr)
+			if !isSel {
+				return nil, fmt.Errorf("invalid AppendValue expression for %s", key)
+			}
+
+			aggregate = &ProviderSpec{
+				ASTExpr: &ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent("AppendValues")},
+				},
+				Type:              ProviderTypeFunction,
+				Provides:          provider.Provides,
+				ReferencedImports: make(map[string]*Import),
+				SetName:           provider.SetName,
+			}
+			aggregates[key] = aggregate
+			merged = append(merged, aggregate)
+		} else if aggregate.SetName != provider.SetName {
+			aggregate.SetName = ""
+		}
+
+		call := aggregate.ASTExpr.(*ast.CallExpr)
+		call.Args = append(call.Args, provider.ASTExpr)
+		maps.Copy(aggregate.ReferencedImports, provider.ReferencedImports)
+	}
+
+	return merged, nil
+}
+
+// collectImplementers makes every kessoku.CollectImplementing provider require the concrete types
+// implementing its interface, one per provider result in declaration order.
+func collectImplementers(providers []*ProviderSpec) error {
+	for _, collect := range providers {
+		if collect.Type != ProviderTypeCollect {
+			continue
+		}
+
+		iface := collect.Provides[0][0].(*types.Slice).Elem()
+		collect.Requires = nil
+		for _, provider := range providers {
+			switch provider.Type {
+			case ProviderTypeArg, ProviderTypeStruct, ProviderTypePopulate, ProviderTypeCollect:
+				continue
+			}
+
+			for _, typeGroup := range provider.Provides {
+				// Types provided together (e.g. by Bind) are the same value, so it is collected once
+				idx := slices.IndexFunc(typeGroup, func(t types.Type) bool {
+					return t != nil && !types.IsInterface(t) && types.Implements(t, iface.Underlying().(*types.Interface))
+				})
+				if idx != -1 {
+					collect.Requires = append(collect.Requires, typeGroup[idx])
+				}
+			}
+		}
+
+		if len(collect.Requires) == 0 && !collect.AllowEmpty {
+			return fmt.Errorf("no provided type implements %s for CollectImplementing", iface)
+		}
+	}
+
+	return nil
+}
+
+// selectTaggedProviders keeps, for each kessoku.Use selection, only the tagged provider
+// with the selected tag and drops the other tagged providers of the selected type.
+// Untagged providers are kept, so they still conflict with the selected one.
+func selectTaggedProviders(keys *typeKeys, providers []*ProviderSpec, uses []*TagSelection) ([]*ProviderSpec, error) {
+	if len(uses) == 0 {
+		return providers, nil
+	}
+
+	unused := make(map[*ProviderSpec]bool)
+	for _, use := range uses {
+		key := keys.key(use.Type)
+		found := false
+		for _, provider := range providers {
+			if provider.Tag == "" || !providesType(keys, provider, key) {
+				continue
+			}
+
+			if provider.Tag == use.Tag {
+				found = true
+			} else {
+				unused[provider] = true
+			}
+		}
+
+		if !found {
+			return nil, &MissingProviderError{Type: use.Type, Usage: fmt.Sprintf("tagged %q", use.Tag)}
+		}
+	}
+
+	return slices.DeleteFunc(slices.Clone(providers), func(provider *ProviderSpec) bool {
+		return unused[provider]
+	}), nil
+}
+
+// providesType reports whether provider provides the type with the given key.
+func providesType(keys *typeKeys, provider *ProviderSpec, key string) bool {
+	for _, typeGroup := range provider.Provides {
+		for _, t := range typeGroup {
+			if t != nil && keys.key(t) == key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// conflictSource describes the sets two conflicting providers were declared in,
+// or returns "" if neither comes from a set.
+func conflictSource(a, b *ProviderSpec) string {
+	sourceName := func(p *ProviderSpec) string {
+		if p.SetName == "" {
+			return "the injector"
+		}
+		return p.SetName
+	}
+
+	switch {
+	case a.SetName == "" && b.SetName == "":
+		return ""
+	case a.SetName == b.SetName:
+		return fmt.Sprintf(" (both declared in %s)", a.SetName)
+	default:
+		return fmt.Sprintf(" (declared in %s and %s)", sourceName(a), sourceName(b))
+	}
+}
+
+// unusualArgKind returns the kind of t if it is unlikely to be meant as an injector argument,
+// or "" otherwise. Named types are assumed to be intentional.
+func unusualArgKind(t types.Type) string {
+	switch types.Unalias(t).(type) {
+	case *types.Chan:
+		return "chan"
+	case *types.Signature:
+		return "func"
+	case *types.Struct:
+		return "unnamed struct"
+	}
+
+	return ""
+}
+
 func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 	injector := &Injector{
 		Name:          g.injectorName,
+		Implements:    g.implements,
+		ErrorWrapper:  g.errorWrapper,
+		ChannelTrace:  g.channelTrace,
 		IsReturnError: g.isReturnError(),
+		IsLazy:        g.isLazy,
+		PanicFormat:   g.panicFormat,
+		BlankImports:  g.blankImports,
+		SharedName:    g.sharedName,
+		IsMust:        g.isMust,
+		Overrides:     g.overrides,
+		WithCancel:    g.withCancel,
+		CleanupCloser: g.cleanupCloser,
+		RunWrapper:    g.runWrapper,
+		ForTest:       g.forTest,
+	}
+
+	if g.disableAsync {
+		// Treat every provider as synchronous so neither errgroup nor a context argument is generated
+		for _, n := range g.nodes {
+			if n.providerSpec != nil {
+				n.providerSpec.IsAsync = false
+			}
+		}
 	}
 
 	maxAnchainSize := g.findMaximumAntichainSize()
+	if maxAnchainSize > 1 && g.providerCount() < g.asyncThreshold {
+		// errgroup costs more than it saves in small graphs, so every provider goes into a single
+		// sequential pool. The context.Context argument is kept so that the injector signature
+		// does not change as providers are added.
+		maxAnchainSize = 1
+	}
 	pools := make([][]*node, maxAnchainSize)
 
 	initialProvidedNodes := make(map[*node]struct{})
@@ -697,10 +1448,12 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 
 	nodeProvidedNodes := make(map[*node]map[*node]struct{}, len(g.nodes))
 	nodeToPoolIdx := make(map[*node]int, len(g.nodes))
+	topologicalIdx := make(map[*node]int, len(g.nodes))
 
 	// First pass: assign nodes to pools and collect return values
 	for n := range g.topologicalSortIter() {
 		slog.Debug("Processing node", "node", n)
+		topologicalIdx[n] = len(topologicalIdx)
 
 		var (
 			returnValues  []*InjectorParam
@@ -713,6 +1466,9 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 			poolIdx = -1 // Arguments are not in any pool
 
 			param := NewInjectorParamWithImports([]types.Type{n.arg.Type}, true, metaData.Package.Path, metaData.Imports, varPool)
+			if n.arg.Name != "" {
+				param.name = varPool.GetName(n.arg.Name)
+			}
 			injector.Params = append(injector.Params, param)
 			returnValues = append(returnValues, param)
 
@@ -720,6 +1476,7 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 				Param:       param,
 				Type:        n.arg.Type,
 				ASTTypeExpr: n.arg.ASTTypeExpr,
+				Name:        n.arg.Name,
 			})
 		case n.providerSpec != nil:
 			poolIdx = g.findOptimalPool(n, pools, poolProvidedNodes)
@@ -734,11 +1491,41 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 				injector.Params = append(injector.Params, param)
 				injector.Vars = append(injector.Vars, param)
 				returnValues = append(returnValues, param)
+
+				// Values of kessoku.Value are owned by the caller and are left open
+				if g.cleanupCloser && n.providerSpec.Type == ProviderTypeFunction && !n.providerSpec.IsValue && implementsCloser(param.Type()) {
+					param.closes = true
+					param.Ref(false)
+				}
 			}
 
 			if n.providerSpec.IsReturnError {
 				injector.IsReturnError = true
 			}
+
+			for _, check := range n.providerSpec.ReadyChecks {
+				idx := slices.IndexFunc(n.providerSpec.Provides, func(ts []types.Type) bool {
+					return slices.ContainsFunc(ts, func(t types.Type) bool { return types.Identical(t, check.Type) })
+				})
+				if idx == -1 {
+					return nil, fmt.Errorf("ready check of %s is given to a provider that does not provide it", check.Type)
+				}
+
+				// The ready check closure captures the value once the injector has created it
+				returnValues[idx].Ref(false)
+				injector.ReadyChecks = append(injector.ReadyChecks, &InjectorReadyCheck{
+					Check: check,
+					Param: returnValues[idx],
+				})
+			}
+
+			if n.providerSpec.IsDeprecated {
+				slog.Warn("Deprecated provider is used",
+					"injector", g.injectorName,
+					"provider", types.ExprString(n.providerSpec.ASTExpr),
+					"message", n.providerSpec.DeprecatedMessage,
+				)
+			}
 		default:
 			return nil, errors.New("invalid node")
 		}
@@ -750,7 +1537,7 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 		providedNodes[n] = struct{}{}
 		nodeProvidedNodes[n] = maps.Clone(providedNodes)
 
-		if n == g.returnValue.node {
+		if n == g.returnValue.node && g.returnType != nil {
 			returnValues[g.returnValue.returnIndex].Ref(false)
 			injector.Return = &InjectorReturn{
 				Param:  returnValues[g.returnValue.returnIndex],
@@ -759,6 +1546,19 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 		}
 	}
 
+	if len(injector.ReadyChecks) > 0 {
+		switch {
+		case g.isLazy:
+			return nil, fmt.Errorf("WithReadyCheck cannot be combined with LazyInjector")
+		case g.isMust:
+			return nil, fmt.Errorf("WithReadyCheck cannot be combined with MustInject")
+		case g.implements != nil:
+			return nil, fmt.Errorf("WithReadyCheck cannot be combined with Implements")
+		case g.returnType == nil:
+			return nil, fmt.Errorf("WithReadyCheck is not supported for Populate")
+		}
+	}
+
 	// Second pass: set up dependencies with correct IsWait flags
 	for n := range g.topologicalSortIter() {
 		providedNodes := nodeProvidedNodes[n]
@@ -779,21 +1579,37 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 
 			edge.node.providerArgs[edge.provideArgDst] = &InjectorCallArgument{
 				Param:  param,
+				Unary:  edge.unary,
 				IsWait: shouldWait,
 			}
-			param.Ref(shouldWait)
+			if edge.orderOnly {
+				param.RefOrder(shouldWait)
+			} else {
+				param.Ref(shouldWait)
+			}
 		}
 	}
 
-	if injector.Return == nil {
+	if injector.Return == nil && g.returnType != nil {
 		return nil, errors.New("no return value provider found")
 	}
 
+	// Emit pools in a stable order so that regenerated code does not produce noisy diffs
+	sortPoolsByTopologicalOrder(pools, topologicalIdx)
+
+	// Scheduling decisions are otherwise only visible in the generated code
+	for i, pool := range pools {
+		for _, n := range pool {
+			slog.Debug("Scheduled provider", "injector", g.injectorName, "provider", n.providerSpec.name(), "async", n.providerSpec.IsAsync, "pool", i)
+		}
+	}
+
 	var err error
 	injector.Stmts, err = g.buildStmts(pools, nodeProvidedNodes, initialProvidedNodes)
 	if err != nil {
 		return nil, fmt.Errorf("build statements: %w", err)
 	}
+	injector.Stmts = startHandoffChains(injector.Stmts)
 
 	// Inject context.Context argument if async providers exist
 	err = g.injectContextArg(injector, metaData, varPool)
@@ -801,9 +1617,92 @@ func (g *Graph) Build(metaData *MetaData, varPool *VarPool) (*Injector, error) {
 		return nil, fmt.Errorf("inject context argument: %w", err)
 	}
 
+	// The getter of a lazy injector only constructs on its first call, so the arguments of later calls would be ignored
+	if g.isLazy {
+		contextArg := injector.ContextArg()
+		for _, arg := range injector.Args {
+			if arg != contextArg {
+				return nil, fmt.Errorf("LazyInjector cannot take an argument of type %s", arg.Type)
+			}
+		}
+	}
+
 	return injector, nil
 }
 
+// verifyMethodSets checks that the value a provider returns implements the interfaces it is provided as,
+// such as those of kessoku.Bind. A value of type T lacks the methods with pointer receivers, so
+// providing it as an interface needing them would generate code that does not compile.
+func verifyMethodSets(providers []*ProviderSpec) error {
+	for _, provider := range providers {
+		for _, group := range provider.Provides {
+			if len(group) < 2 || types.IsInterface(group[0]) {
+				continue
+			}
+			for _, t := range group[1:] {
+				if types.IsInterface(t) {
+					if err := checkImplements(group[0], t); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkImplements returns an error if t does not implement the interface iface, naming the method
+// with a pointer receiver that *T has and T lacks if only *T implements iface.
+func checkImplements(t, iface types.Type) error {
+	ifaceType, ok := iface.Underlying().(*types.Interface)
+	if !ok || types.Implements(t, ifaceType) {
+		return nil
+	}
+
+	ptr := types.NewPointer(t)
+	if _, isPtr := t.Underlying().(*types.Pointer); !isPtr && types.Implements(ptr, ifaceType) {
+		valueMethods := types.NewMethodSet(t)
+		ptrMethods := types.NewMethodSet(ptr)
+		for method := range ifaceType.Methods() {
+			if valueMethods.Lookup(method.Pkg(), method.Name()) == nil && ptrMethods.Lookup(method.Pkg(), method.Name()) != nil {
+				return fmt.Errorf("%s implements %s but %s does not, as method %s has a pointer receiver; provide a pointer", ptr, iface, t, method.Name())
+			}
+		}
+	}
+
+	if method, _ := types.MissingMethod(t, ifaceType, true); method != nil {
+		return fmt.Errorf("%s does not implement %s (missing method %s)", t, iface, method.Name())
+	}
+	return fmt.Errorf("%s does not implement %s", t, iface)
+}
+
+// implementsCloser reports whether t has a Close() error method like io.Closer.
+func implementsCloser(t types.Type) bool {
+	errorType := types.Universe.Lookup("error").Type()
+	closeSig := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewParam(token.NoPos, nil, "", errorType)), false)
+	closer := types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, nil, "Close", closeSig)}, nil).Complete()
+
+	return types.Implements(t, closer)
+}
+
+// sortPoolsByTopologicalOrder orders pools by the topological index of their first node.
+// Empty pools are moved to the end.
+func sortPoolsByTopologicalOrder(pools [][]*node, topologicalIdx map[*node]int) {
+	slices.SortStableFunc(pools, func(a, b []*node) int {
+		switch {
+		case len(a) == 0 && len(b) == 0:
+			return 0
+		case len(a) == 0:
+			return 1
+		case len(b) == 0:
+			return -1
+		}
+
+		return topologicalIdx[a[0]] - topologicalIdx[b[0]]
+	})
+}
+
 func (g *Graph) isReturnError() bool {
 	for _, node := range g.nodes {
 		if node.providerSpec != nil && node.providerSpec.IsReturnError {
@@ -814,6 +1713,18 @@ func (g *Graph) isReturnError() bool {
 	return false
 }
 
+// providerCount returns the number of provider nodes in the graph.
+func (g *Graph) providerCount() int {
+	count := 0
+	for _, n := range g.nodes {
+		if n.providerSpec != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
 // findMaximumAntichainSize finds the maximum antichain using level-based approach
 func (g *Graph) findMaximumAntichainSize() uint64 {
 	node2Idx := make(map[*node]int, len(g.nodes))
@@ -1141,6 +2052,90 @@ func (g *Graph) buildStmts(pools [][]*node, nodeProvidedNodes map[*node]map[*nod
 	return stmts, nil
 }
 
+// startHandoffChains moves each async pool whose first provider only waits for values it is the
+// single consumer of, all returned by one provider of another pool, right after that provider.
+// The pool then starts once the values are ready and takes them from the shared variables,
+// so no channel is allocated for them. Values with several consumers keep their channels,
+// which are closed to signal every consumer at once.
+func startHandoffChains(stmts []InjectorStmt) []InjectorStmt {
+	for {
+		moved := false
+		for i, stmt := range stmts {
+			chain, ok := stmt.(*InjectorChainStmt)
+			if !ok || len(chain.Statements) == 0 {
+				continue
+			}
+			first, ok := chain.Statements[0].(*InjectorProviderCallStmt)
+			if !ok {
+				continue
+			}
+
+			handoffs := handoffArguments(first)
+			if len(handoffs) == 0 {
+				continue
+			}
+			container, idx := findProducerStmt(&stmts, handoffs[0].Param)
+			if container == nil || slices.ContainsFunc(handoffs, func(arg *InjectorCallArgument) bool {
+				return !slices.Contains((*container)[idx].(*InjectorProviderCallStmt).Returns, arg.Param)
+			}) {
+				continue
+			}
+
+			for _, arg := range handoffs {
+				arg.IsWait = false
+				arg.Param.withChannel = false
+			}
+			*container = slices.Insert(*container, idx+1, InjectorStmt(chain))
+			if container == &stmts && idx < i {
+				i++
+			}
+			stmts = slices.Delete(stmts, i, i+1)
+			moved = true
+			break
+		}
+
+		if !moved {
+			return stmts
+		}
+	}
+}
+
+// handoffArguments returns the arguments stmt waits for if it is the single consumer of each of them,
+// and every other argument is an injector argument; it returns nil otherwise.
+func handoffArguments(stmt *InjectorProviderCallStmt) []*InjectorCallArgument {
+	var handoffs []*InjectorCallArgument
+	for _, arg := range stmt.Arguments {
+		switch {
+		case arg.Param.isArg:
+		case arg.IsWait && arg.Param.withChannel && arg.Param.refCounter+arg.Param.waitCounter == 1:
+			handoffs = append(handoffs, arg)
+		default:
+			return nil
+		}
+	}
+
+	return handoffs
+}
+
+// findProducerStmt returns the statement list holding the provider call returning param, searching
+// into chains, and the index of the call in it, or nil if no call returns param.
+func findProducerStmt(stmts *[]InjectorStmt, param *InjectorParam) (*[]InjectorStmt, int) {
+	for i, stmt := range *stmts {
+		switch s := stmt.(type) {
+		case *InjectorChainStmt:
+			if container, idx := findProducerStmt(&s.Statements, param); container != nil {
+				return container, idx
+			}
+		case *InjectorProviderCallStmt:
+			if slices.Contains(s.Returns, param) {
+				return stmts, i
+			}
+		}
+	}
+
+	return nil, 0
+}
+
 // buildPoolStmtsSimple builds statements for a single pool without recursive dependency processing
 func (g *Graph) buildPoolStmtsSimple(pool []*node) ([]InjectorStmt, error) {
 	stmts := make([]InjectorStmt, 0, len(pool))
@@ -1,9 +1,16 @@
 package kessoku
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"go/ast"
+	"go/format"
+	"go/token"
 	"go/types"
+	"log/slog"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -550,6 +557,82 @@ func TestCreateASTTypeExpr(t *testing.T) {
 	}
 }
 
+func TestCreateASTTypeExpr_GenericInstantiation(t *testing.T) {
+	t.Parallel()
+
+	repoPkg := types.NewPackage("example.com/repo", "repo")
+	newGeneric := func(name string, params ...string) *types.Named {
+		typeParams := make([]*types.TypeParam, 0, len(params))
+		for _, param := range params {
+			typeParams = append(typeParams, types.NewTypeParam(types.NewTypeName(0, repoPkg, param, nil), types.Universe.Lookup("any").Type()))
+		}
+		named := types.NewNamed(types.NewTypeName(0, repoPkg, name, nil), types.NewStruct(nil, nil), nil)
+		named.SetTypeParams(typeParams)
+		return named
+	}
+	userType := types.NewNamed(types.NewTypeName(0, types.NewPackage("example.com/user", "user"), "User", nil), types.NewStruct(nil, nil), nil)
+
+	tests := []struct {
+		generic         *types.Named
+		name            string
+		pkg             string
+		expected        string
+		typeArgs        []types.Type
+		expectedImports []string
+	}{
+		{
+			name:            "single type argument from another package",
+			pkg:             "main",
+			generic:         newGeneric("Repo", "T"),
+			typeArgs:        []types.Type{types.NewPointer(userType)},
+			expected:        "*repo.Repo[*user.User]",
+			expectedImports: []string{"example.com/repo", "example.com/user"},
+		},
+		{
+			name:            "multiple type arguments in the same package",
+			pkg:             "example.com/repo",
+			generic:         newGeneric("Pair", "K", "V"),
+			typeArgs:        []types.Type{types.Typ[types.String], types.Typ[types.Int]},
+			expected:        "*Pair[string, int]",
+			expectedImports: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := types.Instantiate(nil, tt.generic, tt.typeArgs, true)
+			if err != nil {
+				t.Fatalf("Instantiate failed: %v", err)
+			}
+
+			imports := make(map[string]*Import)
+			expr, err := createASTTypeExpr(tt.pkg, types.NewPointer(instance), NewVarPool(), imports)
+			if err != nil {
+				t.Fatalf("createASTTypeExpr failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+				t.Fatalf("format.Node failed: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, buf.String())
+			}
+
+			if len(imports) != len(tt.expectedImports) {
+				t.Errorf("Expected %d imports, got %d", len(tt.expectedImports), len(imports))
+			}
+			for _, expectedImport := range tt.expectedImports {
+				if _, ok := imports[expectedImport]; !ok {
+					t.Errorf("Expected import %q not found", expectedImport)
+				}
+			}
+		})
+	}
+}
+
 func TestAutoAddMissingDependencies(t *testing.T) {
 	t.Parallel()
 
@@ -1567,191 +1650,2563 @@ func TestGraph_Build_ContextInjection_NoDuplicates(t *testing.T) {
 	}
 }
 
-func TestGraph_DetectCycles(t *testing.T) {
+func TestGraph_Build_NamedContext(t *testing.T) {
 	t.Parallel()
 
-	configType, serviceType, intType := createTestTypes()
+	configType, serviceType, _ := createTestTypes()
+	contextType := createContextType()
+
+	requestContextArg := &ProviderSpec{
+		Type:     ProviderTypeArg,
+		ArgName:  "request",
+		Provides: [][]types.Type{{contextType}},
+	}
 
 	tests := []struct {
-		build         *BuildDirective
-		name          string
-		errorContains string
-		expectError   bool
+		build            *BuildDirective
+		name             string
+		errorContains    string
+		expectedArgTypes []string
+		expectedArgNames []string
+		shouldError      bool
 	}{
 		{
-			name: "no cycle - linear dependency chain",
+			name: "named and unnamed contexts are separate arguments",
 			build: &BuildDirective{
 				InjectorName: "InitializeService",
-				Return: &Return{
-					Type: serviceType,
-				},
+				Return:       &Return{Type: serviceType},
 				Providers: []*ProviderSpec{
+					requestContextArg,
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{configType}},
-						Requires:      []types.Type{},
-						IsReturnError: false,
+						Type:         ProviderTypeFunction,
+						Provides:     [][]types.Type{{configType}},
+						Requires:     []types.Type{contextType},
+						RequireNames: []string{"ctx"},
+						IsAsync:      true,
 					},
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{serviceType}},
-						Requires:      []types.Type{configType},
-						IsReturnError: false,
+						Type:         ProviderTypeFunction,
+						Provides:     [][]types.Type{{serviceType}},
+						Requires:     []types.Type{configType, contextType},
+						RequireNames: []string{"config", "request"},
 					},
 				},
 			},
-			expectError: false,
+			expectedArgTypes: []string{"context.Context", "context.Context"},
+			expectedArgNames: []string{"", "request"},
 		},
 		{
-			name: "simple cycle - A depends on B, B depends on A",
+			name: "only named context requested - errgroup context still injected",
 			build: &BuildDirective{
 				InjectorName: "InitializeService",
-				Return: &Return{
-					Type: serviceType,
-				},
+				Return:       &Return{Type: serviceType},
 				Providers: []*ProviderSpec{
+					requestContextArg,
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{configType}},
-						Requires:      []types.Type{serviceType}, // Config depends on Service
-						IsReturnError: false,
+						Type:         ProviderTypeFunction,
+						Provides:     [][]types.Type{{configType}},
+						Requires:     []types.Type{contextType},
+						RequireNames: []string{"request"},
+						IsAsync:      true,
 					},
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{serviceType}},
-						Requires:      []types.Type{configType}, // Service depends on Config
-						IsReturnError: false,
+						Type:         ProviderTypeFunction,
+						Provides:     [][]types.Type{{serviceType}},
+						Requires:     []types.Type{configType},
+						RequireNames: []string{"config"},
 					},
 				},
 			},
-			expectError:   true,
-			errorContains: "dependency cycle detected",
+			expectedArgTypes: []string{"context.Context", "context.Context"},
+			expectedArgNames: []string{"", "request"},
 		},
 		{
-			name: "three-node cycle - A -> B -> C -> A",
+			name: "unrequested named context is not added",
 			build: &BuildDirective{
 				InjectorName: "InitializeService",
-				Return: &Return{
-					Type: serviceType,
-				},
+				Return:       &Return{Type: serviceType},
 				Providers: []*ProviderSpec{
+					requestContextArg,
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{configType}},
-						Requires:      []types.Type{intType}, // Config depends on Int
-						IsReturnError: false,
-					},
-					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{intType}},
-						Requires:      []types.Type{serviceType}, // Int depends on Service
-						IsReturnError: false,
-					},
-					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{serviceType}},
-						Requires:      []types.Type{configType}, // Service depends on Config
-						IsReturnError: false,
+						Type:         ProviderTypeFunction,
+						Provides:     [][]types.Type{{serviceType}},
+						Requires:     []types.Type{contextType},
+						RequireNames: []string{"ctx"},
 					},
 				},
 			},
-			expectError:   true,
-			errorContains: "dependency cycle detected",
+			expectedArgTypes: []string{"context.Context"},
+			expectedArgNames: []string{""},
 		},
 		{
-			name: "no cycle - complex dependency graph",
+			name: "duplicate named context",
 			build: &BuildDirective{
 				InjectorName: "InitializeService",
-				Return: &Return{
-					Type: serviceType,
-				},
+				Return:       &Return{Type: serviceType},
 				Providers: []*ProviderSpec{
+					requestContextArg,
+					requestContextArg,
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{intType}},
-						Requires:      []types.Type{}, // No dependencies
-						IsReturnError: false,
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
 					},
+				},
+			},
+			shouldError:   true,
+			errorContains: `multiple named arguments "request"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, tt.build, varPool)
+			if tt.shouldError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != len(tt.expectedArgTypes) {
+				t.Fatalf("Expected %d arguments, got %d", len(tt.expectedArgTypes), len(injector.Args))
+			}
+
+			for i, arg := range injector.Args {
+				if arg.Type.String() != tt.expectedArgTypes[i] {
+					t.Errorf("Argument %d: expected type %s, got %s", i, tt.expectedArgTypes[i], arg.Type.String())
+				}
+				if arg.Name != tt.expectedArgNames[i] {
+					t.Errorf("Argument %d: expected name %q, got %q", i, tt.expectedArgNames[i], arg.Name)
+				}
+				if arg.Name != "" && arg.Param.Name(varPool) != arg.Name {
+					t.Errorf("Argument %d: expected parameter name %q, got %q", i, arg.Name, arg.Param.Name(varPool))
+				}
+			}
+
+			if ctxArg := injector.ContextArg(); ctxArg != nil && ctxArg.Name != "" {
+				t.Errorf("Expected errgroup context to be unnamed, got %q", ctxArg.Name)
+			}
+		})
+	}
+}
+
+// TestGraph_Build_DeprecatedProvider replaces the default logger, so it must not run in parallel.
+func TestGraph_Build_DeprecatedProvider(t *testing.T) {
+	configType, serviceType, _ := createTestTypes()
+
+	tests := []struct {
+		build        *BuildDirective
+		name         string
+		expectedWarn bool
+	}{
+		{
+			name: "deprecated provider used by injector",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Providers: []*ProviderSpec{
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{configType}},
-						Requires:      []types.Type{intType}, // Config depends on Int
-						IsReturnError: false,
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						IsDeprecated:      true,
+						DeprecatedMessage: "use NewConfigV2",
 					},
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{serviceType}},
-						Requires:      []types.Type{configType, intType}, // Service depends on both Config and Int
-						IsReturnError: false,
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{configType},
 					},
 				},
 			},
-			expectError: false,
+			expectedWarn: true,
 		},
 		{
-			name: "no cycle - with missing dependencies (auto-added as arguments)",
+			name: "deprecated provider not used by injector",
 			build: &BuildDirective{
 				InjectorName: "InitializeService",
-				Return: &Return{
-					Type: serviceType,
-				},
+				Return:       &Return{Type: serviceType},
 				Providers: []*ProviderSpec{
 					{
-						Type:          ProviderTypeFunction,
-						Provides:      [][]types.Type{{serviceType}},
-						Requires:      []types.Type{configType}, // Config will be auto-added as argument
-						IsReturnError: false,
+						Type:              ProviderTypeFunction,
+						Provides:          [][]types.Type{{configType}},
+						IsDeprecated:      true,
+						DeprecatedMessage: "use NewConfigV2",
+					},
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
 					},
 				},
 			},
-			expectError: false,
+			expectedWarn: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
+			var buf bytes.Buffer
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+			defer slog.SetDefault(defaultLogger)
 
 			metaData := &MetaData{
 				Package: Package{
-					Name: "test",
-					Path: "test",
+					Name: "main",
+					Path: "main",
 				},
 				Imports: make(map[string]*Import),
 			}
 
 			graph, err := NewGraph(metaData, tt.build, NewVarPool())
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
 
-			if tt.expectError {
-				if err == nil {
-					t.Fatalf("Expected error but got none")
-				}
+			if _, err := graph.Build(metaData, NewVarPool()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
-				if tt.errorContains != "" && !containsError(err.Error(), tt.errorContains) {
-					t.Fatalf("Expected error to contain %q, got %q", tt.errorContains, err.Error())
-				}
+			output := buf.String()
+			hasWarn := strings.Contains(output, "Deprecated provider is used")
+			if hasWarn != tt.expectedWarn {
+				t.Errorf("Expected deprecation warning %v, got log output %q", tt.expectedWarn, output)
+			}
+			if tt.expectedWarn && !strings.Contains(output, "use NewConfigV2") {
+				t.Errorf("Expected deprecation message in log output, got %q", output)
+			}
+		})
+	}
+}
 
-				// Check if error is or wraps a CycleError
-				var cycleErr *CycleError
-				if !errors.As(err, &cycleErr) {
-					t.Errorf("Expected CycleError but got %T", err)
-				}
+func TestNewGraph_UnusualAutoArgs(t *testing.T) {
+	configType, serviceType, intType := createTestTypes()
 
-				return
+	tests := []struct {
+		required     types.Type
+		name         string
+		expectedKind string
+		declared     bool
+	}{
+		{
+			name:         "channel",
+			required:     types.NewChan(types.SendRecv, intType),
+			expectedKind: "kind=chan",
+		},
+		{
+			name:         "function",
+			required:     types.NewSignatureType(nil, nil, nil, nil, nil, false),
+			expectedKind: "kind=func",
+		},
+		{
+			name:         "unnamed struct",
+			required:     types.NewStruct(nil, nil),
+			expectedKind: `kind="unnamed struct"`,
+		},
+		{
+			name:     "named type",
+			required: configType,
+		},
+		{
+			name:     "basic type",
+			required: intType,
+		},
+		{
+			name:     "declared function argument",
+			required: types.NewSignatureType(nil, nil, nil, nil, nil, false),
+			declared: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+			defer slog.SetDefault(defaultLogger)
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{tt.required},
+					},
+				},
+			}
+			if tt.declared {
+				build.Args = []types.Type{tt.required}
 			}
 
-			if err != nil {
-				t.Fatalf("Unexpected error: %v", err)
+			if _, err := NewGraph(metaData, build, NewVarPool()); err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
 			}
 
-			if graph == nil {
-				t.Fatal("Expected graph to be non-nil")
+			output := buf.String()
+			if tt.expectedKind == "" {
+				if output != "" {
+					t.Errorf("Expected no warning, got %q", output)
+				}
+				return
+			}
+			if !strings.Contains(output, "unusual kind") || !strings.Contains(output, tt.expectedKind) {
+				t.Errorf("Expected warning with %s, got %q", tt.expectedKind, output)
 			}
+		})
+	}
+}
 
-			// Additional verification that the graph is actually cycle-free
-			// by running a simple topological sort check
-			if err := graph.detectCycles(); err != nil {
-				t.Error("Graph should be cycle-free but cycle detection found a cycle")
+func TestGraph_Build_SchedulingLogs(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(defaultLogger)
+
+	configType, serviceType, intType := createTestTypes()
+	metaData := &MetaData{
+		Package: Package{
+			Name: "main",
+			Path: "main",
+		},
+		Imports: make(map[string]*Import),
+	}
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return:       &Return{Type: serviceType},
+		Providers: []*ProviderSpec{
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{configType}},
+				IsAsync:  true,
+			},
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{intType}},
+				IsAsync:  true,
+			},
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{serviceType}},
+				Requires: []types.Type{configType, intType},
+			},
+		},
+	}
+
+	graph, err := NewGraph(metaData, build, NewVarPool())
+	if err != nil {
+		t.Fatalf("Failed to create graph: %v", err)
+	}
+	if _, err := graph.Build(metaData, NewVarPool()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	type record struct {
+		Msg      string `json:"msg"`
+		Injector string `json:"injector"`
+		Provider string `json:"provider"`
+		Async    bool   `json:"async"`
+		Pool     int    `json:"pool"`
+	}
+	scheduled := make(map[string]record)
+	for line := range strings.Lines(buf.String()) {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Failed to decode log record %q: %v", line, err)
+		}
+		if r.Msg == "Scheduled provider" {
+			scheduled[r.Provider] = r
+		}
+	}
+
+	// Providers without a function are labeled with the type they provide
+	if len(scheduled) != 3 {
+		t.Fatalf("Expected 3 scheduled providers, got %v", scheduled)
+	}
+	config, port, service := scheduled["*Config"], scheduled["int"], scheduled["*Service"]
+	if config.Injector != "InitializeService" {
+		t.Errorf("Expected the injector to be logged, got %q", config.Injector)
+	}
+	if !config.Async || !port.Async || service.Async {
+		t.Errorf("Expected only the config and port providers to be async, got %v", scheduled)
+	}
+	if config.Pool == port.Pool {
+		t.Errorf("Expected the independent async providers in different pools, got %v", scheduled)
+	}
+}
+
+func TestGraph_Build_DeclaredArgs(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, intType := createTestTypes()
+	stringType := types.Typ[types.String]
+
+	tests := []struct {
+		build            *BuildDirective
+		name             string
+		errorContains    string
+		expectedArgTypes []string
+		expectedArgNames []string
+		shouldError      bool
+	}{
+		{
+			name: "declared arguments keep declaration order",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Args:         []types.Type{intType, configType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{configType, intType},
+					},
+				},
+			},
+			expectedArgTypes: []string{"int", "*Config"},
+			expectedArgNames: []string{"num", "config"},
+		},
+		{
+			name: "auto-detected arguments follow declared ones",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Args:         []types.Type{intType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{stringType, configType, intType},
+					},
+				},
+			},
+			expectedArgTypes: []string{"int", "string", "*Config"},
+			expectedArgNames: []string{"num", "str", "config"},
+		},
+		{
+			name: "unused declared argument is kept",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Args:         []types.Type{configType, intType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{intType},
+					},
+				},
+			},
+			expectedArgTypes: []string{"*Config", "int"},
+			expectedArgNames: []string{"_", "num"},
+		},
+		{
+			name: "declared argument also provided",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Args:         []types.Type{configType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{configType}},
+					},
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{configType},
+					},
+				},
+			},
+			shouldError:   true,
+			errorContains: "argument *Config is also provided by a provider",
+		},
+		{
+			name: "duplicate declared argument",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Args:         []types.Type{intType, intType},
+			},
+			shouldError:   true,
+			errorContains: "multiple arguments of type int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, tt.build, varPool)
+			if tt.shouldError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != len(tt.expectedArgTypes) {
+				t.Fatalf("Expected %d arguments, got %d", len(tt.expectedArgTypes), len(injector.Args))
+			}
+
+			for i, arg := range injector.Args {
+				if arg.Type.String() != tt.expectedArgTypes[i] {
+					t.Errorf("Argument %d: expected type %s, got %s", i, tt.expectedArgTypes[i], arg.Type.String())
+				}
+				if name := arg.Param.Name(varPool); name != tt.expectedArgNames[i] {
+					t.Errorf("Argument %d: expected name %s, got %s", i, tt.expectedArgNames[i], name)
+				}
+			}
+		})
+	}
+}
+
+func TestGraph_Build_AutoConvert(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+
+	// Writer is an interface implemented by *Buffer and *File
+	sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(types.NewVar(0, nil, "p", types.NewSlice(types.Typ[types.Byte]))), types.NewTuple(types.NewVar(0, nil, "", types.Typ[types.Int])), false)
+	writerType := types.NewNamed(types.NewTypeName(0, nil, "Writer", nil), types.NewInterfaceType([]*types.Func{types.NewFunc(0, nil, "Write", sig)}, nil).Complete(), nil)
+	newWriterImpl := func(name string) types.Type {
+		named := types.NewNamed(types.NewTypeName(0, nil, name, nil), types.NewStruct(nil, nil), nil)
+		recv := types.NewVar(0, nil, "", types.NewPointer(named))
+		named.AddMethod(types.NewFunc(0, nil, "Write", types.NewSignatureType(recv, nil, nil, sig.Params(), sig.Results(), false)))
+		return types.NewPointer(named)
+	}
+	bufferType := newWriterImpl("Buffer")
+	fileType := newWriterImpl("File")
+
+	newBuild := func(autoConvert bool, provided ...types.Type) *BuildDirective {
+		build := &BuildDirective{
+			InjectorName: "InitializeService",
+			Return:       &Return{Type: serviceType},
+			AutoConvert:  autoConvert,
+		}
+		for _, t := range provided {
+			build.Providers = append(build.Providers, &ProviderSpec{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{t}},
+			})
+		}
+		build.Providers = append(build.Providers, &ProviderSpec{
+			Type:     ProviderTypeFunction,
+			Provides: [][]types.Type{{serviceType}},
+			Requires: []types.Type{writerType},
+		})
+		return build
+	}
+
+	tests := []struct {
+		build            *BuildDirective
+		name             string
+		errorContains    string
+		expectedArgTypes []string
+		expectedStmts    int
+	}{
+		{
+			name:          "unique assignable provider",
+			build:         newBuild(true, bufferType),
+			expectedStmts: 2,
+		},
+		{
+			name:             "disabled without AutoConvert",
+			build:            newBuild(false, bufferType),
+			expectedArgTypes: []string{"Writer"},
+			expectedStmts:    1,
+		},
+		{
+			name:          "ambiguous assignable providers",
+			build:         newBuild(true, bufferType, fileType),
+			errorContains: "multiple provided types are assignable to Writer: *Buffer, *File",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, tt.build, varPool)
+			if tt.errorContains != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != len(tt.expectedArgTypes) {
+				t.Fatalf("Expected %d arguments, got %d", len(tt.expectedArgTypes), len(injector.Args))
+			}
+			for i, arg := range injector.Args {
+				if arg.Type.String() != tt.expectedArgTypes[i] {
+					t.Errorf("Argument %d: expected type %s, got %s", i, tt.expectedArgTypes[i], arg.Type.String())
+				}
+			}
+
+			if len(injector.Stmts) != tt.expectedStmts {
+				t.Errorf("Expected %d statements, got %d", tt.expectedStmts, len(injector.Stmts))
+			}
+		})
+	}
+}
+
+func TestGraph_Build_AutoRef(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+	configType := types.NewNamed(types.NewTypeName(0, nil, "Config", nil), types.NewStruct(nil, nil), nil)
+	configPtrType := types.NewPointer(configType)
+
+	tests := []struct {
+		provided         types.Type
+		required         types.Type
+		name             string
+		expectedArgTypes []string
+		expectedUnary    token.Token
+		autoRef          bool
+	}{
+		{
+			name:          "value provided, pointer required",
+			provided:      configType,
+			required:      configPtrType,
+			autoRef:       true,
+			expectedUnary: token.AND,
+		},
+		{
+			name:          "pointer provided, value required",
+			provided:      configPtrType,
+			required:      configType,
+			autoRef:       true,
+			expectedUnary: token.MUL,
+		},
+		{
+			name:          "exact provider",
+			provided:      configPtrType,
+			required:      configPtrType,
+			autoRef:       true,
+			expectedUnary: token.ILLEGAL,
+		},
+		{
+			name:             "disabled without AutoRef",
+			provided:         configType,
+			required:         configPtrType,
+			expectedArgTypes: []string{"*Config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				AutoRef:      tt.autoRef,
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{tt.provided}},
+					},
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{serviceType}},
+						Requires: []types.Type{tt.required},
+					},
+				},
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, build, varPool)
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != len(tt.expectedArgTypes) {
+				t.Fatalf("Expected %d arguments, got %d", len(tt.expectedArgTypes), len(injector.Args))
+			}
+			for i, arg := range injector.Args {
+				if arg.Type.String() != tt.expectedArgTypes[i] {
+					t.Errorf("Argument %d: expected type %s, got %s", i, tt.expectedArgTypes[i], arg.Type.String())
+				}
+			}
+			if len(tt.expectedArgTypes) > 0 {
+				return
+			}
+
+			if len(injector.Stmts) != 2 {
+				t.Fatalf("Expected 2 statements, got %d", len(injector.Stmts))
+			}
+			stmt, ok := injector.Stmts[1].(*InjectorProviderCallStmt)
+			if !ok {
+				t.Fatalf("Expected a provider call statement, got %T", injector.Stmts[1])
+			}
+			if got := stmt.Arguments[0].Unary; got != tt.expectedUnary {
+				t.Errorf("Expected argument conversion %s, got %s", tt.expectedUnary, got)
+			}
+		})
+	}
+}
+
+func TestGraph_Build_DirectionalChannel(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, intType := createTestTypes()
+
+	newBuild := func(provided, required types.Type) *BuildDirective {
+		return &BuildDirective{
+			InjectorName: "InitializeService",
+			Return:       &Return{Type: serviceType},
+			Providers: []*ProviderSpec{
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{provided}},
+				},
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{serviceType}},
+					Requires: []types.Type{required},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		build            *BuildDirective
+		name             string
+		expectedArgTypes []string
+		expectedStmts    int
+	}{
+		{
+			name:          "receive-only requirement",
+			build:         newBuild(types.NewChan(types.SendRecv, intType), types.NewChan(types.RecvOnly, intType)),
+			expectedStmts: 2,
+		},
+		{
+			name:          "send-only requirement",
+			build:         newBuild(types.NewChan(types.SendRecv, intType), types.NewChan(types.SendOnly, intType)),
+			expectedStmts: 2,
+		},
+		{
+			name:             "different element type",
+			build:            newBuild(types.NewChan(types.SendRecv, types.Typ[types.String]), types.NewChan(types.RecvOnly, intType)),
+			expectedArgTypes: []string{"<-chan int"},
+			expectedStmts:    1,
+		},
+		{
+			name:             "directional channel does not satisfy bidirectional requirement",
+			build:            newBuild(types.NewChan(types.RecvOnly, intType), types.NewChan(types.SendRecv, intType)),
+			expectedArgTypes: []string{"chan int"},
+			expectedStmts:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, tt.build, varPool)
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != len(tt.expectedArgTypes) {
+				t.Fatalf("Expected %d arguments, got %d", len(tt.expectedArgTypes), len(injector.Args))
+			}
+			for i, arg := range injector.Args {
+				if arg.Type.String() != tt.expectedArgTypes[i] {
+					t.Errorf("Argument %d: expected type %s, got %s", i, tt.expectedArgTypes[i], arg.Type.String())
+				}
+			}
+
+			if len(injector.Stmts) != tt.expectedStmts {
+				t.Errorf("Expected %d statements, got %d", tt.expectedStmts, len(injector.Stmts))
+			}
+		})
+	}
+}
+
+func TestGraph_Build_SharedProviderCalledOnce(t *testing.T) {
+	t.Parallel()
+
+	newType := func(name string) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, name, nil), types.NewStruct(nil, nil), nil))
+	}
+	configType := newType("Config")
+	databaseType := newType("Database")
+	cacheType := newType("Cache")
+	serviceAType := newType("ServiceA")
+	serviceBType := newType("ServiceB")
+	appType := newType("App")
+
+	tests := []struct {
+		newProviders func() []*ProviderSpec
+		name         string
+	}{
+		{
+			name: "diamond",
+			newProviders: func() []*ProviderSpec {
+				return []*ProviderSpec{
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{configType}}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{databaseType}}, Requires: []types.Type{configType}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{serviceAType}}, Requires: []types.Type{databaseType, configType}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{serviceBType}}, Requires: []types.Type{databaseType, configType}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{appType}}, Requires: []types.Type{serviceAType, serviceBType}},
+				}
+			},
+		},
+		{
+			name: "async diamond",
+			newProviders: func() []*ProviderSpec {
+				return []*ProviderSpec{
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{configType}}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{databaseType}}, Requires: []types.Type{configType}, IsAsync: true},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{serviceAType}}, Requires: []types.Type{databaseType}, IsAsync: true},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{serviceBType}}, Requires: []types.Type{databaseType}, IsAsync: true},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{appType}}, Requires: []types.Type{serviceAType, serviceBType}},
+				}
+			},
+		},
+		{
+			name: "results of one provider used by different dependents",
+			newProviders: func() []*ProviderSpec {
+				return []*ProviderSpec{
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{databaseType}, {cacheType}}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{serviceAType}}, Requires: []types.Type{databaseType}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{serviceBType}}, Requires: []types.Type{cacheType}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{appType}}, Requires: []types.Type{serviceAType, serviceBType, databaseType}},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+			providers := tt.newProviders()
+			build := &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers:    providers,
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, build, varPool)
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			calls := make(map[*ProviderSpec]int)
+			var countCalls func(stmts []InjectorStmt)
+			countCalls = func(stmts []InjectorStmt) {
+				for _, stmt := range stmts {
+					switch stmt := stmt.(type) {
+					case *InjectorProviderCallStmt:
+						calls[stmt.Provider]++
+					case *InjectorChainStmt:
+						countCalls(stmt.Statements)
+					}
+				}
+			}
+			countCalls(injector.Stmts)
+
+			for i, provider := range providers {
+				if calls[provider] != 1 {
+					t.Errorf("Expected provider %d to be called once, got %d calls", i, calls[provider])
+				}
+			}
+		})
+	}
+}
+
+func TestGraph_Build_ChannelReturn(t *testing.T) {
+	t.Parallel()
+
+	configType, _, _ := createTestTypes()
+	eventType := types.NewNamed(types.NewTypeName(0, nil, "Event", nil), types.NewStruct(nil, nil), nil)
+
+	tests := []struct {
+		returnType types.Type
+		name       string
+		expected   string
+	}{
+		{
+			name:       "receive-only channel",
+			returnType: types.NewChan(types.RecvOnly, eventType),
+			expected:   "<-chan Event",
+		},
+		{
+			name:       "send-only channel",
+			returnType: types.NewChan(types.SendOnly, eventType),
+			expected:   "chan<- Event",
+		},
+		{
+			name:       "bidirectional channel",
+			returnType: types.NewChan(types.SendRecv, eventType),
+			expected:   "chan Event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeEvents",
+				Return:       &Return{Type: tt.returnType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{configType}},
+					},
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{tt.returnType}},
+						Requires: []types.Type{configType},
+					},
+				},
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, build, varPool)
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != 0 {
+				t.Errorf("Expected no arguments, got %d", len(injector.Args))
+			}
+			if len(injector.Stmts) != 2 {
+				t.Errorf("Expected 2 statements, got %d", len(injector.Stmts))
+			}
+
+			if injector.Return == nil || injector.Return.Param == nil {
+				t.Fatal("Expected injector return parameter")
+			}
+			if name := injector.Return.Param.Name(varPool); name != "val" {
+				t.Errorf("Expected return variable %q, got %q", "val", name)
+			}
+
+			if !types.Identical(injector.Return.Param.Type(), tt.returnType) {
+				t.Errorf("Expected return parameter of type %s, got %s", tt.returnType, injector.Return.Param.Type())
+			}
+
+			typeExpr, err := createASTTypeExpr("main", tt.returnType, varPool, metaData.Imports)
+			if err != nil {
+				t.Fatalf("Failed to create return type expression: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := format.Node(&buf, token.NewFileSet(), typeExpr); err != nil {
+				t.Fatalf("Failed to format return type: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected return type %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestNewGraph_ConflictingProviderSources(t *testing.T) {
+	t.Parallel()
+
+	configType, _, _ := createTestTypes()
+
+	tests := []struct {
+		name          string
+		firstSet      string
+		secondSet     string
+		expectedError string
+	}{
+		{
+			name:          "different sets",
+			firstSet:      "DatabaseSet",
+			secondSet:     "LegacyDatabaseSet",
+			expectedError: "multiple providers provide *Config (declared in DatabaseSet and LegacyDatabaseSet)",
+		},
+		{
+			name:          "set and injector",
+			firstSet:      "DatabaseSet",
+			expectedError: "multiple providers provide *Config (declared in DatabaseSet and the injector)",
+		},
+		{
+			name:          "same set",
+			firstSet:      "DatabaseSet",
+			secondSet:     "DatabaseSet",
+			expectedError: "multiple providers provide *Config (both declared in DatabaseSet)",
+		},
+		{
+			name:          "injector only",
+			expectedError: "multiple providers provide *Config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeConfig",
+				Return:       &Return{Type: configType},
+				Providers: []*ProviderSpec{
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{configType}},
+						SetName:  tt.firstSet,
+					},
+					{
+						Type:     ProviderTypeFunction,
+						Provides: [][]types.Type{{configType}},
+						SetName:  tt.secondSet,
+					},
+				},
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			_, err := NewGraph(metaData, build, NewVarPool())
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if err.Error() != tt.expectedError {
+				t.Errorf("Expected error %q, got %q", tt.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestMergeAppendValues(t *testing.T) {
+	t.Parallel()
+
+	stringSlice := types.NewSlice(types.Typ[types.String])
+	intSlice := types.NewSlice(types.Typ[types.Int])
+
+	appendValue := func(value string, provides types.Type, setName string) *ProviderSpec {
+		return &ProviderSpec{
+			ASTExpr: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   ast.NewIdent("kessoku"),
+					Sel: ast.NewIdent("AppendValue"),
+				},
+				Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: value}},
+			},
+			Type:     ProviderTypeAppendValue,
+			Provides: [][]types.Type{{provides}},
+			SetName:  setName,
+		}
+	}
+
+	other := &ProviderSpec{
+		Type:     ProviderTypeFunction,
+		Provides: [][]types.Type{{types.Typ[types.Bool]}},
+	}
+
+	providers, err := mergeAppendValues(nil, []*ProviderSpec{
+		appendValue(`"a"`, stringSlice, "MiddlewareSet"),
+		other,
+		appendValue(`"1"`, intSlice, "MiddlewareSet"),
+		appendValue(`"b"`, stringSlice, ""),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(providers) != 3 {
+		t.Fatalf("Expected 3 providers, got %d", len(providers))
+	}
+	if providers[1] != other {
+		t.Errorf("Expected non-AppendValue provider to be kept in place")
+	}
+
+	expected := []struct {
+		expr    string
+		setName string
+		index   int
+	}{
+		{index: 0, expr: `kessoku.AppendValues(kessoku.AppendValue("a"), kessoku.AppendValue("b"))`, setName: ""},
+		{index: 2, expr: `kessoku.AppendValues(kessoku.AppendValue("1"))`, setName: "MiddlewareSet"},
+	}
+	for _, want := range expected {
+		i := want.index
+		provider := providers[i]
+		if provider.Type != ProviderTypeFunction {
+			t.Errorf("providers[%d]: expected type %s, got %s", i, ProviderTypeFunction, provider.Type)
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, token.NewFileSet(), provider.ASTExpr); err != nil {
+			t.Fatalf("Failed to format expression: %v", err)
+		}
+		if buf.String() != want.expr {
+			t.Errorf("providers[%d]: expected expression %s, got %s", i, want.expr, buf.String())
+		}
+		if provider.SetName != want.setName {
+			t.Errorf("providers[%d]: expected set name %q, got %q", i, want.setName, provider.SetName)
+		}
+	}
+}
+
+func TestNewGraph_AppendValueConflict(t *testing.T) {
+	t.Parallel()
+
+	stringSlice := types.NewSlice(types.Typ[types.String])
+
+	build := &BuildDirective{
+		InjectorName: "InitializeNames",
+		Return:       &Return{Type: stringSlice},
+		Providers: []*ProviderSpec{
+			{
+				ASTExpr: &ast.CallExpr{
+					Fun: &ast.SelectorExpr{
+						X:   ast.NewIdent("kessoku"),
+						Sel: ast.NewIdent("AppendValue"),
+					},
+					Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"a"`}},
+				},
+				Type:     ProviderTypeAppendValue,
+				Provides: [][]types.Type{{stringSlice}},
+			},
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{stringSlice}},
+			},
+		},
+	}
+
+	metaData := &MetaData{
+		Package: Package{
+			Name: "main",
+			Path: "main",
+		},
+		Imports: make(map[string]*Import),
+	}
+
+	_, err := NewGraph(metaData, build, NewVarPool())
+	if err == nil {
+		t.Fatal("Expected error but got none")
+	}
+	if expected := "multiple providers provide []string"; err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestNewGraph_TaggedProviders(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	tests := []struct {
+		name          string
+		uses          []*TagSelection
+		untagged      bool
+		expectedTag   string
+		expectedError string
+	}{
+		{
+			name:        "select s3",
+			uses:        []*TagSelection{{Type: configType, Tag: "s3"}},
+			expectedTag: "s3",
+		},
+		{
+			name:        "select gcs",
+			uses:        []*TagSelection{{Type: configType, Tag: "gcs"}},
+			expectedTag: "gcs",
+		},
+		{
+			name:          "unknown tag",
+			uses:          []*TagSelection{{Type: configType, Tag: "azure"}},
+			expectedError: `no provider of *Config tagged "azure"`,
+		},
+		{
+			name:          "no selection",
+			expectedError: "multiple providers provide *Config",
+		},
+		{
+			name:          "untagged provider",
+			uses:          []*TagSelection{{Type: configType, Tag: "s3"}},
+			untagged:      true,
+			expectedError: "multiple providers provide *Config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			providers := []*ProviderSpec{
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{configType}},
+					Tag:      "s3",
+				},
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{configType}},
+					Tag:      "gcs",
+				},
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{serviceType}},
+					Requires: []types.Type{configType},
+				},
+			}
+			if tt.untagged {
+				providers = append(providers, &ProviderSpec{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{configType}},
+				})
+			}
+
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Providers:    providers,
+				Uses:         tt.uses,
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			_, err := NewGraph(metaData, build, NewVarPool())
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %q", tt.expectedError, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			// The other tagged provider is left unused
+			var tags []string
+			for _, provider := range build.Providers {
+				if provider.Tag != "" {
+					tags = append(tags, provider.Tag)
+				}
+			}
+			if len(tags) != 1 || tags[0] != tt.expectedTag {
+				t.Errorf("Expected only the provider tagged %q to remain, got %v", tt.expectedTag, tags)
+			}
+		})
+	}
+}
+
+func TestNewGraph_TypeAliases(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+	// type ConfigAlias = *Config
+	aliasType := types.NewAlias(types.NewTypeName(0, nil, "ConfigAlias", nil), configType)
+
+	tests := []struct {
+		name          string
+		provided      types.Type
+		required      types.Type
+		duplicate     types.Type
+		expectedError string
+	}{
+		{
+			name:     "alias provided, target required",
+			provided: aliasType,
+			required: configType,
+		},
+		{
+			name:     "target provided, alias required",
+			provided: configType,
+			required: aliasType,
+		},
+		{
+			name:     "slice of alias provided, slice of target required",
+			provided: types.NewSlice(aliasType),
+			required: types.NewSlice(configType),
+		},
+		{
+			name:     "map of target provided, map of alias required",
+			provided: types.NewMap(types.Typ[types.String], configType),
+			required: types.NewMap(types.Typ[types.String], aliasType),
+		},
+		{
+			name:          "alias and target both provided",
+			provided:      configType,
+			required:      configType,
+			duplicate:     aliasType,
+			expectedError: "multiple providers provide *Config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			providers := []*ProviderSpec{
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{tt.provided}},
+				},
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{serviceType}},
+					Requires: []types.Type{tt.required},
+				},
+			}
+			if tt.duplicate != nil {
+				providers = append(providers, &ProviderSpec{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{tt.duplicate}},
+				})
+			}
+
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Providers:    providers,
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			graph, err := NewGraph(metaData, build, NewVarPool())
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %q", tt.expectedError, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, NewVarPool())
+			if err != nil {
+				t.Fatalf("Failed to build injector: %v", err)
+			}
+
+			// The requirement is satisfied by the provider instead of becoming an argument
+			if len(injector.Args) != 0 {
+				t.Errorf("Expected no injector arguments, got %d", len(injector.Args))
+			}
+			if len(injector.Stmts) != 2 {
+				t.Errorf("Expected 2 provider calls, got %d", len(injector.Stmts))
+			}
+		})
+	}
+}
+
+func TestNewGraph_NestedStructFields(t *testing.T) {
+	t.Parallel()
+
+	newStruct := func(name string, fields ...*types.Var) *types.Named {
+		return types.NewNamed(types.NewTypeName(0, nil, name, nil), types.NewStruct(fields, nil), nil)
+	}
+	field := func(name string, t types.Type) *types.Var {
+		return types.NewField(0, nil, name, t, false)
+	}
+
+	stringType := types.Typ[types.String]
+	intType := types.Typ[types.Int]
+	primaryType := newStruct("Primary", field("Host", stringType), field("Port", intType))
+	replicaType := newStruct("Replica", field("Host", stringType))
+	configType := types.NewPointer(newStruct("Config", field("Primary", primaryType), field("Replica", replicaType)))
+	serviceType := types.NewPointer(newStruct("Service"))
+
+	newBuild := func(extra ...*ProviderSpec) *BuildDirective {
+		fields, err := extractExportedFields(configType)
+		if err != nil {
+			t.Fatalf("Failed to extract fields: %v", err)
+		}
+
+		build := &BuildDirective{
+			InjectorName: "InitializeService",
+			Return:       &Return{Type: serviceType},
+			Providers: []*ProviderSpec{
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{configType}},
+				},
+				{
+					Type:         ProviderTypeStruct,
+					StructType:   configType,
+					StructFields: fields,
+					Provides:     [][]types.Type{{configType}},
+				},
+				{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{serviceType}},
+					Requires: []types.Type{stringType, intType},
+				},
+			},
+		}
+		build.Providers = append(build.Providers, extra...)
+		return build
+	}
+
+	tests := []struct {
+		build            *BuildDirective
+		name             string
+		expectedArgTypes []string
+		expectedStmts    int
+	}{
+		{
+			name:             "ambiguous nested field becomes an argument",
+			build:            newBuild(),
+			expectedArgTypes: []string{"string"},
+			expectedStmts:    4,
+		},
+		{
+			name: "provider takes precedence over nested fields",
+			build: newBuild(&ProviderSpec{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{stringType}},
+			}),
+			expectedStmts: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+			varPool := NewVarPool()
+
+			graph, err := NewGraph(metaData, tt.build, varPool)
+			if err != nil {
+				t.Fatalf("NewGraph failed: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Build failed: %v", err)
+			}
+
+			argTypes := make([]string, 0, len(injector.Args))
+			for _, arg := range injector.Args {
+				argTypes = append(argTypes, arg.Type.String())
+			}
+			if strings.Join(argTypes, ",") != strings.Join(tt.expectedArgTypes, ",") {
+				t.Errorf("Expected argument types %v, got %v", tt.expectedArgTypes, argTypes)
+			}
+
+			if len(injector.Stmts) != tt.expectedStmts {
+				t.Errorf("Expected %d statements, got %d", tt.expectedStmts, len(injector.Stmts))
+			}
+		})
+	}
+}
+
+func TestGraph_DetectCycles(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, intType := createTestTypes()
+
+	tests := []struct {
+		build         *BuildDirective
+		name          string
+		errorContains string
+		expectError   bool
+	}{
+		{
+			name: "no cycle - linear dependency chain",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return: &Return{
+					Type: serviceType,
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{configType}},
+						Requires:      []types.Type{},
+						IsReturnError: false,
+					},
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{serviceType}},
+						Requires:      []types.Type{configType},
+						IsReturnError: false,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "simple cycle - A depends on B, B depends on A",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return: &Return{
+					Type: serviceType,
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{configType}},
+						Requires:      []types.Type{serviceType}, // Config depends on Service
+						IsReturnError: false,
+					},
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{serviceType}},
+						Requires:      []types.Type{configType}, // Service depends on Config
+						IsReturnError: false,
+					},
+				},
+			},
+			expectError:   true,
+			errorContains: "dependency cycle detected",
+		},
+		{
+			name: "three-node cycle - A -> B -> C -> A",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return: &Return{
+					Type: serviceType,
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{configType}},
+						Requires:      []types.Type{intType}, // Config depends on Int
+						IsReturnError: false,
+					},
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{intType}},
+						Requires:      []types.Type{serviceType}, // Int depends on Service
+						IsReturnError: false,
+					},
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{serviceType}},
+						Requires:      []types.Type{configType}, // Service depends on Config
+						IsReturnError: false,
+					},
+				},
+			},
+			expectError:   true,
+			errorContains: "dependency cycle detected",
+		},
+		{
+			name: "no cycle - complex dependency graph",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return: &Return{
+					Type: serviceType,
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{intType}},
+						Requires:      []types.Type{}, // No dependencies
+						IsReturnError: false,
+					},
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{configType}},
+						Requires:      []types.Type{intType}, // Config depends on Int
+						IsReturnError: false,
+					},
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{serviceType}},
+						Requires:      []types.Type{configType, intType}, // Service depends on both Config and Int
+						IsReturnError: false,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "no cycle - with missing dependencies (auto-added as arguments)",
+			build: &BuildDirective{
+				InjectorName: "InitializeService",
+				Return: &Return{
+					Type: serviceType,
+				},
+				Providers: []*ProviderSpec{
+					{
+						Type:          ProviderTypeFunction,
+						Provides:      [][]types.Type{{serviceType}},
+						Requires:      []types.Type{configType}, // Config will be auto-added as argument
+						IsReturnError: false,
+					},
+				},
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "test",
+					Path: "test",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			graph, err := NewGraph(metaData, tt.build, NewVarPool())
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error but got none")
+				}
+
+				if tt.errorContains != "" && !containsError(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error to contain %q, got %q", tt.errorContains, err.Error())
+				}
+
+				// Check if error is or wraps a CycleError
+				var cycleErr *CycleError
+				if !errors.As(err, &cycleErr) {
+					t.Errorf("Expected CycleError but got %T", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if graph == nil {
+				t.Fatal("Expected graph to be non-nil")
+			}
+
+			// Additional verification that the graph is actually cycle-free
+			// by running a simple topological sort check
+			if err := graph.detectCycles(); err != nil {
+				t.Error("Graph should be cycle-free but cycle detection found a cycle")
+			}
+		})
+	}
+}
+
+func TestGraph_Build_Adapt(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+	pkg := types.NewPackage("main", "main")
+	clientType := types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, "Client", nil), types.NewStruct(nil, nil), nil))
+	storageType := types.NewNamed(types.NewTypeName(0, pkg, "Storage", nil), types.NewInterfaceType(nil, nil), nil)
+
+	// kessoku.Adapt[Storage](kessoku.Provide(NewClient), NewStorage) is parsed into a constructor and an adapter
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return:       &Return{Type: serviceType},
+		Providers: []*ProviderSpec{
+			{
+				Type:          ProviderTypeFunction,
+				Provides:      [][]types.Type{{clientType}},
+				IsReturnError: true,
+			},
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{storageType}},
+				Requires: []types.Type{clientType},
+			},
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{serviceType}},
+				Requires: []types.Type{storageType},
+			},
+		},
+	}
+
+	metaData := &MetaData{
+		Package: Package{
+			Name: "main",
+			Path: "main",
+		},
+		Imports: make(map[string]*Import),
+	}
+
+	varPool := NewVarPool()
+	graph, err := NewGraph(metaData, build, varPool)
+	if err != nil {
+		t.Fatalf("Failed to create graph: %v", err)
+	}
+
+	injector, err := graph.Build(metaData, varPool)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(injector.Args) != 0 {
+		t.Errorf("Expected no arguments, got %d", len(injector.Args))
+	}
+
+	// The constructor, the adapter, and the service are called in dependency order
+	if len(injector.Stmts) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(injector.Stmts))
+	}
+	for i, provider := range build.Providers {
+		stmt, ok := injector.Stmts[i].(*InjectorProviderCallStmt)
+		if !ok {
+			t.Fatalf("Statement %d: expected provider call, got %T", i, injector.Stmts[i])
+		}
+		if stmt.Provider != provider {
+			t.Errorf("Statement %d: expected provider %d to be called", i, i)
+		}
+	}
+}
+
+func TestGraph_Build_Decorate(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+	pkg := types.NewPackage("main", "main")
+	loggerType := types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, "Logger", nil), types.NewStruct(nil, nil), nil))
+	handlerType := types.NewNamed(types.NewTypeName(0, pkg, "Handler", nil), types.NewInterfaceType(nil, nil), nil)
+
+	// kessoku.Decorate[Handler](kessoku.Provide(NewRouter), WithRecovery, WithLogging) is parsed into the
+	// router provider requiring the logger of WithLogging
+	router := &ProviderSpec{
+		Type:     ProviderTypeFunction,
+		Provides: [][]types.Type{{handlerType}},
+		Requires: []types.Type{loggerType},
+		Decorators: []*Decorator{
+			{ASTExpr: ast.NewIdent("WithRecovery")},
+			{ASTExpr: ast.NewIdent("WithLogging"), Requires: []types.Type{loggerType}},
+		},
+	}
+	logger := &ProviderSpec{
+		Type:     ProviderTypeFunction,
+		Provides: [][]types.Type{{loggerType}},
+	}
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return:       &Return{Type: serviceType},
+		Providers: []*ProviderSpec{
+			router,
+			logger,
+			{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{serviceType}},
+				Requires: []types.Type{handlerType},
+			},
+		},
+	}
+
+	metaData := &MetaData{
+		Package: Package{
+			Name: "main",
+			Path: "main",
+		},
+		Imports: make(map[string]*Import),
+	}
+
+	varPool := NewVarPool()
+	graph, err := NewGraph(metaData, build, varPool)
+	if err != nil {
+		t.Fatalf("Failed to create graph: %v", err)
+	}
+
+	injector, err := graph.Build(metaData, varPool)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The logger needed by the decorator is provided before the decorated router is called
+	if len(injector.Args) != 0 {
+		t.Errorf("Expected no arguments, got %d", len(injector.Args))
+	}
+	if len(injector.Stmts) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(injector.Stmts))
+	}
+	for i, provider := range []*ProviderSpec{logger, router, build.Providers[2]} {
+		stmt, ok := injector.Stmts[i].(*InjectorProviderCallStmt)
+		if !ok {
+			t.Fatalf("Statement %d: expected provider call, got %T", i, injector.Stmts[i])
+		}
+		if stmt.Provider != provider {
+			t.Errorf("Statement %d: expected provider %d to be called", i, i)
+		}
+	}
+
+	routerStmt := injector.Stmts[1].(*InjectorProviderCallStmt)
+	if len(routerStmt.Arguments) != 1 || routerStmt.Arguments[0].Param != injector.Stmts[0].(*InjectorProviderCallStmt).Returns[0] {
+		t.Errorf("Expected the decorated router to take the logger, got %v", routerStmt.Arguments)
+	}
+}
+
+func TestGraph_Build_LastWins(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+
+	// The shared set provides a default config that the injector overrides
+	defaultConfig := &ProviderSpec{
+		Type:     ProviderTypeFunction,
+		Provides: [][]types.Type{{configType}},
+		SetName:  "DefaultSet",
+	}
+	config := &ProviderSpec{
+		Type:     ProviderTypeFunction,
+		Provides: [][]types.Type{{configType}},
+	}
+	service := &ProviderSpec{
+		Type:     ProviderTypeFunction,
+		Provides: [][]types.Type{{serviceType}},
+		Requires: []types.Type{configType},
+	}
+	build := &BuildDirective{
+		InjectorName: "InitializeService",
+		Return:       &Return{Type: serviceType},
+		Providers:    []*ProviderSpec{defaultConfig, service, config},
+		LastWins:     true,
+	}
+
+	metaData := &MetaData{
+		Package: Package{
+			Name: "main",
+			Path: "main",
+		},
+		Imports: make(map[string]*Import),
+	}
+
+	varPool := NewVarPool()
+	graph, err := NewGraph(metaData, build, varPool)
+	if err != nil {
+		t.Fatalf("Failed to create graph: %v", err)
+	}
+
+	injector, err := graph.Build(metaData, varPool)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The overridden provider is no longer needed and is left out
+	if len(injector.Stmts) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(injector.Stmts))
+	}
+	for i, provider := range []*ProviderSpec{config, service} {
+		stmt, ok := injector.Stmts[i].(*InjectorProviderCallStmt)
+		if !ok {
+			t.Fatalf("Statement %d: expected provider call, got %T", i, injector.Stmts[i])
+		}
+		if stmt.Provider != provider {
+			t.Errorf("Statement %d: expected provider %d to be called", i, i)
+		}
+	}
+}
+
+func TestGraph_Build_StrictTypeIdentity(t *testing.T) {
+	t.Parallel()
+
+	pkgA := types.NewPackage("example.com/a", "a")
+	pkgB := types.NewPackage("example.com/b", "b")
+	mainPkg := types.NewPackage("main", "main")
+
+	// Both print as struct{debug bool}, but unexported fields of different packages make them distinct
+	newOptions := func(pkg *types.Package, field string) types.Type {
+		return types.NewStruct([]*types.Var{types.NewField(0, pkg, field, types.Typ[types.Bool], false)}, nil)
+	}
+	newClient := func(pkg *types.Package) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, "Client", nil), types.NewStruct(nil, nil), nil))
+	}
+	appType := types.NewPointer(types.NewNamed(types.NewTypeName(0, mainPkg, "App", nil), types.NewStruct(nil, nil), nil))
+
+	tests := []struct {
+		name          string
+		optionsA      types.Type
+		optionsB      types.Type
+		strictTypes   bool
+		errorContains string
+	}{
+		{
+			name:          "string keys collide",
+			optionsA:      newOptions(pkgA, "debug"),
+			optionsB:      newOptions(pkgB, "debug"),
+			errorContains: "multiple providers provide struct{debug bool}",
+		},
+		{
+			name:        "identity keys tell the types apart",
+			optionsA:    newOptions(pkgA, "debug"),
+			optionsB:    newOptions(pkgB, "debug"),
+			strictTypes: true,
+		},
+		{
+			name:          "identical types are still one type",
+			optionsA:      newOptions(pkgA, "Debug"),
+			optionsB:      newOptions(pkgB, "Debug"),
+			strictTypes:   true,
+			errorContains: "multiple providers provide struct{Debug bool}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clientA, clientB := newClient(pkgA), newClient(pkgB)
+			optionsAProvider := &ProviderSpec{Type: ProviderTypeFunction, Provides: [][]types.Type{{tt.optionsA}}}
+			clientAProvider := &ProviderSpec{Type: ProviderTypeFunction, Provides: [][]types.Type{{clientA}}, Requires: []types.Type{tt.optionsA}}
+			optionsBProvider := &ProviderSpec{Type: ProviderTypeFunction, Provides: [][]types.Type{{tt.optionsB}}}
+			clientBProvider := &ProviderSpec{Type: ProviderTypeFunction, Provides: [][]types.Type{{clientB}}, Requires: []types.Type{tt.optionsB}}
+			appProvider := &ProviderSpec{Type: ProviderTypeFunction, Provides: [][]types.Type{{appType}}, Requires: []types.Type{clientA, clientB}}
+
+			build := &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers:    []*ProviderSpec{optionsAProvider, clientAProvider, optionsBProvider, clientBProvider, appProvider},
+				StrictTypes:  tt.strictTypes,
+			}
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			graph, err := NewGraph(metaData, build, NewVarPool())
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			// Each client depends on the options of its own package
+			for client, options := range map[*ProviderSpec]*ProviderSpec{clientAProvider: optionsAProvider, clientBProvider: optionsBProvider} {
+				idx := slices.IndexFunc(graph.nodes, func(n *node) bool { return n.providerSpec == client })
+				if idx == -1 {
+					t.Fatalf("Expected a node for the client provider")
+				}
+				deps := graph.reverseEdges[graph.nodes[idx]]
+				if len(deps) != 1 || deps[0].providerSpec != options {
+					t.Errorf("Expected the client of %s to depend on the options of its package", client.Provides[0][0])
+				}
+			}
+		})
+	}
+}
+
+func TestGraph_Build_CollectImplementing(t *testing.T) {
+	t.Parallel()
+
+	configType, serviceType, _ := createTestTypes()
+	pkg := types.NewPackage("main", "main")
+	nameSig := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewParam(0, pkg, "", types.Typ[types.String])), false)
+	pluginType := types.NewNamed(types.NewTypeName(0, pkg, "Plugin", nil), types.NewInterfaceType([]*types.Func{types.NewFunc(0, pkg, "Name", nameSig)}, nil).Complete(), nil)
+
+	newPlugin := func(name string) types.Type {
+		named := types.NewNamed(types.NewTypeName(0, pkg, name, nil), types.NewStruct(nil, nil), nil)
+		named.AddMethod(types.NewFunc(0, pkg, "Name", types.NewSignatureType(types.NewVar(0, pkg, "p", types.NewPointer(named)), nil, nil, nil, nameSig.Results(), false)))
+		return types.NewPointer(named)
+	}
+	authType := newPlugin("AuthPlugin")
+	cacheType := newPlugin("CachePlugin")
+
+	tests := []struct {
+		name          string
+		provided      []types.Type
+		bound         bool
+		allowEmpty    bool
+		expected      []types.Type
+		errorContains string
+	}{
+		{
+			name:     "mixed implementers and non-implementers",
+			provided: []types.Type{authType, configType, cacheType},
+			expected: []types.Type{authType, cacheType},
+		},
+		{
+			name:     "bound implementer is collected once",
+			provided: []types.Type{cacheType, authType},
+			bound:    true,
+			expected: []types.Type{cacheType, authType},
+		},
+		{
+			name:          "no implementers",
+			provided:      []types.Type{configType},
+			errorContains: "no provided type implements main.Plugin",
+		},
+		{
+			name:       "no implementers allowed",
+			provided:   []types.Type{configType},
+			allowEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var providers []*ProviderSpec
+			for i, provided := range tt.provided {
+				group := []types.Type{provided}
+				if tt.bound && i == 0 {
+					group = append(group, pluginType)
+				}
+				providers = append(providers, &ProviderSpec{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{group},
+				})
+			}
+			collect := &ProviderSpec{
+				Type:       ProviderTypeCollect,
+				Provides:   [][]types.Type{{types.NewSlice(pluginType)}},
+				AllowEmpty: tt.allowEmpty,
+			}
+			providers = append(providers, collect, &ProviderSpec{
+				Type:     ProviderTypeFunction,
+				Provides: [][]types.Type{{serviceType}},
+				Requires: []types.Type{types.NewSlice(pluginType)},
+			})
+
+			build := &BuildDirective{
+				InjectorName: "InitializeService",
+				Return:       &Return{Type: serviceType},
+				Providers:    providers,
+			}
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			_, err := NewGraph(metaData, build, NewVarPool())
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			// The implementers are collected in declaration order
+			if !slices.EqualFunc(collect.Requires, tt.expected, types.Identical) {
+				t.Errorf("Expected collected types %v, got %v", tt.expected, collect.Requires)
+			}
+		})
+	}
+}
+
+func TestGraph_Build_InterfaceReturn(t *testing.T) {
+	t.Parallel()
+
+	configType, _, _ := createTestTypes()
+	pkg := types.NewPackage("main", "main")
+	saveMethod := types.NewFunc(0, pkg, "Save", types.NewSignatureType(nil, nil, nil, nil, nil, false))
+	repositoryType := types.NewNamed(types.NewTypeName(0, pkg, "Repository", nil), types.NewInterfaceType([]*types.Func{saveMethod}, nil).Complete(), nil)
+
+	newImpl := func(name string) types.Type {
+		named := types.NewNamed(types.NewTypeName(0, pkg, name, nil), types.NewStruct(nil, nil), nil)
+		named.AddMethod(types.NewFunc(0, pkg, "Save", types.NewSignatureType(types.NewVar(0, pkg, "r", types.NewPointer(named)), nil, nil, nil, nil, false)))
+		return types.NewPointer(named)
+	}
+	postgresType := newImpl("PostgresRepository")
+	memoryType := newImpl("MemoryRepository")
+
+	tests := []struct {
+		name          string
+		provided      []types.Type
+		expectedType  string
+		errorContains string
+	}{
+		{
+			name:         "single implementer",
+			provided:     []types.Type{configType, postgresType},
+			expectedType: postgresType.String(),
+		},
+		{
+			name:          "multiple implementers",
+			provided:      []types.Type{postgresType, memoryType},
+			errorContains: "multiple provided types are assignable to main.Repository",
+		},
+		{
+			name:         "interface provided directly",
+			provided:     []types.Type{repositoryType, postgresType},
+			expectedType: repositoryType.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeRepository",
+				Return:       &Return{Type: repositoryType},
+			}
+			for _, provided := range tt.provided {
+				build.Providers = append(build.Providers, &ProviderSpec{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{provided}},
+				})
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			varPool := NewVarPool()
+			graph, err := NewGraph(metaData, build, varPool)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
+			}
+
+			injector, err := graph.Build(metaData, varPool)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(injector.Args) != 0 {
+				t.Errorf("Expected no arguments, got %d", len(injector.Args))
+			}
+
+			// A concrete result is returned as the interface type
+			if got := injector.Return.Param.Type().String(); got != tt.expectedType {
+				t.Errorf("Expected return value of type %s, got %s", tt.expectedType, got)
+			}
+			if injector.Return.Return.Type != repositoryType {
+				t.Errorf("Expected injector return type %s, got %s", repositoryType, injector.Return.Return.Type)
+			}
+			// Only the implementer is called
+			if len(injector.Stmts) != 1 {
+				t.Errorf("Expected 1 statement, got %d", len(injector.Stmts))
+			}
+		})
+	}
+}
+
+func TestGraph_Build_After(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("main", "main")
+	newType := func(name string) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, pkg, name, nil), types.NewStruct(nil, nil), nil))
+	}
+	dbType := newType("DB")
+	migrationType := newType("Migration")
+	seedType := newType("Seed")
+	appType := newType("App")
+	unknownType := newType("Unknown")
+
+	tests := []struct {
+		name          string
+		orders        []*Ordering
+		expectedError string
+	}{
+		{
+			name:   "seed after migration",
+			orders: []*Ordering{{Before: migrationType, After: seedType}},
+		},
+		{
+			name: "ordering cycle",
+			orders: []*Ordering{
+				{Before: migrationType, After: seedType},
+				{Before: seedType, After: migrationType},
+			},
+			expectedError: "dependency cycle detected",
+		},
+		{
+			name:          "ordering against a dependency",
+			orders:        []*Ordering{{Before: appType, After: dbType}},
+			expectedError: "dependency cycle detected",
+		},
+		{
+			name:          "unknown type",
+			orders:        []*Ordering{{Before: unknownType, After: seedType}},
+			expectedError: "no provider of *main.Unknown ordered with After",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers: []*ProviderSpec{
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{dbType}}},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{migrationType}}, Requires: []types.Type{dbType}, IsAsync: true},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{seedType}}, Requires: []types.Type{dbType}, IsAsync: true},
+					{Type: ProviderTypeFunction, Provides: [][]types.Type{{appType}}, Requires: []types.Type{dbType, seedType}},
+				},
+				Orders: tt.orders,
+			}
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			injector, err := CreateInjector(metaData, build, NewVarPool(), false, 0)
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %q", tt.expectedError, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateInjector failed: %v", err)
+			}
+
+			// The migration is only required by the ordering, but it is still created before the seed
+			var calls []types.Type
+			var seedCall *InjectorProviderCallStmt
+			var walk func(stmts []InjectorStmt)
+			walk = func(stmts []InjectorStmt) {
+				for _, stmt := range stmts {
+					switch s := stmt.(type) {
+					case *InjectorProviderCallStmt:
+						calls = append(calls, s.Provider.Provides[0][0])
+						if s.Provider.Provides[0][0] == seedType {
+							seedCall = s
+						}
+					case *InjectorChainStmt:
+						walk(s.Statements)
+					}
+				}
+			}
+			walk(injector.Stmts)
+
+			migrationIdx := slices.Index(calls, migrationType)
+			seedIdx := slices.Index(calls, seedType)
+			if migrationIdx == -1 || seedIdx == -1 {
+				t.Fatalf("Expected both the migration and the seed to be called, got %v", calls)
+			}
+			if seedCall == nil || len(seedCall.Arguments) != 2 {
+				t.Fatalf("Expected the seed call to take the database and wait for the migration, got %+v", seedCall)
+			}
+			if waited := seedCall.Arguments[1].Param; waited.Type() != migrationType || waited.refCounter != 0 {
+				t.Errorf("Expected the seed to wait for the migration without using its value, got %+v", waited)
+			}
+		})
+	}
+}
+
+func TestLinkFactories(t *testing.T) {
+	t.Parallel()
+
+	_, serviceType, _ := createTestTypes()
+	errorType := types.Universe.Lookup("error").Type()
+	newFactoryType := func(results ...types.Type) types.Type {
+		vars := make([]*types.Var, 0, len(results))
+		for _, result := range results {
+			vars = append(vars, types.NewParam(0, nil, "", result))
+		}
+		return types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(vars...), false)
+	}
+
+	tests := []struct {
+		name          string
+		factoryType   types.Type
+		target        *Injector
+		wrapError     bool
+		errorContains string
+	}{
+		{
+			name:        "injector returning an error",
+			factoryType: newFactoryType(serviceType, errorType),
+			target:      &Injector{Name: "InitializeService", IsReturnError: true},
+		},
+		{
+			name:        "injector returning no error",
+			factoryType: newFactoryType(serviceType, errorType),
+			target:      &Injector{Name: "InitializeService"},
+			wrapError:   true,
+		},
+		{
+			name:        "factory without an error",
+			factoryType: newFactoryType(serviceType),
+			target:      &Injector{Name: "InitializeService"},
+		},
+		{
+			name:          "error dropped by the factory",
+			factoryType:   newFactoryType(serviceType),
+			target:        &Injector{Name: "InitializeService", IsReturnError: true},
+			errorContains: "injector InitializeService returns an error",
+		},
+		{
+			name:          "injector taking a context",
+			factoryType:   newFactoryType(serviceType, errorType),
+			target:        &Injector{Name: "InitializeService", Args: []*InjectorArgument{{}}},
+			errorContains: "injector InitializeService takes arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			factory := &Factory{
+				Injector:     "InitializeService",
+				ReturnsError: tt.factoryType.(*types.Signature).Results().Len() == 2,
+			}
+			build := &BuildDirective{
+				InjectorName: "InitializeHandler",
+				Providers: []*ProviderSpec{{
+					Type:     ProviderTypeFactory,
+					Provides: [][]types.Type{{tt.factoryType}},
+					Factory:  factory,
+				}},
+			}
+
+			err := linkFactories(build, map[string]*Injector{tt.target.Name: tt.target})
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("linkFactories failed: %v", err)
+			}
+			if factory.wrapError != tt.wrapError {
+				t.Errorf("Expected wrapError %v, got %v", tt.wrapError, factory.wrapError)
+			}
+		})
+	}
+
+	// Factories of injectors that could not be created are left to the errors of those injectors
+	build := &BuildDirective{Providers: []*ProviderSpec{{Type: ProviderTypeFactory, Factory: &Factory{Injector: "InitializeMissing"}}}}
+	if err := linkFactories(build, nil); err != nil {
+		t.Errorf("Expected no error for a missing injector, got %v", err)
+	}
+}
+
+func TestGraph_Build_BindMethodSet(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("main", "main")
+	saveMethod := types.NewFunc(0, pkg, "Save", types.NewSignatureType(nil, nil, nil, nil, nil, false))
+	repositoryType := types.NewNamed(types.NewTypeName(0, pkg, "Repository", nil), types.NewInterfaceType([]*types.Func{saveMethod}, nil).Complete(), nil)
+
+	// Save has a pointer receiver, so only *MemoryRepository implements Repository
+	memoryType := types.NewNamed(types.NewTypeName(0, pkg, "MemoryRepository", nil), types.NewStruct(nil, nil), nil)
+	memoryType.AddMethod(types.NewFunc(0, pkg, "Save", types.NewSignatureType(types.NewVar(0, pkg, "r", types.NewPointer(memoryType)), nil, nil, nil, nil, false)))
+	otherType := types.NewNamed(types.NewTypeName(0, pkg, "Other", nil), types.NewStruct(nil, nil), nil)
+
+	tests := []struct {
+		name          string
+		provided      types.Type
+		errorContains string
+	}{
+		{
+			name:     "pointer provided",
+			provided: types.NewPointer(memoryType),
+		},
+		{
+			name:          "value provided",
+			provided:      memoryType,
+			errorContains: "*main.MemoryRepository implements main.Repository but main.MemoryRepository does not, as method Save has a pointer receiver; provide a pointer",
+		},
+		{
+			name:          "unrelated type provided",
+			provided:      otherType,
+			errorContains: "main.Other does not implement main.Repository (missing method Save)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			build := &BuildDirective{
+				InjectorName: "InitializeRepository",
+				Return:       &Return{Type: repositoryType},
+				Providers: []*ProviderSpec{{
+					Type:     ProviderTypeFunction,
+					Provides: [][]types.Type{{tt.provided, repositoryType}},
+				}},
+			}
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			_, err := NewGraph(metaData, build, NewVarPool())
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create graph: %v", err)
 			}
 		})
 	}
@@ -1,18 +1,26 @@
 package kessoku
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"go/ast"
 	"go/constant"
+	"go/format"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"log/slog"
+	"maps"
+	"math"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 
+	"golang.org/x/mod/module"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
 )
@@ -30,13 +38,22 @@ const (
 type Parser struct {
 	fset     *token.FileSet
 	packages map[string]*types.Package
+	// genericSets holds the generic set instantiations being expanded, to reject recursive sets
+	genericSets map[string]bool
+	// diagnostics holds the injectors skipped because they failed to parse, reported by the Processor
+	diagnostics []*Diagnostic
+	// buildTags holds the build tags used to load packages
+	buildTags []string
+	// importAliases maps import paths to the names used for them in generated files
+	importAliases map[string]string
 }
 
 // NewParser creates a new parser instance.
 func NewParser() *Parser {
 	return &Parser{
-		fset:     token.NewFileSet(),
-		packages: make(map[string]*types.Package),
+		fset:        token.NewFileSet(),
+		packages:    make(map[string]*types.Package),
+		genericSets: make(map[string]bool),
 	}
 }
 
@@ -77,6 +94,10 @@ func (p *Parser) ParseFile(filename string, varPool *VarPool) (*MetaData, []*Bui
 			Path: pkg.PkgPath,
 		},
 		Imports: make(map[string]*Import, len(pkg.Imports)),
+		pkg:     pkg,
+	}
+	if pkg.Module != nil {
+		metaData.LocalPrefix = pkg.Module.Path
 	}
 
 	slog.Debug("kessoku package", "kessokuPkg", kessokuPkg)
@@ -95,7 +116,9 @@ func (p *Parser) ParseFile(filename string, varPool *VarPool) (*MetaData, []*Bui
 	}
 
 	for _, f := range pkg.Syntax {
-		if f == nil {
+		// Identifiers declared by a previous generation are about to be replaced,
+		// so reserving them would make regenerated names drift between runs.
+		if f == nil || isGeneratedFile(f) {
 			continue
 		}
 
@@ -130,6 +153,13 @@ func (p *Parser) ParseFile(filename string, varPool *VarPool) (*MetaData, []*Bui
 		}
 	}
 
+	// Aliased imports claim their names before the imports of the package
+	for _, path := range slices.Sorted(maps.Keys(p.importAliases)) {
+		metaData.Imports[path] = &Import{
+			Name: varPool.GetName(p.importAliases[path]),
+		}
+	}
+
 	for _, f := range pkg.Syntax {
 		if f == nil {
 			continue
@@ -173,7 +203,131 @@ func (p *Parser) ParseFile(filename string, varPool *VarPool) (*MetaData, []*Bui
 		return nil, nil, fmt.Errorf("find inject directives: %w", err)
 	}
 
-	return metaData, builds, nil
+	builds = append(builds, p.findInjectComments(targetFile, pkg, metaData.Imports, varPool)...)
+
+	if isTestFile(filename) {
+		// Injectors of a test file may use its test-only providers, so they are generated for tests only
+		for _, build := range builds {
+			build.ForTest = true
+		}
+	}
+
+	return metaData, p.findInjectorFactories(builds), nil
+}
+
+// findInjectorFactories provides the function-typed provider parameters, such as
+// func() (*Session, error), that no provider of an injector supplies with the injector of the file
+// returning the value, so that providers can construct fresh instances on demand.
+// Injectors with a parameter matching several injectors are reported and skipped.
+func (p *Parser) findInjectorFactories(builds []*BuildDirective) []*BuildDirective {
+	found := make([]*BuildDirective, 0, len(builds))
+	for _, build := range builds {
+		if err := addInjectorFactories(build, builds); err != nil {
+			slog.Error("findInjectorFactories failed", "injector", build.InjectorName, "error", err)
+			p.diagnostics = append(p.diagnostics, &Diagnostic{Pos: build.Pos, Injector: build.InjectorName, Err: err})
+			continue
+		}
+		found = append(found, build)
+	}
+
+	return found
+}
+
+// addInjectorFactories adds a ProviderTypeFactory provider to build for each of its unprovided
+// factory parameters returning the value of one of builds.
+func addInjectorFactories(build *BuildDirective, builds []*BuildDirective) error {
+	provided := slices.Clone(build.Args)
+	for _, provider := range build.Providers {
+		for _, group := range provider.Provides {
+			provided = append(provided, group...)
+		}
+	}
+	isProvided := func(t types.Type) bool {
+		return slices.ContainsFunc(provided, func(p types.Type) bool { return types.Identical(p, t) })
+	}
+
+	for _, provider := range slices.Clone(build.Providers) {
+		for _, required := range provider.Requires {
+			sig, ok := required.Underlying().(*types.Signature)
+			if !ok || !isFactorySignature(sig) || isProvided(required) {
+				continue
+			}
+
+			var target *BuildDirective
+			for _, other := range builds {
+				if other == build || !isFactoryInjector(other, build) || !types.Identical(other.Return.Type, sig.Results().At(0).Type()) {
+					continue
+				}
+				if target != nil {
+					return fmt.Errorf("provider parameter %s matches both injectors %s and %s", required, target.InjectorName, other.InjectorName)
+				}
+				target = other
+			}
+			if target == nil {
+				continue
+			}
+
+			provided = append(provided, required)
+			build.Providers = append(build.Providers, &ProviderSpec{
+				ASTExpr:  ast.NewIdent(target.InjectorName),
+				Type:     ProviderTypeFactory,
+				Provides: [][]types.Type{{required}},
+				Factory: &Factory{
+					Injector:     target.InjectorName,
+					ReturnType:   target.Return.ASTTypeExpr,
+					ReturnsError: sig.Results().Len() == 2,
+				},
+			})
+		}
+	}
+
+	return nil
+}
+
+// isFactorySignature reports whether sig takes no parameters and returns a value, optionally followed by an error.
+func isFactorySignature(sig *types.Signature) bool {
+	if sig.Recv() != nil || sig.Params().Len() != 0 {
+		return false
+	}
+
+	switch sig.Results().Len() {
+	case 1:
+		return true
+	case 2:
+		return types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+	default:
+		return false
+	}
+}
+
+// isFactoryInjector reports whether the injector of other may be called as a factory by the injector of build:
+// it must take no arguments and return only its value and an error, and be visible to the generated code of build.
+func isFactoryInjector(other, build *BuildDirective) bool {
+	return other.Return != nil && len(other.Args) == 0 && !other.CommandArgs &&
+		!other.WithCancel && !other.CleanupCloser && other.Implements == nil && other.ErrorWrapper == nil &&
+		(build.ForTest || !other.ForTest)
+}
+
+// isTestFile reports whether filename is a Go test file.
+func isTestFile(filename string) bool {
+	return strings.HasSuffix(filename, "_test.go")
+}
+
+// isGeneratedFile reports whether f was written by the kessoku generator.
+func isGeneratedFile(f *ast.File) bool {
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+
+		for _, c := range group.List {
+			if c.Text == generatedHeader {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 // initializeSSA initializes SSA analysis for a file.
@@ -182,8 +336,13 @@ func (p *Parser) initializePackages(filename string) (*packages.Package, error)
 	cfg := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
 			packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes |
-			packages.NeedSyntax | packages.NeedTypesInfo,
+			packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
 		Fset: p.fset,
+		// Load the test variant of the package for test files, which also holds the providers of the other test files
+		Tests: isTestFile(filename),
+	}
+	if len(p.buildTags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(p.buildTags, ",")}
 	}
 
 	// Load the specific file and its dependencies
@@ -235,6 +394,9 @@ func (p *Parser) findInjectDirectives(file *ast.File, pkg *packages.Package, kes
 		return nil, nil
 	}
 
+	// Populate has the same signature as Inject, so calls are told apart by the called function
+	populateObj := kessokuPackageScope.Lookup("Populate")
+
 	var builds []*BuildDirective
 
 	ast.Inspect(file, func(n ast.Node) bool {
@@ -275,10 +437,15 @@ func (p *Parser) findInjectDirectives(file *ast.File, pkg *packages.Package, kes
 		}
 
 		build, err := p.parseInjectCall(pkg, kessokuPackageScope, callExpr, imports, fileImports, varPool)
+		if err == nil && populateObj != nil && pkg.TypesInfo.Uses[baseFunc.Sel] == populateObj {
+			err = parsePopulateTarget(build)
+		}
 		if err != nil {
-			slog.Warn("parseInjectCall failed", "callExpr", callExpr, "error", err)
+			slog.Error("parseInjectCall failed", "pos", pkg.Fset.Position(callExpr.Pos()), "error", err)
+			p.diagnostics = append(p.diagnostics, &Diagnostic{Pos: pkg.Fset.Position(callExpr.Pos()), Err: err})
 			return true
 		}
+		build.Pos = pkg.Fset.Position(callExpr.Pos())
 
 		builds = append(builds, build)
 		return false
@@ -287,6 +454,106 @@ func (p *Parser) findInjectDirectives(file *ast.File, pkg *packages.Package, kes
 	return builds, nil
 }
 
+// findInjectComments finds provider functions annotated with a //kessoku:inject comment directive.
+func (p *Parser) findInjectComments(file *ast.File, pkg *packages.Package, imports map[string]*Import, varPool *VarPool) []*BuildDirective {
+	var builds []*BuildDirective
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Doc == nil {
+			continue
+		}
+
+		for _, comment := range funcDecl.Doc.List {
+			args, ok := strings.CutPrefix(comment.Text, injectCommentDirective)
+			if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+				continue
+			}
+
+			build, err := p.parseInjectComment(pkg, funcDecl, strings.Fields(args), imports, varPool)
+			if err != nil {
+				slog.Error("parseInjectComment failed", "func", funcDecl.Name.Name, "error", err)
+				p.diagnostics = append(p.diagnostics, &Diagnostic{Pos: pkg.Fset.Position(comment.Pos()), Injector: funcDecl.Name.Name, Err: err})
+				continue
+			}
+			build.Pos = pkg.Fset.Position(comment.Pos())
+
+			builds = append(builds, build)
+		}
+	}
+
+	return builds
+}
+
+// parseInjectComment builds a single-provider injector from "//kessoku:inject Name Type" above funcDecl.
+func (p *Parser) parseInjectComment(pkg *packages.Package, funcDecl *ast.FuncDecl, args []string, imports map[string]*Import, varPool *VarPool) (*BuildDirective, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%s requires an injector name and a return type", injectCommentDirective)
+	}
+	injectorName, typeExpr := args[0], args[1]
+	if !token.IsIdentifier(injectorName) {
+		return nil, fmt.Errorf("invalid injector name %q", injectorName)
+	}
+
+	fn, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+	if !ok {
+		return nil, fmt.Errorf("function %s is not type-checked", funcDecl.Name.Name)
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() != nil || sig.TypeParams() != nil {
+		return nil, fmt.Errorf("%s must be a non-generic package-level function", funcDecl.Name.Name)
+	}
+
+	tv, err := types.Eval(p.fset, pkg.Types, funcDecl.Pos(), typeExpr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve return type %s: %w", typeExpr, err)
+	}
+	if !tv.IsType() {
+		return nil, fmt.Errorf("%s is not a type", typeExpr)
+	}
+
+	result := parseProviderSignature(sig)
+	if !slices.ContainsFunc(result.Provides, func(provided []types.Type) bool {
+		return types.Identical(provided[0], tv.Type)
+	}) {
+		return nil, fmt.Errorf("%s does not return %s", funcDecl.Name.Name, typeExpr)
+	}
+
+	kessokuImport, ok := imports[kessokuPkgPath]
+	if !ok {
+		return nil, fmt.Errorf("kessoku package is not imported")
+	}
+
+	returnExpr, err := createASTTypeExpr(pkg.PkgPath, tv.Type, varPool, imports)
+	if err != nil {
+		return nil, fmt.Errorf("create AST type expr: %w", err)
+	}
+
+	return &BuildDirective{
+		InjectorName: injectorName,
+		Return: &Return{
+			Type:        tv.Type,
+			ASTTypeExpr: returnExpr,
+		},
+		Providers: []*ProviderSpec{{
+			// Generated code calls the provider as kessoku.Provide(fn)
+			ASTExpr: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   ast.NewIdent(kessokuImport.Name),
+					Sel: ast.NewIdent("Provide"),
+				},
+				Args: []ast.Expr{ast.NewIdent(funcDecl.Name.Name)},
+			},
+			Type:              ProviderTypeFunction,
+			Provides:          result.Provides,
+			Requires:          result.Requires,
+			RequireNames:      result.RequireNames,
+			IsReturnError:     result.IsReturnError,
+			ReferencedImports: map[string]*Import{kessokuPkgPath: kessokuImport},
+			fn:                fn,
+		}},
+	}, nil
+}
+
 // parseInjectCall parses a kessoku.Inject call expression.
 func (p *Parser) parseInjectCall(pkg *packages.Package, kessokuPackageScope *types.Scope, call *ast.CallExpr, imports map[string]*Import, fileImports []*ast.ImportSpec, varPool *VarPool) (*BuildDirective, error) {
 	build := &BuildDirective{
@@ -341,9 +608,216 @@ func (p *Parser) parseInjectCall(pkg *packages.Package, kessokuPackageScope *typ
 		}
 	}
 
+	if isWildcardType(build.Return.Type) {
+		if err := inferReturnType(pkg, build, imports, varPool); err != nil {
+			return nil, fmt.Errorf("infer return type of %s: %w", build.InjectorName, err)
+		}
+	}
+
+	if build.WithCancel {
+		switch {
+		case build.IsLazy:
+			return nil, fmt.Errorf("WithCancel cannot be combined with LazyInjector")
+		case build.IsMust:
+			return nil, fmt.Errorf("WithCancel cannot be combined with MustInject")
+		case build.OmitContext:
+			return nil, fmt.Errorf("WithCancel cannot be combined with OmitContext")
+		case build.Implements != nil:
+			return nil, fmt.Errorf("WithCancel cannot be combined with Implements")
+		}
+	}
+
+	if build.PanicFormat != "" && !build.IsMust {
+		return nil, fmt.Errorf("PanicMsg requires MustInject")
+	}
+
+	if build.Overrides && build.IsLazy {
+		return nil, fmt.Errorf("DynamicOverrides cannot be combined with LazyInjector")
+	}
+
+	if build.IsLazy && (len(build.Args) > 0 || build.CommandArgs || slices.ContainsFunc(build.Providers, func(provider *ProviderSpec) bool {
+		return provider.Type == ProviderTypeArg
+	})) {
+		return nil, fmt.Errorf("LazyInjector cannot be combined with injector arguments")
+	}
+
+	if build.SharedName != "" && !build.IsLazy {
+		return nil, fmt.Errorf("Shared requires LazyInjector")
+	}
+
+	if build.CleanupCloser {
+		switch {
+		case build.WithCancel:
+			return nil, fmt.Errorf("CleanupCloser cannot be combined with WithCancel")
+		case build.IsLazy:
+			return nil, fmt.Errorf("CleanupCloser cannot be combined with LazyInjector")
+		case build.IsMust:
+			return nil, fmt.Errorf("CleanupCloser cannot be combined with MustInject")
+		case build.Implements != nil:
+			return nil, fmt.Errorf("CleanupCloser cannot be combined with Implements")
+		}
+	}
+
 	return build, nil
 }
 
+// parsePopulateTarget turns the type argument of a kessoku.Populate call into the first argument
+// of the generated function and adds a provider assigning the target's exported fields.
+func parsePopulateTarget(build *BuildDirective) error {
+	target := build.Return.Type
+	ptr, ok := target.(*types.Pointer)
+	if !ok {
+		return fmt.Errorf("populate target %s is not a pointer to a struct", target)
+	}
+	if _, ok := ptr.Elem().Underlying().(*types.Struct); !ok {
+		return fmt.Errorf("populate target %s is not a pointer to a struct", target)
+	}
+
+	if build.IsLazy {
+		return fmt.Errorf("LazyInjector is not supported for Populate")
+	}
+	if build.IsMust {
+		return fmt.Errorf("MustInject is not supported for Populate")
+	}
+	if build.WithCancel {
+		return fmt.Errorf("WithCancel is not supported for Populate")
+	}
+	if build.CleanupCloser {
+		return fmt.Errorf("CleanupCloser is not supported for Populate")
+	}
+	if build.RunWrapper {
+		return fmt.Errorf("WithRunWrapper is not supported for Populate")
+	}
+
+	fields, err := extractExportedFields(target)
+	if err != nil {
+		return fmt.Errorf("extract fields of populate target %s: %w", target, err)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("populate target %s has no exported fields", target)
+	}
+
+	structType, _ := ptr.Elem().Underlying().(*types.Struct)
+	for _, field := range fields {
+		value, ok := reflect.StructTag(structType.Tag(field.Index)).Lookup("default")
+		if !ok {
+			continue
+		}
+
+		field.Default, err = defaultLiteral(field.Type, value)
+		if err != nil {
+			return fmt.Errorf("default of field %s: %w", field.Name, err)
+		}
+	}
+
+	requires := make([]types.Type, 0, len(fields)+1)
+	requires = append(requires, target)
+	for _, field := range fields {
+		requires = append(requires, field.Type)
+	}
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		Type:         ProviderTypePopulate,
+		StructType:   target,
+		StructFields: fields,
+		Requires:     requires,
+	})
+	build.Args = slices.Insert(build.Args, 0, target)
+	build.Return = nil
+
+	return nil
+}
+
+// defaultLiteral parses the value of a `default:"..."` struct tag into a literal of the field type t,
+// which must be a string, boolean, or numeric type.
+func defaultLiteral(t types.Type, value string) (ast.Expr, error) {
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return nil, fmt.Errorf("default tag is not supported for %s", t)
+	}
+	bitSize := int(types.SizesFor("gc", "amd64").Sizeof(basic) * 8)
+
+	info := basic.Info()
+	switch {
+	case info&types.IsString != 0:
+		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(value)}, nil
+	case info&types.IsBoolean != 0:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s default %q", t, value)
+		}
+		return ast.NewIdent(strconv.FormatBool(b)), nil
+	case info&types.IsUnsigned != 0:
+		u, err := strconv.ParseUint(value, 0, bitSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s default %q", t, value)
+		}
+		return &ast.BasicLit{Kind: token.INT, Value: strconv.FormatUint(u, 10)}, nil
+	case info&types.IsInteger != 0:
+		i, err := strconv.ParseInt(value, 0, bitSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s default %q", t, value)
+		}
+		return &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(i, 10)}, nil
+	case info&types.IsFloat != 0:
+		f, err := strconv.ParseFloat(value, bitSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s default %q", t, value)
+		}
+		return &ast.BasicLit{Kind: token.FLOAT, Value: strconv.FormatFloat(f, 'g', -1, bitSize)}, nil
+	default:
+		return nil, fmt.Errorf("default tag is not supported for %s", t)
+	}
+}
+
+// isWildcardType reports whether t is the unnamed empty interface,
+// which kessoku.Inject[any] uses to ask for the return type to be inferred.
+func isWildcardType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if _, ok := types.Unalias(t).(*types.Named); ok {
+		return false
+	}
+
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// inferReturnType sets build.Return to the single type provided by the only provider of build.
+func inferReturnType(pkg *packages.Package, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	var candidate *ProviderSpec
+	for _, provider := range build.Providers {
+		if provider.Type == ProviderTypeArg {
+			continue
+		}
+		if candidate != nil {
+			return fmt.Errorf("return type can only be inferred for injectors with a single provider")
+		}
+		candidate = provider
+	}
+
+	if candidate == nil || candidate.Type != ProviderTypeFunction {
+		return fmt.Errorf("return type can only be inferred from a function provider")
+	}
+	if len(candidate.Provides) != 1 || len(candidate.Provides[0]) != 1 {
+		return fmt.Errorf("return type can only be inferred from a provider with a single result")
+	}
+
+	returnType := candidate.Provides[0][0]
+	expr, err := createASTTypeExpr(pkg.PkgPath, returnType, varPool, imports)
+	if err != nil {
+		return fmt.Errorf("create AST type expr: %w", err)
+	}
+
+	build.Return = &Return{
+		Type:        returnType,
+		ASTTypeExpr: expr,
+	}
+
+	return nil
+}
+
 // parseProviderArgument parses a provider argument in kessoku.Inject call.
 func (p *Parser) parseProviderArgument(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, build *BuildDirective, imports map[string]*Import, fileImports []*ast.ImportSpec, varPool *VarPool) error {
 	providerType := pkg.TypesInfo.TypeOf(arg)
@@ -363,10 +837,49 @@ func (p *Parser) parseProviderArgument(pkg *packages.Package, kessokuPackageScop
 		return nil
 	}
 
+	if p.parseInjectOption(kessokuPackageScope, providerType, build) {
+		return nil
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "argsProvider") {
+		// A set declaring Args may be used by an injector declaring it as well
+		if !build.CommandArgs {
+			build.CommandArgs = true
+			build.Args = append(build.Args, commandArgsType)
+		}
+		return nil
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "injectorNameProvider") {
+		return p.parseInjectorName(pkg, arg, build, imports, varPool)
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "channelTrace") {
+		return p.parseChannelTrace(pkg, arg, build, imports, varPool)
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "panicMsgOption") {
+		return p.parsePanicMsg(pkg, kessokuPackageScope, arg, build)
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "blankImport") {
+		return p.parseBlankImport(pkg, kessokuPackageScope, arg, build)
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "sharedOption") {
+		return p.parseShared(pkg, kessokuPackageScope, arg, build, varPool)
+	}
+
+	if isKessokuType(kessokuPackageScope, providerType, "groupOption") {
+		//lint:ignore ST1005 Group is the name of the kessoku option.
+		return fmt.Errorf("Group must be passed to Provide or Set")
+	}
+
 	if types.Identical(providerType, setType) {
 		var (
 			callExpr   *ast.CallExpr
 			currentArg = arg
+			setName    string
 		)
 		for callExpr == nil && currentArg != nil {
 			switch v := currentArg.(type) {
@@ -393,6 +906,9 @@ func (p *Parser) parseProviderArgument(pkg *packages.Package, kessokuPackageScop
 					slog.Warn("var declaration not found. Ignoring this Set call.", "obj", varObj)
 					return nil
 				}
+				if setName == "" {
+					setName = varObj.Name()
+				}
 				continue
 			case *ast.SelectorExpr:
 				//lint:ignore ST1005 is ignored because Set is a kessoku-specific proper noun, so capitalizing it in the error string is intentional and not a generic sentence case issue.
@@ -406,23 +922,159 @@ func (p *Parser) parseProviderArgument(pkg *packages.Package, kessokuPackageScop
 			return fmt.Errorf("invalid Set call expression")
 		}
 
+		var group string
+		start := len(build.Providers)
 		for _, setArg := range callExpr.Args {
+			if isKessokuType(kessokuPackageScope, pkg.TypesInfo.TypeOf(setArg), "groupOption") {
+				var err error
+				group, err = p.parseGroup(pkg, kessokuPackageScope, setArg)
+				if err != nil {
+					return fmt.Errorf("parse Set group: %w", err)
+				}
+				continue
+			}
+
 			if err := p.parseProviderArgument(pkg, kessokuPackageScope, setArg, build, imports, fileImports, varPool); err != nil {
 				return fmt.Errorf("parse Set provider argument: %w", err)
 			}
 		}
+		setProviderSetName(build.Providers[start:], setName)
+		setProviderGroup(build.Providers[start:], group)
 
 		return nil
 	}
 
+	if named, ok := providerType.(*types.Named); ok {
+		switch named.Obj().Name() {
+		case "argProvider":
+			build.Args = append(build.Args, named.TypeArgs().At(0))
+			return nil
+		case "envProvider":
+			return p.parseEnv(pkg, arg, named, build, imports, varPool)
+		case "namedArg":
+			return p.parseNamedArg(pkg, arg, named, build)
+		case "GenericSet":
+			return p.parseGenericSet(pkg, kessokuPackageScope, arg, build, imports, fileImports, varPool)
+		case "httpClientProvider":
+			return p.parseHTTPClient(pkg, kessokuPackageScope, arg, build, imports, varPool)
+		case "implementsOption":
+			return p.parseImplements(pkg, arg, named, build)
+		case "errorWrapper":
+			return p.parseWrapError(pkg, arg, named, build, imports, varPool)
+		case "adaptProvider":
+			return p.parseAdapt(pkg, kessokuPackageScope, arg, named, build, imports, fileImports, varPool)
+		case "decorateProvider":
+			return p.parseDecorate(pkg, kessokuPackageScope, arg, named, build, imports, fileImports, varPool)
+		case "ldFlag":
+			return p.parseLDFlag(pkg, arg, named, build, imports, varPool)
+		case "nilProvider":
+			return p.parseNil(pkg, arg, named, build, imports, varPool)
+		case "pooledProvider":
+			return p.parsePooled(pkg, arg, named, build, imports, varPool)
+		case "collectImplementing":
+			return p.parseCollectImplementing(pkg, kessokuPackageScope, arg, named, build, imports, varPool)
+		case "useProvider":
+			return p.parseUse(pkg, arg, named, build)
+		case "after":
+			build.Orders = append(build.Orders, &Ordering{
+				Before: named.TypeArgs().At(0),
+				After:  named.TypeArgs().At(1),
+			})
+			return nil
+		case "appendValue":
+			expr, referencedImports := p.collectDependencies(arg, pkg.TypesInfo, imports, varPool)
+			build.Providers = append(build.Providers, &ProviderSpec{
+				ASTExpr:           expr,
+				Type:              ProviderTypeAppendValue,
+				Provides:          [][]types.Type{{types.NewSlice(named.TypeArgs().At(0))}},
+				ReferencedImports: referencedImports,
+			})
+			return nil
+		}
+	}
+
+	// The same provider function reached through overlapping sets collapses to a single provider
+	providerFn := resolveProviderFunc(pkg, arg)
+	if providerFn != nil {
+		for _, existing := range build.Providers {
+			if existing.fn == providerFn && types.Identical(existing.providerType, providerType) {
+				slog.Debug("skip duplicate provider", "func", providerFn.Pkg().Path()+"."+providerFn.Name())
+				return nil
+			}
+		}
+	}
+
 	result, err := p.parseProviderType(pkg, providerType, varPool)
 	if err != nil {
-		return fmt.Errorf("parse provider type: %w", err)
+		return fmt.Errorf("parse provider type at %s: %w", pkg.Fset.Position(arg.Pos()), err)
+	}
+
+	isDeprecated, deprecatedMessage, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "deprecatedOption", "deprecation message")
+	if err != nil {
+		return fmt.Errorf("parse provider options: %w", err)
+	}
+
+	_, tag, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "tagOption", "tag")
+	if err != nil {
+		return fmt.Errorf("parse provider options: %w", err)
+	}
+
+	group, err := p.parseGroup(pkg, kessokuPackageScope, arg)
+	if err != nil {
+		return fmt.Errorf("parse provider options: %w", err)
+	}
+
+	cost, err := p.parseCost(pkg, kessokuPackageScope, arg)
+	if err != nil {
+		return fmt.Errorf("parse provider options: %w", err)
+	}
+
+	readyChecks, err := p.parseReadyChecks(pkg, kessokuPackageScope, arg, result.Provides, imports, varPool)
+	if err != nil {
+		return fmt.Errorf("parse provider options: %w", err)
+	}
+
+	hasSpan, spanName, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "spanOption", "span name")
+	if err != nil {
+		return fmt.Errorf("parse provider options: %w", err)
+	}
+	var spanTypes []types.Type
+	if hasSpan {
+		if result.IsStruct {
+			return fmt.Errorf("span is not supported for struct providers")
+		}
+
+		spanTypes, err = spanRequires(kessokuPackageScope)
+		if err != nil {
+			return fmt.Errorf("resolve span dependencies: %w", err)
+		}
+	}
+
+	callExpr, err := parseCallProvider(pkg, arg)
+	if err != nil {
+		return err
+	}
+	if callExpr == nil {
+		callExpr, err = parseConstProvider(pkg, arg)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Collect dependencies from provider expression and get referenced imports
 	var referencedImports map[string]*Import
 	arg, referencedImports = p.collectDependencies(arg, pkg.TypesInfo, imports, varPool)
+	if callExpr != nil {
+		// The call is generated in place of the provider, so only its imports are used
+		_, referencedImports = p.collectDependencies(callExpr, pkg.TypesInfo, imports, varPool)
+	}
+	isValue := isValueProvider(pkg, arg)
+	if len(readyChecks) > 0 && !result.IsStruct {
+		arg, err = p.bindReadyChecks(arg, readyChecks)
+		if err != nil {
+			return fmt.Errorf("bind ready checks: %w", err)
+		}
+	}
 
 	// Check if this is a struct provider (even if wrapped in Async/Bind)
 	if result.IsStruct {
@@ -446,6 +1098,9 @@ func (p *Parser) parseProviderArgument(pkg *packages.Package, kessokuPackageScop
 			Requires:          result.Requires,
 			IsReturnError:     result.IsReturnError,
 			IsAsync:           result.IsAsync,
+			Tag:               tag,
+			Group:             group,
+			Cost:              cost,
 			ReferencedImports: referencedImports,
 		})
 	} else {
@@ -454,22 +1109,1411 @@ func (p *Parser) parseProviderArgument(pkg *packages.Package, kessokuPackageScop
 			Type:              ProviderTypeFunction,
 			Provides:          result.Provides,
 			Requires:          result.Requires,
+			RequireNames:      result.RequireNames,
 			IsReturnError:     result.IsReturnError,
 			IsAsync:           result.IsAsync,
+			IsDeprecated:      isDeprecated,
+			IsValue:           isValue,
+			CallExpr:          callExpr,
+			DeprecatedMessage: deprecatedMessage,
+			SpanName:          spanName,
+			SpanRequires:      spanTypes,
+			Tag:               tag,
+			Group:             group,
+			Cost:              cost,
+			ReadyChecks:       readyChecks,
 			ReferencedImports: referencedImports,
+			fn:                providerFn,
+			providerType:      providerType,
 		})
 	}
 
 	return nil
 }
 
-// parseProviderTypeResult holds the result of parsing a provider type.
-type parseProviderTypeResult struct {
-	StructType    types.Type
-	Requires      []types.Type
-	Provides      [][]types.Type
-	IsReturnError bool
-	IsAsync       bool
+// parseGenericSet expands an instantiated generic set function such as RepositorySet[User]().
+// The returned expression is copied with the function's type parameters replaced by the
+// type arguments and type-checked again, so its providers are parsed for the concrete types.
+func (p *Parser) parseGenericSet(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, build *BuildDirective, imports map[string]*Import, fileImports []*ast.ImportSpec, varPool *VarPool) error {
+	expr := ast.Unparen(arg)
+	if ident, ok := expr.(*ast.Ident); ok {
+		varObj, varOk := pkg.TypesInfo.ObjectOf(ident).(*types.Var)
+		if !varOk || varObj.Pkg() == nil || varObj.Pkg().Path() != pkg.PkgPath {
+			return fmt.Errorf("unsupported GenericSet reference: %s", ident.Name)
+		}
+
+		expr = p.getVarDecl(pkg, varObj)
+		if expr == nil {
+			return fmt.Errorf("var declaration of GenericSet %s not found", ident.Name)
+		}
+		expr = ast.Unparen(expr)
+	}
+
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 0 {
+		return fmt.Errorf("GenericSet must be referenced by calling a generic function without arguments")
+	}
+
+	var (
+		funExpr      ast.Expr
+		typeArgExprs []ast.Expr
+	)
+	switch fun := ast.Unparen(callExpr.Fun).(type) {
+	case *ast.IndexExpr:
+		funExpr, typeArgExprs = fun.X, []ast.Expr{fun.Index}
+	case *ast.IndexListExpr:
+		funExpr, typeArgExprs = fun.X, fun.Indices
+	default:
+		return fmt.Errorf("GenericSet function must be instantiated with explicit type arguments")
+	}
+
+	funIdent, ok := funExpr.(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("GenericSet function from another package is not supported")
+	}
+
+	funcObj, ok := pkg.TypesInfo.ObjectOf(funIdent).(*types.Func)
+	if !ok {
+		return fmt.Errorf("GenericSet %s is not a function", funIdent.Name)
+	}
+
+	funcDecl := p.getFuncDecl(pkg, funcObj)
+	if funcDecl == nil || funcDecl.Body == nil {
+		return fmt.Errorf("declaration of GenericSet function %s not found", funIdent.Name)
+	}
+
+	if len(funcDecl.Body.List) != 1 {
+		return fmt.Errorf("GenericSet function %s must consist of a single return statement", funIdent.Name)
+	}
+	returnStmt, ok := funcDecl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(returnStmt.Results) != 1 {
+		return fmt.Errorf("GenericSet function %s must consist of a single return statement", funIdent.Name)
+	}
+
+	var typeParamNames []string
+	if funcDecl.Type.TypeParams != nil {
+		for _, field := range funcDecl.Type.TypeParams.List {
+			for _, name := range field.Names {
+				typeParamNames = append(typeParamNames, name.Name)
+			}
+		}
+	}
+	if len(typeParamNames) != len(typeArgExprs) {
+		return fmt.Errorf("GenericSet function %s expects %d type arguments, got %d", funIdent.Name, len(typeParamNames), len(typeArgExprs))
+	}
+
+	typeArgs := make(map[string]string, len(typeParamNames))
+	typeArgStrs := make([]string, 0, len(typeArgExprs))
+	for i, typeArgExpr := range typeArgExprs {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, p.fset, typeArgExpr); err != nil {
+			return fmt.Errorf("format type argument: %w", err)
+		}
+		typeArgs[typeParamNames[i]] = buf.String()
+		typeArgStrs = append(typeArgStrs, buf.String())
+	}
+
+	key := funcObj.FullName() + "[" + strings.Join(typeArgStrs, ", ") + "]"
+	if p.genericSets[key] {
+		return fmt.Errorf("GenericSet %s is instantiated recursively", key)
+	}
+	p.genericSets[key] = true
+	defer delete(p.genericSets, key)
+
+	setExpr, err := p.instantiateExpr(returnStmt.Results[0], typeArgs)
+	if err != nil {
+		return fmt.Errorf("instantiate GenericSet %s: %w", key, err)
+	}
+
+	// Type-check the instantiated expression in the scope of the generic function's file
+	if err := types.CheckExpr(p.fset, pkg.Types, funcDecl.Pos(), setExpr, pkg.TypesInfo); err != nil {
+		return fmt.Errorf("instantiate GenericSet %s: %w", key, err)
+	}
+
+	setCall, ok := setExpr.(*ast.CallExpr)
+	if !ok {
+		return fmt.Errorf("GenericSet function %s must return a NewGenericSet call", funIdent.Name)
+	}
+
+	newGenericSet := kessokuPackageScope.Lookup("NewGenericSet")
+	if newGenericSet == nil || newGenericSet != calleeObject(pkg.TypesInfo, setCall.Fun) {
+		// The set is built by another generic set function, e.g. return UserSet[T]()
+		return p.parseProviderArgument(pkg, kessokuPackageScope, setCall, build, imports, fileImports, varPool)
+	}
+
+	start := len(build.Providers)
+	for _, setArg := range setCall.Args {
+		if err := p.parseProviderArgument(pkg, kessokuPackageScope, setArg, build, imports, fileImports, varPool); err != nil {
+			return fmt.Errorf("parse GenericSet provider argument: %w", err)
+		}
+	}
+	setProviderSetName(build.Providers[start:], funIdent.Name+"["+strings.Join(typeArgStrs, ", ")+"]")
+
+	return nil
+}
+
+// setProviderSetName records setName on providers that are not already attributed to a nested set.
+func setProviderSetName(providers []*ProviderSpec, setName string) {
+	if setName == "" {
+		return
+	}
+
+	for _, provider := range providers {
+		if provider.SetName == "" {
+			provider.SetName = setName
+		}
+	}
+}
+
+// setProviderGroup labels the providers of a Set declared with kessoku.Group that have no group of their own.
+func setProviderGroup(providers []*ProviderSpec, group string) {
+	if group == "" {
+		return
+	}
+
+	for _, provider := range providers {
+		if provider.Group == "" {
+			provider.Group = group
+		}
+	}
+}
+
+// parseGroup returns the name given to a kessoku.Group option in arg, or "" if there is none.
+// The name is written into a line comment, so it must fit on a single line.
+func (p *Parser) parseGroup(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr) (string, error) {
+	_, group, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "groupOption", "group name")
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(group, "\r\n") {
+		return "", fmt.Errorf("group name %q must not contain line breaks", group)
+	}
+
+	return group, nil
+}
+
+// cloneExpr returns a copy of expr sharing no nodes with it, so that the copy can be rewritten
+// without affecting the other providers declared by the same expression.
+func (p *Parser) cloneExpr(expr ast.Expr) (ast.Expr, error) {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, p.fset, expr); err != nil {
+		return nil, fmt.Errorf("format expression: %w", err)
+	}
+
+	cloned, err := parser.ParseExprFrom(p.fset, "", buf.Bytes(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("parse expression: %w", err)
+	}
+
+	return cloned, nil
+}
+
+// instantiateExpr returns a copy of expr with the identifiers of type parameters replaced by type arguments.
+func (p *Parser) instantiateExpr(expr ast.Expr, typeArgs map[string]string) (ast.Expr, error) {
+	cloned, err := p.cloneExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var replaceErr error
+	result := astutil.Apply(cloned, func(c *astutil.Cursor) bool {
+		ident, ok := c.Node().(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if _, isSelector := c.Parent().(*ast.SelectorExpr); isSelector && c.Name() == "Sel" {
+			return true
+		}
+
+		typeArg, ok := typeArgs[ident.Name]
+		if !ok {
+			return true
+		}
+
+		typeArgExpr, err := parser.ParseExprFrom(p.fset, "", typeArg, 0)
+		if err != nil {
+			replaceErr = fmt.Errorf("parse type argument: %w", err)
+			return false
+		}
+		c.Replace(typeArgExpr)
+
+		return true
+	}, nil)
+	if replaceErr != nil {
+		return nil, replaceErr
+	}
+
+	return result.(ast.Expr), nil
+}
+
+// getFuncDecl finds the declaration of a package-level function.
+func (p *Parser) getFuncDecl(pkg *packages.Package, obj *types.Func) *ast.FuncDecl {
+	for _, file := range pkg.Syntax {
+		if file == nil {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if ok && funcDecl.Recv == nil && pkg.TypesInfo.Defs[funcDecl.Name] == obj {
+				return funcDecl
+			}
+		}
+	}
+
+	return nil
+}
+
+// calleeObject returns the object of the function called through fun, ignoring type arguments.
+func calleeObject(info *types.Info, fun ast.Expr) types.Object {
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.IndexExpr:
+		return calleeObject(info, f.X)
+	case *ast.IndexListExpr:
+		return calleeObject(info, f.X)
+	case *ast.SelectorExpr:
+		return info.ObjectOf(f.Sel)
+	case *ast.Ident:
+		return info.ObjectOf(f)
+	}
+
+	return nil
+}
+
+// parseNamedArg parses a kessoku.Named[T]("name") declaration into a named argument spec.
+func (p *Parser) parseNamedArg(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("invalid Named call expression")
+	}
+
+	tv, ok := pkg.TypesInfo.Types[callExpr.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return fmt.Errorf("named argument name must be a constant string")
+	}
+
+	argName := constant.StringVal(tv.Value)
+	if !token.IsIdentifier(argName) {
+		return fmt.Errorf("named argument name %q is not a valid identifier", argName)
+	}
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr:  arg,
+		Type:     ProviderTypeArg,
+		ArgName:  argName,
+		Provides: [][]types.Type{{named.TypeArgs().At(0)}},
+	})
+
+	return nil
+}
+
+// parseEnv parses kessoku.Env[T](key) into a provider whose reading of the environment variable key
+// and conversion to T are generated into the injector.
+func (p *Parser) parseEnv(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("Env must be called directly")
+	}
+	indexExpr, ok := ast.Unparen(callExpr.Fun).(*ast.IndexExpr)
+	if !ok {
+		return fmt.Errorf("Env must be called with an explicit type argument")
+	}
+
+	tv, ok := pkg.TypesInfo.Types[callExpr.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return fmt.Errorf("Env variable name must be a constant string")
+	}
+
+	// The type constraint of Env limits the value to string, int, and bool types
+	valueType := named.TypeArgs().At(0)
+	basic, ok := valueType.Underlying().(*types.Basic)
+	if !ok {
+		return fmt.Errorf("Env type %s is not a string, int, or bool type", valueType)
+	}
+
+	// Only the type is emitted, so the kessoku import of the call is not referenced
+	typeExpr, referencedImports := p.collectDependencies(indexExpr.Index, pkg.TypesInfo, imports, varPool)
+	importName := func(path, defaultName string) string {
+		imp, ok := imports[path]
+		if !ok {
+			name := varPool.GetName(defaultName)
+			imp = &Import{
+				Name:          name,
+				IsDefaultName: name == defaultName,
+			}
+			imports[path] = imp
+		}
+		referencedImports[path] = imp
+
+		return imp.Name
+	}
+
+	env := &EnvVar{
+		Key:        constant.StringVal(tv.Value),
+		Kind:       basic.Kind(),
+		Convert:    !types.Identical(valueType, types.Typ[basic.Kind()]),
+		osName:     importName(osPkgPath, osPkgName),
+		errorsName: importName(errorsPkgPath, errorsPkgName),
+	}
+	if env.Kind != types.String {
+		env.fmtName = importName(fmtPkgPath, fmtPkgName)
+		env.strconvName = importName(strconvPkgPath, strconvPkgName)
+	}
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr:           typeExpr,
+		Type:              ProviderTypeEnv,
+		Provides:          [][]types.Type{{valueType}},
+		IsReturnError:     true,
+		Env:               env,
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parseUse parses a kessoku.Use[T]("tag") selection of a tagged provider.
+func (p *Parser) parseUse(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("invalid Use call expression")
+	}
+
+	tv, ok := pkg.TypesInfo.Types[callExpr.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return fmt.Errorf("selected tag must be a constant string")
+	}
+
+	t := named.TypeArgs().At(0)
+	for _, use := range build.Uses {
+		if types.Identical(use.Type, t) {
+			return fmt.Errorf("multiple Use declarations for %s", t)
+		}
+	}
+
+	build.Uses = append(build.Uses, &TagSelection{
+		Type: t,
+		Tag:  constant.StringVal(tv.Value),
+	})
+
+	return nil
+}
+
+// parseImplements parses a kessoku.Implements[T]("Method") declaration.
+// The injector signature is checked against the method during generation.
+func (p *Parser) parseImplements(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective) error {
+	if build.Implements != nil {
+		return fmt.Errorf("multiple Implements declarations")
+	}
+
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("invalid Implements call expression")
+	}
+
+	tv, ok := pkg.TypesInfo.Types[callExpr.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return fmt.Errorf("implemented method name must be a constant string")
+	}
+	methodName := constant.StringVal(tv.Value)
+
+	ifaceType := named.TypeArgs().At(0)
+	iface, ok := ifaceType.Underlying().(*types.Interface)
+	if !ok {
+		return fmt.Errorf("%s is not an interface", ifaceType)
+	}
+
+	var method *types.Func
+	for m := range iface.Methods() {
+		if m.Name() == methodName {
+			method = m
+			break
+		}
+	}
+	if method == nil {
+		return fmt.Errorf("interface %s has no method %s", ifaceType, methodName)
+	}
+	if iface.NumMethods() != 1 {
+		return fmt.Errorf("interface %s must have %s as its only method to be implemented by an injector", ifaceType, methodName)
+	}
+
+	build.Implements = &Implementation{
+		Interface: ifaceType,
+		Method:    method,
+	}
+
+	return nil
+}
+
+// parseWrapError parses a kessoku.WrapError(fn) declaration into the error type returned by the injector.
+func (p *Parser) parseWrapError(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	if build.ErrorWrapper != nil {
+		return fmt.Errorf("multiple WrapError declarations")
+	}
+
+	expr, referencedImports := p.collectDependencies(arg, pkg.TypesInfo, imports, varPool)
+	callExpr, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("invalid WrapError call expression")
+	}
+
+	errType := named.TypeArgs().At(0)
+	errorIface, _ := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	if !types.Implements(errType, errorIface) {
+		return fmt.Errorf("%s does not implement error", errType)
+	}
+
+	build.ErrorWrapper = &ErrorWrapper{
+		Type:              errType,
+		ASTExpr:           callExpr.Args[0],
+		ReferencedImports: referencedImports,
+	}
+
+	return nil
+}
+
+// parseAdapt parses kessoku.Adapt[T](provider, adapter) into the wrapped provider and a provider
+// calling the adapter with its result, which the graph chains through the adapted type.
+func (p *Parser) parseAdapt(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, fileImports []*ast.ImportSpec, varPool *VarPool) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 2 {
+		return fmt.Errorf("invalid Adapt call expression")
+	}
+
+	if err := p.parseProviderArgument(pkg, kessokuPackageScope, callExpr.Args[0], build, imports, fileImports, varPool); err != nil {
+		return fmt.Errorf("parse Adapt provider argument: %w", err)
+	}
+
+	// Call the adapter as kessoku.Provide(adapter) through the package name used by the Adapt call
+	fun := ast.Unparen(callExpr.Fun)
+	switch v := fun.(type) {
+	case *ast.IndexExpr:
+		fun = v.X
+	case *ast.IndexListExpr:
+		fun = v.X
+	}
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return fmt.Errorf("invalid Adapt call expression")
+	}
+
+	kessokuExpr, referencedImports := p.collectDependencies(sel.X, pkg.TypesInfo, imports, varPool)
+	adapterExpr, adapterImports := p.collectDependencies(callExpr.Args[1], pkg.TypesInfo, imports, varPool)
+	maps.Copy(referencedImports, adapterImports)
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr: &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: kessokuExpr, Sel: ast.NewIdent("Provide")},
+			Args: []ast.Expr{adapterExpr},
+		},
+		Type:              ProviderTypeFunction,
+		Provides:          [][]types.Type{{named.TypeArgs().At(0)}},
+		Requires:          []types.Type{named.TypeArgs().At(1)},
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parseDecorate parses kessoku.Decorate[T](provider, decorators...) into the wrapped provider with
+// the decorators attached, whose parameters following the decorated value become requirements.
+func (p *Parser) parseDecorate(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, fileImports []*ast.ImportSpec, varPool *VarPool) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) < 1 {
+		return fmt.Errorf("invalid Decorate call expression")
+	}
+	if callExpr.Ellipsis.IsValid() {
+		return fmt.Errorf("Decorate decorators must be listed individually")
+	}
+	decorated := named.TypeArgs().At(0)
+
+	start := len(build.Providers)
+	if err := p.parseProviderArgument(pkg, kessokuPackageScope, callExpr.Args[0], build, imports, fileImports, varPool); err != nil {
+		return fmt.Errorf("parse Decorate provider argument: %w", err)
+	}
+	if len(build.Providers) != start+1 {
+		return fmt.Errorf("Decorate provider of %s must be a single provider that is not listed elsewhere", decorated)
+	}
+	provider := build.Providers[start]
+	// The decorated value is no longer the concrete value of the provider, so bound types are not allowed
+	if provider.Type != ProviderTypeFunction || len(provider.Provides) != 1 || len(provider.Provides[0]) != 1 ||
+		!types.Identical(provider.Provides[0][0], decorated) {
+		return fmt.Errorf("Decorate provider must provide only %s", decorated)
+	}
+
+	// Names of the decorator parameters follow the names of the provider parameters, if any
+	for len(provider.RequireNames) < len(provider.Requires) {
+		provider.RequireNames = append(provider.RequireNames, "")
+	}
+
+	for _, decoratorArg := range callExpr.Args[1:] {
+		sig, ok := pkg.TypesInfo.TypeOf(decoratorArg).Underlying().(*types.Signature)
+		if !ok {
+			return fmt.Errorf("decorator %s is not a function", types.ExprString(decoratorArg))
+		}
+		params := sig.Params()
+		if params.Len() == 0 || !types.Identical(params.At(0).Type(), decorated) || sig.Variadic() ||
+			sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), decorated) {
+			return fmt.Errorf("decorator %s must be a func(%s, ...) %s, got %s", types.ExprString(decoratorArg), decorated, decorated, sig)
+		}
+
+		decorator := &Decorator{}
+		for i := 1; i < params.Len(); i++ {
+			decorator.Requires = append(decorator.Requires, params.At(i).Type())
+			provider.RequireNames = append(provider.RequireNames, params.At(i).Name())
+		}
+		provider.Requires = append(provider.Requires, decorator.Requires...)
+
+		var referencedImports map[string]*Import
+		decorator.ASTExpr, referencedImports = p.collectDependencies(decoratorArg, pkg.TypesInfo, imports, varPool)
+		maps.Copy(provider.ReferencedImports, referencedImports)
+		provider.Decorators = append(provider.Decorators, decorator)
+	}
+
+	return nil
+}
+
+// parseLDFlag parses kessoku.LDFlag[T](name) into a provider reading the package-level variable name.
+func (p *Parser) parseLDFlag(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	// Call kessoku.Value through the package name used by the LDFlag call
+	expr, referencedImports := p.collectDependencies(arg, pkg.TypesInfo, imports, varPool)
+	callExpr, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("invalid LDFlag call expression")
+	}
+	fun := ast.Unparen(callExpr.Fun)
+	if index, isIndex := fun.(*ast.IndexExpr); isIndex {
+		fun = index.X
+	}
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return fmt.Errorf("invalid LDFlag call expression")
+	}
+
+	tv, ok := pkg.TypesInfo.Types[callExpr.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return fmt.Errorf("LDFlag variable name must be a constant string")
+	}
+	name := constant.StringVal(tv.Value)
+
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 || idx == len(name)-1 {
+		return fmt.Errorf("LDFlag variable name %q must be of the form importpath.name", name)
+	}
+	pkgPath, varName := name[:idx], name[idx+1:]
+
+	// The linker refers to package main as "main" rather than by its import path
+	varPkg := pkg.Types
+	if pkgPath != pkg.PkgPath && (pkgPath != "main" || pkg.Name != "main") {
+		varPkg = nil
+		for _, imported := range pkg.Types.Imports() {
+			if imported.Path() == pkgPath {
+				varPkg = imported
+				break
+			}
+		}
+		if varPkg == nil {
+			return fmt.Errorf("LDFlag package %s is not imported by %s", pkgPath, pkg.PkgPath)
+		}
+	}
+
+	varObj, ok := varPkg.Scope().Lookup(varName).(*types.Var)
+	if !ok {
+		return fmt.Errorf("LDFlag variable %s is not found", name)
+	}
+	if varPkg != pkg.Types && !varObj.Exported() {
+		return fmt.Errorf("LDFlag variable %s is not exported", name)
+	}
+	valueType := named.TypeArgs().At(0)
+	if !types.Identical(varObj.Type(), valueType) {
+		return fmt.Errorf("LDFlag variable %s has type %s, not %s", name, varObj.Type(), valueType)
+	}
+
+	var varExpr ast.Expr = ast.NewIdent(varName)
+	if varPkg != pkg.Types {
+		imp, ok := imports[pkgPath]
+		if !ok {
+			importName := varPool.GetName(varPkg.Name())
+			imp = &Import{
+				Name:          importName,
+				IsDefaultName: importName == varPkg.Name(),
+			}
+			imports[pkgPath] = imp
+		}
+		referencedImports[pkgPath] = imp
+		varExpr = &ast.SelectorExpr{X: ast.NewIdent(imp.Name), Sel: ast.NewIdent(varName)}
+	}
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr: &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent("Value")},
+			Args: []ast.Expr{varExpr},
+		},
+		Type:              ProviderTypeFunction,
+		Provides:          [][]types.Type{{valueType}},
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parseNil parses kessoku.Nil[T]() into a provider of a nil T, validating that T is an interface.
+func (p *Parser) parseNil(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	nilType := named.TypeArgs().At(0)
+	if !types.IsInterface(nilType) {
+		return fmt.Errorf("kessoku.Nil type %s at %s is not an interface", nilType, pkg.Fset.Position(arg.Pos()))
+	}
+
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok {
+		return fmt.Errorf("Nil must be called directly")
+	}
+	indexExpr, ok := ast.Unparen(callExpr.Fun).(*ast.IndexExpr)
+	if !ok {
+		return fmt.Errorf("Nil must be called with an explicit type argument")
+	}
+
+	// Only the type is emitted, so the kessoku import of the call is not referenced
+	typeExpr, referencedImports := p.collectDependencies(indexExpr.Index, pkg.TypesInfo, imports, varPool)
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr:           typeExpr,
+		Type:              ProviderTypeNil,
+		Provides:          [][]types.Type{{nilType}},
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parseCollectImplementing parses kessoku.CollectImplementing[T]() into a provider of []T,
+// whose values NewGraph collects from the providers of types implementing T.
+func (p *Parser) parseCollectImplementing(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	ifaceType := named.TypeArgs().At(0)
+	iface, ok := ifaceType.Underlying().(*types.Interface)
+	if !ok {
+		return fmt.Errorf("kessoku.CollectImplementing type %s at %s is not an interface", ifaceType, pkg.Fset.Position(arg.Pos()))
+	}
+	if iface.NumMethods() == 0 {
+		return fmt.Errorf("kessoku.CollectImplementing type %s at %s has no methods, so every provided type implements it", ifaceType, pkg.Fset.Position(arg.Pos()))
+	}
+
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok {
+		return fmt.Errorf("CollectImplementing must be called directly")
+	}
+	indexExpr, ok := ast.Unparen(callExpr.Fun).(*ast.IndexExpr)
+	if !ok {
+		return fmt.Errorf("CollectImplementing must be called with an explicit type argument")
+	}
+	allowEmpty := slices.ContainsFunc(callExpr.Args, func(opt ast.Expr) bool {
+		return isKessokuType(kessokuPackageScope, pkg.TypesInfo.TypeOf(opt), "allowEmptyOption")
+	})
+
+	// Only the type is emitted, in the slice literal, so the kessoku import of the call is not referenced
+	typeExpr, referencedImports := p.collectDependencies(indexExpr.Index, pkg.TypesInfo, imports, varPool)
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr:           typeExpr,
+		Type:              ProviderTypeCollect,
+		Provides:          [][]types.Type{{types.NewSlice(ifaceType)}},
+		AllowEmpty:        allowEmpty,
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parsePooled parses kessoku.Pooled[T](fn) into a provider of the func() (T, func()) acquiring
+// instances of T from a pool created with fn.
+func (p *Parser) parsePooled(pkg *packages.Package, arg ast.Expr, named *types.Named, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return fmt.Errorf("Pooled must be called directly with a constructor")
+	}
+
+	// Only the constructor is emitted, in the pool, so the kessoku import of the call is not referenced
+	fnExpr, referencedImports := p.collectDependencies(callExpr.Args[0], pkg.TypesInfo, imports, varPool)
+
+	pooledType := named.TypeArgs().At(0)
+	release := types.NewSignatureType(nil, nil, nil, nil, nil, false)
+	acquire := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(
+		types.NewParam(token.NoPos, nil, "", pooledType),
+		types.NewParam(token.NoPos, nil, "", release),
+	), false)
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr:           fnExpr,
+		Type:              ProviderTypePool,
+		Provides:          [][]types.Type{{acquire}},
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parseHTTPClient parses kessoku.HTTPClient(opts...) into a provider of a *http.Client whose
+// construction from the options is generated into the injector:
+//
+//	&http.Client{Timeout: 10 * time.Second}
+func (p *Parser) parseHTTPClient(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	callExpr, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok {
+		return fmt.Errorf("HTTPClient must be called directly")
+	}
+	if callExpr.Ellipsis.IsValid() {
+		return fmt.Errorf("HTTPClient options at %s must be passed directly, not as a slice", pkg.Fset.Position(callExpr.Ellipsis))
+	}
+
+	// The client type is taken from the loaded net/http, which kessoku itself does not import
+	httpPkg := findImportedPackage(pkg.Types, httpPkgPath)
+	if httpPkg == nil {
+		return fmt.Errorf("HTTPClient at %s requires %s to be imported by %s", pkg.Fset.Position(arg.Pos()), httpPkgPath, pkg.PkgPath)
+	}
+	clientObj, ok := httpPkg.Scope().Lookup("Client").(*types.TypeName)
+	if !ok {
+		return fmt.Errorf("type Client is not found in %s", httpPkgPath)
+	}
+
+	referencedImports := map[string]*Import{}
+	fields := map[string]bool{}
+	var elts []ast.Expr
+	for _, opt := range callExpr.Args {
+		optCall, ok := ast.Unparen(opt).(*ast.CallExpr)
+		if !ok || len(optCall.Args) != 1 {
+			return fmt.Errorf("HTTPClient option at %s must be a call of HTTPTimeout or HTTPTransport", pkg.Fset.Position(opt.Pos()))
+		}
+
+		var field string
+		switch calleeObject(pkg.TypesInfo, optCall.Fun) {
+		case kessokuPackageScope.Lookup("HTTPTimeout"):
+			field = "Timeout"
+		case kessokuPackageScope.Lookup("HTTPTransport"):
+			field = "Transport"
+			if !isRoundTripper(pkg.TypesInfo.TypeOf(optCall.Args[0])) {
+				return fmt.Errorf("HTTPTransport argument at %s of type %s does not implement %s.RoundTripper", pkg.Fset.Position(optCall.Args[0].Pos()), pkg.TypesInfo.TypeOf(optCall.Args[0]), httpPkgPath)
+			}
+		default:
+			return fmt.Errorf("HTTPClient option at %s must be a call of HTTPTimeout or HTTPTransport", pkg.Fset.Position(opt.Pos()))
+		}
+		if _, ok := fields[field]; ok {
+			return fmt.Errorf("HTTPClient option %s at %s is given more than once", field, pkg.Fset.Position(opt.Pos()))
+		}
+
+		value, valueImports := p.collectDependencies(optCall.Args[0], pkg.TypesInfo, imports, varPool)
+		maps.Copy(referencedImports, valueImports)
+		fields[field] = true
+		elts = append(elts, &ast.KeyValueExpr{Key: ast.NewIdent(field), Value: value})
+	}
+
+	imp, ok := imports[httpPkgPath]
+	if !ok {
+		name := varPool.GetName(httpPkgName)
+		imp = &Import{
+			Name:          name,
+			IsDefaultName: name == httpPkgName,
+		}
+		imports[httpPkgPath] = imp
+	}
+	referencedImports[httpPkgPath] = imp
+
+	clientExpr := &ast.UnaryExpr{
+		Op: token.AND,
+		X: &ast.CompositeLit{
+			Type: &ast.SelectorExpr{X: ast.NewIdent(imp.Name), Sel: ast.NewIdent("Client")},
+			Elts: elts,
+		},
+	}
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr:           clientExpr,
+		Type:              ProviderTypeFunction,
+		Provides:          [][]types.Type{{types.NewPointer(clientObj.Type())}},
+		CallExpr:          clientExpr,
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// findImportedPackage returns the package with the import path among the packages imported by pkg,
+// directly or indirectly, or nil if it is not imported.
+func findImportedPackage(pkg *types.Package, path string) *types.Package {
+	visited := map[*types.Package]bool{}
+	var find func(pkg *types.Package) *types.Package
+	find = func(pkg *types.Package) *types.Package {
+		if pkg.Path() == path {
+			return pkg
+		}
+		visited[pkg] = true
+		for _, imported := range pkg.Imports() {
+			if visited[imported] {
+				continue
+			}
+			if found := find(imported); found != nil {
+				return found
+			}
+		}
+
+		return nil
+	}
+
+	return find(pkg)
+}
+
+// isRoundTripper reports whether t has the RoundTrip method of http.RoundTripper.
+func isRoundTripper(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "RoundTrip")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Signature()
+	if sig.Params().Len() != 1 || sig.Results().Len() != 2 {
+		return false
+	}
+
+	return isHTTPType(sig.Params().At(0).Type(), "Request") &&
+		isHTTPType(sig.Results().At(0).Type(), "Response") &&
+		types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// isHTTPType reports whether t is a pointer to the named type of net/http.
+func isHTTPType(t types.Type, name string) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == httpPkgPath && named.Obj().Name() == name
+}
+
+// resolveProviderFunc returns the package-level function or function variable wrapped by a provider
+// expression such as kessoku.Async(kessoku.Provide(NewX)), or nil if it is not a plain reference to one.
+func resolveProviderFunc(pkg *packages.Package, arg ast.Expr) types.Object {
+	expr := ast.Unparen(arg)
+	for {
+		callExpr, ok := expr.(*ast.CallExpr)
+		if !ok || len(callExpr.Args) == 0 {
+			break
+		}
+		expr = ast.Unparen(callExpr.Args[0])
+	}
+
+	var ident *ast.Ident
+	switch v := expr.(type) {
+	case *ast.Ident:
+		ident = v
+	case *ast.SelectorExpr:
+		// Method values share a *types.Func across receivers, so only package-qualified functions qualify
+		x, ok := v.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		if _, ok := pkg.TypesInfo.Uses[x].(*types.PkgName); !ok {
+			return nil
+		}
+		ident = v.Sel
+	default:
+		return nil
+	}
+
+	switch obj := pkg.TypesInfo.Uses[ident].(type) {
+	case *types.Func:
+		return obj
+	case *types.Var:
+		// Constructors assigned to package-level variables, e.g. var NewX = func() *X {...}
+		if obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() {
+			return nil
+		}
+		if _, ok := obj.Type().Underlying().(*types.Signature); !ok {
+			return nil
+		}
+		return obj
+	}
+
+	return nil
+}
+
+// isValueProvider reports whether arg is a kessoku.Value or kessoku.Call call, possibly wrapped in Async or Bind.
+func isValueProvider(pkg *packages.Package, arg ast.Expr) bool {
+	return findKessokuCall(pkg, arg, "Value") != nil || findKessokuCall(pkg, arg, "Call") != nil || findKessokuCall(pkg, arg, "Const") != nil
+}
+
+// findKessokuCall returns the call of the kessoku function name in arg, possibly wrapped in Async or Bind.
+func findKessokuCall(pkg *packages.Package, arg ast.Expr, name string) *ast.CallExpr {
+	expr := ast.Unparen(arg)
+	for {
+		callExpr, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return nil
+		}
+
+		fun := ast.Unparen(callExpr.Fun)
+		switch v := fun.(type) {
+		case *ast.IndexExpr:
+			fun = v.X
+		case *ast.IndexListExpr:
+			fun = v.X
+		}
+		if sel, ok := fun.(*ast.SelectorExpr); ok {
+			fun = sel.Sel
+		}
+		if ident, ok := fun.(*ast.Ident); ok {
+			if fn, ok := pkg.TypesInfo.Uses[ident].(*types.Func); ok && fn.Pkg() != nil &&
+				fn.Pkg().Path() == kessokuPkgPath && fn.Name() == name {
+				return callExpr
+			}
+		}
+
+		if len(callExpr.Args) == 0 {
+			return nil
+		}
+		expr = ast.Unparen(callExpr.Args[0])
+	}
+}
+
+// parseCallProvider returns the function call given to kessoku.Call in arg, or nil if arg is not a
+// kessoku.Call provider. Conversions are rejected, as they are values for kessoku.Value.
+func parseCallProvider(pkg *packages.Package, arg ast.Expr) (ast.Expr, error) {
+	call := findKessokuCall(pkg, arg, "Call")
+	if call == nil {
+		return nil, nil
+	}
+	if len(call.Args) != 1 {
+		return nil, fmt.Errorf("kessoku.Call requires exactly one argument")
+	}
+
+	fnCall, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	if !ok || pkg.TypesInfo.Types[fnCall.Fun].IsType() {
+		return nil, fmt.Errorf("kessoku.Call requires a function call, use kessoku.Value for %s", types.ExprString(call.Args[0]))
+	}
+
+	return fnCall, nil
+}
+
+// parseConstProvider returns the constant given to kessoku.Const in arg, or nil if arg is not a
+// kessoku.Const provider. The constant must be of a named type, so that it is emitted by name.
+func parseConstProvider(pkg *packages.Package, arg ast.Expr) (ast.Expr, error) {
+	call := findKessokuCall(pkg, arg, "Const")
+	if call == nil {
+		return nil, nil
+	}
+	if len(call.Args) != 1 {
+		return nil, fmt.Errorf("kessoku.Const requires exactly one argument")
+	}
+
+	var ident *ast.Ident
+	switch v := ast.Unparen(call.Args[0]).(type) {
+	case *ast.Ident:
+		ident = v
+	case *ast.SelectorExpr:
+		ident = v.Sel
+	}
+	var constObj *types.Const
+	if ident != nil {
+		constObj, _ = pkg.TypesInfo.Uses[ident].(*types.Const)
+	}
+	if constObj == nil {
+		return nil, fmt.Errorf("kessoku.Const requires a constant, use kessoku.Value for %s", types.ExprString(call.Args[0]))
+	}
+	if _, ok := types.Unalias(constObj.Type()).(*types.Named); !ok {
+		return nil, fmt.Errorf("kessoku.Const requires a constant of a named type, %s is of type %s", constObj.Name(), constObj.Type())
+	}
+
+	return call.Args[0], nil
+}
+
+// parseStringOption looks for a provider option of the given kessoku type passed to a provider,
+// including providers wrapped in Async or Bind, and returns its constant string argument.
+// what names the argument in error messages.
+func (p *Parser) parseStringOption(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, typeName, what string) (bool, string, error) {
+	found, value, err := p.parseConstOption(pkg, kessokuPackageScope, arg, typeName, what)
+	if err != nil || !found {
+		return found, "", err
+	}
+	if value == nil || value.Kind() != constant.String {
+		return false, "", fmt.Errorf("%s must be a constant string", what)
+	}
+
+	return true, constant.StringVal(value), nil
+}
+
+// parseCost parses the estimated cost given to kessoku.Cost, returning 0 if there is none.
+func (p *Parser) parseCost(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr) (int, error) {
+	found, value, err := p.parseConstOption(pkg, kessokuPackageScope, arg, "costOption", "cost")
+	if err != nil || !found {
+		return 0, err
+	}
+
+	if value != nil {
+		value = constant.ToInt(value)
+	}
+	if value == nil || value.Kind() != constant.Int || constant.Sign(value) <= 0 {
+		return 0, fmt.Errorf("cost must be a positive constant integer")
+	}
+	cost, ok := constant.Int64Val(value)
+	if !ok || cost > math.MaxInt32 {
+		return 0, fmt.Errorf("cost %s is too large", value)
+	}
+
+	return int(cost), nil
+}
+
+// parseReadyChecks parses the kessoku.WithReadyCheck options passed to a provider,
+// each of which must check one of the provided types.
+func (p *Parser) parseReadyChecks(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, provides [][]types.Type, imports map[string]*Import, varPool *VarPool) ([]*ReadyCheck, error) {
+	optionObj := kessokuPackageScope.Lookup("readyCheckOption")
+	if optionObj == nil {
+		return nil, nil
+	}
+
+	var (
+		callExprs []*ast.CallExpr
+		err       error
+	)
+	ast.Inspect(arg, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+
+		switch v := n.(type) {
+		case *ast.FuncLit:
+			// Options inside a provider function body do not belong to the provider
+			return false
+		case *ast.CallExpr:
+			named, ok := pkg.TypesInfo.TypeOf(v).(*types.Named)
+			if !ok || named.Obj() != optionObj {
+				return true
+			}
+			if len(v.Args) != 1 {
+				err = fmt.Errorf("invalid ready check option call expression")
+				return false
+			}
+			callExprs = append(callExprs, v)
+
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	readyChecks := make([]*ReadyCheck, 0, len(callExprs))
+	for _, callExpr := range callExprs {
+		checkType := pkg.TypesInfo.TypeOf(callExpr).(*types.Named).TypeArgs().At(0)
+
+		provided := false
+		for _, ts := range provides {
+			if slices.ContainsFunc(ts, func(t types.Type) bool { return types.Identical(t, checkType) }) {
+				provided = true
+				break
+			}
+		}
+		if !provided {
+			return nil, fmt.Errorf("ready check of %s is given to a provider that does not provide it", checkType)
+		}
+
+		expr, referencedImports := p.collectDependencies(callExpr, pkg.TypesInfo, imports, varPool)
+		readyChecks = append(readyChecks, &ReadyCheck{
+			Type:              checkType,
+			ASTExpr:           expr,
+			ReferencedImports: referencedImports,
+		})
+	}
+
+	return readyChecks, nil
+}
+
+// bindReadyChecks returns a copy of the provider expression in which the ready check options
+// are replaced by the variables the generated injector binds them to, so that each check is
+// evaluated once for both the provider and the returned ready check:
+//
+//	databaseCheck := kessoku.WithReadyCheck((*Database).Ping)
+//	database, err := kessoku.Provide(NewDatabase, databaseCheck).Fn()()
+func (p *Parser) bindReadyChecks(expr ast.Expr, readyChecks []*ReadyCheck) (ast.Expr, error) {
+	cloned, err := p.cloneExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The copy has the same structure, so a call is found at the same position in the preorder of both
+	callIndex := func(root ast.Expr) []*ast.CallExpr {
+		var calls []*ast.CallExpr
+		ast.Inspect(root, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				calls = append(calls, call)
+			}
+			return true
+		})
+		return calls
+	}
+	calls, clonedCalls := callIndex(expr), callIndex(cloned)
+
+	idents := make(map[ast.Node]*ast.Ident, len(readyChecks))
+	for _, readyCheck := range readyChecks {
+		i := slices.Index(calls, readyCheck.ASTExpr.(*ast.CallExpr))
+		if i == -1 {
+			return nil, fmt.Errorf("ready check option is not found in the provider expression")
+		}
+		readyCheck.ASTExpr = clonedCalls[i]
+		readyCheck.ident = ast.NewIdent("_")
+		idents[clonedCalls[i]] = readyCheck.ident
+	}
+
+	return astutil.Apply(cloned, func(c *astutil.Cursor) bool {
+		if ident, ok := idents[c.Node()]; ok {
+			c.Replace(ident)
+			return false
+		}
+		return true
+	}, nil).(ast.Expr), nil
+}
+
+// parseConstOption finds the call of the kessoku option typeName in a provider expression
+// and returns the constant value of its single argument, nil if it is not constant.
+func (p *Parser) parseConstOption(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, typeName, what string) (bool, constant.Value, error) {
+	var (
+		found bool
+		value constant.Value
+		err   error
+	)
+	ast.Inspect(arg, func(n ast.Node) bool {
+		if err != nil || found {
+			return false
+		}
+
+		switch v := n.(type) {
+		case *ast.FuncLit:
+			// Options inside a provider function body do not belong to the provider
+			return false
+		case *ast.CallExpr:
+			if !isKessokuType(kessokuPackageScope, pkg.TypesInfo.TypeOf(v), typeName) {
+				return true
+			}
+
+			found = true
+			if len(v.Args) != 1 {
+				err = fmt.Errorf("invalid %s option call expression", what)
+				return false
+			}
+			value = pkg.TypesInfo.Types[v.Args[0]].Value
+
+			return false
+		}
+
+		return true
+	})
+
+	return found, value, err
+}
+
+// spanRequires returns the Tracer and context.Context types a provider with kessoku.Span depends on.
+func spanRequires(kessokuPackageScope *types.Scope) ([]types.Type, error) {
+	obj := kessokuPackageScope.Lookup("Tracer")
+	if obj == nil || obj.Type() == nil {
+		return nil, fmt.Errorf("kessoku.Tracer is not found")
+	}
+
+	sig, ok := obj.Type().Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() == 0 {
+		return nil, fmt.Errorf("kessoku.Tracer is not a function type")
+	}
+
+	return []types.Type{obj.Type(), sig.Params().At(0).Type()}, nil
+}
+
+// parseInjectOption applies an injector-level option such as kessoku.LazyInjector() to build.
+// It reports whether the argument was an option rather than a provider.
+func (p *Parser) parseInjectOption(kessokuPackageScope *types.Scope, providerType types.Type, build *BuildDirective) bool {
+	switch {
+	case isKessokuType(kessokuPackageScope, providerType, "lazyInjector"):
+		build.IsLazy = true
+	case isKessokuType(kessokuPackageScope, providerType, "mustInject"):
+		build.IsMust = true
+	case isKessokuType(kessokuPackageScope, providerType, "dynamicOverrides"):
+		build.Overrides = true
+	case isKessokuType(kessokuPackageScope, providerType, "autoConvert"):
+		build.AutoConvert = true
+	case isKessokuType(kessokuPackageScope, providerType, "autoRef"):
+		build.AutoRef = true
+	case isKessokuType(kessokuPackageScope, providerType, "withCancel"):
+		build.WithCancel = true
+	case isKessokuType(kessokuPackageScope, providerType, "omitContext"):
+		build.OmitContext = true
+	case isKessokuType(kessokuPackageScope, providerType, "lastWins"):
+		build.LastWins = true
+	case isKessokuType(kessokuPackageScope, providerType, "strictTypeIdentity"):
+		build.StrictTypes = true
+	case isKessokuType(kessokuPackageScope, providerType, "cleanupCloser"):
+		build.CleanupCloser = true
+	case isKessokuType(kessokuPackageScope, providerType, "runWrapper"):
+		build.RunWrapper = true
+	case isKessokuType(kessokuPackageScope, providerType, "forTest"):
+		build.ForTest = true
+	default:
+		return false
+	}
+
+	return true
+}
+
+// parseInjectorName parses kessoku.InjectorName() into a provider of the injector name as a string constant.
+func (p *Parser) parseInjectorName(pkg *packages.Package, arg ast.Expr, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	// Call kessoku.Value through the package name used by the InjectorName call
+	expr, referencedImports := p.collectDependencies(arg, pkg.TypesInfo, imports, varPool)
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return fmt.Errorf("InjectorName must be called directly")
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return fmt.Errorf("InjectorName must be called directly")
+	}
+
+	build.Providers = append(build.Providers, &ProviderSpec{
+		ASTExpr: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent("Value")},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(build.InjectorName)},
+			},
+		},
+		Type:              ProviderTypeFunction,
+		Provides:          [][]types.Type{{types.Typ[types.String]}},
+		ReferencedImports: referencedImports,
+	})
+
+	return nil
+}
+
+// parseChannelTrace parses kessoku.WithChannelTrace(...), whose Trace method the generated code calls.
+func (p *Parser) parseChannelTrace(pkg *packages.Package, arg ast.Expr, build *BuildDirective, imports map[string]*Import, varPool *VarPool) error {
+	if build.ChannelTrace != nil {
+		return fmt.Errorf("multiple WithChannelTrace declarations")
+	}
+
+	expr, referencedImports := p.collectDependencies(arg, pkg.TypesInfo, imports, varPool)
+	if _, ok := ast.Unparen(expr).(*ast.CallExpr); !ok {
+		return fmt.Errorf("WithChannelTrace must be called directly")
+	}
+
+	build.ChannelTrace = &ChannelTrace{
+		ASTExpr:           expr,
+		ReferencedImports: referencedImports,
+	}
+
+	return nil
+}
+
+// parsePanicMsg parses kessoku.PanicMsg(...), whose format must wrap the error of the injector
+// with its only verb.
+func (p *Parser) parsePanicMsg(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, build *BuildDirective) error {
+	if build.PanicFormat != "" {
+		return fmt.Errorf("multiple PanicMsg declarations")
+	}
+
+	_, format, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "panicMsgOption", "panic message")
+	if err != nil {
+		return err
+	}
+
+	verbs := formatVerbs(format)
+	if len(verbs) != 1 || (verbs[0] != 'w' && verbs[0] != 'v') {
+		return fmt.Errorf("panic message %q must have a single %%w or %%v verb for the error", format)
+	}
+
+	build.PanicFormat = format
+
+	return nil
+}
+
+// parseBlankImport parses the package path given to kessoku.BlankImport.
+func (p *Parser) parseBlankImport(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, build *BuildDirective) error {
+	_, path, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "blankImport", "blank import path")
+	if err != nil {
+		return err
+	}
+
+	if err := module.CheckImportPath(path); err != nil {
+		return fmt.Errorf("invalid blank import path: %w", err)
+	}
+	if path == pkg.PkgPath {
+		return fmt.Errorf("blank import of %s imports the package of the injector itself", path)
+	}
+
+	// The same import reached through several sets is imported once
+	if !slices.Contains(build.BlankImports, path) {
+		build.BlankImports = append(build.BlankImports, path)
+	}
+
+	return nil
+}
+
+// formatVerbs returns the verbs of the fmt format string, skipping their flags, width, and precision.
+func formatVerbs(format string) []rune {
+	var verbs []rune
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			continue
+		}
+
+		i++
+		for i < len(runes) && strings.ContainsRune("+-# 0123456789.[]", runes[i]) {
+			i++
+		}
+		if i < len(runes) && runes[i] != '%' {
+			verbs = append(verbs, runes[i])
+		}
+	}
+
+	return verbs
+}
+
+// parseShared parses kessoku.Shared(...), reserving the name of the cached result variable
+// so that it collides neither with package-level declarations nor with other generated names.
+func (p *Parser) parseShared(pkg *packages.Package, kessokuPackageScope *types.Scope, arg ast.Expr, build *BuildDirective, varPool *VarPool) error {
+	if build.SharedName != "" {
+		return fmt.Errorf("multiple Shared declarations")
+	}
+
+	_, name, err := p.parseStringOption(pkg, kessokuPackageScope, arg, "sharedOption", "shared variable name")
+	if err != nil {
+		return err
+	}
+	if !token.IsIdentifier(name) || name == "_" {
+		return fmt.Errorf("shared variable name %q is not a valid identifier", name)
+	}
+	if !varPool.Reserve(name) {
+		return fmt.Errorf("shared variable name %s is already declared", name)
+	}
+
+	build.SharedName = name
+
+	return nil
+}
+
+// isKessokuType reports whether t is the non-generic kessoku type named typeName.
+func isKessokuType(kessokuPackageScope *types.Scope, t types.Type, typeName string) bool {
+	obj := kessokuPackageScope.Lookup(typeName)
+	if obj == nil || obj.Type() == nil {
+		return false
+	}
+
+	return types.Identical(t, obj.Type())
+}
+
+// parseProviderTypeResult holds the result of parsing a provider type.
+type parseProviderTypeResult struct {
+	StructType    types.Type
+	Requires      []types.Type
+	RequireNames  []string
+	Provides      [][]types.Type
+	IsReturnError bool
+	IsAsync       bool
 	IsStruct      bool
 }
 
@@ -504,15 +2548,26 @@ func (p *Parser) parseProviderType(pkg *packages.Package, providerType types.Typ
 			return nil, fmt.Errorf("parse internal provider type: %w", err)
 		}
 
+		bound := false
 		for i, provide := range result.Provides {
 			for _, providedType := range provide {
+				// Implements also checks the method set of generic instantiations such as *Repo[User]
 				if types.Implements(providedType, intrfcType) {
-					// If the provided type is the interface type, we can skip it
 					result.Provides[i] = append(result.Provides[i], interfaceType)
+					bound = true
 					break
 				}
 			}
 		}
+		if !bound {
+			// A value lacking the pointer-receiver methods of the interface is the common cause, reported precisely
+			for _, provide := range result.Provides {
+				if len(provide) > 0 && !types.IsInterface(provide[0]) && types.Implements(types.NewPointer(provide[0]), intrfcType) {
+					return nil, checkImplements(provide[0], interfaceType)
+				}
+			}
+			return nil, fmt.Errorf("no type provided by the bound provider implements %s", interfaceType)
+		}
 
 		// Propagate struct info through bind wrapper
 		return result, nil
@@ -535,35 +2590,29 @@ func (p *Parser) parseProviderType(pkg *packages.Package, providerType types.Typ
 			return nil, fmt.Errorf("fnProvider requires at least 1 type argument")
 		}
 
-		providerFnSig, ok := typeArgs.At(0).(*types.Signature)
+		// Function variables may be declared with a named function type
+		providerFnSig, ok := typeArgs.At(0).Underlying().(*types.Signature)
 		if !ok || providerFnSig == nil {
 			slog.Debug("fnType is nil", "providerType", providerType)
 			return nil, fmt.Errorf("fnProvider type argument is not a function signature")
 		}
 
-		requires := make([]types.Type, 0, providerFnSig.Params().Len())
-		for v := range providerFnSig.Params().Variables() {
-			requires = append(requires, v.Type())
-		}
-
-		isReturnError := false
-		provides := make([][]types.Type, 0, providerFnSig.Results().Len())
-		for v := range providerFnSig.Results().Variables() {
-			if types.Identical(v.Type(), types.Universe.Lookup("error").Type()) {
-				isReturnError = true
-				continue
+		errorResults, lastErrorIndex := 0, -1
+		for i := range providerFnSig.Results().Len() {
+			if types.Identical(providerFnSig.Results().At(i).Type(), types.Universe.Lookup("error").Type()) {
+				errorResults++
+				lastErrorIndex = i
 			}
-
-			provides = append(provides, []types.Type{v.Type()})
+		}
+		if errorResults > 1 {
+			return nil, fmt.Errorf("provider %s returns %d error results, but at most 1 is allowed", providerFnSig, errorResults)
+		}
+		// The generated code assigns the error from the last result, as returned by idiomatic constructors
+		if errorResults == 1 && lastErrorIndex != providerFnSig.Results().Len()-1 {
+			return nil, fmt.Errorf("provider %s must return its error as the last result", providerFnSig)
 		}
 
-		return &parseProviderTypeResult{
-			Requires:      requires,
-			Provides:      provides,
-			IsReturnError: isReturnError,
-			IsAsync:       false,
-			IsStruct:      false,
-		}, nil
+		return parseProviderSignature(providerFnSig), nil
 	case "structProvider":
 		if typeArgs.Len() < 1 {
 			return nil, fmt.Errorf("structProvider requires 1 type argument")
@@ -585,6 +2634,36 @@ func (p *Parser) parseProviderType(pkg *packages.Package, providerType types.Typ
 	return nil, errors.New("no valid provider function found")
 }
 
+// parseProviderSignature derives the requirements and provided types of a provider function.
+func parseProviderSignature(providerFnSig *types.Signature) *parseProviderTypeResult {
+	requires := make([]types.Type, 0, providerFnSig.Params().Len())
+	requireNames := make([]string, 0, providerFnSig.Params().Len())
+	for v := range providerFnSig.Params().Variables() {
+		requires = append(requires, v.Type())
+		requireNames = append(requireNames, v.Name())
+	}
+
+	isReturnError := false
+	provides := make([][]types.Type, 0, providerFnSig.Results().Len())
+	for v := range providerFnSig.Results().Variables() {
+		if types.Identical(v.Type(), types.Universe.Lookup("error").Type()) {
+			isReturnError = true
+			continue
+		}
+
+		provides = append(provides, []types.Type{v.Type()})
+	}
+
+	return &parseProviderTypeResult{
+		Requires:      requires,
+		RequireNames:  requireNames,
+		Provides:      provides,
+		IsReturnError: isReturnError,
+		IsAsync:       false,
+		IsStruct:      false,
+	}
+}
+
 // extractExportedFields extracts exported fields from a struct type.
 // Fields are returned in alphabetical order by name for deterministic output.
 // Unexported fields are ignored.
@@ -1,8 +1,15 @@
 package kessoku
 
 import (
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -1491,3 +1498,3228 @@ var _ = kessoku.Inject[*App](
 		})
 	}
 }
+
+func TestParseBindGenericImplementer(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type User struct{}
+
+type UserRepository interface {
+	Find(id int) (User, error)
+}
+
+type Repo[T any] struct{}
+
+func NewRepo[T any]() *Repo[T] { return &Repo[T]{} }
+
+func NewRepoValue[T any]() Repo[T] { return Repo[T]{} }
+
+func (r *Repo[T]) Find(id int) (T, error) {
+	var zero T
+	return zero, nil
+}
+
+type Service struct{}
+
+func NewService(users UserRepository) *Service { return &Service{} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Bind[UserRepository](kessoku.Provide(NewRepo[User])),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*Service](
+	"InitializeValueService",
+	kessoku.Bind[UserRepository](kessoku.Provide(NewRepoValue[User])),
+	kessoku.Provide(NewService),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// Repo[User] only implements the interface through its pointer, so binding the value is reported and skipped
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+	if len(parser.diagnostics) != 1 || !strings.Contains(parser.diagnostics[0].Error(), "implements") {
+		t.Errorf("Expected a diagnostic about the unimplemented interface, got %v", parser.diagnostics)
+	}
+
+	var provides []string
+	for _, typeGroup := range builds[0].Providers[0].Provides {
+		for _, providedType := range typeGroup {
+			provides = append(provides, providedType.String())
+		}
+	}
+
+	expected := []string{
+		"*command-line-arguments.Repo[command-line-arguments.User]",
+		"command-line-arguments.UserRepository",
+	}
+	if !slices.Equal(provides, expected) {
+		t.Errorf("Expected bind provider to provide %v, got %v", expected, provides)
+	}
+}
+
+func TestParseBindEmbeddedImplementer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		decls          string
+		bound          string
+		expectedBuilds int
+	}{
+		{
+			name:  "embedded interface field",
+			bound: "Reader",
+			decls: `type Impl struct{ Reader }
+
+func NewImpl() Impl { return Impl{} }`,
+			expectedBuilds: 1,
+		},
+		{
+			name:  "embedded pointer with pointer receivers",
+			bound: "ReadCloser",
+			decls: `type Base struct{}
+
+func (b *Base) Read() string { return "" }
+
+func (b *Base) Close() error { return nil }
+
+type Impl struct{ *Base }
+
+func NewImpl() Impl { return Impl{Base: &Base{}} }`,
+			expectedBuilds: 1,
+		},
+		{
+			name:  "embedded value with pointer receivers provided as a pointer",
+			bound: "ReadCloser",
+			decls: `type Base struct{}
+
+func (b *Base) Read() string { return "" }
+
+func (b *Base) Close() error { return nil }
+
+type Impl struct{ Base }
+
+func NewImpl() *Impl { return &Impl{} }`,
+			expectedBuilds: 1,
+		},
+		{
+			name:  "embedded value with pointer receivers provided as a value",
+			bound: "ReadCloser",
+			decls: `type Base struct{}
+
+func (b *Base) Read() string { return "" }
+
+func (b *Base) Close() error { return nil }
+
+type Impl struct{ Base }
+
+func NewImpl() Impl { return Impl{} }`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Reader interface {
+	Read() string
+}
+
+type Closer interface {
+	Close() error
+}
+
+type ReadCloser interface {
+	Reader
+	Closer
+}
+
+` + tt.decls + `
+
+type Service struct{}
+
+func NewService(reader ` + tt.bound + `) *Service { return &Service{} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Bind[` + tt.bound + `](kessoku.Provide(NewImpl)),
+	kessoku.Provide(NewService),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			metaData, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Providers whose method set lacks the promoted methods are reported and skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d: %v", tt.expectedBuilds, len(builds), parser.diagnostics)
+			}
+			if len(builds) == 0 {
+				return
+			}
+
+			if _, err := CreateInjector(metaData, builds[0], NewVarPool(), false, 0); err != nil {
+				t.Errorf("CreateInjector failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseNamedArg(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		argName           string
+		expectedArgName   string
+		expectedRequires  []string
+		expectedProviders int
+	}{
+		{
+			name:              "named context",
+			argName:           `"request"`,
+			expectedProviders: 2,
+			expectedArgName:   "request",
+			expectedRequires:  []string{"request"},
+		},
+		{
+			name:              "constant name",
+			argName:           `requestName`,
+			expectedProviders: 2,
+			expectedArgName:   "request",
+			expectedRequires:  []string{"request"},
+		},
+		{
+			name:              "invalid identifier",
+			argName:           `"request-ctx"`,
+			expectedProviders: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"context"
+
+	"github.com/mazrean/kessoku"
+)
+
+const requestName = "request"
+
+type Handler struct{}
+
+func NewHandler(request context.Context) *Handler {
+	return &Handler{}
+}
+
+var _ = kessoku.Inject[*Handler](
+	"InitializeHandler",
+	kessoku.Named[context.Context](` + tt.argName + `),
+	kessoku.Provide(NewHandler),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if tt.expectedProviders == 0 {
+				if len(builds) != 0 {
+					t.Errorf("Expected invalid Named declaration to be rejected, got %d builds", len(builds))
+				}
+				return
+			}
+
+			if len(builds) != 1 {
+				t.Fatalf("Expected 1 build directive, got %d", len(builds))
+			}
+
+			providers := builds[0].Providers
+			if len(providers) != tt.expectedProviders {
+				t.Fatalf("Expected %d providers, got %d", tt.expectedProviders, len(providers))
+			}
+
+			namedArg := providers[0]
+			if namedArg.Type != ProviderTypeArg {
+				t.Errorf("Expected provider type %s, got %s", ProviderTypeArg, namedArg.Type)
+			}
+			if namedArg.ArgName != tt.expectedArgName {
+				t.Errorf("Expected argument name %q, got %q", tt.expectedArgName, namedArg.ArgName)
+			}
+			if got := namedArg.Provides[0][0].String(); got != "context.Context" {
+				t.Errorf("Expected named argument type context.Context, got %s", got)
+			}
+
+			handler := providers[1]
+			if !slices.Equal(handler.RequireNames, tt.expectedRequires) {
+				t.Errorf("Expected require names %v, got %v", tt.expectedRequires, handler.RequireNames)
+			}
+		})
+	}
+}
+
+func TestParseEnvProvider(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DatabaseURL string
+
+type Server struct{}
+
+func NewServer(url DatabaseURL, port int, debug bool) *Server {
+	return &Server{}
+}
+
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.Env[DatabaseURL]("DATABASE_URL"),
+	kessoku.Env[int]("PORT"),
+	kessoku.Env[bool]("DEBUG"),
+	kessoku.Provide(NewServer),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	providers := builds[0].Providers
+	if len(providers) != 4 {
+		t.Fatalf("Expected 4 providers, got %d", len(providers))
+	}
+
+	expected := []struct {
+		typ     string
+		key     string
+		kind    types.BasicKind
+		convert bool
+	}{
+		{typ: "command-line-arguments.DatabaseURL", key: "DATABASE_URL", kind: types.String, convert: true},
+		{typ: "int", key: "PORT", kind: types.Int},
+		{typ: "bool", key: "DEBUG", kind: types.Bool},
+	}
+	for i, e := range expected {
+		provider := providers[i]
+		if provider.Type != ProviderTypeEnv {
+			t.Errorf("Env provider %d: expected type %s, got %s", i, ProviderTypeEnv, provider.Type)
+		}
+		if provider.Env == nil || provider.Env.Key != e.key || provider.Env.Kind != e.kind || provider.Env.Convert != e.convert {
+			t.Errorf("Env provider %d: expected variable %s of kind %v converted %t, got %+v", i, e.key, e.kind, e.convert, provider.Env)
+		}
+		// The generated code only uses the packages it reads and parses the variable with
+		if _, ok := provider.ReferencedImports[kessokuPkgPath]; ok {
+			t.Errorf("Env provider %d: expected the kessoku import not to be referenced", i)
+		}
+		if len(provider.Requires) != 0 {
+			t.Errorf("Env provider %d: expected no requirements, got %v", i, provider.Requires)
+		}
+		if !provider.IsReturnError {
+			t.Errorf("Env provider %d: expected to return an error", i)
+		}
+		if len(provider.Provides) != 1 || provider.Provides[0][0].String() != e.typ {
+			t.Errorf("Env provider %d: expected to provide %s, got %v", i, e.typ, provider.Provides)
+		}
+	}
+}
+
+func TestParseClockProvider(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type TokenIssuer struct{}
+
+func NewTokenIssuer(now kessoku.Now) *TokenIssuer {
+	return &TokenIssuer{}
+}
+
+var _ = kessoku.Inject[*TokenIssuer](
+	"InitializeTokenIssuer",
+	kessoku.Clock(),
+	kessoku.Provide(NewTokenIssuer),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	providers := builds[0].Providers
+	if len(providers) != 2 {
+		t.Fatalf("Expected 2 providers, got %d", len(providers))
+	}
+
+	clock := providers[0]
+	if len(clock.Requires) != 0 || clock.IsReturnError {
+		t.Errorf("Expected Clock to have no requirements and no error, got requires %v, error %v", clock.Requires, clock.IsReturnError)
+	}
+	if len(clock.Provides) != 1 || clock.Provides[0][0].String() != "github.com/mazrean/kessoku.Now" {
+		t.Errorf("Expected Clock to provide kessoku.Now, got %v", clock.Provides)
+	}
+	if len(providers[1].Requires) != 1 || providers[1].Requires[0].String() != clock.Provides[0][0].String() {
+		t.Errorf("Expected NewTokenIssuer to require the provided clock, got %v", providers[1].Requires)
+	}
+}
+
+func TestParseHTTPClientProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		options            string
+		expectedClient     string
+		expectedReferenced []string
+	}{
+		{
+			name:               "no options",
+			expectedClient:     "&http.Client{}",
+			expectedReferenced: []string{"net/http"},
+		},
+		{
+			name:               "timeout",
+			options:            "kessoku.HTTPTimeout(10*time.Second)",
+			expectedClient:     "&http.Client{Timeout: 10 * time.Second}",
+			expectedReferenced: []string{"net/http", "time"},
+		},
+		{
+			name:               "timeout and transport",
+			options:            "kessoku.HTTPTimeout(timeout), kessoku.HTTPTransport(http.DefaultTransport)",
+			expectedClient:     "&http.Client{Timeout: timeout, Transport: http.DefaultTransport}",
+			expectedReferenced: []string{"net/http"},
+		},
+		{
+			name:    "transport not a round tripper",
+			options: "kessoku.HTTPTransport(timeout)",
+		},
+		{
+			name:    "option given twice",
+			options: "kessoku.HTTPTimeout(timeout), kessoku.HTTPTimeout(timeout)",
+		},
+		{
+			name:    "options passed as a slice",
+			options: "options...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+const timeout = 5 * time.Second
+
+var options = slice(kessoku.HTTPTimeout(timeout))
+
+func slice[T any](values ...T) []T {
+	return values
+}
+
+type APIClient struct{}
+
+func NewAPIClient(client *http.Client) *APIClient {
+	return &APIClient{}
+}
+
+var _ = kessoku.Inject[*APIClient](
+	"InitializeAPIClient",
+	kessoku.HTTPClient(` + tt.options + `),
+	kessoku.Provide(NewAPIClient),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if tt.expectedClient == "" {
+				if len(builds) != 0 {
+					t.Errorf("Expected invalid HTTPClient declaration to be rejected, got %d builds", len(builds))
+				}
+				return
+			}
+
+			if len(builds) != 1 {
+				t.Fatalf("Expected 1 build directive, got %d", len(builds))
+			}
+
+			providers := builds[0].Providers
+			if len(providers) != 2 {
+				t.Fatalf("Expected 2 providers, got %d", len(providers))
+			}
+
+			client := providers[0]
+			if len(client.Requires) != 0 || client.IsReturnError {
+				t.Errorf("Expected HTTPClient to have no requirements and no error, got requires %v, error %v", client.Requires, client.IsReturnError)
+			}
+			if len(client.Provides) != 1 || client.Provides[0][0].String() != "*net/http.Client" {
+				t.Errorf("Expected HTTPClient to provide *http.Client, got %v", client.Provides)
+			}
+
+			// The client is constructed by the generated code rather than by the kessoku package
+			var buf strings.Builder
+			if err := format.Node(&buf, token.NewFileSet(), client.CallExpr); err != nil {
+				t.Fatalf("Failed to format the client expression: %v", err)
+			}
+			if buf.String() != tt.expectedClient {
+				t.Errorf("Expected client expression %s, got %s", tt.expectedClient, buf.String())
+			}
+			referenced := slices.Sorted(maps.Keys(client.ReferencedImports))
+			if !slices.Equal(referenced, tt.expectedReferenced) {
+				t.Errorf("Expected referenced imports %v, got %v", tt.expectedReferenced, referenced)
+			}
+		})
+	}
+}
+
+func TestParseInjectorName(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Worker struct{}
+
+func NewWorker(injector string) *Worker {
+	return &Worker{}
+}
+
+var WorkerSet = kessoku.Set(
+	kessoku.InjectorName(),
+	kessoku.Provide(NewWorker),
+)
+
+var _ = kessoku.Inject[*Worker]("InitializeWorker", WorkerSet)
+
+var _ = kessoku.Inject[*Worker]("InitializeBatchWorker", WorkerSet)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 2 {
+		t.Fatalf("Expected 2 build directives, got %d", len(builds))
+	}
+
+	for _, build := range builds {
+		provider := build.Providers[0]
+		if len(provider.Provides) != 1 || provider.Provides[0][0].String() != "string" {
+			t.Errorf("%s: expected InjectorName to provide string, got %v", build.InjectorName, provider.Provides)
+		}
+
+		call, ok := provider.ASTExpr.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			t.Fatalf("%s: expected a kessoku.Value call, got %T", build.InjectorName, provider.ASTExpr)
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if expected := `"` + build.InjectorName + `"`; !ok || lit.Value != expected {
+			t.Errorf("%s: expected literal %s, got %v", build.InjectorName, expected, call.Args[0])
+		}
+	}
+}
+
+func TestParseValueEProvider(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import (
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+type Poller struct{}
+
+func NewPoller(interval time.Duration) *Poller {
+	return &Poller{}
+}
+
+var _ = kessoku.Inject[*Poller](
+	"InitializePoller",
+	kessoku.ValueE(time.ParseDuration("30s")),
+	kessoku.Provide(NewPoller),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	value := builds[0].Providers[0]
+	if !value.IsReturnError {
+		t.Error("Expected ValueE to return an error")
+	}
+	if len(value.Requires) != 0 {
+		t.Errorf("Expected ValueE to have no requirements, got %v", value.Requires)
+	}
+	if len(value.Provides) != 1 || value.Provides[0][0].String() != "time.Duration" {
+		t.Errorf("Expected ValueE to provide time.Duration, got %v", value.Provides)
+	}
+	if _, ok := value.ReferencedImports["time"]; !ok {
+		t.Errorf("Expected the expression to reference the time package, got %v", value.ReferencedImports)
+	}
+}
+
+func TestParseCallProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		provider         string
+		expectedCall     string
+		expectedBuilds   int
+		expectedProvides string
+	}{
+		{
+			name:             "function call",
+			provider:         "kessoku.Call(http.NewServeMux())",
+			expectedCall:     "http.NewServeMux()",
+			expectedBuilds:   1,
+			expectedProvides: "*net/http.ServeMux",
+		},
+		{
+			name:             "bound function call",
+			provider:         "kessoku.Bind[http.Handler](kessoku.Call(http.NewServeMux()))",
+			expectedCall:     "http.NewServeMux()",
+			expectedBuilds:   1,
+			expectedProvides: "*net/http.ServeMux",
+		},
+		{
+			name:           "conversion",
+			provider:       "kessoku.Call(http.HandlerFunc(nil))",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "not a call",
+			provider:       "kessoku.Call(http.DefaultServeMux)",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"net/http"
+
+	"github.com/mazrean/kessoku"
+)
+
+var _ = kessoku.Inject[http.Handler](
+	"InitializeHandler",
+	` + tt.provider + `,
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			provider := builds[0].Providers[0]
+			if provider.CallExpr == nil {
+				t.Fatal("Expected the provider to have a call expression")
+			}
+			if got := types.ExprString(provider.CallExpr); got != tt.expectedCall {
+				t.Errorf("Expected call %s, got %s", tt.expectedCall, got)
+			}
+			if !provider.IsValue {
+				t.Error("Expected the call result to be owned by the caller")
+			}
+			if len(provider.Requires) != 0 {
+				t.Errorf("Expected no requirements, got %v", provider.Requires)
+			}
+			if len(provider.Provides) == 0 || provider.Provides[0][0].String() != tt.expectedProvides {
+				t.Errorf("Expected the provider to provide %s, got %v", tt.expectedProvides, provider.Provides)
+			}
+
+			// Only the imports of the call are used by the generated code
+			if _, ok := provider.ReferencedImports["net/http"]; !ok {
+				t.Errorf("Expected the call to reference net/http, got %v", provider.ReferencedImports)
+			}
+			if _, ok := provider.ReferencedImports[kessokuPkgPath]; ok {
+				t.Errorf("Expected the kessoku package not to be referenced, got %v", provider.ReferencedImports)
+			}
+		})
+	}
+}
+
+func TestParseConstProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		provider         string
+		expectedExpr     string
+		expectedProvides string
+		expectedBuilds   int
+	}{
+		{
+			name:             "iota enum constant",
+			provider:         "kessoku.Const[Level](InfoLevel)",
+			expectedExpr:     "InfoLevel",
+			expectedProvides: "command-line-arguments.Level",
+			expectedBuilds:   1,
+		},
+		{
+			name:             "constant of imported package",
+			provider:         "kessoku.Const(time.Second)",
+			expectedExpr:     "time.Second",
+			expectedProvides: "time.Duration",
+			expectedBuilds:   1,
+		},
+		{
+			name:           "untyped constant",
+			provider:       "kessoku.Const(maxRetries)",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "not a constant",
+			provider:       "kessoku.Const(Level(1))",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package app
+
+import (
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+)
+
+const maxRetries = 3
+
+type Logger struct{}
+
+func NewLogger(level Level, interval time.Duration) *Logger { return &Logger{} }
+
+var _ = kessoku.Inject[*Logger](
+	"InitializeLogger",
+	` + tt.provider + `,
+	kessoku.Provide(NewLogger),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			provider := builds[0].Providers[0]
+			if provider.CallExpr == nil {
+				t.Fatal("Expected the provider to have a constant expression")
+			}
+			if got := types.ExprString(provider.CallExpr); got != tt.expectedExpr {
+				t.Errorf("Expected constant %s, got %s", tt.expectedExpr, got)
+			}
+			if !provider.IsValue {
+				t.Error("Expected the constant to be owned by the caller")
+			}
+			if len(provider.Provides) == 0 || provider.Provides[0][0].String() != tt.expectedProvides {
+				t.Errorf("Expected the provider to provide %s, got %v", tt.expectedProvides, provider.Provides)
+			}
+			if _, ok := provider.ReferencedImports[kessokuPkgPath]; ok {
+				t.Errorf("Expected the kessoku package not to be referenced, got %v", provider.ReferencedImports)
+			}
+		})
+	}
+}
+
+func TestParseCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Flags struct{}
+
+func NewFlags(args []string) *Flags {
+	return &Flags{}
+}
+
+var FlagSet = kessoku.Set(
+	kessoku.Args(),
+	kessoku.Provide(NewFlags),
+)
+
+var _ = kessoku.Inject[*Flags](
+	"InitializeFlags",
+	kessoku.Args(),
+	FlagSet,
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	// Args declared by both the set and the injector add a single argument
+	build := builds[0]
+	if !build.CommandArgs {
+		t.Error("Expected the injector to declare the command-line arguments")
+	}
+	if len(build.Args) != 1 || build.Args[0].String() != "[]string" {
+		t.Errorf("Expected a single []string argument, got %v", build.Args)
+	}
+}
+
+func TestParseDeprecatedOption(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		provider        string
+		expectedMessage string
+		isDeprecated    bool
+	}{
+		{
+			name:     "not deprecated",
+			provider: `kessoku.Provide(NewLegacyClient)`,
+		},
+		{
+			name:            "deprecated provider",
+			provider:        `kessoku.Provide(NewLegacyClient, kessoku.Deprecated("use NewClient"))`,
+			isDeprecated:    true,
+			expectedMessage: "use NewClient",
+		},
+		{
+			name:            "deprecated async provider",
+			provider:        `kessoku.Async(kessoku.Provide(NewLegacyClient, kessoku.Deprecated("use NewClient")))`,
+			isDeprecated:    true,
+			expectedMessage: "use NewClient",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Client struct{}
+
+func NewLegacyClient() *Client {
+	return &Client{}
+}
+
+var _ = kessoku.Inject[*Client](
+	"InitializeClient",
+	` + tt.provider + `,
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if len(builds) != 1 || len(builds[0].Providers) != 1 {
+				t.Fatalf("Expected 1 build directive with 1 provider, got %d builds", len(builds))
+			}
+
+			provider := builds[0].Providers[0]
+			if provider.IsDeprecated != tt.isDeprecated {
+				t.Errorf("Expected IsDeprecated %v, got %v", tt.isDeprecated, provider.IsDeprecated)
+			}
+			if provider.DeprecatedMessage != tt.expectedMessage {
+				t.Errorf("Expected deprecation message %q, got %q", tt.expectedMessage, provider.DeprecatedMessage)
+			}
+		})
+	}
+}
+
+func TestParseArgDeclarations(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+type Logger struct{}
+
+type App struct{}
+
+func NewApp(logger *Logger, config *Config, port int) *App {
+	return &App{}
+}
+
+var ArgSet = kessoku.Set(
+	kessoku.Arg[*Config](),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Arg[*Logger](),
+	ArgSet,
+	kessoku.Arg[string](),
+	kessoku.Provide(NewApp),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	if len(builds[0].Providers) != 1 {
+		t.Errorf("Expected Arg declarations not to be recorded as providers, got %d providers", len(builds[0].Providers))
+	}
+
+	expectedArgs := []string{
+		"*command-line-arguments.Logger",
+		"*command-line-arguments.Config",
+		"string",
+	}
+	args := builds[0].Args
+	if len(args) != len(expectedArgs) {
+		t.Fatalf("Expected %d declared arguments, got %d", len(expectedArgs), len(args))
+	}
+	for i, expected := range expectedArgs {
+		if args[i].String() != expected {
+			t.Errorf("Declared argument %d: expected %s, got %s", i, expected, args[i].String())
+		}
+	}
+}
+
+func TestParseDuplicateProvidersAcrossSets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		injectArgs        string
+		expectedProviders int
+	}{
+		{
+			name:              "overlapping sets",
+			injectArgs:        `DatabaseSet, RepositorySet, kessoku.Provide(NewService)`,
+			expectedProviders: 4,
+		},
+		{
+			name:              "same provider listed twice",
+			injectArgs:        `kessoku.Provide(NewConfig), kessoku.Provide(NewConfig), kessoku.Provide(NewDatabase), kessoku.Provide(NewRepository), kessoku.Provide(NewService)`,
+			expectedProviders: 4,
+		},
+		{
+			name:              "same function with different wrappers is kept",
+			injectArgs:        `kessoku.Provide(NewConfig), kessoku.Async(kessoku.Provide(NewConfig)), kessoku.Provide(NewDatabase), kessoku.Provide(NewRepository), kessoku.Provide(NewService)`,
+			expectedProviders: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+type Database struct{}
+
+type Repository struct{}
+
+type Service struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewDatabase(config *Config) *Database {
+	return &Database{}
+}
+
+func NewRepository(db *Database, config *Config) *Repository {
+	return &Repository{}
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{}
+}
+
+var DatabaseSet = kessoku.Set(
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+)
+
+var RepositorySet = kessoku.Set(
+	DatabaseSet,
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewRepository),
+)
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	` + tt.injectArgs + `,
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if len(builds) != 1 {
+				t.Fatalf("Expected 1 build directive, got %d", len(builds))
+			}
+
+			if len(builds[0].Providers) != tt.expectedProviders {
+				t.Errorf("Expected %d providers, got %d", tt.expectedProviders, len(builds[0].Providers))
+			}
+		})
+	}
+}
+
+func TestParseDecorate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		decorate           string
+		expectedDecorators []string
+		expectedRequires   []string
+		expectedBuilds     int
+	}{
+		{
+			name:               "two decorators",
+			decorate:           "kessoku.Decorate[Handler](kessoku.Provide(NewRouter), WithRecovery, WithLogging)",
+			expectedDecorators: []string{"WithRecovery", "WithLogging"},
+			expectedRequires:   []string{"*command-line-arguments.Logger"},
+			expectedBuilds:     1,
+		},
+		{
+			name:               "async base provider",
+			decorate:           "kessoku.Decorate[Handler](kessoku.Async(kessoku.Provide(NewRouter)), WithLogging)",
+			expectedDecorators: []string{"WithLogging"},
+			expectedRequires:   []string{"*command-line-arguments.Logger"},
+			expectedBuilds:     1,
+		},
+		{
+			name:           "decorator of another type",
+			decorate:       "kessoku.Decorate[Handler](kessoku.Provide(NewRouter), NewLogger)",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "bound base provider",
+			decorate:       "kessoku.Decorate[Handler](kessoku.Bind[Handler](kessoku.Provide(NewMux)), WithRecovery)",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Handler interface {
+	Serve(path string) string
+}
+
+type Mux struct{}
+
+func (m *Mux) Serve(path string) string { return path }
+
+type Logger struct{}
+
+type Server struct{}
+
+func NewLogger() *Logger { return &Logger{} }
+
+func NewRouter() Handler { return &Mux{} }
+
+func NewMux() *Mux { return &Mux{} }
+
+func WithRecovery(next Handler) Handler { return next }
+
+func WithLogging(next Handler, logger *Logger) Handler { return next }
+
+func NewServer(handler Handler) *Server { return &Server{} }
+
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.Provide(NewLogger),
+	` + tt.decorate + `,
+	kessoku.Provide(NewServer),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid decorations are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if len(builds) == 0 {
+				return
+			}
+
+			router := builds[0].Providers[1]
+			var decorators []string
+			for _, decorator := range router.Decorators {
+				decorators = append(decorators, types.ExprString(decorator.ASTExpr))
+			}
+			if !slices.Equal(decorators, tt.expectedDecorators) {
+				t.Errorf("Expected decorators %v, got %v", tt.expectedDecorators, decorators)
+			}
+
+			var requires []string
+			for _, require := range router.Requires {
+				requires = append(requires, require.String())
+			}
+			if !slices.Equal(requires, tt.expectedRequires) {
+				t.Errorf("Expected requirements %v, got %v", tt.expectedRequires, requires)
+			}
+			if len(router.RequireNames) != len(router.Requires) || router.RequireNames[len(router.RequireNames)-1] != "logger" {
+				t.Errorf("Expected the decorator parameter name logger, got %v", router.RequireNames)
+			}
+		})
+	}
+}
+
+func TestParseFunctionVariableProvider(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+type Database struct{}
+
+type DatabaseConstructor func(cfg *Config) (*Database, error)
+
+var NewConfig = func() *Config {
+	return &Config{}
+}
+
+var NewDatabase DatabaseConstructor = func(cfg *Config) (*Database, error) {
+	return &Database{}, nil
+}
+
+var DatabaseSet = kessoku.Set(
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+)
+
+var _ = kessoku.Inject[*Database](
+	"InitializeDatabase",
+	DatabaseSet,
+	kessoku.Provide(NewConfig),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	// NewConfig is listed twice and collapses like a function
+	providers := builds[0].Providers
+	if len(providers) != 2 {
+		t.Fatalf("Expected 2 providers, got %d", len(providers))
+	}
+
+	config, database := providers[0], providers[1]
+	if config.name() != "NewConfig" || len(config.Requires) != 0 {
+		t.Errorf("Expected NewConfig without requirements, got %s requiring %v", config.name(), config.Requires)
+	}
+	if database.name() != "NewDatabase" {
+		t.Errorf("Expected NewDatabase, got %s", database.name())
+	}
+	if len(database.Requires) != 1 || database.Requires[0].String() != "*command-line-arguments.Config" {
+		t.Errorf("Expected NewDatabase to require *Config, got %v", database.Requires)
+	}
+	if len(database.Provides) != 1 || database.Provides[0][0].String() != "*command-line-arguments.Database" {
+		t.Errorf("Expected NewDatabase to provide *Database, got %v", database.Provides)
+	}
+	if !database.IsReturnError {
+		t.Error("Expected NewDatabase to return an error")
+	}
+}
+
+func TestParseInferredReturnType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		injectArgs     string
+		expectedReturn string
+		expectedBuilds int
+	}{
+		{
+			name:           "single provider",
+			injectArgs:     `kessoku.Provide(NewConfig)`,
+			expectedReturn: "*command-line-arguments.Config",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "single provider with error result",
+			injectArgs:     `kessoku.Provide(NewDatabase)`,
+			expectedReturn: "*command-line-arguments.Database",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "multiple providers are ambiguous",
+			injectArgs:     `kessoku.Provide(NewConfig), kessoku.Provide(NewDatabase)`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "multiple results are ambiguous",
+			injectArgs:     `kessoku.Provide(NewPair)`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+type Database struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{}, nil
+}
+
+func NewPair() (*Config, *Database) {
+	return &Config{}, &Database{}
+}
+
+var _ = kessoku.Inject[any](
+	"Initialize",
+	` + tt.injectArgs + `,
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Ambiguous injectors are reported and skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			if got := builds[0].Return.Type.String(); got != tt.expectedReturn {
+				t.Errorf("Expected inferred return type %s, got %s", tt.expectedReturn, got)
+			}
+			if builds[0].Return.ASTTypeExpr == nil {
+				t.Error("Expected inferred return type expression")
+			}
+		})
+	}
+}
+
+func TestParseInjectComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		directive         string
+		expectedInjector  string
+		expectedReturn    string
+		expectedRequires  int
+		expectedBuilds    int
+		expectedReturnErr bool
+	}{
+		{
+			name:              "valid directive",
+			directive:         "//kessoku:inject InitializeService *Service",
+			expectedBuilds:    1,
+			expectedInjector:  "InitializeService",
+			expectedReturn:    "*command-line-arguments.Service",
+			expectedRequires:  1,
+			expectedReturnErr: true,
+		},
+		{
+			name:           "type not returned by the function",
+			directive:      "//kessoku:inject InitializeService *Config",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "unknown type",
+			directive:      "//kessoku:inject InitializeService *Unknown",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "missing return type",
+			directive:      "//kessoku:inject InitializeService",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "invalid injector name",
+			directive:      "//kessoku:inject Initialize-Service *Service",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "other directive with the same prefix",
+			directive:      "//kessoku:injector InitializeService *Service",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import _ "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+type Service struct{}
+
+// NewService creates a Service.
+//
+` + tt.directive + `
+func NewService(config *Config) (*Service, error) {
+	return &Service{}, nil
+}
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid directives are reported and skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			build := builds[0]
+			if build.InjectorName != tt.expectedInjector {
+				t.Errorf("Expected injector name %s, got %s", tt.expectedInjector, build.InjectorName)
+			}
+			if got := build.Return.Type.String(); got != tt.expectedReturn {
+				t.Errorf("Expected return type %s, got %s", tt.expectedReturn, got)
+			}
+			if len(build.Providers) != 1 {
+				t.Fatalf("Expected 1 provider, got %d", len(build.Providers))
+			}
+			if got := types.ExprString(build.Providers[0].ASTExpr); got != "kessoku.Provide(NewService)" {
+				t.Errorf("Expected provider expression kessoku.Provide(NewService), got %s", got)
+			}
+			if len(build.Providers[0].Requires) != tt.expectedRequires {
+				t.Errorf("Expected %d requirements, got %d", tt.expectedRequires, len(build.Providers[0].Requires))
+			}
+			if build.Providers[0].IsReturnError != tt.expectedReturnErr {
+				t.Errorf("Expected IsReturnError %v, got %v", tt.expectedReturnErr, build.Providers[0].IsReturnError)
+			}
+		})
+	}
+}
+
+func TestParseGenericSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		sets             string
+		injectArgs       string
+		expectedProvides []string
+		expectedBuilds   int
+	}{
+		{
+			name: "instantiated for two types",
+			sets: `func RepositorySet[T any]() kessoku.GenericSet[T] {
+	return kessoku.NewGenericSet[T](
+		kessoku.Provide(NewRepository[T]),
+		kessoku.Provide(NewService[T]),
+	)
+}`,
+			injectArgs:     "RepositorySet[User](), RepositorySet[Post](), kessoku.Provide(NewApp)",
+			expectedBuilds: 1,
+			expectedProvides: []string{
+				"*command-line-arguments.Repository[command-line-arguments.User]",
+				"*command-line-arguments.Service[command-line-arguments.User]",
+				"*command-line-arguments.Repository[command-line-arguments.Post]",
+				"*command-line-arguments.Service[command-line-arguments.Post]",
+				"*command-line-arguments.App",
+			},
+		},
+		{
+			name: "nested generic set",
+			sets: `func RepositorySet[T any]() kessoku.GenericSet[T] {
+	return kessoku.NewGenericSet[T](kessoku.Provide(NewRepository[T]))
+}
+
+func ServiceSet[T any]() kessoku.GenericSet[T] {
+	return kessoku.NewGenericSet[T](RepositorySet[T](), kessoku.Provide(NewService[T]))
+}`,
+			injectArgs:     "ServiceSet[User](), ServiceSet[Post](), kessoku.Provide(NewApp)",
+			expectedBuilds: 1,
+			expectedProvides: []string{
+				"*command-line-arguments.Repository[command-line-arguments.User]",
+				"*command-line-arguments.Service[command-line-arguments.User]",
+				"*command-line-arguments.Repository[command-line-arguments.Post]",
+				"*command-line-arguments.Service[command-line-arguments.Post]",
+				"*command-line-arguments.App",
+			},
+		},
+		{
+			name: "multiple statements",
+			sets: `func RepositorySet[T any]() kessoku.GenericSet[T] {
+	set := kessoku.NewGenericSet[T](kessoku.Provide(NewRepository[T]))
+	return set
+}`,
+			injectArgs:     "RepositorySet[User](), kessoku.Provide(NewApp)",
+			expectedBuilds: 0,
+		},
+		{
+			name: "recursive instantiation",
+			sets: `func RepositorySet[T any]() kessoku.GenericSet[T] {
+	return kessoku.NewGenericSet[T](RepositorySet[T]())
+}`,
+			injectArgs:     "RepositorySet[User](), kessoku.Provide(NewApp)",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DB struct{}
+
+type User struct{}
+
+type Post struct{}
+
+type Repository[T any] struct{}
+
+func NewRepository[T any](db *DB) *Repository[T] { return &Repository[T]{} }
+
+type Service[T any] struct{}
+
+func NewService[T any](repo *Repository[T]) *Service[T] { return &Service[T]{} }
+
+type App struct{}
+
+func NewApp(users *Service[User], posts *Service[Post]) *App { return &App{} }
+
+` + tt.sets + `
+
+var _ = kessoku.Inject[*App]("InitializeApp", ` + tt.injectArgs + `)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Unsupported generic sets are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			providers := builds[0].Providers
+			if len(providers) != len(tt.expectedProvides) {
+				t.Fatalf("Expected %d providers, got %d", len(tt.expectedProvides), len(providers))
+			}
+			for i, provider := range providers {
+				if got := provider.Provides[0][0].String(); got != tt.expectedProvides[i] {
+					t.Errorf("Provider %d: expected %s, got %s", i, tt.expectedProvides[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSetProvenance(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DB struct{}
+
+func NewDB() *DB { return &DB{} }
+
+func NewLegacyDB() *DB { return &DB{} }
+
+type Config struct{}
+
+func NewConfig() *Config { return &Config{} }
+
+type App struct{}
+
+func NewApp(db *DB) *App { return &App{} }
+
+var ConfigSet = kessoku.Set(kessoku.Provide(NewConfig))
+
+var DatabaseSet = kessoku.Set(ConfigSet, kessoku.Provide(NewDB))
+
+var LegacyDatabaseSet = kessoku.Set(kessoku.Provide(NewLegacyDB))
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	DatabaseSet,
+	LegacyDatabaseSet,
+	kessoku.Provide(NewApp),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	varPool := NewVarPool()
+	metaData, builds, err := parser.ParseFile(testFile, varPool)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	// Providers are attributed to the innermost set they are declared in
+	expectedSetNames := []string{"ConfigSet", "DatabaseSet", "LegacyDatabaseSet", ""}
+	providers := builds[0].Providers
+	if len(providers) != len(expectedSetNames) {
+		t.Fatalf("Expected %d providers, got %d", len(expectedSetNames), len(providers))
+	}
+	for i, provider := range providers {
+		if provider.SetName != expectedSetNames[i] {
+			t.Errorf("Provider %d: expected set name %q, got %q", i, expectedSetNames[i], provider.SetName)
+		}
+	}
+
+	_, err = NewGraph(metaData, builds[0], varPool)
+	if err == nil {
+		t.Fatal("Expected conflicting sets to fail")
+	}
+	expectedErr := "multiple providers provide *command-line-arguments.DB (declared in DatabaseSet and LegacyDatabaseSet)"
+	if !containsString(err.Error(), expectedErr) {
+		t.Errorf("Expected error containing %q, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestParseLDFlag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		option         string
+		expectedExpr   string
+		expectedType   string
+		expectedBuilds int
+	}{
+		{
+			name:           "variable of package main",
+			option:         `kessoku.LDFlag[string]("main.version")`,
+			expectedBuilds: 1,
+			expectedExpr:   "kessoku.Value(version)",
+			expectedType:   "string",
+		},
+		{
+			name:           "variable of imported package",
+			option:         `kessoku.LDFlag[[]string]("os.Args")`,
+			expectedBuilds: 1,
+			expectedExpr:   "kessoku.Value(os.Args)",
+			expectedType:   "[]string",
+		},
+		{
+			name:           "type mismatch",
+			option:         `kessoku.LDFlag[int]("main.version")`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "unknown variable",
+			option:         `kessoku.LDFlag[string]("main.commit")`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "constant instead of variable",
+			option:         `kessoku.LDFlag[string]("main.defaultVersion")`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "package not imported",
+			option:         `kessoku.LDFlag[string]("example.com/build.Version")`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "missing package path",
+			option:         `kessoku.LDFlag[string]("version")`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"os"
+
+	"github.com/mazrean/kessoku"
+)
+
+const defaultVersion = "dev"
+
+var version = defaultVersion
+
+var _ = os.Args
+
+type Service struct{}
+
+func NewService() *Service { return &Service{} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	` + tt.option + `,
+	kessoku.Provide(NewService),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid declarations are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			provider := builds[0].Providers[0]
+			if expr := types.ExprString(provider.ASTExpr); expr != tt.expectedExpr {
+				t.Errorf("Expected expression %s, got %s", tt.expectedExpr, expr)
+			}
+			if len(provider.Provides) != 1 || provider.Provides[0][0].String() != tt.expectedType {
+				t.Errorf("Expected LDFlag to provide %s, got %v", tt.expectedType, provider.Provides)
+			}
+		})
+	}
+}
+
+func TestParseImplements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		option         string
+		expectedMethod string
+		expectedBuilds int
+	}{
+		{
+			name:           "single method interface",
+			option:         `kessoku.Implements[ServiceFactory]("NewService")`,
+			expectedBuilds: 1,
+			expectedMethod: "NewService",
+		},
+		{
+			name:           "unknown method",
+			option:         `kessoku.Implements[ServiceFactory]("Create")`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "interface with other methods",
+			option:         `kessoku.Implements[ServiceRegistry]("NewService")`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "not an interface",
+			option:         `kessoku.Implements[Service]("NewService")`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Service struct{}
+
+func NewService() *Service { return &Service{} }
+
+type ServiceFactory interface {
+	NewService() *Service
+}
+
+type ServiceRegistry interface {
+	NewService() *Service
+	Close() error
+}
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	` + tt.option + `,
+	kessoku.Provide(NewService),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid declarations are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			implements := builds[0].Implements
+			if implements == nil {
+				t.Fatal("Expected Implements to be set")
+			}
+			if implements.Method.Name() != tt.expectedMethod {
+				t.Errorf("Expected method %s, got %s", tt.expectedMethod, implements.Method.Name())
+			}
+			if len(builds[0].Providers) != 1 {
+				t.Errorf("Expected Implements not to be parsed as a provider, got %d providers", len(builds[0].Providers))
+			}
+		})
+	}
+}
+
+func TestParseWrapError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        string
+		expectedType   string
+		expectedBuilds int
+	}{
+		{
+			name:           "custom error type",
+			options:        `kessoku.WrapError(NewInitError),`,
+			expectedBuilds: 1,
+			expectedType:   "*command-line-arguments.InitError",
+		},
+		{
+			name:           "function literal",
+			options:        `kessoku.WrapError(func(err error) *InitError { return &InitError{Err: err} }),`,
+			expectedBuilds: 1,
+			expectedType:   "*command-line-arguments.InitError",
+		},
+		{
+			name:           "multiple declarations",
+			options:        `kessoku.WrapError(NewInitError), kessoku.WrapError(NewInitError),`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Service struct{}
+
+func NewService() (*Service, error) { return &Service{}, nil }
+
+type InitError struct {
+	Err error
+}
+
+func (e *InitError) Error() string { return e.Err.Error() }
+
+func NewInitError(err error) *InitError { return &InitError{Err: err} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	` + tt.options + `
+	kessoku.Provide(NewService),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid declarations are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			wrapper := builds[0].ErrorWrapper
+			if wrapper == nil {
+				t.Fatal("Expected ErrorWrapper to be set")
+			}
+			if wrapper.Type.String() != tt.expectedType {
+				t.Errorf("Expected error type %s, got %s", tt.expectedType, wrapper.Type)
+			}
+			if len(builds[0].Providers) != 1 {
+				t.Errorf("Expected WrapError not to be parsed as a provider, got %d providers", len(builds[0].Providers))
+			}
+		})
+	}
+}
+
+func TestParseAdapt(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Client struct{}
+
+func NewClient() (*Client, error) { return &Client{}, nil }
+
+type Storage interface {
+	Save() error
+}
+
+type clientStorage struct {
+	client *Client
+}
+
+func (s *clientStorage) Save() error { return nil }
+
+func NewStorage(client *Client) Storage { return &clientStorage{client: client} }
+
+type Service struct{}
+
+func NewService(storage Storage) *Service { return &Service{} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Adapt[Storage](kessoku.Async(kessoku.Provide(NewClient)), NewStorage),
+	kessoku.Provide(NewService),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	providers := builds[0].Providers
+	if len(providers) != 3 {
+		t.Fatalf("Expected 3 providers, got %d", len(providers))
+	}
+
+	ctor := providers[0]
+	if len(ctor.Provides) != 1 || ctor.Provides[0][0].String() != "*command-line-arguments.Client" {
+		t.Errorf("Expected constructor to provide *Client, got %v", ctor.Provides)
+	}
+	if !ctor.IsReturnError || !ctor.IsAsync {
+		t.Errorf("Expected constructor options to be kept, got IsReturnError=%v IsAsync=%v", ctor.IsReturnError, ctor.IsAsync)
+	}
+
+	adapter := providers[1]
+	if len(adapter.Provides) != 1 || adapter.Provides[0][0].String() != "command-line-arguments.Storage" {
+		t.Errorf("Expected adapter to provide Storage, got %v", adapter.Provides)
+	}
+	if len(adapter.Requires) != 1 || adapter.Requires[0].String() != "*command-line-arguments.Client" {
+		t.Errorf("Expected adapter to require *Client, got %v", adapter.Requires)
+	}
+
+	// The adapter is called as kessoku.Provide(NewStorage)
+	call, ok := adapter.ASTExpr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		t.Fatalf("Expected adapter expression to be a call with 1 argument, got %T", adapter.ASTExpr)
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Provide" {
+		t.Errorf("Expected adapter to be wrapped in kessoku.Provide, got %T", call.Fun)
+	}
+	if ident, ok := call.Args[0].(*ast.Ident); !ok || ident.Name != "NewStorage" {
+		t.Errorf("Expected adapter argument NewStorage, got %T", call.Args[0])
+	}
+}
+
+func TestParseMultipleErrorResults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		constructor    string
+		expectedBuilds int
+	}{
+		{
+			name:           "single error result",
+			constructor:    `func NewService() (*Service, error) { return &Service{}, nil }`,
+			expectedBuilds: 1,
+		},
+		{
+			name:           "two error results",
+			constructor:    `func NewService() (*Service, error, error) { return &Service{}, nil, nil }`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "error before the provided value",
+			constructor:    `func NewService() (error, *Service) { return nil, &Service{} }`,
+			expectedBuilds: 0,
+		},
+		{
+			name:           "error between provided values",
+			constructor:    `func NewService() (*Service, error, int) { return &Service{}, nil, 0 }`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Service struct{}
+
+` + tt.constructor + `
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewService),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Constructors returning several errors or a non-last error are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			for _, diagnostic := range parser.diagnostics {
+				if !strings.Contains(diagnostic.Err.Error(), testFile+":11:") {
+					t.Errorf("Expected diagnostic %q to report the provider position", diagnostic.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestParsePopulate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		directive      string
+		expectedFields []string
+		expectedBuilds int
+	}{
+		{
+			name: "struct pointer",
+			directive: `var _ = kessoku.Populate[*Handler](
+	"PopulateHandler",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)`,
+			expectedBuilds: 1,
+			expectedFields: []string{"Config", "Service"},
+		},
+		{
+			name: "inject is not populate",
+			directive: `var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)`,
+			expectedBuilds: 1,
+		},
+		{
+			name: "struct value",
+			directive: `var _ = kessoku.Populate[Handler](
+	"PopulateHandler",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)`,
+			expectedBuilds: 0,
+		},
+		{
+			name: "must inject",
+			directive: `var _ = kessoku.Populate[*Handler](
+	"PopulateHandler",
+	kessoku.MustInject(),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)`,
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config { return &Config{} }
+
+type Service struct{}
+
+func NewService(config *Config) (*Service, error) { return &Service{}, nil }
+
+type Handler struct {
+	Service *Service
+	Config  *Config
+	name    string
+}
+
+` + tt.directive + `
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid targets are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			build := builds[0]
+			populate := build.Providers[len(build.Providers)-1]
+			if tt.expectedFields == nil {
+				if build.Return == nil || populate.Type == ProviderTypePopulate {
+					t.Error("Expected Inject not to be parsed as Populate")
+				}
+				return
+			}
+
+			if build.Return != nil {
+				t.Errorf("Expected no return type, got %s", build.Return.Type)
+			}
+			if len(build.Args) != 1 || build.Args[0].String() != "*command-line-arguments.Handler" {
+				t.Errorf("Expected the target to be the first argument, got %v", build.Args)
+			}
+			if populate.Type != ProviderTypePopulate {
+				t.Fatalf("Expected a populate provider, got %s", populate.Type)
+			}
+
+			fieldNames := make([]string, 0, len(populate.StructFields))
+			for _, field := range populate.StructFields {
+				fieldNames = append(fieldNames, field.Name)
+			}
+			if !slices.Equal(fieldNames, tt.expectedFields) {
+				t.Errorf("Expected fields %v, got %v", tt.expectedFields, fieldNames)
+			}
+			// The target is required first, followed by the field types
+			if len(populate.Requires) != len(tt.expectedFields)+1 {
+				t.Errorf("Expected %d requirements, got %d", len(tt.expectedFields)+1, len(populate.Requires))
+			}
+		})
+	}
+}
+
+func TestParseNil(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		nilType        string
+		expectedBuilds int
+	}{
+		{
+			name:           "interface",
+			nilType:        "Metrics",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "interface from another package",
+			nilType:        "io.Writer",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "pointer",
+			nilType:        "*Service",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"io"
+
+	"github.com/mazrean/kessoku"
+)
+
+type Metrics interface {
+	Record(name string)
+}
+
+type Service struct{}
+
+func NewService(metrics Metrics, w io.Writer) *Service { return &Service{} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Nil[` + tt.nilType + `](),
+	kessoku.Provide(NewService),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Nil of a non-interface type is reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if tt.expectedBuilds == 0 {
+				return
+			}
+
+			provider := builds[0].Providers[0]
+			if provider.Type != ProviderTypeNil {
+				t.Errorf("Expected provider type %s, got %s", ProviderTypeNil, provider.Type)
+			}
+			if len(provider.Provides) != 1 || !types.IsInterface(provider.Provides[0][0]) {
+				t.Errorf("Expected Nil to provide an interface, got %v", provider.Provides)
+			}
+			if len(provider.Requires) != 0 {
+				t.Errorf("Expected Nil to require nothing, got %v", provider.Requires)
+			}
+
+			// The provider expression is the interface type, which no longer references kessoku
+			if got := types.ExprString(provider.ASTExpr); got != tt.nilType {
+				t.Errorf("Expected type expression %s, got %s", tt.nilType, got)
+			}
+			if _, ok := provider.ReferencedImports[kessokuPkgPath]; ok {
+				t.Error("Expected Nil not to reference the kessoku import")
+			}
+		})
+	}
+}
+
+func TestParseTaggedProviders(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Storage struct{ Name string }
+
+func NewS3Storage() *Storage { return &Storage{Name: "s3"} }
+
+func NewGCSStorage() *Storage { return &Storage{Name: "gcs"} }
+
+type App struct{}
+
+func NewApp(storage *Storage) *App { return &App{} }
+
+var StorageSet = kessoku.Set(
+	kessoku.Provide(NewS3Storage, kessoku.Tag("s3")),
+	kessoku.Async(kessoku.Provide(NewGCSStorage, kessoku.Tag("gcs"))),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeS3App",
+	StorageSet,
+	kessoku.Use[*Storage]("s3"),
+	kessoku.Provide(NewApp),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeGCSApp",
+	StorageSet,
+	kessoku.Use[*Storage]("gcs"),
+	kessoku.Provide(NewApp),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeDuplicateUse",
+	StorageSet,
+	kessoku.Use[*Storage]("s3"),
+	kessoku.Use[*Storage]("gcs"),
+	kessoku.Provide(NewApp),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// The injector selecting two tags of the same type is reported and skipped
+	if len(builds) != 2 {
+		t.Fatalf("Expected 2 build directives, got %d", len(builds))
+	}
+
+	expectedTags := map[string]string{
+		"InitializeS3App":  "s3",
+		"InitializeGCSApp": "gcs",
+	}
+	for _, build := range builds {
+		expectedTag, ok := expectedTags[build.InjectorName]
+		if !ok {
+			t.Fatalf("Unexpected injector %s", build.InjectorName)
+		}
+
+		if len(build.Uses) != 1 {
+			t.Fatalf("%s: expected 1 Use, got %d", build.InjectorName, len(build.Uses))
+		}
+		if use := build.Uses[0]; use.Tag != expectedTag || !strings.HasSuffix(use.Type.String(), ".Storage") {
+			t.Errorf("%s: expected Use[*Storage](%q), got Use[%s](%q)", build.InjectorName, expectedTag, use.Type, use.Tag)
+		}
+
+		if got := build.Providers[0].Tag; got != "s3" {
+			t.Errorf("%s: expected first provider tagged s3, got %q", build.InjectorName, got)
+		}
+		if got := build.Providers[1].Tag; got != "gcs" {
+			t.Errorf("%s: expected async provider tagged gcs, got %q", build.InjectorName, got)
+		}
+		if got := build.Providers[2].Tag; got != "" {
+			t.Errorf("%s: expected untagged provider, got %q", build.InjectorName, got)
+		}
+	}
+}
+
+func TestParseGroup(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config { return &Config{} }
+
+type Cache struct{}
+
+func NewCache() *Cache { return &Cache{} }
+
+type App struct{}
+
+func NewApp(config *Config, cache *Cache) *App { return &App{} }
+
+var InfraSet = kessoku.Set(
+	kessoku.Group("infra"),
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewCache, kessoku.Group("cache"))),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	InfraSet,
+	kessoku.Provide(NewApp),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeInvalidApp",
+	kessoku.Group("infra"),
+	InfraSet,
+	kessoku.Provide(NewApp),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewParser()
+	_, builds, err := parser.ParseFile(testFile, NewVarPool())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// Group outside of Provide and Set is reported and the injector is skipped
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build directive, got %d", len(builds))
+	}
+
+	// The group of the set does not override the group of a provider
+	expectedGroups := []string{"infra", "cache", ""}
+	if len(builds[0].Providers) != len(expectedGroups) {
+		t.Fatalf("Expected %d providers, got %d", len(expectedGroups), len(builds[0].Providers))
+	}
+	for i, provider := range builds[0].Providers {
+		if provider.Group != expectedGroups[i] {
+			t.Errorf("Provider %d: expected group %q, got %q", i, expectedGroups[i], provider.Group)
+		}
+	}
+}
+
+func TestParseCost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		option         string
+		expectedCost   int
+		expectedBuilds int
+	}{
+		{
+			name:           "without cost",
+			option:         "",
+			expectedCost:   0,
+			expectedBuilds: 1,
+		},
+		{
+			name:           "constant cost",
+			option:         ", kessoku.Cost(200)",
+			expectedCost:   200,
+			expectedBuilds: 1,
+		},
+		{
+			name:           "zero cost",
+			option:         ", kessoku.Cost(0)",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "non-constant cost",
+			option:         ", kessoku.Cost(len(os.Args))",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"os"
+
+	"github.com/mazrean/kessoku"
+)
+
+var _ = os.Args
+
+type Service struct{}
+
+func NewService() *Service { return &Service{} }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Async(kessoku.Provide(NewService` + tt.option + `)),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid costs are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if len(builds) == 0 {
+				return
+			}
+
+			if cost := builds[0].Providers[0].Cost; cost != tt.expectedCost {
+				t.Errorf("Expected cost %d, got %d", tt.expectedCost, cost)
+			}
+		})
+	}
+}
+
+func TestParseReadyCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		option         string
+		expectedChecks []string
+		expectedBuilds int
+	}{
+		{
+			name:           "without ready check",
+			option:         "",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "method expression",
+			option:         ", kessoku.WithReadyCheck((*Service).Ping)",
+			expectedChecks: []string{"*Service"},
+			expectedBuilds: 1,
+		},
+		{
+			name:           "bound interface",
+			option:         ", kessoku.WithReadyCheck(func(p Pinger, ctx context.Context) error { return p.Ping(ctx) })",
+			expectedChecks: []string{"Pinger"},
+			expectedBuilds: 1,
+		},
+		{
+			name:           "type not provided",
+			option:         ", kessoku.WithReadyCheck(func(s string, ctx context.Context) error { return nil })",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import (
+	"context"
+
+	"github.com/mazrean/kessoku"
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type Service struct{}
+
+func NewService() *Service { return &Service{} }
+
+func (s *Service) Ping(ctx context.Context) error { return nil }
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Bind[Pinger](kessoku.Provide(NewService` + tt.option + `)),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Ready checks of types the provider does not provide are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if len(builds) == 0 {
+				return
+			}
+
+			provider := builds[0].Providers[0]
+			var checks []string
+			for _, check := range provider.ReadyChecks {
+				checks = append(checks, types.TypeString(check.Type, func(*types.Package) string { return "" }))
+
+				// The provider refers to the check through the variable the injector binds it to
+				found := false
+				ast.Inspect(provider.ASTExpr, func(n ast.Node) bool {
+					found = found || n == check.ident
+					return !found
+				})
+				if !found {
+					t.Errorf("Expected the provider expression %s to refer to the bound ready check", types.ExprString(provider.ASTExpr))
+				}
+			}
+			if !slices.Equal(checks, tt.expectedChecks) {
+				t.Errorf("Expected ready checks of %v, got %v", tt.expectedChecks, checks)
+			}
+			if len(checks) > 0 && strings.Contains(types.ExprString(provider.ASTExpr), "WithReadyCheck") {
+				t.Errorf("Expected the ready check to be evaluated outside the provider expression, got %s", types.ExprString(provider.ASTExpr))
+			}
+		})
+	}
+}
+
+func TestParseLazyInjector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        string
+		expectedBuilds int
+	}{
+		{
+			name:           "without arguments",
+			options:        "kessoku.LazyInjector(),",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "with argument",
+			options:        "kessoku.LazyInjector(), kessoku.Arg[string](),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "with named argument",
+			options:        "kessoku.LazyInjector(), kessoku.Named[string](\"dsn\"),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "with command arguments",
+			options:        "kessoku.LazyInjector(), kessoku.Args(),",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DB struct{}
+
+func OpenDB() *DB { return &DB{} }
+
+var _ = kessoku.Inject[*DB](
+	"GetDB",
+	` + tt.options + `
+	kessoku.Provide(OpenDB),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Only the first call of a lazy getter would use its arguments, so the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+		})
+	}
+}
+
+func TestParseShared(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        string
+		expectedShared string
+		expectedBuilds int
+	}{
+		{
+			name:           "exported name",
+			options:        "kessoku.LazyInjector(), kessoku.Shared(\"SharedDB\"),",
+			expectedShared: "SharedDB",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "package-private name",
+			options:        "kessoku.LazyInjector(), kessoku.Shared(\"dbPool\"),",
+			expectedShared: "dbPool",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "without lazy injector",
+			options:        "kessoku.Shared(\"dbPool\"),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "declared name",
+			options:        "kessoku.LazyInjector(), kessoku.Shared(\"defaultDSN\"),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "invalid name",
+			options:        "kessoku.LazyInjector(), kessoku.Shared(\"db-pool\"),",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DB struct{}
+
+const defaultDSN = "postgres://localhost"
+
+func OpenDB() *DB { return &DB{} }
+
+var _ = kessoku.Inject[*DB](
+	"GetDB",
+	` + tt.options + `
+	kessoku.Provide(OpenDB),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid shared variable names are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if len(builds) == 0 {
+				return
+			}
+
+			if builds[0].SharedName != tt.expectedShared {
+				t.Errorf("Expected shared variable %s, got %s", tt.expectedShared, builds[0].SharedName)
+			}
+		})
+	}
+}
+
+func TestParsePanicMsg(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        string
+		expectedFormat string
+		expectedBuilds int
+	}{
+		{
+			name:           "wrapped error",
+			options:        "kessoku.MustInject(), kessoku.PanicMsg(\"failed to open db: %w\"),",
+			expectedFormat: "failed to open db: %w",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "formatted error with flags",
+			options:        "kessoku.MustInject(), kessoku.PanicMsg(\"100%% failed: %+v\"),",
+			expectedFormat: "100%% failed: %+v",
+			expectedBuilds: 1,
+		},
+		{
+			name:           "without verb",
+			options:        "kessoku.MustInject(), kessoku.PanicMsg(\"failed to open db\"),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "non-error verb",
+			options:        "kessoku.MustInject(), kessoku.PanicMsg(\"failed to open db: %d\"),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "multiple verbs",
+			options:        "kessoku.MustInject(), kessoku.PanicMsg(\"%s: %w\"),",
+			expectedBuilds: 0,
+		},
+		{
+			name:           "without must injector",
+			options:        "kessoku.PanicMsg(\"failed to open db: %w\"),",
+			expectedBuilds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DB struct{}
+
+func OpenDB() (*DB, error) { return &DB{}, nil }
+
+var _ = kessoku.Inject[*DB](
+	"GetDB",
+	` + tt.options + `
+	kessoku.Provide(OpenDB),
+)
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// Invalid panic messages are reported and the injector is skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if len(builds) == 0 {
+				return
+			}
+
+			if builds[0].PanicFormat != tt.expectedFormat {
+				t.Errorf("Expected panic format %q, got %q", tt.expectedFormat, builds[0].PanicFormat)
+			}
+		})
+	}
+}
+
+func TestDefaultLiteral(t *testing.T) {
+	t.Parallel()
+
+	named := types.NewNamed(types.NewTypeName(0, nil, "Region", nil), types.Typ[types.String], nil)
+
+	tests := []struct {
+		name          string
+		typ           types.Type
+		value         string
+		expected      string
+		expectedError string
+	}{
+		{name: "string", typ: types.Typ[types.String], value: ":8080", expected: `":8080"`},
+		{name: "named string", typ: named, value: "us-east-1", expected: `"us-east-1"`},
+		{name: "int", typ: types.Typ[types.Int], value: "-4", expected: "-4"},
+		{name: "hex uint8", typ: types.Typ[types.Uint8], value: "0x10", expected: "16"},
+		{name: "bool", typ: types.Typ[types.Bool], value: "true", expected: "true"},
+		{name: "float", typ: types.Typ[types.Float64], value: "0.5", expected: "0.5"},
+		{name: "invalid int", typ: types.Typ[types.Int], value: "four", expectedError: `invalid int default "four"`},
+		{name: "int8 overflow", typ: types.Typ[types.Int8], value: "128", expectedError: `invalid int8 default "128"`},
+		{name: "invalid bool", typ: types.Typ[types.Bool], value: "yes", expectedError: `invalid bool default "yes"`},
+		{name: "unsupported type", typ: types.NewPointer(types.Typ[types.Int]), value: "1", expectedError: "default tag is not supported for *int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := defaultLiteral(tt.typ, tt.value)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("defaultLiteral failed: %v", err)
+			}
+
+			if got := types.ExprString(expr); got != tt.expected {
+				t.Errorf("Expected literal %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseInjectorFactories(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		injectors       string
+		expectedBuilds  int
+		expectedFactory string
+		returnsError    bool
+	}{
+		{
+			name: "factory returning an error",
+			injectors: `var _ = kessoku.Inject[*Handler]("InitializeHandler", kessoku.Provide(NewHandler))
+var _ = kessoku.Inject[*Session]("InitializeSession", kessoku.Provide(NewSession))`,
+			expectedBuilds:  2,
+			expectedFactory: "InitializeSession",
+			returnsError:    true,
+		},
+		{
+			name: "factory provided explicitly",
+			injectors: `var _ = kessoku.Inject[*Handler]("InitializeHandler", kessoku.Provide(NewHandler), kessoku.Value(func() (*Session, error) { return nil, nil }))
+var _ = kessoku.Inject[*Session]("InitializeSession", kessoku.Provide(NewSession))`,
+			expectedBuilds: 2,
+		},
+		{
+			name: "injector taking arguments",
+			injectors: `var _ = kessoku.Inject[*Handler]("InitializeHandler", kessoku.Provide(NewHandler))
+var _ = kessoku.Inject[*Session]("InitializeSession", kessoku.Arg[string](), kessoku.Provide(NewSession))`,
+			expectedBuilds: 2,
+		},
+		{
+			name: "several matching injectors",
+			injectors: `var _ = kessoku.Inject[*Handler]("InitializeHandler", kessoku.Provide(NewHandler))
+var _ = kessoku.Inject[*Session]("InitializeSession", kessoku.Provide(NewSession))
+var _ = kessoku.Inject[*Session]("InitializeOtherSession", kessoku.Provide(NewSession))`,
+			expectedBuilds: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Session struct{}
+
+func NewSession() *Session { return &Session{} }
+
+type Handler struct{}
+
+func NewHandler(newSession func() (*Session, error)) *Handler { return &Handler{} }
+
+` + tt.injectors + `
+`
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			parser := NewParser()
+			_, builds, err := parser.ParseFile(testFile, NewVarPool())
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			// An injector with a parameter matching several injectors is reported and skipped
+			if len(builds) != tt.expectedBuilds {
+				t.Fatalf("Expected %d build directives, got %d", tt.expectedBuilds, len(builds))
+			}
+			if builds[0].InjectorName != "InitializeHandler" {
+				return
+			}
+
+			var factories []*ProviderSpec
+			for _, provider := range builds[0].Providers {
+				if provider.Type == ProviderTypeFactory {
+					factories = append(factories, provider)
+				}
+			}
+			if tt.expectedFactory == "" {
+				if len(factories) != 0 {
+					t.Errorf("Expected no factory providers, got %d", len(factories))
+				}
+				return
+			}
+
+			if len(factories) != 1 {
+				t.Fatalf("Expected 1 factory provider, got %d", len(factories))
+			}
+			factory := factories[0]
+			if factory.Factory.Injector != tt.expectedFactory {
+				t.Errorf("Expected factory calling %s, got %s", tt.expectedFactory, factory.Factory.Injector)
+			}
+			if factory.Factory.ReturnsError != tt.returnsError {
+				t.Errorf("Expected ReturnsError %v, got %v", tt.returnsError, factory.Factory.ReturnsError)
+			}
+			if _, ok := factory.Provides[0][0].(*types.Signature); !ok {
+				t.Errorf("Expected factory to provide a function, got %s", factory.Provides[0][0])
+			}
+			if got := types.ExprString(factory.Factory.ReturnType); got != "*Session" {
+				t.Errorf("Expected return type *Session, got %s", got)
+			}
+		})
+	}
+}
@@ -1,85 +1,844 @@
 package kessoku
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrGeneratedCodeOutdated is returned in diff mode when the generated code differs from the files on disk.
+var ErrGeneratedCodeOutdated = errors.New("generated code is not up to date")
+
 // Processor handles the overall dependency injection code generation process.
 type Processor struct {
-	parser  *Parser
-	varPool *VarPool
+	output         io.Writer
+	diffOutput     io.Writer
+	reportOutput   io.Writer
+	describeOutput io.Writer
+	parser         *Parser
+	varPool        *VarPool
+	cache          *generationCache
+	stamp          *VersionStamp
+	localPrefix    string
+	outputSuffix   string
+	reportFormat   ReportFormat
+	formatter      []string
+	metrics        []*GraphMetrics
+	descriptions   []*InjectorDescription
+	maxNodes       int
+	asyncThreshold int
+	warnUnusedArgs bool
+	emitRegistry   bool
+	emitInspector  bool
+	namedReturns   bool
+	skipBroken     bool
+	disableAsync   bool
+	strictTypes    bool
+	hasDiff        bool
+}
+
+// ProcessorOption configures a Processor.
+type ProcessorOption func(*Processor)
+
+// WithTypeVarNames overrides the variable names generated for the given types.
+// Keys are type strings as printed by go/types (e.g. "*database/sql.DB").
+func WithTypeVarNames(typeNames map[string]string) ProcessorOption {
+	return func(p *Processor) {
+		p.varPool.SetTypeNames(typeNames)
+	}
+}
+
+// WithBuildTags loads the packages of the injector files with the build tags, e.g. to use providers
+// declared in files with a //go:build constraint.
+func WithBuildTags(tags ...string) ProcessorOption {
+	return func(p *Processor) {
+		p.parser.buildTags = tags
+	}
+}
+
+// WithImportAliases names the imports of the generated code after aliases keyed by package path,
+// e.g. "database/sql" to "stdsql", instead of after the packages.
+func WithImportAliases(aliases map[string]string) ProcessorOption {
+	return func(p *Processor) {
+		p.parser.importAliases = aliases
+	}
+}
+
+// WithOutputSuffix names the generated files after the injector files with suffix instead of _band,
+// e.g. kessoku_gen.go and kessoku_gen_test.go for kessoku.go with _gen.
+func WithOutputSuffix(suffix string) ProcessorOption {
+	return func(p *Processor) {
+		p.outputSuffix = suffix
+	}
+}
+
+// WithStrictTypes matches the types of every injector by types.Identical, as if each declared
+// kessoku.StrictTypeIdentity.
+func WithStrictTypes() ProcessorOption {
+	return func(p *Processor) {
+		p.strictTypes = true
+	}
+}
+
+// WithUnusedArgWarnings logs a warning for every injector argument that ends up unreferenced.
+func WithUnusedArgWarnings() ProcessorOption {
+	return func(p *Processor) {
+		p.warnUnusedArgs = true
+	}
+}
+
+// WithLocalImportPrefix groups generated imports under prefix as local imports
+// instead of the module path of the processed package.
+func WithLocalImportPrefix(prefix string) ProcessorOption {
+	return func(p *Processor) {
+		p.localPrefix = prefix
+	}
+}
+
+// WithRegistry additionally writes a registry of the generated injectors for each package.
+func WithRegistry() ProcessorOption {
+	return func(p *Processor) {
+		p.emitRegistry = true
+	}
+}
+
+// WithInspector additionally generates, for each injector, a variant returning a struct of every value
+// it constructs in place of its result, to inspect the graph when debugging.
+func WithInspector() ProcessorOption {
+	return func(p *Processor) {
+		p.emitInspector = true
+	}
+}
+
+// WithNamedReturns names the results in the signatures of the generated injectors after their types,
+// e.g. func InitializeApp() (app *App, err error), to document what each result is.
+func WithNamedReturns() ProcessorOption {
+	return func(p *Processor) {
+		p.namedReturns = true
+	}
+}
+
+// WithVersionStamp stamps the generated files with version below their header, followed by a hash of
+// the generated code, or by the generation time if withTime is set, to correlate generated code with
+// the kessoku build that produced it.
+func WithVersionStamp(version string, withTime bool) ProcessorOption {
+	return func(p *Processor) {
+		p.stamp = &VersionStamp{Version: version}
+		if withTime {
+			p.stamp.Time = time.Now()
+		}
+	}
+}
+
+// WithSkipBrokenFiles makes ProcessFiles log and skip files with syntax errors instead of failing,
+// so that one unparseable file does not block generation for the others. Other errors still fail.
+// The skipped files are listed after all files are processed.
+func WithSkipBrokenFiles() ProcessorOption {
+	return func(p *Processor) {
+		p.skipBroken = true
+	}
+}
+
+// WithoutAsync generates providers marked with kessoku.Async sequentially,
+// which helps when debugging concurrency issues or when goroutines are not worth their overhead.
+func WithoutAsync() ProcessorOption {
+	return func(p *Processor) {
+		p.disableAsync = true
+	}
+}
+
+// WithAsyncThreshold generates injectors with fewer than n providers sequentially even if
+// providers are marked with kessoku.Async, since errgroup is not worth its overhead in small graphs.
+// The context.Context argument of async providers is kept. A non-positive n disables the threshold.
+func WithAsyncThreshold(n int) ProcessorOption {
+	return func(p *Processor) {
+		p.asyncThreshold = n
+	}
+}
+
+// WithDiff writes a unified diff of the generated code against the files on disk to w
+// instead of overwriting them. ProcessFiles then returns ErrGeneratedCodeOutdated if any file differs.
+func WithDiff(w io.Writer) ProcessorOption {
+	return func(p *Processor) {
+		p.diffOutput = w
+	}
+}
+
+// WithOutput writes the generated code to w instead of the _band.go files, e.g. to print it to stdout.
+// Existing files are neither read nor removed. Files declaring both regular and ForTest injectors are rejected,
+// since their code belongs to two files.
+func WithOutput(w io.Writer) ProcessorOption {
+	return func(p *Processor) {
+		p.output = w
+	}
+}
+
+// WithReport writes the complexity metrics of every injector graph to w in the given format
+// after all files are processed.
+func WithReport(w io.Writer, format ReportFormat) ProcessorOption {
+	return func(p *Processor) {
+		p.reportOutput = w
+		p.reportFormat = format
+	}
+}
+
+// WithDescribe writes the name and type of the arguments of every generated injector to w
+// as JSON after all files are processed.
+func WithDescribe(w io.Writer) ProcessorOption {
+	return func(p *Processor) {
+		p.describeOutput = w
+	}
+}
+
+// WithMaxNodes makes ProcessFiles fail with ErrGraphBudgetExceeded
+// when an injector graph has more than n nodes. A non-positive n disables the check.
+func WithMaxNodes(n int) ProcessorOption {
+	return func(p *Processor) {
+		p.maxNodes = n
+	}
+}
+
+// WithFormatter pipes the generated code through the external command name with args,
+// such as gofumpt, before writing it. The command reads the code from stdin and prints the result to stdout.
+func WithFormatter(name string, args ...string) ProcessorOption {
+	return func(p *Processor) {
+		p.formatter = append([]string{name}, args...)
+	}
+}
+
+// WithCache skips generation when the files, the packages of the module they depend on, and
+// go.mod and go.sum are unchanged since the last run and the generated files are intact.
+// The hashes are recorded under dir, such as the Go build cache; a change of version,
+// e.g. of kessoku, invalidates them. The cache is not used with WithRegistry, WithDiff,
+// WithOutput, WithReport, or WithDescribe, whose runs need every injector.
+func WithCache(dir, version string) ProcessorOption {
+	return func(p *Processor) {
+		p.cache = &generationCache{dir: dir, version: version}
+	}
 }
 
 // NewProcessor creates a new processor instance.
-func NewProcessor() *Processor {
-	return &Processor{
-		parser:  NewParser(),
-		varPool: NewVarPool(),
+func NewProcessor(opts ...ProcessorOption) *Processor {
+	p := &Processor{
+		parser:       NewParser(),
+		varPool:      NewVarPool(),
+		outputSuffix: defaultOutputSuffix,
+	}
+
+	for _, opt := range opts {
+		opt(p)
 	}
+
+	return p
 }
 
 // ProcessFiles processes specified Go files for wire generation.
 func (p *Processor) ProcessFiles(files []string) error {
+	var cacheRun *cacheRun
+	if p.useCache() && len(files) > 0 {
+		run, fresh, err := p.cache.lookup(files, p.fingerprint(), p.outputSuffix)
+		if err != nil {
+			return fmt.Errorf("look up generation cache: %w", err)
+		}
+		if fresh {
+			slog.Info("Skipping generation of unchanged files", "files", files)
+			return nil
+		}
+		cacheRun = run
+	}
+
+	var (
+		registryDirs []string
+		registries   = make(map[string]*registry)
+	)
+	var skipped []string
 	for _, filename := range files {
-		if err := p.processFile(filename); err != nil {
+		pkgName, injectors, err := p.processFile(filename)
+		if p.skipBroken && isSyntaxError(err) {
+			slog.Error("Skipping file with syntax errors", "file", filename, "error", err)
+			skipped = append(skipped, filename)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if !p.emitRegistry || len(injectors) == 0 {
+			continue
+		}
+
+		dir := filepath.Dir(filename)
+		r, ok := registries[dir]
+		if !ok {
+			r = &registry{pkgName: pkgName}
+			registries[dir] = r
+			registryDirs = append(registryDirs, dir)
+		}
+		r.injectors = append(r.injectors, injectors...)
+	}
+
+	for _, dir := range registryDirs {
+		if err := p.writeRegistry(filepath.Join(dir, registryFileName(p.outputSuffix)), registries[dir]); err != nil {
 			return err
 		}
 	}
+
+	if p.reportOutput != nil {
+		if err := writeReport(p.reportOutput, p.reportFormat, p.metrics); err != nil {
+			return err
+		}
+	}
+
+	if p.describeOutput != nil {
+		if err := writeDescriptions(p.describeOutput, p.descriptions); err != nil {
+			return err
+		}
+	}
+
+	if p.hasDiff {
+		return ErrGeneratedCodeOutdated
+	}
+
+	if len(skipped) > 0 {
+		slog.Warn("Skipped files with syntax errors", "count", len(skipped), "files", skipped)
+
+		// The skipped files are reported again by the next run instead of being cached as up to date
+		return nil
+	}
+
+	if cacheRun != nil {
+		if err := p.cache.store(cacheRun, p.outputFiles(files)); err != nil {
+			// Failing to cache only costs a regeneration next time
+			slog.Warn("Failed to update generation cache", "error", err)
+		}
+	}
+
 	return nil
 }
 
-// processFile processes a single Go file for wire generation.
-func (p *Processor) processFile(filename string) error {
-	slog.Debug("Processing file", "file", filename)
+// isSyntaxError reports whether err is caused by a file that cannot be parsed,
+// as opposed to an error in the wiring of its injectors.
+func isSyntaxError(err error) bool {
+	var syntaxErr scanner.ErrorList
+	return errors.As(err, &syntaxErr)
+}
+
+// useCache reports whether runs may be skipped by the generation cache.
+func (p *Processor) useCache() bool {
+	return p.cache != nil && !p.emitRegistry && p.diffOutput == nil && p.output == nil && p.reportOutput == nil && p.describeOutput == nil
+}
+
+// fingerprint identifies the options that change the generated code or whether generation succeeds.
+func (p *Processor) fingerprint() string {
+	var stamp string
+	if p.stamp != nil {
+		// The time differs on every run, so only whether it is stamped is part of the fingerprint
+		stamp = fmt.Sprint(p.stamp.Version, !p.stamp.Time.IsZero())
+	}
+
+	return fmt.Sprint(p.varPool.typeNames, p.localPrefix, p.formatter, p.asyncThreshold, p.maxNodes, p.disableAsync, p.emitInspector, p.namedReturns, stamp,
+		p.parser.buildTags, p.parser.importAliases, p.outputSuffix, p.strictTypes)
+}
+
+// Diagnostic is a wiring problem found by ValidateFiles.
+type Diagnostic struct {
+	Err      error
+	Injector string
+	Pos      token.Position
+}
 
+func (d *Diagnostic) Error() string {
+	var sb strings.Builder
+	if d.Pos.Filename != "" || d.Pos.IsValid() {
+		// token.Position prints just the file name when the line is unknown
+		sb.WriteString(d.Pos.String())
+		sb.WriteString(": ")
+	}
+	if d.Injector != "" {
+		sb.WriteString("injector ")
+		sb.WriteString(d.Injector)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(d.Err.Error())
+
+	return sb.String()
+}
+
+func (d *Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// ValidateFiles parses the specified files and builds the graph of every injector
+// without writing any output, returning all the problems found instead of stopping at the first.
+func (p *Processor) ValidateFiles(files []string) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	for _, filename := range files {
+		diagnostics = append(diagnostics, p.validateFile(filename)...)
+	}
+
+	return diagnostics
+}
+
+// parseFile parses the injectors of filename, also returning the injectors skipped because they failed to parse.
+func (p *Processor) parseFile(filename string) (*MetaData, []*BuildDirective, []*Diagnostic, error) {
+	p.parser.diagnostics = nil
 	metaData, builds, err := p.parser.ParseFile(filename, p.varPool)
+	diagnostics := p.parser.diagnostics
+	p.parser.diagnostics = nil
 	if err != nil {
-		return fmt.Errorf("parse file %s: %w", filename, err)
+		return nil, nil, diagnostics, err
 	}
 
-	if len(builds) == 0 {
+	if p.strictTypes {
+		for _, build := range builds {
+			build.StrictTypes = true
+		}
+	}
+
+	return metaData, builds, diagnostics, nil
+}
+
+func (p *Processor) validateFile(filename string) []*Diagnostic {
+	metaData, builds, diagnostics, err := p.parseFile(filename)
+	if err != nil {
+		return append(diagnostics, &Diagnostic{Pos: token.Position{Filename: filename}, Err: err})
+	}
+
+	// Every injector is created before any is generated, so that factories can be linked to the injectors they call
+	injectors := make([]*Injector, len(builds))
+	injectorsByName := make(map[string]*Injector, len(builds))
+	for i, build := range builds {
+		injector, err := CreateInjector(metaData, build, p.varPool, p.disableAsync, p.asyncThreshold)
+		if err != nil {
+			suggestProviders(metaData, err, p.outputSuffix)
+			diagnostics = append(diagnostics, &Diagnostic{Pos: build.Pos, Injector: build.InjectorName, Err: err})
+			continue
+		}
+		injectors[i] = injector
+		injectorsByName[injector.Name] = injector
+	}
+
+	for i, build := range builds {
+		if injectors[i] == nil {
+			continue
+		}
+
+		err := linkFactories(build, injectorsByName)
+		if err == nil {
+			_, err = generateInjectorDecl(metaData, injectors[i], p.varPool)
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, &Diagnostic{Pos: build.Pos, Injector: build.InjectorName, Err: err})
+		}
+	}
+
+	return diagnostics
+}
+
+// suggestProviders fills the suggestions of a MissingProviderError in err with the package-level
+// functions of the injector's package whose first result is the missing type, or implements it
+// if it is an interface.
+func suggestProviders(metaData *MetaData, err error, outputSuffix string) {
+	var missing *MissingProviderError
+	if !errors.As(err, &missing) || metaData.pkg == nil || metaData.pkg.Types == nil {
+		return
+	}
+
+	kessokuName := "kessoku"
+	if imp, ok := metaData.Imports[kessokuPkgPath]; ok {
+		kessokuName = imp.Name
+	}
+	iface, isInterface := missing.Type.Underlying().(*types.Interface)
+
+	scope := metaData.pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		fn, ok := scope.Lookup(name).(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Signature()
+		if sig.TypeParams().Len() > 0 || sig.Results().Len() == 0 {
+			continue
+		}
+		// Generated injectors return the type as well, but they are not constructors
+		if isGeneratedOutput(filepath.Base(metaData.pkg.Fset.Position(fn.Pos()).Filename), outputSuffix) {
+			continue
+		}
+
+		result := sig.Results().At(0).Type()
+		switch {
+		case types.Identical(result, missing.Type):
+			missing.Suggestions = append(missing.Suggestions, fmt.Sprintf("%s.Provide(%s)", kessokuName, name))
+		case isInterface && !types.IsInterface(result) && types.Implements(result, iface):
+			typeName := types.TypeString(missing.Type, types.RelativeTo(metaData.pkg.Types))
+			missing.Suggestions = append(missing.Suggestions, fmt.Sprintf("%s.Bind[%s](%s.Provide(%s))", kessokuName, typeName, kessokuName, name))
+		}
+	}
+}
+
+// registry collects the injectors generated for a single package.
+type registry struct {
+	pkgName   string
+	injectors []*Injector
+}
+
+func (p *Processor) writeRegistry(filename string, r *registry) error {
+	var buf bytes.Buffer
+	if genErr := GenerateRegistry(&buf, r.pkgName, r.injectors); genErr != nil {
+		return fmt.Errorf("generate registry: %w", genErr)
+	}
+
+	return p.writeOutput(filename, buf.Bytes())
+}
+
+// writeOutput writes generated code to filename, or its diff against filename in diff mode.
+func (p *Processor) writeOutput(filename string, content []byte) error {
+	content, err := p.format(filename, content)
+	if err != nil {
+		return err
+	}
+
+	if p.output != nil {
+		if _, err := p.output.Write(content); err != nil {
+			return fmt.Errorf("write generated code of %s: %w", filename, err)
+		}
 		return nil
 	}
 
-	slog.Info("Found inject directives", "file", filename, "count", len(builds))
+	if p.diffOutput == nil {
+		if err := os.WriteFile(filename, content, 0644); err != nil {
+			return fmt.Errorf("write file %s: %w", filename, err)
+		}
+		return nil
+	}
+
+	current, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read file %s: %w", filename, err)
+	}
+
+	diff := unifiedDiff(filename, filename, current, content)
+	if diff == "" {
+		return nil
+	}
+	p.hasDiff = true
+
+	if _, err := io.WriteString(p.diffOutput, diff); err != nil {
+		return fmt.Errorf("write diff of %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// format pipes the generated code of filename through the external formatter, if any.
+// The command runs in the directory of filename so that it picks up the project configuration.
+func (p *Processor) format(filename string, content []byte) ([]byte, error) {
+	if len(p.formatter) == 0 {
+		return content, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.formatter[0], p.formatter[1:]...)
+	cmd.Dir = filepath.Dir(filename)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("format %s with %s: %w: %s", filename, p.formatter[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
 
-	outputFileName := outputFileName(filename)
+// processFile processes a single Go file for wire generation.
+// It returns the package name and the injectors generated for the file.
+func (p *Processor) processFile(filename string) (string, []*Injector, error) {
+	slog.Debug("Processing file", "file", filename)
+
+	metaData, builds, diagnostics, err := p.parseFile(filename)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse file %s: %w", filename, err)
+	}
+
+	// Generating the rest would drop the functions of the skipped injectors, so the previous output is kept
+	if len(diagnostics) > 0 {
+		errs := make([]error, 0, len(diagnostics))
+		for _, diagnostic := range diagnostics {
+			errs = append(errs, diagnostic)
+		}
+		return "", nil, fmt.Errorf("parse injectors of %s: %w", filename, errors.Join(errs...))
+	}
+
+	outputFileName := outputFileName(filename, p.outputSuffix)
 	slog.Debug("outputFileName", "outputFileName", outputFileName)
 
-	injectors := make([]*Injector, 0, len(builds))
+	if len(builds) == 0 {
+		// The file has no Inject calls anymore, so nothing generated from it is current
+		if removeErr := p.removeStaleOutput(outputFileName); removeErr != nil {
+			return "", nil, removeErr
+		}
+		if removeErr := p.removeStaleOutput(testOutputFileName(filename, p.outputSuffix)); removeErr != nil {
+			return "", nil, removeErr
+		}
+		return "", nil, nil
+	}
+
+	if p.localPrefix != "" {
+		metaData.LocalPrefix = p.localPrefix
+	}
+	metaData.Stamp = p.stamp
+
+	slog.Info("Found inject directives", "file", filename, "count", len(builds))
+
+	var injectors, testInjectors, created []*Injector
 	for _, build := range builds {
-		injector, injectorErr := CreateInjector(metaData, build, p.varPool)
+		injector, injectorErr := CreateInjector(metaData, build, p.varPool, p.disableAsync, p.asyncThreshold)
 		if injectorErr != nil {
-			return fmt.Errorf("create injector: %w", injectorErr)
+			suggestProviders(metaData, injectorErr, p.outputSuffix)
+			return "", nil, fmt.Errorf("create injector: %w", injectorErr)
 		}
 
+		if p.maxNodes > 0 && injector.Metrics.Nodes > p.maxNodes {
+			return "", nil, fmt.Errorf("%w: injector %s has %d nodes, more than %d", ErrGraphBudgetExceeded, injector.Name, injector.Metrics.Nodes, p.maxNodes)
+		}
+		injector.Inspector = p.emitInspector
+		injector.NamedReturns = p.namedReturns
+		p.metrics = append(p.metrics, injector.Metrics)
+		created = append(created, injector)
+
+		if p.warnUnusedArgs {
+			for _, arg := range injector.UnusedArgs() {
+				slog.Warn("Injector argument is not used by any provider", "injector", injector.Name, "type", arg.Type.String())
+			}
+		}
+
+		if injector.ForTest {
+			testInjectors = append(testInjectors, injector)
+			continue
+		}
 		injectors = append(injectors, injector)
 	}
 
-	slog.Debug("injectors", "injectors", injectors)
+	createdByName := make(map[string]*Injector, len(created))
+	for _, injector := range created {
+		createdByName[injector.Name] = injector
+	}
+	for _, build := range builds {
+		if err := linkFactories(build, createdByName); err != nil {
+			return "", nil, fmt.Errorf("create injector %s: %w", build.InjectorName, err)
+		}
+	}
 
-	f, err := os.Create(outputFileName)
-	if err != nil {
-		return fmt.Errorf("create file %s: %w", outputFileName, err)
+	slog.Debug("injectors", "injectors", injectors, "testInjectors", testInjectors)
+
+	// The code of both files written to a single output would not be a valid Go file
+	if p.output != nil && !isTestFile(filename) && len(injectors) > 0 && len(testInjectors) > 0 {
+		return "", nil, fmt.Errorf("%s declares both regular and ForTest injectors, which cannot be written to a single output", filename)
 	}
-	defer func() {
-		if closeErr := f.Close(); closeErr != nil {
-			slog.Error("Failed to close file", "error", closeErr)
+
+	// Every injector of a test file is a test injector, and no regular output may be written from it
+	if !isTestFile(filename) {
+		if err := p.generateOutput(filename, outputFileName, metaData, injectors); err != nil {
+			return "", nil, err
 		}
-	}()
+	}
+	if err := p.generateOutput(filename, testOutputFileName(filename, p.outputSuffix), metaData, testInjectors); err != nil {
+		return "", nil, err
+	}
+
+	if p.describeOutput != nil {
+		for _, injector := range created {
+			p.descriptions = append(p.descriptions, describeInjector(metaData, injector, p.varPool))
+		}
+	}
+
+	// Test injectors are only visible to tests, so they are left out of the registry
+	return metaData.Package.Name, injectors, nil
+}
 
-	if genErr := Generate(f, filename, metaData, injectors, p.varPool); genErr != nil {
+// generateOutput writes the code of injectors generated from filename to outputFileName,
+// or removes the previously generated file if there are none.
+func (p *Processor) generateOutput(filename, outputFileName string, metaData *MetaData, injectors []*Injector) error {
+	if len(injectors) == 0 {
+		return p.removeStaleOutput(outputFileName)
+	}
+
+	// The generator marks the imports it uses, so the marks of another output file must not leak into this one
+	for _, imp := range metaData.Imports {
+		imp.IsUsed = false
+	}
+
+	var buf bytes.Buffer
+	if genErr := Generate(&buf, filename, metaData, injectors, p.varPool); genErr != nil {
 		return fmt.Errorf("generate: %w", genErr)
 	}
 
+	if p.output == nil {
+		if staleErr := warnStaleFuncs(outputFileName, buf.Bytes()); staleErr != nil {
+			return staleErr
+		}
+	}
+
+	return p.writeOutput(outputFileName, buf.Bytes())
+}
+
+// removeStaleOutput removes a previously generated file whose source no longer declares any injectors.
+// Files without the generated header are left alone. In diff mode, the removal is reported as a diff instead.
+func (p *Processor) removeStaleOutput(filename string) error {
+	if p.output != nil {
+		return nil
+	}
+
+	current, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read file %s: %w", filename, err)
+	}
+	if !bytes.HasPrefix(current, []byte(generatedHeader)) {
+		return nil
+	}
+
+	if p.diffOutput != nil {
+		p.hasDiff = true
+		if _, err := io.WriteString(p.diffOutput, unifiedDiff(filename, filename, current, nil)); err != nil {
+			return fmt.Errorf("write diff of %s: %w", filename, err)
+		}
+		return nil
+	}
+
+	slog.Warn("Removing stale generated file", "file", filename)
+	if err := os.Remove(filename); err != nil {
+		return fmt.Errorf("remove file %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// warnStaleFuncs logs the functions of the previously generated file that are not part of content,
+// such as injectors whose Inject call was renamed or removed. Regeneration drops them.
+func warnStaleFuncs(filename string, content []byte) error {
+	current, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read file %s: %w", filename, err)
+	}
+	if !bytes.HasPrefix(current, []byte(generatedHeader)) {
+		return nil
+	}
+
+	generated, err := generatedFuncNames(filename, content)
+	if err != nil {
+		return err
+	}
+	previous, err := generatedFuncNames(filename, current)
+	if err != nil {
+		// A broken generated file is overwritten anyway
+		slog.Debug("Failed to parse previously generated file", "file", filename, "error", err)
+		return nil
+	}
+
+	for _, name := range previous {
+		if !slices.Contains(generated, name) {
+			slog.Warn("Removing stale generated function", "file", filename, "function", name)
+		}
+	}
+
 	return nil
 }
 
-func outputFileName(filename string) string {
+// generatedFuncNames returns the names of the top-level functions in src, excluding methods.
+func generatedFuncNames(filename string, src []byte) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parse generated file %s: %w", filename, err)
+	}
+
+	var names []string
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil {
+			names = append(names, funcDecl.Name.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// outputFileName returns the file that the injectors of filename are generated into.
+func outputFileName(filename, suffix string) string {
 	ext := filepath.Ext(filename)
-	return strings.TrimSuffix(filename, ext) + "_band" + ext
+	return strings.TrimSuffix(filename, ext) + suffix + ext
+}
+
+// testOutputFileName returns the file that injectors declared with kessoku.ForTest are generated into.
+func testOutputFileName(filename, suffix string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + suffix + "_test" + ext
+}
+
+// registryFileName returns the file that holds the injector registry of a package.
+func registryFileName(suffix string) string {
+	return registryFilePrefix + suffix + ".go"
+}
+
+// outputFiles returns the files that may be generated from files.
+func (p *Processor) outputFiles(files []string) []string {
+	outputs := make([]string, 0, 2*len(files))
+	for _, file := range files {
+		outputs = append(outputs, outputFileName(file, p.outputSuffix), testOutputFileName(file, p.outputSuffix))
+	}
+
+	return outputs
+}
+
+// FindInjectorFiles returns the Go files in dir that import kessoku, sorted by name.
+// Test files and generated files are skipped.
+func FindInjectorFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		filename := filepath.Join(dir, name)
+		file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parse file %s: %w", filename, err)
+		}
+		if isGeneratedFile(file) {
+			continue
+		}
+
+		if slices.ContainsFunc(file.Imports, func(imp *ast.ImportSpec) bool {
+			return imp.Path.Value == strconv.Quote(kessokuPkgPath)
+		}) {
+			files = append(files, filename)
+		}
+	}
+
+	return files, nil
 }
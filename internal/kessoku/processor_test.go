@@ -1,10 +1,19 @@
 package kessoku
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/parser"
+	"go/token"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewProcessor(t *testing.T) {
@@ -885,3 +894,1748 @@ func invalid syntax here {
 		})
 	}
 }
+
+func TestProcessFiles_Registry(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+type Database struct{}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{}, nil
+}
+
+type Server struct{}
+
+func NewServer(db *Database, port int) *Server {
+	return &Server{}
+}
+
+type InitError struct {
+	Err error
+}
+
+func (e *InitError) Error() string {
+	return e.Err.Error()
+}
+
+func NewInitError(err error) *InitError {
+	return &InitError{Err: err}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+
+var _ = kessoku.Inject[*Database](
+	"InitializeDatabase",
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+)
+
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewServer),
+)
+
+var _ = kessoku.Inject[*Database](
+	"InitializeWrappedDatabase",
+	kessoku.WrapError(NewInitError),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+)
+`
+
+	tests := []struct {
+		name             string
+		opts             []ProcessorOption
+		expectedContains []string
+		notContains      []string
+		expectRegistry   bool
+	}{
+		{
+			name:           "registry enabled",
+			opts:           []ProcessorOption{WithRegistry()},
+			expectRegistry: true,
+			expectedContains: []string{
+				"// Code generated by kessoku. DO NOT EDIT.",
+				"package main",
+				`import "context"`,
+				"var InjectorRegistry = map[string]func(ctx context.Context) (any, error){",
+				`"InitializeConfig": func(ctx context.Context) (any, error) {`,
+				"return InitializeConfig(), nil",
+				`"InitializeDatabase": func(ctx context.Context) (any, error) {`,
+				"return InitializeDatabase(ctx)",
+				`"InitializeWrappedDatabase": func(ctx context.Context) (any, error) {`,
+				"v, err := InitializeWrappedDatabase()",
+				"return nil, err",
+				"return v, nil",
+			},
+			notContains: []string{
+				"InitializeServer",
+			},
+		},
+		{
+			name:           "registry disabled",
+			expectRegistry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			processor := NewProcessor(tt.opts...)
+			if err := processor.ProcessFiles([]string{testFile}); err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			registryFile := filepath.Join(tempDir, registryFileName(defaultOutputSuffix))
+			generated, err := os.ReadFile(registryFile)
+			if !tt.expectRegistry {
+				if err == nil {
+					t.Error("Expected no registry file to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read registry file: %v", err)
+			}
+
+			generatedStr := string(generated)
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(generatedStr, expected) {
+					t.Errorf("Expected registry to contain %q, got:\n%s", expected, generatedStr)
+				}
+			}
+			for _, notExpected := range tt.notContains {
+				if strings.Contains(generatedStr, notExpected) {
+					t.Errorf("Expected registry NOT to contain %q, got:\n%s", notExpected, generatedStr)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFiles_Diff(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+`
+
+	tests := []struct {
+		name             string
+		existing         string
+		expectedContains []string
+		expectDiff       bool
+		generateFirst    bool
+	}{
+		{
+			name:          "up to date",
+			generateFirst: true,
+		},
+		{
+			name:       "outdated",
+			existing:   "// Code generated by kessoku. DO NOT EDIT.\n\npackage main\n",
+			expectDiff: true,
+			expectedContains: []string{
+				"--- ",
+				"+++ ",
+				"@@ -1,3 +1,10 @@",
+				"+func InitializeConfig() *Config {",
+			},
+		},
+		{
+			name:       "not generated yet",
+			expectDiff: true,
+			expectedContains: []string{
+				"@@ -0,0 +1,10 @@",
+				"+// Code generated by kessoku. DO NOT EDIT.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+			generatedFile := filepath.Join(tempDir, "test_band.go")
+
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			if tt.generateFirst {
+				if err := NewProcessor().ProcessFiles([]string{testFile}); err != nil {
+					t.Fatalf("ProcessFiles failed: %v", err)
+				}
+			}
+			if tt.existing != "" {
+				if err := os.WriteFile(generatedFile, []byte(tt.existing), 0644); err != nil {
+					t.Fatalf("Failed to write generated file: %v", err)
+				}
+			}
+
+			before, _ := os.ReadFile(generatedFile)
+
+			var diff bytes.Buffer
+			err := NewProcessor(WithDiff(&diff)).ProcessFiles([]string{testFile})
+			if tt.expectDiff {
+				if !errors.Is(err, ErrGeneratedCodeOutdated) {
+					t.Fatalf("Expected ErrGeneratedCodeOutdated, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			if !tt.expectDiff && diff.Len() != 0 {
+				t.Errorf("Expected no diff, got:\n%s", diff.String())
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(diff.String(), expected) {
+					t.Errorf("Expected diff to contain %q, got:\n%s", expected, diff.String())
+				}
+			}
+
+			// The generated file must be left untouched in diff mode
+			after, _ := os.ReadFile(generatedFile)
+			if !bytes.Equal(before, after) {
+				t.Errorf("Expected generated file to be unchanged, got:\n%s", after)
+			}
+		})
+	}
+}
+
+func TestProcessFiles_Output(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	generatedFile := filepath.Join(tempDir, "test_band.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	var output bytes.Buffer
+	if err := NewProcessor(WithOutput(&output)).ProcessFiles([]string{testFile}); err != nil {
+		t.Fatalf("ProcessFiles failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by kessoku. DO NOT EDIT.",
+		"package main",
+		"func InitializeConfig() *Config {",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output.String())
+		}
+	}
+
+	if _, err := os.Stat(generatedFile); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected no generated file to be written, got error %v", err)
+	}
+}
+
+func TestProcessFiles_OutputWithTestInjectors(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+
+var _ = kessoku.Inject[*Config](
+	"InitializeTestConfig",
+	kessoku.ForTest(),
+	kessoku.Provide(NewConfig),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	// The code of test_band.go and test_band_test.go cannot be printed as a single file
+	var output bytes.Buffer
+	if err := NewProcessor(WithOutput(&output)).ProcessFiles([]string{testFile}); err == nil {
+		t.Errorf("Expected ProcessFiles to fail, got output:\n%s", output.String())
+	}
+	if output.Len() > 0 {
+		t.Errorf("Expected no output, got:\n%s", output.String())
+	}
+}
+
+func TestProcessFiles_SkipBrokenFiles(t *testing.T) {
+	t.Parallel()
+
+	valid := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+`
+	broken := `package main
+
+import "github.com/mazrean/kessoku"
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig,
+`
+	unwired := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+)
+`
+
+	tests := []struct {
+		name        string
+		opts        []ProcessorOption
+		files       []string
+		expectError bool
+	}{
+		{
+			name:  "broken file skipped",
+			opts:  []ProcessorOption{WithSkipBrokenFiles()},
+			files: []string{valid, broken, valid},
+		},
+		{
+			name:        "broken file without skipping",
+			files:       []string{valid, broken, valid},
+			expectError: true,
+		},
+		{
+			name:        "wiring error is not skipped",
+			opts:        []ProcessorOption{WithSkipBrokenFiles()},
+			files:       []string{valid, unwired},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Each file is a package of its own, so that the broken file only breaks itself
+			tempDir := t.TempDir()
+			files := make([]string, 0, len(tt.files))
+			for i, content := range tt.files {
+				dir := filepath.Join(tempDir, "app"+strconv.Itoa(i))
+				if err := os.Mkdir(dir, 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				file := filepath.Join(dir, "kessoku.go")
+				if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write test file: %v", err)
+				}
+				files = append(files, file)
+			}
+
+			err := NewProcessor(tt.opts...).ProcessFiles(files)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			// The files after the broken one are still generated
+			for i, file := range files {
+				_, statErr := os.Stat(outputFileName(file, defaultOutputSuffix))
+				if tt.files[i] == broken {
+					if !errors.Is(statErr, fs.ErrNotExist) {
+						t.Errorf("Expected no generated file for the broken file, got error %v", statErr)
+					}
+					continue
+				}
+				if statErr != nil {
+					t.Errorf("Expected generated file for %s: %v", file, statErr)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFiles_StaleOutput(t *testing.T) {
+	t.Parallel()
+
+	header := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+type Service struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewService(config *Config) *Service {
+	return &Service{}
+}
+`
+	configInjector := `
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+`
+	serviceInjector := `
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)
+`
+	brokenServiceInjector := `
+func NewBrokenService(config *Config) (*Service, error, error) {
+	return &Service{}, nil, nil
+}
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewBrokenService),
+)
+`
+
+	tests := []struct {
+		name          string
+		existing      string
+		regenerated   string
+		contains      []string
+		notContains   []string
+		expectRemoved bool
+		expectErr     bool
+	}{
+		{
+			name:        "removed injector",
+			regenerated: header + configInjector,
+			contains:    []string{"func InitializeConfig() *Config {"},
+			notContains: []string{"InitializeService"},
+		},
+		{
+			name:          "all injectors removed",
+			regenerated:   strings.Replace(header, `import "github.com/mazrean/kessoku"`, "", 1),
+			expectRemoved: true,
+		},
+		{
+			name:        "hand-written file is kept",
+			existing:    "package main\n\nfunc InitializeService() {}\n",
+			regenerated: strings.Replace(header, `import "github.com/mazrean/kessoku"`, "", 1),
+			contains:    []string{"func InitializeService() {}"},
+		},
+		{
+			name:        "unparsable injector keeps output",
+			regenerated: header + configInjector + brokenServiceInjector,
+			contains:    []string{"func InitializeConfig() *Config {", "func InitializeService() *Service {"},
+			expectErr:   true,
+		},
+		{
+			name:        "all injectors unparsable keeps output",
+			regenerated: header + brokenServiceInjector,
+			contains:    []string{"func InitializeConfig() *Config {", "func InitializeService() *Service {"},
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+			generatedFile := filepath.Join(tempDir, "test_band.go")
+
+			if tt.existing != "" {
+				if err := os.WriteFile(generatedFile, []byte(tt.existing), 0644); err != nil {
+					t.Fatalf("Failed to write generated file: %v", err)
+				}
+			} else {
+				if err := os.WriteFile(testFile, []byte(header+configInjector+serviceInjector), 0644); err != nil {
+					t.Fatalf("Failed to write test file: %v", err)
+				}
+				if err := NewProcessor().ProcessFiles([]string{testFile}); err != nil {
+					t.Fatalf("ProcessFiles failed: %v", err)
+				}
+			}
+
+			if err := os.WriteFile(testFile, []byte(tt.regenerated), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+			err := NewProcessor().ProcessFiles([]string{testFile})
+			if tt.expectErr && err == nil {
+				t.Error("Expected ProcessFiles to fail for a skipped injector")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			generated, err := os.ReadFile(generatedFile)
+			if tt.expectRemoved {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("Expected stale generated file to be removed, got error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+
+			for _, expected := range tt.contains {
+				if !strings.Contains(string(generated), expected) {
+					t.Errorf("Expected generated file to contain %q, got:\n%s", expected, generated)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(string(generated), unexpected) {
+					t.Errorf("Expected generated file not to contain %q, got:\n%s", unexpected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFiles_WithoutAsync(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+type Database struct{}
+type Cache struct{}
+type App struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{}, nil
+}
+
+func NewCache(config *Config) *Cache {
+	return &Cache{}
+}
+
+func NewApp(db *Database, cache *Cache) *App {
+	return &App{}
+}
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewApp),
+)
+`
+
+	generate := func(t *testing.T, opts ...ProcessorOption) string {
+		t.Helper()
+
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "test.go")
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+
+		if err := NewProcessor(opts...).ProcessFiles([]string{testFile}); err != nil {
+			t.Fatalf("ProcessFiles failed: %v", err)
+		}
+
+		generated, err := os.ReadFile(filepath.Join(tempDir, "test_band.go"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+
+		return string(generated)
+	}
+
+	async := generate(t)
+	for _, expected := range []string{`"golang.org/x/sync/errgroup"`, "func InitializeApp(ctx context.Context) (*App, error)", "eg.Go("} {
+		if !strings.Contains(async, expected) {
+			t.Errorf("Expected async output to contain %q, got:\n%s", expected, async)
+		}
+	}
+
+	sequential := generate(t, WithoutAsync())
+	for _, unexpected := range []string{"errgroup", "context", "eg.Go("} {
+		if strings.Contains(sequential, unexpected) {
+			t.Errorf("Expected forced sync output not to contain %q, got:\n%s", unexpected, sequential)
+		}
+	}
+	for _, expected := range []string{"func InitializeApp() (*App, error)", "kessoku.Async(kessoku.Provide(NewDatabase)).Fn()(config)"} {
+		if !strings.Contains(sequential, expected) {
+			t.Errorf("Expected forced sync output to contain %q, got:\n%s", expected, sequential)
+		}
+	}
+}
+
+func TestProcessFiles_AsyncThreshold(t *testing.T) {
+	t.Parallel()
+
+	header := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+type Database struct{}
+type Cache struct{}
+type App struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{}, nil
+}
+
+func NewCache(config *Config) *Cache {
+	return &Cache{}
+}
+
+func NewApp(db *Database, cache *Cache) *App {
+	return &App{}
+}
+
+func NewChainedApp(db *Database) *App {
+	return &App{}
+}
+`
+
+	tests := []struct {
+		name              string
+		injector          string
+		threshold         int
+		expectAsync       bool
+		expectedSignature string
+	}{
+		{
+			name: "above threshold",
+			injector: `
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewApp),
+)
+`,
+			threshold:         4,
+			expectAsync:       true,
+			expectedSignature: "func InitializeApp(ctx context.Context) (*App, error)",
+		},
+		{
+			name: "below threshold",
+			injector: `
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewApp),
+)
+`,
+			threshold:         5,
+			expectedSignature: "func InitializeApp(ctx context.Context) (*App, error)",
+		},
+		{
+			name: "no parallelism",
+			injector: `
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Provide(NewChainedApp),
+)
+`,
+			expectedSignature: "func InitializeApp(ctx context.Context) (*App, error)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+			if err := os.WriteFile(testFile, []byte(header+tt.injector), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			if err := NewProcessor(WithAsyncThreshold(tt.threshold)).ProcessFiles([]string{testFile}); err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			generated, err := os.ReadFile(filepath.Join(tempDir, "test_band.go"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+
+			// The context argument is kept either way so that the signature does not depend on the graph size
+			if !strings.Contains(string(generated), tt.expectedSignature) {
+				t.Errorf("Expected generated code to contain %q, got:\n%s", tt.expectedSignature, generated)
+			}
+			if hasAsync := strings.Contains(string(generated), "eg.Go("); hasAsync != tt.expectAsync {
+				t.Errorf("Expected async generation %v, got %v:\n%s", tt.expectAsync, hasAsync, generated)
+			}
+		})
+	}
+}
+
+func TestProcessFiles_Describe(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mazrean/kessoku"
+)
+
+type Config struct{}
+type Service struct{}
+
+func NewService(ctx context.Context, config *Config, logger *slog.Logger, handlers []string) *Service {
+	return &Service{}
+}
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Arg[*Config](),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Value(&Config{}),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	var output bytes.Buffer
+	if err := NewProcessor(WithDescribe(&output)).ProcessFiles([]string{testFile}); err != nil {
+		t.Fatalf("ProcessFiles failed: %v", err)
+	}
+
+	var descriptions []*InjectorDescription
+	if err := json.Unmarshal(output.Bytes(), &descriptions); err != nil {
+		t.Fatalf("Failed to decode descriptions %q: %v", output.String(), err)
+	}
+
+	// Declared arguments come first, followed by the auto-added ones in the order of the signature
+	expected := []*InjectorDescription{
+		{
+			Injector: "InitializeService",
+			Package:  "command-line-arguments",
+			Args: []*ArgumentDescription{
+				{Name: "config", Type: "*Config"},
+				{Name: "ctx", Type: "context.Context"},
+				{Name: "logger", Type: "*slog.Logger"},
+				{Name: "val", Type: "[]string"},
+			},
+		},
+		{
+			Injector: "InitializeConfig",
+			Package:  "command-line-arguments",
+			Args:     []*ArgumentDescription{},
+		},
+	}
+	if len(descriptions) != len(expected) {
+		t.Fatalf("Expected %d descriptions, got %d: %s", len(expected), len(descriptions), output.String())
+	}
+	for i, description := range descriptions {
+		if description.Injector != expected[i].Injector || description.Package != expected[i].Package {
+			t.Errorf("Expected injector %s of %s, got %s of %s", expected[i].Injector, expected[i].Package, description.Injector, description.Package)
+		}
+		if !slices.EqualFunc(description.Args, expected[i].Args, func(a, b *ArgumentDescription) bool { return *a == *b }) {
+			t.Errorf("Expected arguments of %s to be %s, got %s", expected[i].Injector, formatArgs(expected[i].Args), formatArgs(description.Args))
+		}
+	}
+
+	// The descriptions match the generated signature
+	generated, err := os.ReadFile(filepath.Join(tempDir, "test_band.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if signature := "func InitializeService(config *Config, ctx context.Context, logger *slog.Logger, val []string) *Service {"; !strings.Contains(string(generated), signature) {
+		t.Errorf("Expected generated code to contain %q, got:\n%s", signature, generated)
+	}
+}
+
+func formatArgs(args []*ArgumentDescription) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, arg.Name+" "+arg.Type)
+	}
+
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func TestProcessFiles_ReportAndMaxNodes(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+type Service struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewService(config *Config) *Service {
+	return &Service{}
+}
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig, kessoku.Cost(50)),
+	kessoku.Provide(NewService),
+)
+`
+
+	tests := []struct {
+		name           string
+		expectedReport string
+		maxNodes       int
+		expectErr      bool
+	}{
+		{
+			name:     "within budget",
+			maxNodes: 2,
+			expectedReport: `INJECTOR           NODES  EDGES  MAX ANTICHAIN  LONGEST PATH  ASYNC CRITICAL PATH  TOTAL COST  CRITICAL COST  CRITICAL PATH
+InitializeService  2      1      1              2             0                    51          51             NewConfig -> NewService
+`,
+		},
+		{
+			name:      "over budget",
+			maxNodes:  1,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			var report bytes.Buffer
+			err := NewProcessor(WithReport(&report, ReportFormatTable), WithMaxNodes(tt.maxNodes)).ProcessFiles([]string{testFile})
+			if tt.expectErr {
+				if !errors.Is(err, ErrGraphBudgetExceeded) {
+					t.Fatalf("Expected ErrGraphBudgetExceeded, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			if report.String() != tt.expectedReport {
+				t.Errorf("Expected report:\n%s\ngot:\n%s", tt.expectedReport, report.String())
+			}
+		})
+	}
+}
+
+func TestProcessFiles_TestFileProviders(t *testing.T) {
+	t.Parallel()
+
+	files := []fileContent{
+		{
+			name: "service.go",
+			content: `package main
+
+type Clock interface {
+	Now() int64
+}
+
+type Service struct {
+	clock Clock
+}
+
+func NewService(clock Clock) *Service {
+	return &Service{clock: clock}
+}
+`,
+		},
+		{
+			name: "fake_test.go",
+			content: `package main
+
+type fakeClock struct{}
+
+func (c *fakeClock) Now() int64 { return 0 }
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{}
+}
+`,
+		},
+		{
+			name: "wire_test.go",
+			content: `package main
+
+import "github.com/mazrean/kessoku"
+
+var _ = kessoku.Inject[*Service](
+	"InitializeFakeService",
+	kessoku.Bind[Clock](kessoku.Provide(newFakeClock)),
+	kessoku.Provide(NewService),
+)
+`,
+		},
+	}
+
+	// Outside of a module, a file is loaded without the other files of its package, so the package is created in this one.
+	// The underscore prefix keeps it out of ./... patterns while the test runs.
+	tempDir, err := os.MkdirTemp(".", "_test_file_providers")
+	if err != nil {
+		t.Fatalf("Failed to create package directory: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tempDir)
+	})
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, file.name), []byte(file.content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file.name, err)
+		}
+	}
+
+	if err := NewProcessor().ProcessFiles([]string{filepath.Join(tempDir, "wire_test.go")}); err != nil {
+		t.Fatalf("ProcessFiles failed: %v", err)
+	}
+
+	// Code generated from a test file may reference test-only providers, so it must be a test file too
+	if _, err := os.Stat(filepath.Join(tempDir, "wire_test_band.go")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected no regular output for a test file, got %v", err)
+	}
+
+	generated, err := os.ReadFile(filepath.Join(tempDir, "wire_test_band_test.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated test file: %v", err)
+	}
+	for _, expected := range []string{"package main", "func InitializeFakeService() *Service", "newFakeClock"} {
+		if !strings.Contains(string(generated), expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, generated)
+		}
+	}
+}
+
+func TestProcessFiles_Formatter(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+`
+
+	tests := []struct {
+		name          string
+		formatter     []string
+		expectedAdded string
+		expectErr     bool
+	}{
+		{
+			name:      "no-op formatter",
+			formatter: []string{"cat"},
+		},
+		{
+			name:          "rewriting formatter",
+			formatter:     []string{"sh", "-c", "cat; echo '// formatted'"},
+			expectedAdded: "// formatted\n",
+		},
+		{
+			name:      "failing formatter",
+			formatter: []string{"false"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "test.go")
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			var plain bytes.Buffer
+			if err := NewProcessor(WithOutput(&plain)).ProcessFiles([]string{testFile}); err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			var formatted bytes.Buffer
+			err := NewProcessor(WithOutput(&formatted), WithFormatter(tt.formatter[0], tt.formatter[1:]...)).ProcessFiles([]string{testFile})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("Expected ProcessFiles to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			if expected := plain.String() + tt.expectedAdded; formatted.String() != expected {
+				t.Errorf("Expected formatted output:\n%s\ngot:\n%s", expected, formatted.String())
+			}
+		})
+	}
+}
+
+func TestProcessFiles_ProjectOptions(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import (
+	"bytes"
+
+	"github.com/mazrean/kessoku"
+)
+
+type Renderer struct{}
+
+func NewRenderer(buf *bytes.Buffer) *Renderer {
+	return &Renderer{}
+}
+
+var _ = kessoku.Inject[*Renderer](
+	"InitializeRenderer",
+	kessoku.Provide(NewBuffer),
+	kessoku.Provide(NewRenderer),
+)
+`
+	// NewBuffer is only declared with the integration build tag
+	taggedContent := `//go:build integration
+
+package main
+
+import "bytes"
+
+func NewBuffer() *bytes.Buffer {
+	return new(bytes.Buffer)
+}
+`
+	tests := []struct {
+		name             string
+		opts             []ProcessorOption
+		expectedFile     string
+		expectedContains []string
+		expectErr        bool
+	}{
+		{
+			name:      "without build tags",
+			expectErr: true,
+		},
+		{
+			name:             "build tags",
+			opts:             []ProcessorOption{WithBuildTags("integration")},
+			expectedFile:     "test_band.go",
+			expectedContains: []string{"func InitializeRenderer() *Renderer {"},
+		},
+		{
+			name:             "output suffix",
+			opts:             []ProcessorOption{WithBuildTags("integration"), WithOutputSuffix("_gen")},
+			expectedFile:     "test_gen.go",
+			expectedContains: []string{"func InitializeRenderer() *Renderer {"},
+		},
+		{
+			name:         "import aliases",
+			opts:         []ProcessorOption{WithBuildTags("integration"), WithImportAliases(map[string]string{"github.com/mazrean/kessoku": "di"})},
+			expectedFile: "test_band.go",
+			expectedContains: []string{
+				`di "github.com/mazrean/kessoku"`,
+				"renderer := di.Provide(NewRenderer).Fn()(buffer)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Build constraints only apply to the files of a package, so the files are put in this module
+			tempDir, err := os.MkdirTemp("testdata", "project_options_")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			t.Cleanup(func() { os.RemoveAll(tempDir) })
+			testFile := filepath.Join(tempDir, "test.go")
+			for name, fileContent := range map[string]string{
+				"test.go":   content,
+				"buffer.go": taggedContent,
+			} {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte(fileContent), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+
+			err = NewProcessor(tt.opts...).ProcessFiles([]string{testFile})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("Expected ProcessFiles to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessFiles failed: %v", err)
+			}
+
+			generated, err := os.ReadFile(filepath.Join(tempDir, tt.expectedFile))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(string(generated), expected) {
+					t.Errorf("Expected generated file to contain %q, got:\n%s", expected, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessor_StrictTypes(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+var _ = kessoku.Inject[*Config](
+	"InitializeConfig",
+	kessoku.Provide(NewConfig),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	for _, strict := range []bool{false, true} {
+		var opts []ProcessorOption
+		if strict {
+			opts = append(opts, WithStrictTypes())
+		}
+
+		_, builds, _, err := NewProcessor(opts...).parseFile(testFile)
+		if err != nil {
+			t.Fatalf("parseFile failed: %v", err)
+		}
+		if len(builds) != 1 {
+			t.Fatalf("Expected 1 build directive, got %d", len(builds))
+		}
+		if builds[0].StrictTypes != strict {
+			t.Errorf("Expected StrictTypes %v, got %v", strict, builds[0].StrictTypes)
+		}
+	}
+}
+
+func TestProcessFiles_ForTest(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+type Service struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewService(config *Config) *Service {
+	return &Service{}
+}
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*Service](
+	"InitializeTestService",
+	kessoku.ForTest(),
+	kessoku.Async(kessoku.Provide(NewConfig)),
+	kessoku.Provide(NewService),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "kessoku.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	if err := NewProcessor().ProcessFiles([]string{testFile}); err != nil {
+		t.Fatalf("ProcessFiles failed: %v", err)
+	}
+
+	tests := []struct {
+		filename   string
+		expected   string
+		unexpected []string
+	}{
+		{
+			filename:   "kessoku_band.go",
+			expected:   "func InitializeService() *Service",
+			unexpected: []string{"InitializeTestService", "errgroup", "context"},
+		},
+		{
+			filename:   "kessoku_band_test.go",
+			expected:   "func InitializeTestService(ctx context.Context) *Service",
+			unexpected: []string{"InitializeService("},
+		},
+	}
+
+	for _, tt := range tests {
+		generated, err := os.ReadFile(filepath.Join(tempDir, tt.filename))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.filename, err)
+		}
+
+		// The test file declares the package of the injector file to reach its unexported providers
+		file, err := parser.ParseFile(token.NewFileSet(), tt.filename, generated, parser.PackageClauseOnly)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.filename, err)
+		}
+		if file.Name.Name != "main" {
+			t.Errorf("Expected %s to declare package main, got %s", tt.filename, file.Name.Name)
+		}
+
+		if !strings.Contains(string(generated), tt.expected) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", tt.filename, tt.expected, generated)
+		}
+		for _, unexpected := range tt.unexpected {
+			if strings.Contains(string(generated), unexpected) {
+				t.Errorf("Expected %s not to contain %q, got:\n%s", tt.filename, unexpected, generated)
+			}
+		}
+	}
+
+	// Dropping the regular injector removes its output but keeps the test file
+	content = strings.Replace(content, `var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)
+`, "", 1)
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := NewProcessor().ProcessFiles([]string{testFile}); err != nil {
+		t.Fatalf("ProcessFiles failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "kessoku_band.go")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected kessoku_band.go to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "kessoku_band_test.go")); err != nil {
+		t.Errorf("Expected kessoku_band_test.go to be kept, got %v", err)
+	}
+}
+
+func TestFindInjectorFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"kessoku.go": `package main
+
+import "github.com/mazrean/kessoku"
+
+var _ = kessoku.Inject[*Service]("InitializeService")
+`,
+		"wire.go": `package main
+
+import (
+	"fmt"
+
+	di "github.com/mazrean/kessoku"
+)
+
+var _ = di.Inject[*Service]("InitializeOther")
+var _ = fmt.Sprint
+`,
+		"main.go": `package main
+
+type Service struct{}
+`,
+		"kessoku_band.go": generatedHeader + `
+
+package main
+
+import "github.com/mazrean/kessoku"
+
+var _ = kessoku.Provide
+`,
+		"kessoku_test.go": `package main
+
+import "github.com/mazrean/kessoku"
+
+var _ = kessoku.Provide
+`,
+		"notes.txt": `import "github.com/mazrean/kessoku"`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	got, err := FindInjectorFiles(dir)
+	if err != nil {
+		t.Fatalf("FindInjectorFiles failed: %v", err)
+	}
+
+	expected := []string{filepath.Join(dir, "kessoku.go"), filepath.Join(dir, "wire.go")}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected files %v, got %v", expected, got)
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type Config struct{}
+type Service struct{}
+type A struct{}
+type B struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func NewOtherConfig() *Config {
+	return &Config{}
+}
+
+func NewService(config *Config) *Service {
+	return &Service{}
+}
+
+func NewA(b *B) *A {
+	return &A{}
+}
+
+func NewB(a *A) *B {
+	return &B{}
+}
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*Service](
+	"InitializeDuplicate",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewOtherConfig),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*A](
+	"InitializeCycle",
+	kessoku.Provide(NewA),
+	kessoku.Provide(NewB),
+)
+
+var _ = kessoku.Inject[*Service](
+	"InitializeInvalidOption",
+	kessoku.WithCancel(),
+	kessoku.CleanupCloser(),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewService),
+)
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	diagnostics := NewProcessor().ValidateFiles([]string{testFile})
+
+	expectedLines := []int{36, 43, 49}
+	if len(diagnostics) != len(expectedLines) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expectedLines), len(diagnostics), diagnostics)
+	}
+	for _, line := range expectedLines {
+		found := false
+		for _, diagnostic := range diagnostics {
+			if diagnostic.Pos.Filename == testFile && diagnostic.Pos.Line == line {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected a diagnostic at line %d, got %v", line, diagnostics)
+		}
+	}
+
+	for _, diagnostic := range diagnostics {
+		if !strings.HasPrefix(diagnostic.Error(), testFile+":") {
+			t.Errorf("Expected diagnostic %q to start with the file position", diagnostic.Error())
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "test_band.go")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected no output file to be written, got %v", err)
+	}
+}
+
+func TestValidateFiles_SuggestProviders(t *testing.T) {
+	t.Parallel()
+
+	content := `package main
+
+import "github.com/mazrean/kessoku"
+
+type DB struct{}
+type Cache struct{}
+
+type Store interface {
+	Get(key string) string
+}
+
+type PostgresStore struct{}
+
+func (s *PostgresStore) Get(key string) string { return "" }
+
+type App struct{}
+
+func NewDB() (*DB, error) {
+	return &DB{}, nil
+}
+
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+func NewPostgresStore() *PostgresStore {
+	return &PostgresStore{}
+}
+
+func NewApp(cache *Cache, store Store) *App {
+	return &App{}
+}
+
+var _ = kessoku.Inject[*Cache](
+	"InitializeCache",
+	kessoku.After[*Cache, *DB](),
+	kessoku.Provide(NewCache),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Use[Store]("postgres"),
+	kessoku.Provide(NewCache),
+	kessoku.Provide(NewApp),
+)
+`
+	// Injectors generated before are not suggested as constructors
+	generated := `// Code generated by kessoku. DO NOT EDIT.
+
+package main
+
+func InitializeDB() *DB {
+	return &DB{}
+}
+`
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "test_band.go"), []byte(generated), 0644); err != nil {
+		t.Fatalf("Failed to write generated file: %v", err)
+	}
+
+	diagnostics := NewProcessor().ValidateFiles([]string{testFile})
+
+	expected := map[string][]string{
+		"InitializeCache": {"kessoku.Provide(NewDB)"},
+		"InitializeApp":   {"kessoku.Bind[Store](kessoku.Provide(NewPostgresStore))"},
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(diagnostics), diagnostics)
+	}
+	for _, diagnostic := range diagnostics {
+		var missing *MissingProviderError
+		if !errors.As(diagnostic, &missing) {
+			t.Errorf("Expected a missing provider error, got %v", diagnostic)
+			continue
+		}
+		if !slices.Equal(missing.Suggestions, expected[diagnostic.Injector]) {
+			t.Errorf("Expected suggestions %v for %s, got %v", expected[diagnostic.Injector], diagnostic.Injector, missing.Suggestions)
+		}
+	}
+}
+
+func TestProcessFiles_Cache(t *testing.T) {
+	t.Parallel()
+
+	// The package is created in this module so that its imports of the module are hashed too.
+	// The underscore prefix keeps it out of ./... patterns while the test runs.
+	tempDir, err := os.MkdirTemp(".", "_cache")
+	if err != nil {
+		t.Fatalf("Failed to create package directory: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tempDir)
+	})
+	cacheDir := t.TempDir()
+
+	serviceFile := filepath.Join(tempDir, "service.go")
+	kessokuFile := filepath.Join(tempDir, "kessoku.go")
+	outputFile := filepath.Join(tempDir, "kessoku_band.go")
+	writeFile := func(filename, content string) {
+		t.Helper()
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+	writeFile(serviceFile, `package main
+
+type Service struct{}
+
+func NewService() *Service {
+	return &Service{}
+}
+`)
+	writeFile(kessokuFile, `package main
+
+import "github.com/mazrean/kessoku"
+
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewService),
+)
+`)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	steps := []struct {
+		name        string
+		change      func()
+		version     string
+		regenerated bool
+	}{
+		{
+			name:        "first run",
+			change:      func() {},
+			version:     "v1",
+			regenerated: true,
+		},
+		{
+			name:        "unchanged",
+			change:      func() {},
+			version:     "v1",
+			regenerated: false,
+		},
+		{
+			name: "provider changed",
+			change: func() {
+				writeFile(serviceFile, `package main
+
+type Service struct{}
+
+func NewService() (*Service, error) {
+	return &Service{}, nil
+}
+`)
+			},
+			version:     "v1",
+			regenerated: true,
+		},
+		{
+			name:        "unchanged after regeneration",
+			change:      func() {},
+			version:     "v1",
+			regenerated: false,
+		},
+		{
+			name:        "kessoku version changed",
+			change:      func() {},
+			version:     "v2",
+			regenerated: true,
+		},
+		{
+			name: "output edited",
+			change: func() {
+				writeFile(outputFile, "package main\n")
+			},
+			version:     "v2",
+			regenerated: true,
+		},
+		{
+			name: "output removed",
+			change: func() {
+				if err := os.Remove(outputFile); err != nil {
+					t.Fatalf("Failed to remove output: %v", err)
+				}
+			},
+			version:     "v2",
+			regenerated: true,
+		},
+	}
+
+	for _, step := range steps {
+		step.change()
+		if _, err := os.Stat(outputFile); err == nil {
+			if err := os.Chtimes(outputFile, past, past); err != nil {
+				t.Fatalf("%s: Failed to set output time: %v", step.name, err)
+			}
+		}
+
+		processor := NewProcessor(WithCache(cacheDir, step.version))
+		if err := processor.ProcessFiles([]string{kessokuFile}); err != nil {
+			t.Fatalf("%s: ProcessFiles failed: %v", step.name, err)
+		}
+
+		info, err := os.Stat(outputFile)
+		if err != nil {
+			t.Fatalf("%s: Expected the output to exist: %v", step.name, err)
+		}
+		if regenerated := !info.ModTime().Equal(past); regenerated != step.regenerated {
+			t.Errorf("%s: Expected regenerated to be %v, got %v", step.name, step.regenerated, regenerated)
+		}
+	}
+
+	generated, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(generated), "func InitializeService() (*Service, error) {") {
+		t.Errorf("Expected the output to follow the changed provider, got:\n%s", generated)
+	}
+}
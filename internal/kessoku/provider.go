@@ -5,7 +5,10 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"slices"
 	"strconv"
+
+	"golang.org/x/tools/go/packages"
 )
 
 type Package struct {
@@ -53,8 +56,11 @@ func GetUsedImports(imports map[string]*Import) map[string]*Import {
 }
 
 type MetaData struct {
-	Imports map[string]*Import
-	Package Package
+	Imports     map[string]*Import
+	Package     Package
+	LocalPrefix string            // Import path prefix grouped last in the generated imports
+	Stamp       *VersionStamp     // Stamp added below the generated header, omitted if nil
+	pkg         *packages.Package // Loaded package, searched for constructors suggested for missing providers
 }
 
 // ProviderType represents the type of provider.
@@ -65,6 +71,23 @@ const (
 	ProviderTypeArg         ProviderType = "arg"
 	ProviderTypeStruct      ProviderType = "struct"
 	ProviderTypeFieldAccess ProviderType = "field_access"
+	// ProviderTypeAppendValue is a kessoku.AppendValue contribution, merged per slice type by NewGraph
+	ProviderTypeAppendValue ProviderType = "append_value"
+	// ProviderTypePopulate assigns its dependencies to the fields of a kessoku.Populate target
+	ProviderTypePopulate ProviderType = "populate"
+	// ProviderTypeNil provides a nil interface declared with kessoku.Nil; ASTExpr is the interface type
+	ProviderTypeNil ProviderType = "nil"
+	// ProviderTypePool provides the acquire function of a sync.Pool declared with kessoku.Pooled; ASTExpr is the constructor
+	ProviderTypePool ProviderType = "pool"
+	// ProviderTypeCollect provides a slice of the provided values implementing an interface, declared with
+	// kessoku.CollectImplementing; ASTExpr is the interface type and NewGraph fills Requires
+	ProviderTypeCollect ProviderType = "collect"
+	// ProviderTypeFactory provides a function calling another injector of the file, detected by the
+	// parser for function-typed provider parameters; ASTExpr is the injector name
+	ProviderTypeFactory ProviderType = "factory"
+	// ProviderTypeEnv provides an environment variable declared with kessoku.Env, read and parsed by
+	// the generated code; ASTExpr is the value type
+	ProviderTypeEnv ProviderType = "env"
 )
 
 // StructFieldSpec represents a field extracted from a struct for dependency injection.
@@ -73,21 +96,112 @@ type StructFieldSpec struct {
 	Name      string     // Field name (e.g., "DBHost") - used in generated code
 	Index     int        // Original field index in struct - preserved for proper access
 	Anonymous bool       // True for embedded fields - affects naming
+	// Default is the literal of a `default:"..."` tag, which kessoku.Populate assigns
+	// when no provider supplies the field; UseDefault is set by NewGraph in that case.
+	Default    ast.Expr
+	UseDefault bool
 }
 
 // ProviderSpec represents a provider specification from annotations.
 type ProviderSpec struct {
-	ASTExpr           ast.Expr
 	StructType        types.Type
+	providerType      types.Type // Type of the provider expression, used to collapse duplicates
+	ASTExpr           ast.Expr
+	CallExpr          ast.Expr     // Client literal of kessoku.HTTPClient, function call given to kessoku.Call, or constant given to kessoku.Const, generated in place of ASTExpr
+	fn                types.Object // Wrapped package-level function or function variable, used to collapse duplicates
 	ReferencedImports map[string]*Import
 	SourceField       *StructFieldSpec
 	Type              ProviderType
-	Provides          [][]types.Type
+	ArgName           string // Declared name of a named argument (ProviderTypeArg)
+	DeprecatedMessage string // Message given to kessoku.Deprecated
+	SpanName          string // Span name given to kessoku.Span
+	Tag               string // Tag given to kessoku.Tag, selected by kessoku.Use
+	Group             string // Group given to kessoku.Group, commented in the generated injector
+	SetName           string // Set variable the provider was declared in, empty if listed in the injector directly
 	Requires          []types.Type
+	SpanRequires      []types.Type // Tracer and context.Context consumed by the span, not passed to the provider
 	StructFields      []*StructFieldSpec
+	Factory           *Factory // Injector called by a ProviderTypeFactory provider
+	Env               *EnvVar  // Environment variable read by a ProviderTypeEnv provider
+	RequireNames      []string // Parameter names of Requires, used to match named arguments
+	Provides          [][]types.Type
 	DeclOrder         int
+	Cost              int           // Estimated milliseconds given to kessoku.Cost, 0 if unset
+	ReadyChecks       []*ReadyCheck // Checks declared with kessoku.WithReadyCheck
+	Decorators        []*Decorator  // Applied in order to the provided value, declared with kessoku.Decorate
 	IsReturnError     bool
 	IsAsync           bool
+	IsDeprecated      bool
+	IsValue           bool // Declared with kessoku.Value or kessoku.Call, so the value is owned by the caller
+	AllowEmpty        bool // Let a ProviderTypeCollect provider collect no values, declared with kessoku.AllowEmpty
+}
+
+// dependencies returns every type the provider call depends on:
+// the provider parameters followed by SpanRequires.
+func (p *ProviderSpec) dependencies() []types.Type {
+	return slices.Concat(p.Requires, p.SpanRequires)
+}
+
+// cost returns the weight of the provider on the critical path of the report.
+func (p *ProviderSpec) cost() int {
+	if p.Cost > 0 {
+		return p.Cost
+	}
+
+	return 1
+}
+
+// name returns the provider function, or the first provided type if it has none, for the report and logs.
+func (p *ProviderSpec) name() string {
+	if p.fn != nil {
+		return p.fn.Name()
+	}
+	if len(p.Provides) > 0 && len(p.Provides[0]) > 0 {
+		return types.TypeString(p.Provides[0][0], func(pkg *types.Package) string { return pkg.Name() })
+	}
+
+	return "unknown"
+}
+
+// decoratorRequires returns the number of trailing Requires consumed by the decorators.
+func (p *ProviderSpec) decoratorRequires() int {
+	n := 0
+	for _, decorator := range p.Decorators {
+		n += len(decorator.Requires)
+	}
+
+	return n
+}
+
+// requireName returns the parameter name of the i-th required type, or "" if unknown.
+func (p *ProviderSpec) requireName(i int) string {
+	if i < len(p.RequireNames) {
+		return p.RequireNames[i]
+	}
+
+	return ""
+}
+
+// Factory is a function-typed provider parameter, such as func() (*Session, error), satisfied by
+// another injector of the file so that providers can construct fresh instances on demand.
+type Factory struct {
+	Injector     string
+	ReturnType   ast.Expr // Type of the value returned by the injector
+	ReturnsError bool     // The function type returns an error after the value
+	// wrapError is set by linkFactories when the injector returns no error, so the factory
+	// is a closure returning a nil error rather than the injector itself.
+	wrapError     bool
+	returnImports map[string]*Import
+}
+
+// EnvVar is an environment variable declared with kessoku.Env, which the generated injector reads
+// with os.Getenv and parses with strconv according to the underlying type of the value.
+type EnvVar struct {
+	Key     string
+	Kind    types.BasicKind // Underlying kind of the value type: String, Int, or Bool
+	Convert bool            // The value type is a named type the read value is converted to
+	// Names of the imports used by the generated code
+	osName, errorsName, fmtName, strconvName string
 }
 
 type Return struct {
@@ -95,11 +209,87 @@ type Return struct {
 	ASTTypeExpr ast.Expr
 }
 
-// BuildDirective represents a kessoku.Inject call.
+// BuildDirective represents a kessoku.Inject or kessoku.Populate call.
 type BuildDirective struct {
-	InjectorName string
-	Return       *Return
-	Providers    []*ProviderSpec
+	Return        *Return         // Nil for kessoku.Populate, which fills its target instead
+	Implements    *Implementation // Interface method declared with kessoku.Implements
+	ErrorWrapper  *ErrorWrapper   // Error conversion declared with kessoku.WrapError
+	ChannelTrace  *ChannelTrace   // Channel tracing declared with kessoku.WithChannelTrace
+	InjectorName  string
+	PanicFormat   string         // Format of the panic of the Must variant, declared with kessoku.PanicMsg
+	SharedName    string         // Name of the cached result variable of a lazy injector, declared with kessoku.Shared
+	Pos           token.Position // Position of the Inject call or //kessoku:inject comment
+	Providers     []*ProviderSpec
+	Args          []types.Type    // Arguments declared with kessoku.Arg, in declaration order
+	BlankImports  []string        // Packages imported for their side effects, declared with kessoku.BlankImport
+	Uses          []*TagSelection // Tagged providers selected with kessoku.Use
+	Orders        []*Ordering     // Orderings between providers declared with kessoku.After
+	IsLazy        bool
+	CommandArgs   bool // Args holds the args []string argument declared with kessoku.Args
+	IsMust        bool // Also generate a variant that panics on error, declared with kessoku.MustInject
+	Overrides     bool // Also generate a variant taking values by type, declared with kessoku.DynamicOverrides
+	WithCancel    bool // Return the cancel function of the context given to the providers, declared with kessoku.WithCancel
+	OmitContext   bool // Run async providers without a context argument if none requires one, declared with kessoku.OmitContext
+	LastWins      bool // Let later providers replace earlier ones of the same type, declared with kessoku.LastWins
+	StrictTypes   bool // Match types by types.Identical rather than their string form, declared with kessoku.StrictTypeIdentity
+	CleanupCloser bool // Return a closer of the created io.Closer values, declared with kessoku.CleanupCloser
+	RunWrapper    bool // Also generate a variant running a callback with the result, declared with kessoku.WithRunWrapper
+	AutoConvert   bool // Satisfy requirements with a uniquely assignable provided type
+	AutoRef       bool // Satisfy *T requirements with a provided T and T requirements with a provided *T
+	ForTest       bool // Generate into a _test.go file, declared with kessoku.ForTest
+}
+
+// TagSelection selects the provider of Type labeled with Tag.
+type TagSelection struct {
+	Type types.Type
+	Tag  string
+}
+
+// Ordering makes the provider of After run after the provider of Before.
+type Ordering struct {
+	Before types.Type
+	After  types.Type
+}
+
+// ErrorWrapper converts the errors returned by an injector to a custom error type.
+type ErrorWrapper struct {
+	Type              types.Type
+	ASTExpr           ast.Expr // Function converting an error to Type
+	ReferencedImports map[string]*Import
+}
+
+// ChannelTrace reports the channel events of an injector's async providers.
+type ChannelTrace struct {
+	ASTExpr           ast.Expr // kessoku.WithChannelTrace call whose Trace method receives the events
+	ReferencedImports map[string]*Import
+	varName           string // Variable holding the evaluated option in the generated injector
+}
+
+// ReadyCheck re-validates a provided value in the ready check returned by an injector.
+type ReadyCheck struct {
+	Type              types.Type // Provided type passed to the check
+	ASTExpr           ast.Expr   // kessoku.WithReadyCheck call whose Check method validates the value
+	ReferencedImports map[string]*Import
+	// ident replaces the call in the provider expression, named after the variable the injector binds the check to
+	ident *ast.Ident
+}
+
+// Decorator wraps the value of a provider declared with kessoku.Decorate.
+type Decorator struct {
+	ASTExpr  ast.Expr     // Decorator function called with the value and Requires
+	Requires []types.Type // Parameters following the decorated value
+}
+
+// InjectorReadyCheck is a ready check bound to the variable holding the checked value.
+type InjectorReadyCheck struct {
+	Check *ReadyCheck
+	Param *InjectorParam
+}
+
+// Implementation is an interface method the generated injector is exposed through.
+type Implementation struct {
+	Interface types.Type
+	Method    *types.Func
 }
 
 type InjectorParam struct {
@@ -108,8 +298,10 @@ type InjectorParam struct {
 	channelName       string
 	types             []types.Type
 	refCounter        int
+	waitCounter       int // References that only wait for the provider, see RefOrder
 	withChannel       bool
 	isArg             bool
+	closes            bool // Closed by the CleanupCloser of the injector
 }
 
 func NewInjectorParam(ts []types.Type, isArg bool) *InjectorParam {
@@ -213,6 +405,13 @@ func (p *InjectorParam) Ref(isWait bool) {
 	p.withChannel = !p.isArg && (p.withChannel || isWait)
 }
 
+// RefOrder references the param only to wait for its provider, for kessoku.After.
+// The value is not used, so it does not get a variable name by itself.
+func (p *InjectorParam) RefOrder(isWait bool) {
+	p.waitCounter++
+	p.withChannel = !p.isArg && (p.withChannel || isWait)
+}
+
 func (p *InjectorParam) Name(varPool *VarPool) string {
 	if p.name != "" {
 		return p.name
@@ -231,7 +430,7 @@ func (p *InjectorParam) ChannelName(varPool *VarPool) string {
 		return p.channelName
 	}
 
-	if p.refCounter == 0 {
+	if p.refCounter == 0 && p.waitCounter == 0 {
 		return "_"
 	}
 	p.channelName = varPool.GetChannel(p.types[0])
@@ -251,6 +450,7 @@ type InjectorArgument struct {
 	Param       *InjectorParam
 	Type        types.Type
 	ASTTypeExpr ast.Expr
+	Name        string // Declared name for kessoku.Named arguments, empty otherwise
 }
 
 type InjectorReturn struct {
@@ -265,13 +465,22 @@ type InjectorStmt interface {
 
 type InjectorCallArgument struct {
 	Param  *InjectorParam
+	Unary  token.Token // token.AND passes the address of Param and token.MUL dereferences it, declared with kessoku.AutoRef
 	IsWait bool
 }
 
 type InjectorProviderCallStmt struct {
-	Provider  *ProviderSpec
-	Arguments []*InjectorCallArgument
-	Returns   []*InjectorParam
+	Provider     *ProviderSpec
+	Arguments    []*InjectorCallArgument
+	Returns      []*InjectorParam
+	groupComment string        // Section comment of the kessoku.Group started by the provider, if any
+	pool         *injectorPool // Pool declared for a kessoku.Pooled provider
+}
+
+// injectorPool is the package-level sync.Pool generated for a kessoku.Pooled provider.
+type injectorPool struct {
+	name     string   // Variable holding the pool
+	typeExpr ast.Expr // Type of the pooled instances
 }
 
 func (stmt *InjectorProviderCallStmt) HasAsync() bool {
@@ -343,11 +552,72 @@ func (stmt *InjectorFieldAccessStmt) HasAsync() bool {
 }
 
 type Injector struct {
-	Return        *InjectorReturn
-	Name          string
-	Params        []*InjectorParam
-	Args          []*InjectorArgument
-	Vars          []*InjectorParam
-	Stmts         []InjectorStmt
-	IsReturnError bool
+	Return         *InjectorReturn
+	Implements     *Implementation
+	ErrorWrapper   *ErrorWrapper
+	ChannelTrace   *ChannelTrace
+	Metrics        *GraphMetrics
+	Name           string
+	PanicFormat    string // Format the Must variant wraps the error with, panicking with the error itself if empty
+	SharedName     string // Name of the variable caching the result of a lazy injector, generated from the injector name if empty
+	Params         []*InjectorParam
+	Args           []*InjectorArgument
+	BlankImports   []string // Packages the generated file imports for their side effects
+	Vars           []*InjectorParam
+	Stmts          []InjectorStmt
+	ReadyChecks    []*InjectorReadyCheck // Composed into a returned func(context.Context) error
+	IsReturnError  bool
+	IsLazy         bool
+	IsMust         bool
+	Overrides      bool                // Generate the body into a variant taking values by type, called by the injector
+	WithCancel     bool                // Return the cancel function of a context derived from the context argument
+	CleanupCloser  bool                // Return a closer of the values whose params are marked to be closed
+	RunWrapper     bool                // Also generate Run<Name>, which passes the result to a callback and defers the cleanups
+	ForTest        bool                // Generated into a _test.go file instead of the regular output file
+	Inspector      bool                // Also generate a variant returning every constructed value, enabled with WithInspector
+	NamedReturns   bool                // Name the results in the signature of the injector, enabled with WithNamedReturns
+	closerTypeName string              // Type of the closer generated next to the injector
+	closerVarName  string              // Variable holding the closer in the generated injector
+	comments       map[ast.Stmt]string // Comments written on their own line before generated statements
+	overrides      *injectorOverrides
+	inspection     *injectorInspection
+}
+
+// injectorInspection holds the struct the variant generated with Inspector returns in place of the result.
+type injectorInspection struct {
+	typeExpr ast.Expr // Pointer to the struct type
+	lit      ast.Expr // Struct of the constructed values
+}
+
+// injectorOverrides holds the names used by the lookups of the variant generated with Overrides.
+type injectorOverrides struct {
+	typeExprs   map[*InjectorParam]ast.Expr // Types of the values provided by the statements of the injector
+	mapName     string                      // Map argument of the variant
+	valueName   string                      // Value found in the map
+	reflectName string                      // Name of the reflect package
+}
+
+// ContextArg returns the unnamed context.Context argument that async execution is bound to, or nil.
+// Named contexts are passed through to the providers that request them and never drive the errgroup.
+func (i *Injector) ContextArg() *InjectorArgument {
+	for _, arg := range i.Args {
+		if arg.Name == "" && isContextType(arg.Type) {
+			return arg
+		}
+	}
+
+	return nil
+}
+
+// UnusedArgs returns the injector arguments that no generated statement references.
+// Such arguments are passed through without being wired to any provider.
+func (i *Injector) UnusedArgs() []*InjectorArgument {
+	var unused []*InjectorArgument
+	for _, arg := range i.Args {
+		if arg.Param.refCounter == 0 {
+			unused = append(unused, arg)
+		}
+	}
+
+	return unused
 }
@@ -325,3 +325,65 @@ func TestInjectorParam_Type(t *testing.T) {
 		})
 	}
 }
+
+func TestInjector_UnusedArgs(t *testing.T) {
+	t.Parallel()
+
+	configType, _, intType := createTestTypes()
+
+	tests := []struct {
+		name          string
+		argRefCounts  []int
+		expectedTypes []string
+	}{
+		{
+			name:          "no arguments",
+			argRefCounts:  nil,
+			expectedTypes: nil,
+		},
+		{
+			name:          "all arguments referenced",
+			argRefCounts:  []int{1, 2},
+			expectedTypes: nil,
+		},
+		{
+			name:          "unreferenced argument",
+			argRefCounts:  []int{1, 0},
+			expectedTypes: []string{"int"},
+		},
+		{
+			name:          "all arguments unreferenced",
+			argRefCounts:  []int{0, 0},
+			expectedTypes: []string{configType.String(), "int"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			argTypes := []types.Type{configType, intType}
+			injector := &Injector{Name: "InitializeService"}
+			for i, refCount := range tt.argRefCounts {
+				param := NewInjectorParam([]types.Type{argTypes[i]}, true)
+				for range refCount {
+					param.Ref(false)
+				}
+				injector.Args = append(injector.Args, &InjectorArgument{
+					Param: param,
+					Type:  argTypes[i],
+				})
+			}
+
+			unused := injector.UnusedArgs()
+			if len(unused) != len(tt.expectedTypes) {
+				t.Fatalf("UnusedArgs() returned %d arguments, want %d", len(unused), len(tt.expectedTypes))
+			}
+			for i, arg := range unused {
+				if arg.Type.String() != tt.expectedTypes[i] {
+					t.Errorf("UnusedArgs()[%d] = %s, want %s", i, arg.Type.String(), tt.expectedTypes[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package kessoku
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// ErrGraphBudgetExceeded is returned when an injector graph has more nodes than allowed by WithMaxNodes.
+var ErrGraphBudgetExceeded = errors.New("injector graph exceeds the node budget")
+
+// ReportFormat is the output format of a graph complexity report.
+type ReportFormat string
+
+const (
+	// ReportFormatTable writes the report as an aligned table.
+	ReportFormatTable ReportFormat = "table"
+	// ReportFormatJSON writes the report as a JSON array.
+	ReportFormatJSON ReportFormat = "json"
+)
+
+// GraphMetrics describes the complexity of the dependency graph of an injector.
+type GraphMetrics struct {
+	Injector string `json:"injector"`
+	// Nodes is the number of providers and injector arguments.
+	Nodes int `json:"nodes"`
+	// Edges is the number of dependencies between nodes.
+	Edges int `json:"edges"`
+	// MaxAntichain is the largest number of providers that do not depend on each other.
+	MaxAntichain uint64 `json:"max_antichain"`
+	// LongestPath is the number of providers on the longest dependency chain.
+	LongestPath int `json:"longest_path"`
+	// AsyncCriticalPath is the number of async providers on the dependency chain with the most of them,
+	// which estimates how many async calls must run one after another.
+	AsyncCriticalPath int `json:"async_critical_path"`
+	// TotalCost is the sum of the costs of all providers, where a provider weighs its kessoku.Cost or 1.
+	TotalCost int `json:"total_cost"`
+	// CriticalPathCost is the total cost of CriticalPath. It bounds the time the injector takes even if
+	// every provider runs in parallel, so parallelization cannot help when it is close to TotalCost.
+	CriticalPathCost int `json:"critical_path_cost"`
+	// CriticalPath lists the providers on the dependency chain with the highest total cost, in call order.
+	CriticalPath []string `json:"critical_path"`
+}
+
+// metrics computes the complexity metrics of the graph.
+func (g *Graph) metrics() *GraphMetrics {
+	metrics := &GraphMetrics{
+		Injector:     g.injectorName,
+		Nodes:        len(g.nodes),
+		MaxAntichain: g.findMaximumAntichainSize(),
+	}
+	for _, edges := range g.edges {
+		metrics.Edges += len(edges)
+	}
+
+	// Dependencies are visited before their dependents, so each node extends the deepest chain leading to it
+	depths := make(map[*node]int, len(g.nodes))
+	asyncDepths := make(map[*node]int, len(g.nodes))
+	costs := make(map[*node]int, len(g.nodes))
+	// criticalDependencies holds the dependency each node extends the costliest chain of
+	criticalDependencies := make(map[*node]*node, len(g.nodes))
+	var criticalEnd *node
+	for n := range g.topologicalSortIter() {
+		var depth, asyncDepth, cost int
+		for _, dependency := range g.reverseEdges[n] {
+			depth = max(depth, depths[dependency])
+			asyncDepth = max(asyncDepth, asyncDepths[dependency])
+			if costs[dependency] > cost {
+				cost = costs[dependency]
+				criticalDependencies[n] = dependency
+			}
+		}
+
+		if n.providerSpec != nil {
+			depth++
+			if n.providerSpec.IsAsync {
+				asyncDepth++
+			}
+			cost += n.providerSpec.cost()
+			metrics.TotalCost += n.providerSpec.cost()
+		}
+		depths[n], asyncDepths[n], costs[n] = depth, asyncDepth, cost
+
+		metrics.LongestPath = max(metrics.LongestPath, depth)
+		metrics.AsyncCriticalPath = max(metrics.AsyncCriticalPath, asyncDepth)
+		if cost > metrics.CriticalPathCost {
+			metrics.CriticalPathCost = cost
+			criticalEnd = n
+		}
+	}
+
+	metrics.CriticalPath = []string{}
+	for n := criticalEnd; n != nil; n = criticalDependencies[n] {
+		if n.providerSpec != nil {
+			metrics.CriticalPath = append(metrics.CriticalPath, n.providerSpec.name())
+		}
+	}
+	slices.Reverse(metrics.CriticalPath)
+
+	return metrics
+}
+
+// writeReport writes the metrics of all injectors to w in the given format.
+func writeReport(w io.Writer, format ReportFormat, metrics []*GraphMetrics) error {
+	switch format {
+	case ReportFormatJSON:
+		if metrics == nil {
+			metrics = []*GraphMetrics{}
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(metrics); err != nil {
+			return fmt.Errorf("encode report: %w", err)
+		}
+
+		return nil
+	case ReportFormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "INJECTOR\tNODES\tEDGES\tMAX ANTICHAIN\tLONGEST PATH\tASYNC CRITICAL PATH\tTOTAL COST\tCRITICAL COST\tCRITICAL PATH")
+		for _, m := range metrics {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", m.Injector, m.Nodes, m.Edges, m.MaxAntichain, m.LongestPath, m.AsyncCriticalPath, m.TotalCost, m.CriticalPathCost, strings.Join(m.CriticalPath, " -> "))
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
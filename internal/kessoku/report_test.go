@@ -0,0 +1,244 @@
+package kessoku
+
+import (
+	"bytes"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestGraph_Metrics(t *testing.T) {
+	t.Parallel()
+
+	newType := func(name string) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, nil, name, nil), types.NewStruct(nil, nil), nil))
+	}
+	configType := newType("Config")
+	dbType := newType("Database")
+	cacheType := newType("Cache")
+	repoType := newType("Repository")
+	appType := newType("App")
+
+	provider := func(provides types.Type, isAsync bool, requires ...types.Type) *ProviderSpec {
+		return &ProviderSpec{
+			Type:     ProviderTypeFunction,
+			Provides: [][]types.Type{{provides}},
+			Requires: requires,
+			IsAsync:  isAsync,
+		}
+	}
+	withCost := func(provider *ProviderSpec, cost int) *ProviderSpec {
+		provider.Cost = cost
+		return provider
+	}
+
+	tests := []struct {
+		build    *BuildDirective
+		name     string
+		expected GraphMetrics
+	}{
+		{
+			name: "diamond with parallel async providers",
+			build: &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers: []*ProviderSpec{
+					provider(configType, false),
+					provider(dbType, true, configType),
+					provider(cacheType, true, configType),
+					provider(appType, false, dbType, cacheType),
+				},
+			},
+			expected: GraphMetrics{
+				Injector:          "InitializeApp",
+				Nodes:             4,
+				Edges:             4,
+				MaxAntichain:      2,
+				LongestPath:       3,
+				AsyncCriticalPath: 1,
+				TotalCost:         4,
+				CriticalPathCost:  3,
+				CriticalPath:      []string{"*Config", "*Database", "*App"},
+			},
+		},
+		{
+			name: "chain of async providers",
+			build: &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers: []*ProviderSpec{
+					provider(configType, false),
+					provider(dbType, true, configType),
+					provider(repoType, true, dbType),
+					provider(appType, false, repoType),
+				},
+			},
+			expected: GraphMetrics{
+				Injector:          "InitializeApp",
+				Nodes:             4,
+				Edges:             3,
+				MaxAntichain:      1,
+				LongestPath:       4,
+				AsyncCriticalPath: 2,
+				TotalCost:         4,
+				CriticalPathCost:  4,
+				CriticalPath:      []string{"*Config", "*Database", "*Repository", "*App"},
+			},
+		},
+		{
+			name: "argument is not counted in the path",
+			build: &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers: []*ProviderSpec{
+					provider(appType, false, configType),
+				},
+			},
+			expected: GraphMetrics{
+				Injector:          "InitializeApp",
+				Nodes:             2,
+				Edges:             1,
+				MaxAntichain:      1,
+				LongestPath:       1,
+				AsyncCriticalPath: 0,
+				TotalCost:         1,
+				CriticalPathCost:  1,
+				CriticalPath:      []string{"*App"},
+			},
+		},
+		{
+			name: "cost outweighs the provider count",
+			build: &BuildDirective{
+				InjectorName: "InitializeApp",
+				Return:       &Return{Type: appType},
+				Providers: []*ProviderSpec{
+					provider(configType, false),
+					provider(repoType, true, configType),
+					provider(dbType, true, repoType),
+					withCost(provider(cacheType, true), 200),
+					provider(appType, false, dbType, cacheType),
+				},
+			},
+			expected: GraphMetrics{
+				Injector:          "InitializeApp",
+				Nodes:             5,
+				Edges:             4,
+				MaxAntichain:      2,
+				LongestPath:       4,
+				AsyncCriticalPath: 2,
+				TotalCost:         204,
+				CriticalPathCost:  201,
+				CriticalPath:      []string{"*Cache", "*App"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			metaData := &MetaData{
+				Package: Package{
+					Name: "main",
+					Path: "main",
+				},
+				Imports: make(map[string]*Import),
+			}
+
+			graph, err := NewGraph(metaData, tt.build, NewVarPool())
+			if err != nil {
+				t.Fatalf("NewGraph failed: %v", err)
+			}
+
+			if metrics := graph.metrics(); !reflect.DeepEqual(*metrics, tt.expected) {
+				t.Errorf("Expected metrics %+v, got %+v", tt.expected, *metrics)
+			}
+		})
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	t.Parallel()
+
+	metrics := []*GraphMetrics{
+		{
+			Injector: "InitializeApp", Nodes: 4, Edges: 4, MaxAntichain: 2, LongestPath: 3, AsyncCriticalPath: 1,
+			TotalCost: 203, CriticalPathCost: 202, CriticalPath: []string{"NewConfig", "NewDatabase", "NewApp"},
+		},
+		{
+			Injector: "InitializeConfig", Nodes: 1, MaxAntichain: 1, LongestPath: 1,
+			TotalCost: 1, CriticalPathCost: 1, CriticalPath: []string{"NewConfig"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		format   ReportFormat
+		expected string
+		metrics  []*GraphMetrics
+		wantErr  bool
+	}{
+		{
+			name:    "table",
+			format:  ReportFormatTable,
+			metrics: metrics,
+			expected: `INJECTOR          NODES  EDGES  MAX ANTICHAIN  LONGEST PATH  ASYNC CRITICAL PATH  TOTAL COST  CRITICAL COST  CRITICAL PATH
+InitializeApp     4      4      2              3             1                    203         202            NewConfig -> NewDatabase -> NewApp
+InitializeConfig  1      0      1              1             0                    1           1              NewConfig
+`,
+		},
+		{
+			name:    "json",
+			format:  ReportFormatJSON,
+			metrics: metrics[1:],
+			expected: `[
+  {
+    "injector": "InitializeConfig",
+    "nodes": 1,
+    "edges": 0,
+    "max_antichain": 1,
+    "longest_path": 1,
+    "async_critical_path": 0,
+    "total_cost": 1,
+    "critical_path_cost": 1,
+    "critical_path": [
+      "NewConfig"
+    ]
+  }
+]
+`,
+		},
+		{
+			name:     "json without injectors",
+			format:   ReportFormatJSON,
+			expected: "[]\n",
+		},
+		{
+			name:    "unknown format",
+			format:  "yaml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := writeReport(&buf, tt.format, tt.metrics)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("writeReport failed: %v", err)
+			}
+
+			if buf.String() != tt.expected {
+				t.Errorf("Expected report:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test adapting the result of a third-party constructor to a domain interface
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Adapt[Storage](kessoku.Provide(NewBucketClient), NewBucketStorage),
+	kessoku.Adapt[Clock](kessoku.Async(kessoku.Provide(NewTimeSource)), func(source *TimeSource) Clock {
+		return source
+	}),
+	kessoku.Provide(NewService),
+)
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BucketClient stands in for a client returned by a third-party library.
+type BucketClient struct {
+	bucket string
+}
+
+func NewBucketClient() (*BucketClient, error) {
+	return &BucketClient{bucket: "assets"}, nil
+}
+
+func (c *BucketClient) PutObject(key string, data []byte) error {
+	return nil
+}
+
+// TimeSource stands in for a third-party clock implementation.
+type TimeSource struct{}
+
+func NewTimeSource(ctx context.Context) *TimeSource {
+	return &TimeSource{}
+}
+
+func (s *TimeSource) Now() time.Time {
+	return time.Now()
+}
+
+// Storage is the interface the application depends on.
+type Storage interface {
+	Save(key string, data []byte) error
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type bucketStorage struct {
+	client *BucketClient
+}
+
+func (s *bucketStorage) Save(key string, data []byte) error {
+	return s.client.PutObject(key, data)
+}
+
+// NewBucketStorage adapts a BucketClient to Storage.
+func NewBucketStorage(client *BucketClient) Storage {
+	return &bucketStorage{client: client}
+}
+
+type Service struct {
+	storage Storage
+	clock   Clock
+}
+
+func NewService(storage Storage, clock Clock) *Service {
+	return &Service{storage: storage, clock: clock}
+}
+
+func main() {
+	service, err := InitializeService(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(service.clock.Now().IsZero(), service.storage.Save("key", nil))
+}
@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+var MiddlewareSet = kessoku.Set(
+	kessoku.AppendValue[Middleware](Logging),
+	kessoku.AppendValue[Middleware](Recovery),
+)
+
+// Test slice aggregated from values declared in a set and in the injector
+var _ = kessoku.Inject[*Router](
+	"InitializeRouter",
+	MiddlewareSet,
+	kessoku.AppendValue[Middleware](Auth),
+	kessoku.Provide(NewRouter),
+)
@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+type Middleware func(string) string
+
+func Logging(path string) string  { return "log(" + path + ")" }
+func Recovery(path string) string { return "recover(" + path + ")" }
+func Auth(path string) string     { return "auth(" + path + ")" }
+
+type Router struct {
+	middlewares []Middleware
+}
+
+func NewRouter(middlewares []Middleware) *Router {
+	return &Router{middlewares: middlewares}
+}
+
+func (r *Router) Handle(path string) string {
+	for _, middleware := range r.middlewares {
+		path = middleware(path)
+	}
+
+	return path
+}
+
+func main() {
+	router := InitializeRouter()
+	fmt.Println(router.Handle("/"))
+}
@@ -0,0 +1,17 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test a single async provider whose result is handed to async consumers waiting in separate goroutines
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewReadConfig)),
+	kessoku.Async(kessoku.Provide(NewWriteConfig)),
+	kessoku.Async(kessoku.Provide(NewReader)),
+	kessoku.Async(kessoku.Provide(NewWriter)),
+	kessoku.Async(kessoku.Provide(NewAuditor)),
+	kessoku.Provide(NewApp),
+)
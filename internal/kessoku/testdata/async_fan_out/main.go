@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// dials counts the connections made, which must be shared by every consumer
+var dials atomic.Int32
+
+type Database struct {
+	id int32
+}
+
+// NewDatabase is slow, so that both consumers are already waiting for it
+func NewDatabase() *Database {
+	time.Sleep(50 * time.Millisecond)
+	return &Database{id: dials.Add(1)}
+}
+
+type ReadConfig struct{}
+
+func NewReadConfig() *ReadConfig {
+	return &ReadConfig{}
+}
+
+type WriteConfig struct{}
+
+func NewWriteConfig() *WriteConfig {
+	return &WriteConfig{}
+}
+
+type Reader struct {
+	db *Database
+}
+
+func NewReader(db *Database, _ *ReadConfig) *Reader {
+	return &Reader{db: db}
+}
+
+type Writer struct {
+	db *Database
+}
+
+func NewWriter(db *Database, _ *WriteConfig) *Writer {
+	return &Writer{db: db}
+}
+
+type Auditor struct {
+	db *Database
+}
+
+func NewAuditor(db *Database) *Auditor {
+	return &Auditor{db: db}
+}
+
+type App struct {
+	reader  *Reader
+	writer  *Writer
+	auditor *Auditor
+}
+
+func NewApp(reader *Reader, writer *Writer, auditor *Auditor) *App {
+	return &App{reader: reader, writer: writer, auditor: auditor}
+}
+
+func main() {
+	app := InitializeApp(context.Background())
+
+	db := app.auditor.db
+	if db == nil || app.reader.db != db || app.writer.db != db {
+		fmt.Println("consumers received different databases")
+		os.Exit(1)
+	}
+	fmt.Println("dials:", dials.Load())
+}
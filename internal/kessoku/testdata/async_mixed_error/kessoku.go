@@ -0,0 +1,14 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test async providers with and without errors sharing an errgroup
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewQueue)),
+	kessoku.Provide(NewApp),
+)
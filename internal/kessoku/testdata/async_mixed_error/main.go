@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Cache struct{}
+
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+type Database struct{}
+
+func NewDatabase() (*Database, error) {
+	return &Database{}, nil
+}
+
+type Queue struct{}
+
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+type App struct {
+	cache *Cache
+	db    *Database
+}
+
+func NewApp(cache *Cache, db *Database, queue *Queue) *App {
+	return &App{cache: cache, db: db}
+}
+
+func main() {
+	app, err := InitializeApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(app.cache != nil, app.db != nil)
+}
@@ -4,8 +4,10 @@ package main
 
 import (
 	"context"
-	"github.com/mazrean/kessoku"
+
 	"golang.org/x/sync/errgroup"
+
+	"github.com/mazrean/kessoku"
 )
 
 func InitializeApp(ctx context.Context) (*App, error) {
@@ -52,7 +54,8 @@ func InitializeApp(ctx context.Context) (*App, error) {
 	}
 	app = kessoku.Provide(NewApp).Fn()(database, cache, messaging)
 	if err := eg.Wait(); err != nil {
-		return nil, err
+		var zero *App
+		return zero, err
 	}
 	return app, nil
 }
@@ -0,0 +1,13 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test error paths of an async injector returning a non-pointer value
+var _ = kessoku.Inject[Config](
+	"InitializeConfig",
+	kessoku.Async(kessoku.Provide(NewEndpoint)),
+	kessoku.Async(kessoku.Provide(NewLimits)),
+	kessoku.Provide(NewConfig),
+)
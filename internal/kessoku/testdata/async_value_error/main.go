@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type Endpoint string
+
+func NewEndpoint(ctx context.Context) (Endpoint, error) {
+	return "https://example.com", nil
+}
+
+// NewLimits returns several values and fails.
+func NewLimits(ctx context.Context) (int, bool, error) {
+	return 0, false, errors.New("limits unavailable")
+}
+
+type Config struct {
+	Endpoint Endpoint
+	MaxConns int
+	Strict   bool
+}
+
+func NewConfig(endpoint Endpoint, maxConns int, strict bool) (Config, error) {
+	return Config{Endpoint: endpoint, MaxConns: maxConns, Strict: strict}, nil
+}
+
+func main() {
+	config, err := InitializeConfig(context.Background())
+	fmt.Println(config, err)
+}
@@ -0,0 +1,13 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test wiring a required interface to the single provided type assignable to it
+var _ = kessoku.Inject[*Logger](
+	"InitializeLogger",
+	kessoku.AutoConvert(),
+	kessoku.Provide(NewBuffer),
+	kessoku.Provide(NewLogger),
+)
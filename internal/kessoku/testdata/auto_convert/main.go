@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+func NewBuffer() *bytes.Buffer {
+	return &bytes.Buffer{}
+}
+
+type Logger struct {
+	w io.Writer
+}
+
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+func main() {
+	logger := InitializeLogger()
+	fmt.Fprint(logger.w, "hello")
+
+	fmt.Println(logger.w.(*bytes.Buffer).String())
+}
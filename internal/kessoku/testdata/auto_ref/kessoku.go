@@ -0,0 +1,15 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test passing the address of a provided value and a copy of a provided pointer
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.AutoRef(),
+	kessoku.Provide(LoadConfig),
+	kessoku.Provide(NewLogger),
+	kessoku.Provide(NewHandler),
+	kessoku.Provide(NewServer),
+)
@@ -0,0 +1,42 @@
+package main
+
+import "fmt"
+
+type Config struct {
+	Addr string
+}
+
+func LoadConfig() Config {
+	return Config{Addr: ":8080"}
+}
+
+type Logger struct {
+	Prefix string
+}
+
+func NewLogger() *Logger {
+	return &Logger{Prefix: "[server]"}
+}
+
+type Handler struct {
+	config *Config
+	logger Logger
+}
+
+func NewHandler(config *Config, logger Logger) *Handler {
+	return &Handler{config: config, logger: logger}
+}
+
+type Server struct {
+	handler *Handler
+	config  *Config
+}
+
+func NewServer(handler *Handler, config *Config) *Server {
+	return &Server{handler: handler, config: config}
+}
+
+func main() {
+	server := InitializeServer()
+	fmt.Println(server.handler.logger.Prefix, server.config.Addr, server.handler.config == server.config)
+}
@@ -3,9 +3,10 @@
 package main
 
 import (
-	"github.com/mazrean/kessoku"
 	"io"
 	"os"
+
+	"github.com/mazrean/kessoku"
 )
 
 func InitializeService() *Service {
@@ -0,0 +1,12 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test a package imported only for the handler it registers in init
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.BlankImport("expvar"),
+	kessoku.Provide(NewServer),
+)
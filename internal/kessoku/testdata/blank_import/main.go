@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+type Server struct {
+	mux *http.ServeMux
+}
+
+func NewServer() *Server {
+	return &Server{mux: http.DefaultServeMux}
+}
+
+func main() {
+	server := InitializeServer()
+	_, pattern := server.mux.Handler(httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	fmt.Println(pattern)
+}
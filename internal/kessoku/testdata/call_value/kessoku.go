@@ -0,0 +1,19 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test values provided by the results of existing initializer calls
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Call(slog.Default()),
+	kessoku.Bind[Labeler](kessoku.Call(strings.NewReplacer("-", "_"))),
+	kessoku.Async(kessoku.Call(defaultSettings())),
+	kessoku.Provide(NewApp),
+)
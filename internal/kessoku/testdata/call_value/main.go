@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type Labeler interface {
+	Replace(s string) string
+}
+
+type Settings struct {
+	Name string
+}
+
+func defaultSettings() *Settings {
+	return &Settings{Name: "call-value"}
+}
+
+type App struct {
+	logger   *slog.Logger
+	labeler  Labeler
+	settings *Settings
+}
+
+func NewApp(logger *slog.Logger, labeler Labeler, settings *Settings) *App {
+	return &App{logger: logger, labeler: labeler, settings: settings}
+}
+
+func main() {
+	app := InitializeApp(context.Background())
+	fmt.Println(app.logger != nil, app.labeler.Replace(app.settings.Name))
+}
@@ -0,0 +1,50 @@
+//go:generate go tool kessoku $GOFILE
+
+package main
+
+import "github.com/mazrean/kessoku"
+
+// NewJobQueue creates a bidirectional job channel.
+func NewJobQueue() chan Job {
+	return make(chan Job, 10)
+}
+
+// Producer sends jobs to a send-only channel.
+type Producer struct {
+	jobs chan<- Job
+}
+
+// NewProducer creates a new producer.
+func NewProducer(jobs chan<- Job) *Producer {
+	return &Producer{jobs: jobs}
+}
+
+// Consumer receives jobs from a receive-only channel.
+type Consumer struct {
+	jobs <-chan Job
+}
+
+// NewConsumer creates a new consumer.
+func NewConsumer(jobs <-chan Job) *Consumer {
+	return &Consumer{jobs: jobs}
+}
+
+// Worker pairs a producer with a consumer.
+type Worker struct {
+	producer *Producer
+	consumer *Consumer
+}
+
+// NewWorker creates a new worker.
+func NewWorker(producer *Producer, consumer *Consumer) *Worker {
+	return &Worker{producer: producer, consumer: consumer}
+}
+
+// Test bidirectional channel satisfying send-only and receive-only dependencies
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.Provide(NewJobQueue),
+	kessoku.Provide(NewProducer),
+	kessoku.Provide(NewConsumer),
+	kessoku.Provide(NewWorker),
+)
@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+// Job is a unit of work.
+type Job struct {
+	ID int
+}
+
+func main() {
+	worker := InitializeWorker()
+	worker.producer.jobs <- Job{ID: 1}
+	fmt.Println((<-worker.consumer.jobs).ID)
+}
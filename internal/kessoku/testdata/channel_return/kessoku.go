@@ -0,0 +1,12 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test an injector returning a receive-only channel
+var _ = kessoku.Inject[<-chan Event](
+	"InitializeEventStream",
+	kessoku.Provide(NewProducer),
+	kessoku.Provide(NewEventStream),
+)
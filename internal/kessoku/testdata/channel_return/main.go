@@ -0,0 +1,31 @@
+package main
+
+import "fmt"
+
+type Event struct {
+	Name string
+}
+
+type Producer struct {
+	names []string
+}
+
+func NewProducer() *Producer {
+	return &Producer{names: []string{"started", "ready"}}
+}
+
+func NewEventStream(producer *Producer) <-chan Event {
+	events := make(chan Event, len(producer.names))
+	for _, name := range producer.names {
+		events <- Event{Name: name}
+	}
+	close(events)
+
+	return events
+}
+
+func main() {
+	for event := range InitializeEventStream() {
+		fmt.Println(event.Name)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"log"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test tracing the channel handoff between async providers with slog
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.WithChannelTrace(),
+	kessoku.Async(kessoku.Provide(NewConfig)),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewApp),
+)
+
+// Test tracing with a user callback
+var _ = kessoku.Inject[*App](
+	"InitializeTracedApp",
+	kessoku.WithChannelTrace(func(injector, event, channel string) {
+		log.Printf("%s: %s %s", injector, event, channel)
+	}),
+	kessoku.Async(kessoku.Provide(NewConfig)),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost"}
+}
+
+type Database struct {
+	config *Config
+}
+
+func NewDatabase(ctx context.Context, config *Config) (*Database, error) {
+	return &Database{config: config}, nil
+}
+
+type Cache struct {
+	config *Config
+}
+
+func NewCache(ctx context.Context, config *Config) *Cache {
+	return &Cache{config: config}
+}
+
+type App struct {
+	db    *Database
+	cache *Cache
+}
+
+func NewApp(db *Database, cache *Cache) *App {
+	return &App{db: db, cache: cache}
+}
+
+func main() {
+	app, err := InitializeApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	tracedApp, err := InitializeTracedApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(app.db.config.DSN, tracedApp.cache.config.DSN)
+}
@@ -0,0 +1,30 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"os"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test closing the created values through the returned closer
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.CleanupCloser(),
+	kessoku.Value(os.Stdout),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewCache),
+	kessoku.Provide(NewApp),
+)
+
+// Test closing values created by async providers and on errors
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.CleanupCloser(),
+	kessoku.Value(os.Stdout),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Async(kessoku.Provide(NewListener)),
+	kessoku.Provide(NewServer),
+)
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var closed []string
+
+type Database struct{}
+
+func NewDatabase(out *os.File) (*Database, error) {
+	fmt.Fprintln(out, "open database")
+	return &Database{}, nil
+}
+
+func (d *Database) Close() error {
+	closed = append(closed, "database")
+	return nil
+}
+
+type Cache struct{}
+
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+func (c *Cache) Close() error {
+	closed = append(closed, "cache")
+	return errors.New("cache flush failed")
+}
+
+type App struct {
+	db    *Database
+	cache *Cache
+}
+
+func NewApp(db *Database, cache *Cache) *App {
+	return &App{db: db, cache: cache}
+}
+
+type Listener struct{}
+
+func NewListener(ctx context.Context) (*Listener, error) {
+	return nil, errors.New("address already in use")
+}
+
+type Server struct {
+	listener *Listener
+	app      *App
+}
+
+func NewServer(listener *Listener, db *Database, cache *Cache) *Server {
+	return &Server{listener: listener, app: NewApp(db, cache)}
+}
+
+func main() {
+	app, closer, err := InitializeApp()
+	if err != nil {
+		panic(err)
+	}
+	var _ io.Closer = closer
+
+	fmt.Println(app.db != nil, closer.Close(), closed)
+	closed = nil
+
+	_, closer2, err := InitializeServer(context.Background())
+	fmt.Println(closer2 == nil, err, len(closed))
+}
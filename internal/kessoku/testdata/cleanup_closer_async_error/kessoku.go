@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// Test closing the values of async providers finishing after a provider of the injector failed
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.CleanupCloser(),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Async(kessoku.Provide(NewQueue)),
+	kessoku.Provide(NewStore),
+	kessoku.Async(kessoku.Provide(NewIndex)),
+	kessoku.Provide(NewWorker),
+)
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var cacheClosed atomic.Bool
+
+type Cache struct{}
+
+// NewCache finishes after NewStore failed
+func NewCache(ctx context.Context) (*Cache, error) {
+	time.Sleep(20 * time.Millisecond)
+	return &Cache{}, nil
+}
+
+func (c *Cache) Close() error {
+	cacheClosed.Store(true)
+	return nil
+}
+
+type Queue struct{}
+
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+type Store struct{}
+
+func NewStore(queue *Queue) (*Store, error) {
+	return nil, errors.New("store unavailable")
+}
+
+type Index struct{}
+
+func NewIndex(store *Store) *Index {
+	return &Index{}
+}
+
+type Worker struct{}
+
+func NewWorker(cache *Cache, store *Store, index *Index) *Worker {
+	return &Worker{}
+}
+
+func main() {
+	_, closer, err := InitializeWorker(context.Background())
+	fmt.Println(closer == nil, err, cacheClosed.Load())
+}
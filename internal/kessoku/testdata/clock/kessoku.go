@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test the clock provider in production and the clock as an argument in tests
+var _ = kessoku.Inject[*TokenIssuer](
+	"InitializeTokenIssuer",
+	kessoku.Clock(),
+	kessoku.Provide(NewTokenIssuer),
+)
+
+var _ = kessoku.Inject[*TokenIssuer](
+	"InitializeTestTokenIssuer",
+	kessoku.Arg[kessoku.Now](),
+	kessoku.Provide(NewTokenIssuer),
+)
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+type TokenIssuer struct {
+	now kessoku.Now
+}
+
+func NewTokenIssuer(now kessoku.Now) *TokenIssuer {
+	return &TokenIssuer{now: now}
+}
+
+func (i *TokenIssuer) ExpiresAt() time.Time {
+	return i.now().Add(time.Hour)
+}
+
+func main() {
+	issuer := InitializeTokenIssuer()
+	fmt.Println(issuer.ExpiresAt().After(time.Now()))
+
+	fixed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testIssuer := InitializeTestTokenIssuer(func() time.Time { return fixed })
+	fmt.Println(testIssuer.ExpiresAt().Format(time.RFC3339))
+}
@@ -0,0 +1,19 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// Test collecting the provided values implementing an interface into a slice
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewAuthPlugin),
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewCachePlugin)),
+	kessoku.Bind[Plugin](kessoku.Provide(NewMetricsPlugin)),
+	kessoku.CollectImplementing[Plugin](),
+	kessoku.CollectImplementing[Closer](kessoku.AllowEmpty()),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Plugin interface {
+	Name() string
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Config struct {
+	Prefix string
+}
+
+func NewConfig() *Config {
+	return &Config{Prefix: "plugin:"}
+}
+
+type AuthPlugin struct{}
+
+func NewAuthPlugin() *AuthPlugin {
+	return &AuthPlugin{}
+}
+
+func (p *AuthPlugin) Name() string { return "auth" }
+
+type CachePlugin struct{}
+
+func NewCachePlugin(config *Config) *CachePlugin {
+	return &CachePlugin{}
+}
+
+func (p *CachePlugin) Name() string { return "cache" }
+
+type MetricsPlugin struct{}
+
+func NewMetricsPlugin() MetricsPlugin {
+	return MetricsPlugin{}
+}
+
+func (p MetricsPlugin) Name() string { return "metrics" }
+
+type App struct {
+	config  *Config
+	plugins []Plugin
+	closers []Closer
+}
+
+func NewApp(config *Config, plugins []Plugin, closers []Closer) *App {
+	return &App{config: config, plugins: plugins, closers: closers}
+}
+
+func main() {
+	app := InitializeApp(context.Background())
+
+	for _, plugin := range app.plugins {
+		fmt.Println(app.config.Prefix + plugin.Name())
+	}
+	fmt.Println(len(app.closers), app.closers != nil)
+}
@@ -0,0 +1,19 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+var FlagSet = kessoku.Set(
+	kessoku.Args(),
+	kessoku.Provide(NewFlags),
+)
+
+// Test command-line arguments given to the providers needing a []string
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Args(),
+	FlagSet,
+	kessoku.Provide(NewCommand),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type Flags struct {
+	Verbose bool
+	Rest    []string
+}
+
+func NewFlags(args []string) (*Flags, error) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "verbose output")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	return &Flags{Verbose: *verbose, Rest: fs.Args()}, nil
+}
+
+type Command struct {
+	Args []string
+}
+
+func NewCommand(args []string) *Command {
+	return &Command{Args: args}
+}
+
+type App struct {
+	flags   *Flags
+	command *Command
+}
+
+func NewApp(flags *Flags, command *Command) *App {
+	return &App{flags: flags, command: command}
+}
+
+func main() {
+	app, err := InitializeApp([]string{"-verbose", "serve"})
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(app.flags.Verbose, app.flags.Rest, app.command.Args)
+}
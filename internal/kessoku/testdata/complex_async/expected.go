@@ -4,8 +4,10 @@ package main
 
 import (
 	"context"
-	"github.com/mazrean/kessoku"
+
 	"golang.org/x/sync/errgroup"
+
+	"github.com/mazrean/kessoku"
 )
 
 func InitializeComplexApp(ctx context.Context) *App {
@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test typed constants of iota enums provided by name
+var _ = kessoku.Inject[*Logger](
+	"InitializeLogger",
+	kessoku.Const[Level](InfoLevel),
+	kessoku.Const(FormatJSON),
+	kessoku.Const(time.Second),
+	kessoku.Provide(NewLogger),
+)
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+type Logger struct {
+	level    Level
+	format   Format
+	interval time.Duration
+}
+
+func NewLogger(level Level, format Format, interval time.Duration) *Logger {
+	return &Logger{level: level, format: format, interval: interval}
+}
+
+func main() {
+	logger := InitializeLogger()
+	fmt.Println(logger.level, logger.format, logger.interval)
+}
@@ -0,0 +1,22 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test decorators applied in order around the handler of a base provider
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.Provide(NewLogger),
+	kessoku.Decorate[Handler](kessoku.Provide(NewRouter), WithRecovery, WithLogging),
+	kessoku.Provide(NewServer),
+)
+
+// Test decorators applied once the error of an async base provider is checked
+var _ = kessoku.Inject[*Server](
+	"InitializeLoadedServer",
+	kessoku.Provide(NewLogger),
+	kessoku.Decorate[Handler](kessoku.Async(kessoku.Provide(LoadRouter)), WithRecovery, WithLogging),
+	kessoku.Async(kessoku.Provide(NewMetrics)),
+	kessoku.Provide(NewServerWithMetrics),
+)
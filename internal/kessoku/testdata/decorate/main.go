@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Handler interface {
+	Serve(path string) string
+}
+
+type HandlerFunc func(path string) string
+
+func (f HandlerFunc) Serve(path string) string {
+	return f(path)
+}
+
+type Logger struct {
+	prefix string
+}
+
+type Metrics struct{}
+
+type Server struct {
+	handler Handler
+}
+
+func NewLogger() *Logger {
+	return &Logger{prefix: "log"}
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+func NewRouter() Handler {
+	return HandlerFunc(func(path string) string {
+		return "route " + path
+	})
+}
+
+func LoadRouter() (Handler, error) {
+	return NewRouter(), nil
+}
+
+func WithRecovery(next Handler) Handler {
+	return HandlerFunc(func(path string) string {
+		return "recover(" + next.Serve(path) + ")"
+	})
+}
+
+func WithLogging(next Handler, logger *Logger) Handler {
+	return HandlerFunc(func(path string) string {
+		return logger.prefix + "(" + next.Serve(path) + ")"
+	})
+}
+
+func NewServer(handler Handler) *Server {
+	return &Server{handler: handler}
+}
+
+func NewServerWithMetrics(handler Handler, metrics *Metrics) *Server {
+	return &Server{handler: handler}
+}
+
+func main() {
+	server := InitializeServer()
+	fmt.Println(server.handler.Serve("/"))
+
+	loaded, err := InitializeLoadedServer(context.Background())
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(strings.ToUpper(loaded.handler.Serve("/")))
+}
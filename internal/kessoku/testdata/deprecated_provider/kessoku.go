@@ -0,0 +1,13 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test deprecated providers, which are generated as usual but reported during generation
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Provide(NewLegacyConfig, kessoku.Deprecated("use NewConfig")),
+	kessoku.Async(kessoku.Provide(NewLegacyClient, kessoku.Deprecated("use NewClient"))),
+	kessoku.Provide(NewService),
+)
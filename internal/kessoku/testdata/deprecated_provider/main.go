@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Config struct {
+	Endpoint string
+}
+
+func NewLegacyConfig() *Config {
+	return &Config{Endpoint: "http://localhost"}
+}
+
+type Client struct {
+	endpoint string
+}
+
+func NewLegacyClient() (*Client, error) {
+	return &Client{endpoint: "legacy"}, nil
+}
+
+type Service struct {
+	config *Config
+	client *Client
+}
+
+func NewService(config *Config, client *Client) *Service {
+	return &Service{config: config, client: client}
+}
+
+func main() {
+	service, err := InitializeService(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(service.config.Endpoint, service.client.endpoint)
+}
@@ -0,0 +1,25 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test a variant looking up the values of the providers in a map before calling them
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.DynamicOverrides(),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewClients),
+	kessoku.Provide(NewApp),
+)
+
+// Test overrides of providers running in goroutines
+var _ = kessoku.Inject[*App](
+	"InitializeAsyncApp",
+	kessoku.DynamicOverrides(),
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewClients)),
+	kessoku.Provide(NewApp),
+)
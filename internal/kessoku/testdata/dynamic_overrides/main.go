@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+type Config struct {
+	DSN string
+}
+
+type Database struct {
+	DSN string
+}
+
+type Cache struct {
+	Name string
+}
+
+type Queue struct {
+	Name string
+}
+
+type App struct {
+	db    *Database
+	cache *Cache
+	queue *Queue
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost"}
+}
+
+func NewDatabase(cfg *Config) (*Database, error) {
+	return &Database{DSN: cfg.DSN}, nil
+}
+
+func NewClients(cfg *Config) (*Cache, *Queue) {
+	return &Cache{Name: "redis"}, &Queue{Name: "sqs"}
+}
+
+func NewApp(db *Database, cache *Cache, queue *Queue) *App {
+	return &App{db: db, cache: cache, queue: queue}
+}
+
+func main() {
+	app, err := InitializeApp()
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	fmt.Println(app.db.DSN, app.cache.Name, app.queue.Name)
+
+	overrides := map[reflect.Type]any{
+		reflect.TypeFor[*Database](): &Database{DSN: "memory"},
+		reflect.TypeFor[*Queue]():    &Queue{Name: "local"},
+	}
+
+	app, err = InitializeAppWithOverrides(overrides)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	fmt.Println(app.db.DSN, app.cache.Name, app.queue.Name)
+
+	app, err = InitializeAsyncAppWithOverrides(context.Background(), overrides)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	fmt.Println(app.db.DSN, app.cache.Name, app.queue.Name)
+}
@@ -0,0 +1,23 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test values read from environment variables
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.Env[DatabaseURL]("DATABASE_URL"),
+	kessoku.Env[int]("PORT"),
+	kessoku.Env[bool]("DEBUG"),
+	kessoku.Provide(NewServer),
+)
+
+// Test values converted to named types and read by injectors running async providers
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.Env[Port]("PORT"),
+	kessoku.Async(kessoku.Provide(NewListener)),
+	kessoku.Async(kessoku.Provide(NewQueue)),
+	kessoku.Provide(NewWorker),
+)
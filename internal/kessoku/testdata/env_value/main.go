@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type DatabaseURL string
+
+type Server struct {
+	URL   DatabaseURL
+	Port  int
+	Debug bool
+}
+
+func NewServer(url DatabaseURL, port int, debug bool) *Server {
+	return &Server{URL: url, Port: port, Debug: debug}
+}
+
+type Port int
+
+type Listener struct {
+	Port Port
+}
+
+func NewListener(port Port) *Listener {
+	return &Listener{Port: port}
+}
+
+type Queue struct{}
+
+func NewQueue(ctx context.Context) (*Queue, error) {
+	return &Queue{}, nil
+}
+
+type Worker struct {
+	Listener *Listener
+	Queue    *Queue
+}
+
+func NewWorker(listener *Listener, queue *Queue) *Worker {
+	return &Worker{Listener: listener, Queue: queue}
+}
+
+func main() {
+	server, err := InitializeServer()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	fmt.Printf("%s:%d debug=%t\n", server.URL, server.Port, server.Debug)
+
+	worker, err := InitializeWorker(context.Background())
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	fmt.Println("listening on", worker.Listener.Port)
+}
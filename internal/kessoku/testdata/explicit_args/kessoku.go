@@ -0,0 +1,14 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test explicitly declared injector arguments, which keep their declaration order
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Arg[*Logger](),
+	kessoku.Arg[*Config](),
+	kessoku.Provide(NewService),
+	kessoku.Provide(NewApp),
+)
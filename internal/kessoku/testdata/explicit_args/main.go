@@ -0,0 +1,34 @@
+package main
+
+import "fmt"
+
+type Config struct {
+	Name string
+}
+
+type Logger struct {
+	Prefix string
+}
+
+type Service struct {
+	config *Config
+	logger *Logger
+}
+
+func NewService(config *Config, logger *Logger) *Service {
+	return &Service{config: config, logger: logger}
+}
+
+type App struct {
+	service *Service
+	port    int
+}
+
+func NewApp(service *Service, port int) *App {
+	return &App{service: service, port: port}
+}
+
+func main() {
+	app := InitializeApp(&Logger{Prefix: "[app]"}, &Config{Name: "example"}, 8080)
+	fmt.Println(app.service.logger.Prefix, app.service.config.Name, app.port)
+}
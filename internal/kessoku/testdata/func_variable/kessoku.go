@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+var DatabaseSet = kessoku.Set(
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+)
+
+// Test constructors assigned to package-level function variables
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	DatabaseSet,
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type Config struct {
+	DSN string
+}
+
+type Database struct {
+	dsn string
+}
+
+type DatabaseConstructor func(cfg *Config) (*Database, error)
+
+type App struct {
+	db *Database
+}
+
+var NewConfig = func() *Config {
+	return &Config{DSN: "postgres://localhost"}
+}
+
+// Assigned through a named function type, as configurable codebases swap implementations
+var NewDatabase DatabaseConstructor = func(cfg *Config) (*Database, error) {
+	return &Database{dsn: cfg.DSN}, nil
+}
+
+func NewApp(db *Database) *App {
+	return &App{db: db}
+}
+
+func main() {
+	app, err := InitializeApp()
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(app.db.dsn)
+}
@@ -0,0 +1,16 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// Test Bind with a provider of a generic type instantiation implementing the interface,
+// run in parallel so that the instantiated type is declared in the injector
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.Bind[UserRepository](kessoku.Async(kessoku.Provide(NewRepo[User]))),
+	kessoku.Async(kessoku.Provide(NewConfig)),
+	kessoku.Provide(NewService),
+)
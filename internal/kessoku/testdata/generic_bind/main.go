@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type User struct {
+	Name string
+}
+
+type UserRepository interface {
+	Find(id int) (User, error)
+}
+
+type Repo[T any] struct {
+	items map[int]T
+}
+
+func NewRepo[T any]() *Repo[T] {
+	return &Repo[T]{items: make(map[int]T)}
+}
+
+func (r *Repo[T]) Find(id int) (T, error) {
+	item, ok := r.items[id]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("item %d not found", id)
+	}
+	return item, nil
+}
+
+type Config struct {
+	Limit int
+}
+
+func NewConfig() *Config {
+	return &Config{Limit: 10}
+}
+
+type Service struct {
+	users  UserRepository
+	repo   *Repo[User]
+	config *Config
+}
+
+func NewService(users UserRepository, repo *Repo[User], config *Config) *Service {
+	return &Service{users: users, repo: repo, config: config}
+}
+
+func main() {
+	service := InitializeService(context.Background())
+	if _, err := service.users.Find(1); err == nil {
+		panic("expected error")
+	}
+	if service.repo != service.users {
+		panic("expected the same repository")
+	}
+	fmt.Println("ok")
+}
@@ -0,0 +1,22 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// RepositorySet wires a repository and service for any entity type
+func RepositorySet[T any]() kessoku.GenericSet[T] {
+	return kessoku.NewGenericSet[T](
+		kessoku.Provide(NewRepository[T]),
+		kessoku.Provide(NewService[T]),
+	)
+}
+
+// Test a generic set instantiated for two entity types
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewDB),
+	RepositorySet[User](),
+	RepositorySet[Post](),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,51 @@
+package main
+
+import "fmt"
+
+type DB struct {
+	name string
+}
+
+func NewDB() *DB {
+	return &DB{name: "main"}
+}
+
+type User struct{}
+
+type Post struct{}
+
+type Repository[T any] struct {
+	db *DB
+}
+
+func NewRepository[T any](db *DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+type Service[T any] struct {
+	repo *Repository[T]
+}
+
+func NewService[T any](repo *Repository[T]) *Service[T] {
+	return &Service[T]{repo: repo}
+}
+
+func (s *Service[T]) Describe() string {
+	var entity T
+	return fmt.Sprintf("%T service on %s", entity, s.repo.db.name)
+}
+
+type App struct {
+	users *Service[User]
+	posts *Service[Post]
+}
+
+func NewApp(users *Service[User], posts *Service[Post]) *App {
+	return &App{users: users, posts: posts}
+}
+
+func main() {
+	app := InitializeApp()
+	fmt.Println(app.users.Describe())
+	fmt.Println(app.posts.Describe())
+}
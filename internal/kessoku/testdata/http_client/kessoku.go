@@ -0,0 +1,26 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test the built-in HTTP client provider, constructed by the generated code
+var _ = kessoku.Inject[*APIClient](
+	"InitializeAPIClient",
+	kessoku.HTTPClient(
+		kessoku.HTTPTimeout(10*time.Second),
+		kessoku.HTTPTransport(sharedTransport),
+	),
+	kessoku.Provide(NewAPIClient),
+)
+
+// The transport of a package-level variable is shared by the clients of both injectors
+var _ = kessoku.Inject[*WebhookClient](
+	"InitializeWebhookClient",
+	kessoku.HTTPClient(kessoku.HTTPTransport(sharedTransport)),
+	kessoku.Provide(NewWebhookClient),
+)
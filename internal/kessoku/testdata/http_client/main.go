@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type APIClient struct {
+	client *http.Client
+}
+
+func NewAPIClient(client *http.Client) *APIClient {
+	return &APIClient{client: client}
+}
+
+type WebhookClient struct {
+	client *http.Client
+}
+
+func NewWebhookClient(client *http.Client) *WebhookClient {
+	return &WebhookClient{client: client}
+}
+
+// sharedTransport pools the connections of the clients of every injector.
+var sharedTransport = &userAgentTransport{base: http.DefaultTransport}
+
+// userAgentTransport sets the User-Agent header of every request.
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", "kessoku")
+	return t.base.RoundTrip(req)
+}
+
+func main() {
+	api := InitializeAPIClient()
+	webhook := InitializeWebhookClient()
+	_, ok := api.client.Transport.(*userAgentTransport)
+	fmt.Println(api.client.Timeout, ok, api.client.Transport == webhook.client.Transport)
+}
@@ -0,0 +1,13 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test an injector implementing a factory interface
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Implements[AppFactory]("NewApp"),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type AppFactory interface {
+	NewApp(ctx context.Context) (*App, error)
+}
+
+type Database struct{}
+
+func NewDatabase() (*Database, error) {
+	return &Database{}, nil
+}
+
+type App struct {
+	db *Database
+}
+
+func NewApp(db *Database) *App {
+	return &App{db: db}
+}
+
+func run(factory AppFactory) error {
+	app, err := factory.NewApp(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Println(app.db != nil)
+	return nil
+}
+
+func main() {
+	if err := run(initializeAppImpl{}); err != nil {
+		panic(err)
+	}
+}
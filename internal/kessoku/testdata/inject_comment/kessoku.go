@@ -0,0 +1,29 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	_ "github.com/mazrean/kessoku"
+)
+
+type Config struct {
+	Port int
+}
+
+// NewConfig creates the default configuration.
+//
+//kessoku:inject InitializeConfig *Config
+func NewConfig() *Config {
+	return &Config{Port: 8080}
+}
+
+type Server struct {
+	config *Config
+}
+
+// Test the comment directive form of a single-provider injector
+//
+//kessoku:inject InitializeServer *Server
+func NewServer(config *Config) (*Server, error) {
+	return &Server{config: config}, nil
+}
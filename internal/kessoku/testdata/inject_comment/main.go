@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+func main() {
+	config := InitializeConfig()
+
+	server, err := InitializeServer(config)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(server.config.Port)
+}
@@ -0,0 +1,24 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// Test provider parameters satisfied by other injectors, called to construct fresh instances
+var _ = kessoku.Inject[*Session](
+	"InitializeSession",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewSession),
+)
+
+var _ = kessoku.Inject[*Counter](
+	"InitializeCounter",
+	kessoku.Provide(NewCounter),
+)
+
+var _ = kessoku.Inject[*Handler](
+	"InitializeHandler",
+	kessoku.Provide(NewHandler),
+)
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+)
+
+type Config struct {
+	Addr string
+}
+
+func NewConfig() *Config {
+	return &Config{Addr: "localhost:5432"}
+}
+
+var sessions int
+
+type Session struct {
+	ID   int
+	Addr string
+}
+
+func NewSession(config *Config) (*Session, error) {
+	sessions++
+	return &Session{ID: sessions, Addr: config.Addr}, nil
+}
+
+type Counter struct {
+	Count int
+}
+
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+type Handler struct {
+	newSession func() (*Session, error)
+	newCounter func() (*Counter, error)
+}
+
+func NewHandler(newSession func() (*Session, error), newCounter func() (*Counter, error)) *Handler {
+	return &Handler{newSession: newSession, newCounter: newCounter}
+}
+
+func main() {
+	handler := InitializeHandler()
+
+	for range 2 {
+		session, err := handler.newSession()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(session.ID, session.Addr)
+	}
+
+	counter, err := handler.newCounter()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(counter.Count)
+}
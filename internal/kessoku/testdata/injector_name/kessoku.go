@@ -0,0 +1,22 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+var WorkerSet = kessoku.Set(
+	kessoku.InjectorName(),
+	kessoku.Provide(NewLogger),
+	kessoku.Provide(NewWorker),
+)
+
+// Test injector names provided to a set shared by two injectors
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	WorkerSet,
+)
+
+var _ = kessoku.Inject[*Worker](
+	"InitializeBatchWorker",
+	WorkerSet,
+)
@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+type Logger struct {
+	prefix string
+}
+
+func NewLogger(injector string) *Logger {
+	return &Logger{prefix: "[" + injector + "]"}
+}
+
+func (l *Logger) Log(msg string) {
+	fmt.Println(l.prefix, msg)
+}
+
+type Worker struct {
+	logger *Logger
+}
+
+func NewWorker(logger *Logger) *Worker {
+	return &Worker{logger: logger}
+}
+
+func main() {
+	InitializeWorker().logger.Log("started")
+	InitializeBatchWorker().logger.Log("started")
+}
@@ -0,0 +1,22 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test lazy injectors that construct on first call and cache the result
+var _ = kessoku.Inject[*Service](
+	"GetService",
+	kessoku.LazyInjector(),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*Cache](
+	"GetCache",
+	kessoku.LazyInjector(),
+	kessoku.Async(kessoku.Provide(NewClient)),
+	kessoku.Async(kessoku.Provide(NewStore)),
+	kessoku.Provide(NewCache),
+)
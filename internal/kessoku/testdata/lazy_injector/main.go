@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost:5432/app"}
+}
+
+type Database struct {
+	dsn string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{dsn: config.DSN}, nil
+}
+
+type Service struct {
+	db *Database
+}
+
+func NewService(db *Database) *Service {
+	return &Service{db: db}
+}
+
+type Client struct{}
+
+func NewClient(ctx context.Context) (*Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Client{}, nil
+}
+
+type Store struct{}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+type Cache struct {
+	client *Client
+	store  *Store
+}
+
+func NewCache(client *Client, store *Store) *Cache {
+	return &Cache{client: client, store: store}
+}
+
+func main() {
+	service, err := GetService()
+	if err != nil {
+		fmt.Println("Error initializing service:", err)
+		return
+	}
+
+	// A failure while the context is done is not cached, so the next call constructs the cache
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, canceledErr := GetCache(canceled)
+
+	cache, err := GetCache(context.Background())
+	if err != nil {
+		fmt.Println("Error initializing cache:", err)
+		return
+	}
+
+	fmt.Println(service.db.dsn, canceledErr, cache != nil)
+}
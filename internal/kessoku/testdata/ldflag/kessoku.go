@@ -0,0 +1,12 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test build metadata read from a variable set with -ldflags
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.LDFlag[string]("main.version"),
+	kessoku.Provide(NewServer),
+)
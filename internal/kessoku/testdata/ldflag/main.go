@@ -0,0 +1,19 @@
+package main
+
+import "fmt"
+
+// Set with go build -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+type Server struct {
+	version string
+}
+
+func NewServer(version string) *Server {
+	return &Server{version: version}
+}
+
+func main() {
+	server := InitializeServer()
+	fmt.Println(server.version)
+}
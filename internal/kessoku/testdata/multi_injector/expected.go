@@ -15,12 +15,14 @@ func InitializeUserService() (*UserService, error) {
 	userService := kessoku.Provide(NewUserService).Fn()(database)
 	return userService, nil
 }
+
 func InitializeCacheService() *CacheService {
 	config0 := kessoku.Provide(NewConfig).Fn()()
 	cache := kessoku.Provide(NewCache).Fn()(config0)
 	cacheService := kessoku.Provide(NewCacheService).Fn()(cache)
 	return cacheService
 }
+
 func InitializeConfig() *Config {
 	config1 := kessoku.Provide(NewConfig).Fn()()
 	return config1
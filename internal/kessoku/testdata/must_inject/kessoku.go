@@ -0,0 +1,22 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test Must variants that panic instead of returning an error
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.MustInject(),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewApp),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeAppWithConfig",
+	kessoku.MustInject(),
+	kessoku.Arg[*Config](),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewApp),
+)
@@ -0,0 +1,38 @@
+package main
+
+import "fmt"
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost:5432/app"}
+}
+
+type Database struct {
+	dsn string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	if config.DSN == "" {
+		return nil, fmt.Errorf("empty DSN")
+	}
+	return &Database{dsn: config.DSN}, nil
+}
+
+type App struct {
+	db *Database
+}
+
+func NewApp(db *Database) *App {
+	return &App{db: db}
+}
+
+func main() {
+	app := InitializeAppMust()
+	fmt.Println(app.db.dsn)
+
+	app = InitializeAppWithConfigMust(&Config{DSN: "postgres://localhost:5432/other"})
+	fmt.Println(app.db.dsn)
+}
@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"context"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test named contexts that are passed through separately from the errgroup context
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Named[context.Context]("request"),
+	kessoku.Async(kessoku.Provide(NewWorker)),
+	kessoku.Async(kessoku.Provide(NewHandler)),
+	kessoku.Provide(NewApp),
+)
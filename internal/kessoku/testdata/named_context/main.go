@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Worker struct {
+	ctx context.Context
+}
+
+func NewWorker(ctx context.Context) (*Worker, error) {
+	return &Worker{ctx: ctx}, nil
+}
+
+type Handler struct {
+	request context.Context
+}
+
+func NewHandler(request context.Context) *Handler {
+	return &Handler{request: request}
+}
+
+type App struct {
+	Worker  *Worker
+	Handler *Handler
+}
+
+func NewApp(worker *Worker, handler *Handler) *App {
+	return &App{Worker: worker, Handler: handler}
+}
+
+type requestKey struct{}
+
+func main() {
+	request := context.WithValue(context.Background(), requestKey{}, "req-1")
+
+	app, err := InitializeApp(context.Background(), request)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(app.Handler.request.Value(requestKey{}))
+}
@@ -0,0 +1,26 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"io"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test providing typed nil interfaces for optional dependencies
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.Nil[Metrics](),
+	kessoku.Nil[io.Writer](),
+	kessoku.Provide(NewServer),
+)
+
+// Test a typed nil alongside async providers
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.Nil[Metrics](),
+	kessoku.Async(kessoku.Provide(NewQueue)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewWorker),
+)
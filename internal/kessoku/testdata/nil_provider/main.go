@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Metrics is an optional collaborator; nil disables recording.
+type Metrics interface {
+	Record(name string)
+}
+
+type Server struct {
+	metrics Metrics
+	logOut  io.Writer
+}
+
+func NewServer(metrics Metrics, logOut io.Writer) *Server {
+	return &Server{metrics: metrics, logOut: logOut}
+}
+
+func (s *Server) Handle() {
+	if s.metrics != nil {
+		s.metrics.Record("handle")
+	}
+	if s.logOut != nil {
+		fmt.Fprintln(s.logOut, "handled")
+	}
+}
+
+type Queue struct{}
+
+func NewQueue(ctx context.Context) *Queue {
+	return &Queue{}
+}
+
+type Cache struct {
+	metrics Metrics
+}
+
+func NewCache(ctx context.Context, metrics Metrics) (*Cache, error) {
+	return &Cache{metrics: metrics}, nil
+}
+
+type Worker struct {
+	queue   *Queue
+	cache   *Cache
+	metrics Metrics
+}
+
+func NewWorker(queue *Queue, cache *Cache, metrics Metrics) *Worker {
+	return &Worker{queue: queue, cache: cache, metrics: metrics}
+}
+
+func main() {
+	server := InitializeServer()
+	server.Handle()
+
+	worker, err := InitializeWorker(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(server.metrics == nil, worker.metrics == nil)
+}
@@ -0,0 +1,32 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test async providers running in a plain errgroup without a context argument
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.OmitContext(),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Async(kessoku.Provide(NewQueue)),
+	kessoku.Provide(NewApp),
+)
+
+// Test the context argument kept for a provider requiring it
+var _ = kessoku.Inject[*App](
+	"InitializeAppWithContext",
+	kessoku.OmitContext(),
+	kessoku.Async(kessoku.Provide(NewCacheWithContext)),
+	kessoku.Async(kessoku.Provide(NewQueue)),
+	kessoku.Provide(NewApp),
+)
+
+// Test the context argument kept for a provider that can fail
+var _ = kessoku.Inject[*App](
+	"InitializeAppWithRetry",
+	kessoku.OmitContext(),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Async(kessoku.Provide(NewQueueWithRetry)),
+	kessoku.Provide(NewApp),
+)
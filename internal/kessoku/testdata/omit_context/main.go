@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+type Cache struct {
+	Name string
+}
+
+type Queue struct {
+	Name string
+}
+
+type App struct {
+	cache *Cache
+	queue *Queue
+}
+
+func NewCache() *Cache {
+	return &Cache{Name: "redis"}
+}
+
+func NewCacheWithContext(ctx context.Context) (*Cache, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Cache{Name: "redis"}, nil
+}
+
+func NewQueue() *Queue {
+	return &Queue{Name: "sqs"}
+}
+
+// NewQueueWithRetry fails like a connection to a broker, which keeps the context argument
+func NewQueueWithRetry() (*Queue, error) {
+	return &Queue{Name: "sqs"}, nil
+}
+
+func NewApp(cache *Cache, queue *Queue) *App {
+	return &App{cache: cache, queue: queue}
+}
+
+func main() {
+	app := InitializeApp()
+	fmt.Println(app.cache.Name, app.queue.Name)
+
+	app, err := InitializeAppWithContext(context.Background())
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	fmt.Println(app.cache.Name, app.queue.Name)
+
+	app, err = InitializeAppWithRetry(context.Background())
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	fmt.Println(app.cache.Name, app.queue.Name)
+}
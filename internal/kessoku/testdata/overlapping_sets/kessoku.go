@@ -0,0 +1,28 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test sets that share providers, which are collapsed into a single provider
+var ConfigSet = kessoku.Set(
+	kessoku.Provide(NewConfig),
+)
+
+var DatabaseSet = kessoku.Set(
+	ConfigSet,
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+)
+
+var CacheSet = kessoku.Set(
+	ConfigSet,
+	kessoku.Async(kessoku.Provide(NewCache)),
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	DatabaseSet,
+	CacheSet,
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewApp),
+)
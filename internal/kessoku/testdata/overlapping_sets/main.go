@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "memory"}
+}
+
+type Database struct {
+	dsn string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{dsn: config.DSN}, nil
+}
+
+type Cache struct {
+	dsn string
+}
+
+func NewCache(config *Config) *Cache {
+	return &Cache{dsn: config.DSN}
+}
+
+type App struct {
+	db    *Database
+	cache *Cache
+}
+
+func NewApp(db *Database, cache *Cache) *App {
+	return &App{db: db, cache: cache}
+}
+
+func main() {
+	app, err := InitializeApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(app.db.dsn, app.cache.dsn)
+}
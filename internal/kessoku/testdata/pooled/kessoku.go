@@ -0,0 +1,16 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"bytes"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test instances acquired from and released to a sync.Pool
+var _ = kessoku.Inject[*Renderer](
+	"InitializeRenderer",
+	kessoku.Pooled[*bytes.Buffer](newBuffer),
+	kessoku.Provide(NewRenderer),
+)
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+func newBuffer() *bytes.Buffer {
+	return new(bytes.Buffer)
+}
+
+type Renderer struct {
+	acquire func() (*bytes.Buffer, func())
+}
+
+func NewRenderer(acquire func() (*bytes.Buffer, func())) *Renderer {
+	return &Renderer{acquire: acquire}
+}
+
+func (r *Renderer) Render(name string) string {
+	buf, release := r.acquire()
+	defer release()
+
+	buf.Reset()
+	buf.WriteString("hello, ")
+	buf.WriteString(name)
+	return buf.String()
+}
+
+func main() {
+	renderer := InitializeRenderer()
+	fmt.Println(renderer.Render("pool"))
+	fmt.Println(renderer.Render("again"))
+}
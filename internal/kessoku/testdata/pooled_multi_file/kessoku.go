@@ -0,0 +1,16 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"bytes"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test injectors of several files of a package pooling the same type
+var _ = kessoku.Inject[*Renderer](
+	"InitializeRenderer",
+	kessoku.Pooled[*bytes.Buffer](newBuffer),
+	kessoku.Provide(NewRenderer),
+)
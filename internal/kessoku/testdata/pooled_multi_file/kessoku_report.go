@@ -0,0 +1,15 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"bytes"
+
+	"github.com/mazrean/kessoku"
+)
+
+var _ = kessoku.Inject[*Reporter](
+	"InitializeReporter",
+	kessoku.Pooled[*bytes.Buffer](newBuffer),
+	kessoku.Provide(NewReporter),
+)
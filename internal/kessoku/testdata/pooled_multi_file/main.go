@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+func newBuffer() *bytes.Buffer {
+	return new(bytes.Buffer)
+}
+
+type Renderer struct {
+	acquire func() (*bytes.Buffer, func())
+}
+
+func NewRenderer(acquire func() (*bytes.Buffer, func())) *Renderer {
+	return &Renderer{acquire: acquire}
+}
+
+func (r *Renderer) Render(name string) string {
+	buf, release := r.acquire()
+	defer release()
+
+	buf.Reset()
+	buf.WriteString("hello, ")
+	buf.WriteString(name)
+	return buf.String()
+}
+
+type Reporter struct {
+	acquire func() (*bytes.Buffer, func())
+}
+
+func NewReporter(acquire func() (*bytes.Buffer, func())) *Reporter {
+	return &Reporter{acquire: acquire}
+}
+
+func (r *Reporter) Report(count int) string {
+	buf, release := r.acquire()
+	defer release()
+
+	buf.Reset()
+	fmt.Fprintf(buf, "%d rendered", count)
+	return buf.String()
+}
+
+func main() {
+	renderer := InitializeRenderer()
+	reporter := InitializeReporter()
+	fmt.Println(renderer.Render("pool"))
+	fmt.Println(reporter.Report(1))
+}
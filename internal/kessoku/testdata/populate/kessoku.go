@@ -0,0 +1,13 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test filling the fields of a handler owned by a framework
+var _ = kessoku.Populate[*Handler](
+	"PopulateHandler",
+	kessoku.Provide(NewConfig),
+	kessoku.Async(kessoku.Provide(NewDatabase)),
+	kessoku.Async(kessoku.Provide(NewLogger)),
+)
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost/app"}
+}
+
+type Database struct {
+	dsn string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{dsn: config.DSN}, nil
+}
+
+type Logger struct{}
+
+func NewLogger(ctx context.Context) *Logger {
+	return &Logger{}
+}
+
+// Handler is created by a framework, which leaves its dependencies to be populated.
+type Handler struct {
+	DB     *Database
+	Logger *Logger
+	name   string
+}
+
+func main() {
+	handler := &Handler{name: "users"}
+	if err := PopulateHandler(context.Background(), handler); err != nil {
+		panic(err)
+	}
+	fmt.Println(handler.name, handler.DB.dsn)
+}
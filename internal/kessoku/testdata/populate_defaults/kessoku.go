@@ -0,0 +1,12 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test filling fields without providers from their default tags
+var _ = kessoku.Populate[*Config](
+	"PopulateConfig",
+	kessoku.Provide(NewLogger),
+	kessoku.Value(Region("ap-northeast-1")),
+)
@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+type Region string
+
+type Logger struct {
+	prefix string
+}
+
+func NewLogger() *Logger {
+	return &Logger{prefix: "app"}
+}
+
+// Config is filled with the values of its providers, falling back to its default tags.
+type Config struct {
+	Addr    string  `default:":8080"`
+	Workers int     `default:"4"`
+	Debug   bool    `default:"true"`
+	Ratio   float64 `default:"0.5"`
+	Retries uint8   `default:"0x3"`
+	Region  Region  `default:"us-east-1"`
+	Logger  *Logger
+}
+
+func main() {
+	config := &Config{}
+	PopulateConfig(config)
+	fmt.Println(config.Addr, config.Workers, config.Debug, config.Ratio, config.Retries, config.Region, config.Logger.prefix)
+}
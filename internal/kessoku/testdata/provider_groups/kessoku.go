@@ -0,0 +1,31 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// InfraSet labels all of its providers with the infra group
+var InfraSet = kessoku.Set(
+	kessoku.Group("infra"),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+)
+
+// Test section comments for grouped providers
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	InfraSet,
+	kessoku.Provide(NewCache),
+	kessoku.Provide(NewUserService, kessoku.Group("services")),
+	kessoku.Provide(NewApp),
+)
+
+// Test section comments inside async goroutines
+var _ = kessoku.Inject[*App](
+	"InitializeAsyncApp",
+	kessoku.Provide(NewConfig, kessoku.Group("infra")),
+	kessoku.Async(kessoku.Provide(NewDatabase, kessoku.Group("infra"))),
+	kessoku.Async(kessoku.Provide(NewCache, kessoku.Group("infra"))),
+	kessoku.Provide(NewUserService, kessoku.Group("services")),
+	kessoku.Provide(NewApp),
+)
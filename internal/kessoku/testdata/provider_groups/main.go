@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost/app"}
+}
+
+type Database struct {
+	dsn string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{dsn: config.DSN}, nil
+}
+
+type Cache struct{}
+
+func NewCache(ctx context.Context) *Cache {
+	return &Cache{}
+}
+
+type UserService struct {
+	db *Database
+}
+
+func NewUserService(db *Database) *UserService {
+	return &UserService{db: db}
+}
+
+type App struct {
+	users *UserService
+	cache *Cache
+}
+
+func NewApp(users *UserService, cache *Cache) *App {
+	return &App{users: users, cache: cache}
+}
+
+func main() {
+	app, err := InitializeApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	asyncApp, err := InitializeAsyncApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(app.users.db.dsn, asyncApp.users.db.dsn)
+}
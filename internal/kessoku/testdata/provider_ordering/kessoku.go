@@ -0,0 +1,31 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// Test After ordering independent async providers, where the migration is only required by the ordering
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewDB),
+	kessoku.Async(kessoku.Provide(NewMigration)),
+	kessoku.Async(kessoku.Provide(NewFixtures)),
+	kessoku.Async(kessoku.Provide(NewSeed)),
+	kessoku.After[*Migration, *Seed](),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewApp),
+)
+
+// Test After ordering sequential providers
+var _ = kessoku.Inject[*App](
+	"InitializeSyncApp",
+	kessoku.Provide(NewDB),
+	kessoku.Provide(NewMigration),
+	kessoku.Provide(NewFixtures),
+	kessoku.Provide(NewSeed),
+	kessoku.After[*Migration, *Seed](),
+	kessoku.Provide(NewCache),
+	kessoku.Provide(NewApp),
+)
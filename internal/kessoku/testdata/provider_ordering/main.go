@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+type DB struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (db *DB) record(step string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.steps = append(db.steps, step)
+}
+
+func NewDB() *DB {
+	return &DB{}
+}
+
+type Migration struct{}
+
+func NewMigration(db *DB) (*Migration, error) {
+	// Give the seed a chance to run first if the ordering is not respected
+	time.Sleep(10 * time.Millisecond)
+	db.record("migrate")
+	return &Migration{}, nil
+}
+
+type Fixtures struct{}
+
+func NewFixtures() (*Fixtures, error) {
+	return &Fixtures{}, nil
+}
+
+type Seed struct{}
+
+func NewSeed(db *DB, fixtures *Fixtures) (*Seed, error) {
+	db.record("seed")
+	return &Seed{}, nil
+}
+
+type Cache struct{}
+
+func NewCache() (*Cache, error) {
+	return &Cache{}, nil
+}
+
+type App struct {
+	db *DB
+}
+
+func NewApp(db *DB, seed *Seed, cache *Cache) *App {
+	return &App{db: db}
+}
+
+func main() {
+	app, err := InitializeApp(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	syncApp, err := InitializeSyncApp()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, a := range []*App{app, syncApp} {
+		if !slices.Equal(a.db.steps, []string{"migrate", "seed"}) {
+			panic(fmt.Sprintf("unexpected order: %v", a.db.steps))
+		}
+	}
+	fmt.Println("ok")
+}
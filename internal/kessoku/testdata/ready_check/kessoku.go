@@ -0,0 +1,33 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test composing the ready checks of the used providers into the returned function
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewDatabase, kessoku.WithReadyCheck((*Database).Ping)),
+	kessoku.Async(kessoku.Provide(NewCache, kessoku.WithReadyCheck(func(cache *Cache, ctx context.Context) error {
+		if !cache.connected {
+			return errors.New("cache is not connected")
+		}
+		return nil
+	}))),
+	kessoku.Bind[Queue](kessoku.Provide(NewMemoryQueue, kessoku.WithReadyCheck((*MemoryQueue).Ping))),
+	kessoku.Provide(NewApp),
+)
+
+// Test returning the ready check after the closer, skipping checks of unused providers
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.CleanupCloser(),
+	kessoku.Provide(NewDatabase, kessoku.WithReadyCheck((*Database).Ping)),
+	kessoku.Bind[Queue](kessoku.Provide(NewMemoryQueue, kessoku.WithReadyCheck((*MemoryQueue).Ping))),
+	kessoku.Provide(NewWorker),
+)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type Database struct {
+	down bool
+}
+
+func NewDatabase() (*Database, error) {
+	return &Database{}, nil
+}
+
+func (d *Database) Ping(ctx context.Context) error {
+	if d.down {
+		return errors.New("database is down")
+	}
+	return nil
+}
+
+func (d *Database) Close() error {
+	return nil
+}
+
+type Cache struct {
+	connected bool
+}
+
+func NewCache() *Cache {
+	return &Cache{connected: true}
+}
+
+type Queue interface {
+	Push(job string)
+}
+
+type MemoryQueue struct {
+	jobs []string
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{}
+}
+
+func (q *MemoryQueue) Push(job string) {
+	q.jobs = append(q.jobs, job)
+}
+
+func (q *MemoryQueue) Ping(ctx context.Context) error {
+	return nil
+}
+
+type App struct {
+	db    *Database
+	cache *Cache
+	queue Queue
+}
+
+func NewApp(db *Database, cache *Cache, queue Queue) *App {
+	return &App{db: db, cache: cache, queue: queue}
+}
+
+type Worker struct {
+	queue Queue
+}
+
+func NewWorker(queue Queue) *Worker {
+	return &Worker{queue: queue}
+}
+
+func main() {
+	ctx := context.Background()
+
+	app, ready, err := InitializeApp(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(ready(ctx))
+
+	app.db.down = true
+	app.cache.connected = false
+	fmt.Println(ready(ctx))
+
+	_, closer, workerReady := InitializeWorker()
+	fmt.Println(workerReady(ctx), closer.Close())
+}
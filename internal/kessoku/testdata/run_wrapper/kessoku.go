@@ -0,0 +1,24 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// Test running a callback with the result and closing the created values afterwards
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.WithRunWrapper(),
+	kessoku.CleanupCloser(),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewApp),
+)
+
+// Test canceling the context of the providers once the callback returns
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.WithRunWrapper(),
+	kessoku.WithCancel(),
+	kessoku.Provide(NewWorker),
+)
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type Database struct {
+	closed bool
+}
+
+func NewDatabase() (*Database, error) {
+	return &Database{}, nil
+}
+
+func (d *Database) Close() error {
+	d.closed = true
+	return errors.New("flush failed")
+}
+
+type App struct {
+	db *Database
+}
+
+func NewApp(db *Database) *App {
+	return &App{db: db}
+}
+
+type Worker struct {
+	ctx context.Context
+}
+
+func NewWorker(ctx context.Context) *Worker {
+	return &Worker{ctx: ctx}
+}
+
+func main() {
+	var db *Database
+	err := RunInitializeApp(func(app *App) error {
+		db = app.db
+		fmt.Println("closed while running:", db.closed)
+		return errors.New("run failed")
+	})
+	fmt.Println("closed after running:", db.closed)
+	fmt.Println(err)
+
+	var worker *Worker
+	_ = RunInitializeWorker(context.Background(), func(w *Worker) error {
+		worker = w
+		fmt.Println("canceled while running:", w.ctx.Err() != nil)
+		return nil
+	})
+	fmt.Println("canceled after running:", worker.ctx.Err() != nil)
+}
@@ -16,6 +16,7 @@ func InitializeAppBasic() (*App, error) {
 	app := kessoku.Provide(NewApp).Fn()(userService)
 	return app, nil
 }
+
 func InitializeAppWithInlineSet() (*App, error) {
 	config0 := kessoku.Provide(NewConfig).Fn()()
 	var err0 error
@@ -28,6 +29,7 @@ func InitializeAppWithInlineSet() (*App, error) {
 	app0 := kessoku.Provide(NewApp).Fn()(userService0)
 	return app0, nil
 }
+
 func InitializeAppWithSetVariable() (*App, error) {
 	config1 := kessoku.Provide(NewConfig).Fn()()
 	var err1 error
@@ -40,6 +42,7 @@ func InitializeAppWithSetVariable() (*App, error) {
 	app1 := kessoku.Provide(NewApp).Fn()(userService1)
 	return app1, nil
 }
+
 func InitializeAppWithNestedSets() (*App, error) {
 	config2 := kessoku.Provide(NewConfig).Fn()()
 	var err2 error
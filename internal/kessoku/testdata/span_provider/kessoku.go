@@ -0,0 +1,15 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test providers wrapped in spans started by the injected kessoku.Tracer
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig, kessoku.Span("init-config")),
+	kessoku.Async(kessoku.Provide(NewDatabase, kessoku.Span("init-database"))),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Async(kessoku.Provide(NewQueue, kessoku.Span("init-queue"))),
+	kessoku.Provide(NewApp),
+)
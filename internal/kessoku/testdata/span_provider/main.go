@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type spanKey struct{}
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost"}
+}
+
+type Database struct {
+	span string
+}
+
+func NewDatabase(ctx context.Context, config *Config) (*Database, error) {
+	span, _ := ctx.Value(spanKey{}).(string)
+	return &Database{span: span}, nil
+}
+
+type Cache struct{}
+
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+type Queue struct{}
+
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+type App struct {
+	db    *Database
+	cache *Cache
+	queue *Queue
+}
+
+func NewApp(db *Database, cache *Cache, queue *Queue) *App {
+	return &App{db: db, cache: cache, queue: queue}
+}
+
+func main() {
+	var (
+		mu    sync.Mutex
+		ended []string
+	)
+	tracer := func(ctx context.Context, name string) (context.Context, func()) {
+		return context.WithValue(ctx, spanKey{}, name), func() {
+			mu.Lock()
+			defer mu.Unlock()
+			ended = append(ended, name)
+		}
+	}
+
+	app, err := InitializeApp(context.Background(), tracer)
+	if err != nil {
+		panic(err)
+	}
+
+	sort.Strings(ended)
+	fmt.Println(app.db.span, ended)
+}
@@ -0,0 +1,15 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test expansion of the fields of structs nested in an expanded struct
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig),
+	kessoku.Struct[*Config](),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewServer),
+	kessoku.Provide(NewApp),
+)
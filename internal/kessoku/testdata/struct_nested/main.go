@@ -0,0 +1,62 @@
+package main
+
+import "fmt"
+
+type DatabaseURL string
+
+type ListenAddr string
+
+type DatabaseConfig struct {
+	Replica  *DatabaseConfig
+	URL      DatabaseURL
+	MaxConns int
+}
+
+type ServerConfig struct {
+	Addr  ListenAddr
+	Debug bool
+}
+
+type Config struct {
+	Database DatabaseConfig
+	Server   ServerConfig
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Database: DatabaseConfig{URL: "postgres://localhost", MaxConns: 10},
+		Server:   ServerConfig{Addr: ":8080", Debug: true},
+	}
+}
+
+type Database struct {
+	url      DatabaseURL
+	maxConns int
+}
+
+func NewDatabase(url DatabaseURL, maxConns int) *Database {
+	return &Database{url: url, maxConns: maxConns}
+}
+
+type Server struct {
+	config ServerConfig
+	addr   ListenAddr
+}
+
+func NewServer(config ServerConfig, addr ListenAddr) *Server {
+	return &Server{config: config, addr: addr}
+}
+
+type App struct {
+	db     *Database
+	server *Server
+}
+
+func NewApp(db *Database, server *Server) *App {
+	return &App{db: db, server: server}
+}
+
+func main() {
+	app := InitializeApp()
+	fmt.Println(app.db.url, app.db.maxConns, app.server.addr, app.server.config.Debug)
+}
@@ -0,0 +1,31 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+// StorageSet declares every storage implementation, each labeled with a tag
+var StorageSet = kessoku.Set(
+	kessoku.Provide(NewS3Storage, kessoku.Tag("s3")),
+	kessoku.Provide(NewGCSStorage, kessoku.Tag("gcs")),
+)
+
+// Test selecting the S3 storage
+var _ = kessoku.Inject[*App](
+	"InitializeS3App",
+	StorageSet,
+	kessoku.Use[*Storage]("s3"),
+	kessoku.Value(&Config{Bucket: "assets"}),
+	kessoku.Provide(NewApp),
+)
+
+// Test selecting the GCS storage from the same set
+var _ = kessoku.Inject[*App](
+	"InitializeGCSApp",
+	StorageSet,
+	kessoku.Use[*Storage]("gcs"),
+	kessoku.Value(&Config{Bucket: "assets"}),
+	kessoku.Provide(NewApp),
+)
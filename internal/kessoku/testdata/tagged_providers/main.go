@@ -0,0 +1,38 @@
+package main
+
+import "fmt"
+
+type Config struct {
+	Bucket string
+}
+
+type Storage struct {
+	URL string
+}
+
+func NewS3Storage(config *Config) *Storage {
+	return &Storage{URL: "s3://" + config.Bucket}
+}
+
+func NewGCSStorage(config *Config) (*Storage, error) {
+	return &Storage{URL: "gs://" + config.Bucket}, nil
+}
+
+type App struct {
+	storage *Storage
+}
+
+func NewApp(storage *Storage) *App {
+	return &App{storage: storage}
+}
+
+func main() {
+	s3App := InitializeS3App()
+
+	gcsApp, err := InitializeGCSApp()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(s3App.storage.URL, gcsApp.storage.URL)
+}
@@ -0,0 +1,18 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/mazrean/kessoku"
+)
+
+// Test values computed by fallible expressions
+var _ = kessoku.Inject[*Client](
+	"InitializeClient",
+	kessoku.ValueE(url.Parse("https://api.example.com")),
+	kessoku.ValueE(time.ParseDuration("30s")),
+	kessoku.Provide(NewClient),
+)
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type Client struct {
+	baseURL *url.URL
+	timeout time.Duration
+}
+
+func NewClient(baseURL *url.URL, timeout time.Duration) *Client {
+	return &Client{baseURL: baseURL, timeout: timeout}
+}
+
+func main() {
+	client, err := InitializeClient()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	fmt.Println(client.baseURL, client.timeout)
+}
@@ -0,0 +1,23 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test returning the cancel function of the context given to the providers
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.WithCancel(),
+	kessoku.Provide(NewWorker),
+	kessoku.Provide(NewApp),
+)
+
+// Test canceling the context when a provider fails
+var _ = kessoku.Inject[*Server](
+	"InitializeServer",
+	kessoku.WithCancel(),
+	kessoku.Provide(NewWorker),
+	kessoku.Async(kessoku.Provide(NewListener)),
+	kessoku.Async(kessoku.Provide(NewCache)),
+	kessoku.Provide(NewServer),
+)
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Worker runs in the background until its context is canceled.
+type Worker struct {
+	done chan struct{}
+}
+
+func NewWorker(ctx context.Context) *Worker {
+	w := &Worker{done: make(chan struct{})}
+	go func() {
+		<-ctx.Done()
+		close(w.done)
+	}()
+	return w
+}
+
+type App struct {
+	worker *Worker
+}
+
+func NewApp(worker *Worker) *App {
+	return &App{worker: worker}
+}
+
+type Listener struct{}
+
+func NewListener() (*Listener, error) {
+	return nil, errors.New("address in use")
+}
+
+type Cache struct{}
+
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+type Server struct {
+	worker   *Worker
+	listener *Listener
+	cache    *Cache
+}
+
+func NewServer(worker *Worker, listener *Listener, cache *Cache) *Server {
+	return &Server{worker: worker, listener: listener, cache: cache}
+}
+
+func main() {
+	app, cancel := InitializeApp(context.Background())
+	cancel()
+	<-app.worker.done
+
+	_, cancel, err := InitializeServer(context.Background())
+	fmt.Println("stopped", cancel == nil, err)
+}
@@ -0,0 +1,23 @@
+package main
+
+//go:generate go tool kessoku $GOFILE
+
+import "github.com/mazrean/kessoku"
+
+// Test injectors returning a custom error type converted by WrapError
+var _ = kessoku.Inject[*Service](
+	"InitializeService",
+	kessoku.WrapError(NewInitError),
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewService),
+)
+
+var _ = kessoku.Inject[*Cache](
+	"InitializeCache",
+	kessoku.WrapError(NewInitError),
+	kessoku.MustInject(),
+	kessoku.Async(kessoku.Provide(NewClient)),
+	kessoku.Async(kessoku.Provide(NewStore)),
+	kessoku.Provide(NewCache),
+)
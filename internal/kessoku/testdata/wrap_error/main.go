@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// InitError is a structured initialization error.
+type InitError struct {
+	Err error
+}
+
+func (e *InitError) Error() string {
+	return "initialize: " + e.Err.Error()
+}
+
+func (e *InitError) Unwrap() error {
+	return e.Err
+}
+
+func NewInitError(err error) *InitError {
+	return &InitError{Err: err}
+}
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+type Database struct {
+	dsn string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	if config.DSN == "" {
+		return nil, errors.New("empty DSN")
+	}
+	return &Database{dsn: config.DSN}, nil
+}
+
+type Service struct {
+	db *Database
+}
+
+func NewService(db *Database) *Service {
+	return &Service{db: db}
+}
+
+type Client struct{}
+
+func NewClient() (*Client, error) {
+	return &Client{}, nil
+}
+
+type Store struct{}
+
+func NewStore() (*Store, error) {
+	return &Store{}, nil
+}
+
+type Cache struct {
+	client *Client
+	store  *Store
+}
+
+func NewCache(client *Client, store *Store) *Cache {
+	return &Cache{client: client, store: store}
+}
+
+func main() {
+	if _, err := InitializeService(); err != nil {
+		fmt.Println("Error initializing service:", err.Err)
+	}
+
+	cache := InitializeCacheMust(context.Background())
+	fmt.Println(cache != nil)
+}
@@ -8,7 +8,8 @@ import (
 )
 
 type VarPool struct {
-	vars map[string]int
+	vars      map[string]int
+	typeNames map[string]string
 }
 
 func NewVarPool() *VarPool {
@@ -25,6 +26,12 @@ func NewVarPool() *VarPool {
 	}
 }
 
+// SetTypeNames overrides the base variable names used for specific types.
+// Keys are type strings as printed by go/types (e.g. "*database/sql.DB", "context.Context").
+func (p *VarPool) SetTypeNames(typeNames map[string]string) {
+	p.typeNames = typeNames
+}
+
 func (p *VarPool) GetName(baseName string) string {
 	count := p.vars[baseName]
 	p.vars[baseName] = count + 1
@@ -36,6 +43,22 @@ func (p *VarPool) GetName(baseName string) string {
 	return fmt.Sprintf("%s%d", baseName, count-1)
 }
 
+// Reserve claims name as is, reporting false if it is already declared or reserved.
+func (p *VarPool) Reserve(name string) bool {
+	if p.vars[name] > 0 {
+		return false
+	}
+	p.vars[name] = 1
+
+	return true
+}
+
+// GetInjectorVarName returns a package-level variable name derived from an injector name,
+// e.g. "InitializeApp" with suffix "Once" becomes "initializeAppOnce".
+func (p *VarPool) GetInjectorVarName(injectorName, suffix string) string {
+	return p.GetName(strings.ToLowerCamel(injectorName) + suffix)
+}
+
 func (p *VarPool) Get(t types.Type) string {
 	name := p.getBaseName(t)
 
@@ -60,9 +83,17 @@ func (p *VarPool) GetChannel(t types.Type) string {
 
 // getTypeBaseName extracts a base name from a type for argument naming
 func (p *VarPool) getBaseName(t types.Type) string {
+	if name, ok := p.typeNames[t.String()]; ok {
+		return name
+	}
+
 	// For pointers, recurse on the element type
 	for ptr, ok := t.(*types.Pointer); ok; ptr, ok = t.(*types.Pointer) {
 		t = ptr.Elem()
+
+		if name, ok := p.typeNames[t.String()]; ok {
+			return name
+		}
 	}
 
 	var baseName string
@@ -228,6 +228,79 @@ func TestVarPool_GetBaseName(t *testing.T) {
 			typeExpr: types.NewChan(types.SendRecv, types.Typ[types.String]),
 			expected: "val",
 		},
+		{
+			name:     "receive-only chan type",
+			typeExpr: types.NewChan(types.RecvOnly, types.Typ[types.String]),
+			expected: "val",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := pool.getBaseName(tt.typeExpr)
+			if result != tt.expected {
+				t.Errorf("getBaseName() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVarPool_SetTypeNames(t *testing.T) {
+	t.Parallel()
+
+	dbType := func() types.Type {
+		pkg := types.NewPackage("database/sql", "sql")
+		obj := types.NewTypeName(0, pkg, "DB", nil)
+		return types.NewNamed(obj, types.NewStruct(nil, nil), nil)
+	}()
+	ctxType := func() types.Type {
+		pkg := types.NewPackage("context", "context")
+		obj := types.NewTypeName(0, pkg, "Context", nil)
+		return types.NewNamed(obj, types.NewInterfaceType([]*types.Func{}, nil), nil)
+	}()
+	configType := func() types.Type {
+		pkg := types.NewPackage("example.com/app", "app")
+		obj := types.NewTypeName(0, pkg, "Config", nil)
+		return types.NewNamed(obj, types.NewStruct(nil, nil), nil)
+	}()
+
+	pool := NewVarPool()
+	pool.SetTypeNames(map[string]string{
+		"*database/sql.DB": "conn",
+		"context.Context":  "c",
+	})
+
+	tests := []struct {
+		typeExpr types.Type
+		name     string
+		expected string
+	}{
+		{
+			name:     "overridden pointer type",
+			typeExpr: types.NewPointer(dbType),
+			expected: "conn",
+		},
+		{
+			name:     "non-pointer type without override",
+			typeExpr: dbType,
+			expected: "db",
+		},
+		{
+			name:     "overridden element of pointer type",
+			typeExpr: types.NewPointer(ctxType),
+			expected: "c",
+		},
+		{
+			name:     "overridden builtin mapping",
+			typeExpr: ctxType,
+			expected: "c",
+		},
+		{
+			name:     "default name",
+			typeExpr: types.NewPointer(configType),
+			expected: "config",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,9 +1,13 @@
 package migrate
 
 import (
+	"cmp"
 	"fmt"
 	"go/ast"
+	"io"
 	"log/slog"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -13,6 +17,7 @@ import (
 type Migrator struct {
 	parser      *Parser
 	transformer *Transformer
+	unsupported []UnsupportedConstruct
 }
 
 // NewMigrator creates a new Migrator instance.
@@ -26,7 +31,105 @@ func NewMigrator() *Migrator {
 // MigrateFiles migrates the specified wire files to kessoku format.
 // patterns are Go package patterns (e.g., "./", "./pkg/...", "example.com/pkg").
 func (m *Migrator) MigrateFiles(patterns []string, outputPath string) error {
-	// Load packages with type info
+	m.unsupported = nil
+
+	pkgs, err := loadPackages(patterns)
+	if err != nil {
+		return err
+	}
+
+	// Check for load errors
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return m.convertPackageError(pkg.Errors[0])
+		}
+	}
+
+	_, err = m.migratePackages(pkgs, outputPath)
+	return err
+}
+
+// PackageMigration is the outcome of migrating a single package with MigrateProject.
+type PackageMigration struct {
+	Err     error  // Reason the migration failed, nil on success
+	PkgPath string // Import path of the package
+	Output  string // Written kessoku file, empty if the package has no wire patterns
+}
+
+// MigrateProject migrates every package matched by patterns (e.g., "./...") that imports wire,
+// writing outputName into each package directory. Sets referenced across packages keep their
+// names, so the migrated packages refer to each other's sets like the wire ones did.
+// A failing package does not stop the others; the outcome of each package is returned sorted by import path.
+func (m *Migrator) MigrateProject(patterns []string, outputName string) ([]PackageMigration, error) {
+	m.unsupported = nil
+
+	pkgs, err := loadPackages(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []PackageMigration
+	for _, pkg := range pkgs {
+		if _, ok := pkg.Imports[wireImportPath]; !ok || len(pkg.GoFiles) == 0 {
+			continue
+		}
+
+		migration := PackageMigration{PkgPath: pkg.PkgPath}
+		if len(pkg.Errors) > 0 {
+			migration.Err = m.convertPackageError(pkg.Errors[0])
+		} else {
+			outputPath := filepath.Join(filepath.Dir(pkg.GoFiles[0]), outputName)
+
+			var written bool
+			written, migration.Err = m.migratePackages([]*packages.Package{pkg}, outputPath)
+			if written {
+				migration.Output = outputPath
+			}
+		}
+		migrations = append(migrations, migration)
+	}
+
+	slices.SortFunc(migrations, func(a, b PackageMigration) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
+
+	return migrations, nil
+}
+
+// Unsupported returns the constructs that the last migration left out of its output,
+// sorted by position. They need to be migrated manually.
+func (m *Migrator) Unsupported() []UnsupportedConstruct {
+	constructs := slices.Clone(m.unsupported)
+	slices.SortStableFunc(constructs, func(a, b UnsupportedConstruct) int {
+		return cmp.Or(
+			strings.Compare(a.Position.Filename, b.Position.Filename),
+			cmp.Compare(a.Position.Offset, b.Position.Offset),
+		)
+	})
+	return constructs
+}
+
+// WriteUnsupportedReport writes a report of constructs to w, listing the position of each
+// construct with its suggested manual fix.
+func WriteUnsupportedReport(w io.Writer, constructs []UnsupportedConstruct) error {
+	if len(constructs) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "%d wire construct(s) could not be migrated and need manual changes:\n", len(constructs)); err != nil {
+		return err
+	}
+	for _, c := range constructs {
+		if _, err := fmt.Fprintf(w, "%s: %s\n\tfix: %s\n", c.Position, c.Construct, c.Fix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPackages loads the packages matched by patterns with type information.
+func loadPackages(patterns []string) ([]*packages.Package, error) {
 	// Use wireinject build tag to load wire configuration files
 	cfg := &packages.Config{
 		Mode: packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo |
@@ -36,16 +139,15 @@ func (m *Migrator) MigrateFiles(patterns []string, outputPath string) error {
 
 	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
-		return fmt.Errorf("failed to load packages: %w", err)
+		return nil, fmt.Errorf("failed to load packages: %w", err)
 	}
 
-	// Check for load errors
-	for _, pkg := range pkgs {
-		if len(pkg.Errors) > 0 {
-			return m.convertPackageError(pkg.Errors[0])
-		}
-	}
+	return pkgs, nil
+}
 
+// migratePackages migrates the wire files of pkgs into a single kessoku file at outputPath.
+// It reports whether the file was written, which is not the case when no wire patterns are found.
+func (m *Migrator) migratePackages(pkgs []*packages.Package, outputPath string) (bool, error) {
 	// Extract wire patterns from each file
 	var results []MigrationResult
 	var allWarnings []Warning
@@ -86,7 +188,7 @@ func (m *Migrator) MigrateFiles(patterns []string, outputPath string) error {
 			}
 
 			// Extract source imports for package reference resolution
-			sourceImports := m.parser.ExtractImports(file)
+			sourceImports := m.parser.ExtractImports(file, pkg.TypesInfo)
 
 			// Extract patterns
 			patterns, warnings := m.parser.ExtractPatterns(file, pkg.TypesInfo, wireImport, filePath)
@@ -101,10 +203,13 @@ func (m *Migrator) MigrateFiles(patterns []string, outputPath string) error {
 			}
 
 			// Transform patterns
-			var kessokuPatterns []KessokuPattern
-			kessokuPatterns, err = m.transformer.Transform(patterns, pkg.Types, sharedTypeConverter)
+			kessokuPatterns, err := m.transformer.Transform(patterns, pkg.Types, sharedTypeConverter)
 			if err != nil {
-				return err
+				return false, err
+			}
+			for _, u := range m.transformer.Unsupported() {
+				u.Position = pkg.Fset.Position(u.Pos)
+				m.unsupported = append(m.unsupported, u)
 			}
 
 			results = append(results, MigrationResult{
@@ -127,22 +232,22 @@ func (m *Migrator) MigrateFiles(patterns []string, outputPath string) error {
 	// Check if we have any results
 	if len(results) == 0 {
 		slog.Warn("No wire patterns found in any input file, no output generated")
-		return nil
+		return false, nil
 	}
 
 	// Merge results and create writer
 	merged, writer, err := m.mergeResults(results, sharedTypeConverter)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Write output
 	if err := writer.Write(merged, outputPath); err != nil {
-		return err
+		return false, err
 	}
 
 	slog.Info("Generated kessoku configuration", "output", outputPath)
-	return nil
+	return true, nil
 }
 
 // convertPackageError converts packages.Error to ParseError.
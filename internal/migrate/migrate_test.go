@@ -164,3 +164,121 @@ type MigrateCmd struct {
 	Output   string
 	Patterns []string
 }
+
+// TestMigrateProject tests migrating every wire package of a multi-package project at once.
+func TestMigrateProject(t *testing.T) {
+	projectDir := filepath.Join("testdata", "project")
+	outputName := "kessoku_migrated.go"
+
+	migrator := NewMigrator()
+	migrations, err := migrator.MigrateProject([]string{"./" + projectDir + "/..."}, outputName)
+	t.Cleanup(func() {
+		for _, migration := range migrations {
+			if migration.Output != "" {
+				_ = os.Remove(migration.Output)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	// The model package does not import wire and is not migrated
+	pkgPrefix := "github.com/mazrean/kessoku/internal/migrate/testdata/project/"
+	expected := []struct {
+		pkg           string
+		errorContains string
+	}{
+		{pkg: "app"},
+		{pkg: "broken", errorContains: "multiple constructors found"},
+		{pkg: "infra"},
+	}
+	if len(migrations) != len(expected) {
+		t.Fatalf("expected %d migrated packages, got %d: %+v", len(expected), len(migrations), migrations)
+	}
+
+	for i, want := range expected {
+		migration := migrations[i]
+		if migration.PkgPath != pkgPrefix+want.pkg {
+			t.Errorf("migration %d: expected package %s, got %s", i, pkgPrefix+want.pkg, migration.PkgPath)
+			continue
+		}
+
+		if want.errorContains != "" {
+			if migration.Err == nil || !strings.Contains(migration.Err.Error(), want.errorContains) {
+				t.Errorf("%s: expected error containing %q, got %v", want.pkg, want.errorContains, migration.Err)
+			}
+			if migration.Output != "" {
+				t.Errorf("%s: expected no output, got %s", want.pkg, migration.Output)
+			}
+			continue
+		}
+
+		if migration.Err != nil {
+			t.Errorf("%s: unexpected error: %v", want.pkg, migration.Err)
+			continue
+		}
+
+		dir, err := filepath.Abs(filepath.Join(projectDir, want.pkg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if migration.Output != filepath.Join(dir, outputName) {
+			t.Errorf("%s: expected output %s, got %s", want.pkg, filepath.Join(dir, outputName), migration.Output)
+			continue
+		}
+
+		outputBytes, err := os.ReadFile(migration.Output)
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		expectedBytes, err := os.ReadFile(filepath.Join(dir, "expected.golden"))
+		if err != nil {
+			t.Fatalf("failed to read expected.golden: %v", err)
+		}
+		if string(outputBytes) != string(expectedBytes) {
+			t.Errorf("%s: output mismatch:\n--- expected ---\n%s\n--- got ---\n%s",
+				want.pkg, string(expectedBytes), string(outputBytes))
+		}
+	}
+}
+
+// TestUnsupportedReport tests that constructs left out of the migration are reported with a fix.
+func TestUnsupportedReport(t *testing.T) {
+	inputFile := filepath.Join("testdata", "struct_external_unexported", "input.go")
+	outputPath := filepath.Join(t.TempDir(), "output.go")
+
+	migrator := NewMigrator()
+	if err := migrator.MigrateFiles([]string{inputFile}, outputPath); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	unsupported := migrator.Unsupported()
+	if len(unsupported) != 1 {
+		t.Fatalf("expected 1 unsupported construct, got %d: %+v", len(unsupported), unsupported)
+	}
+	if filepath.Base(unsupported[0].Position.Filename) != "input.go" || unsupported[0].Position.Line != 10 {
+		t.Errorf("expected position input.go:10, got %s", unsupported[0].Position)
+	}
+
+	var report strings.Builder
+	if err := WriteUnsupportedReport(&report, unsupported); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	for _, want := range []string{
+		"input.go:10:30: wire.Struct field secret of external.Options, which is unexported",
+		"fix: add a constructor setting secret to package",
+	} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, report.String())
+		}
+	}
+
+	// Migrations without unsupported constructs report nothing
+	if err := migrator.MigrateFiles([]string{filepath.Join("testdata", "struct_all", "input.go")}, outputPath); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	if unsupported := migrator.Unsupported(); len(unsupported) != 0 {
+		t.Errorf("expected no unsupported constructs, got %+v", unsupported)
+	}
+}
@@ -1,12 +1,16 @@
 package migrate
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
 	"strings"
 )
 
+// wireImportPath is the import path of google/wire.
+const wireImportPath = "github.com/google/wire"
+
 // Constants for wire pattern argument counts.
 const (
 	// wireBindArgCount is the expected number of arguments for wire.Bind.
@@ -32,7 +36,7 @@ func NewParser() *Parser {
 func (p *Parser) FindWireImport(file *ast.File) string {
 	for _, imp := range file.Imports {
 		path := strings.Trim(imp.Path.Value, "\"")
-		if path == "github.com/google/wire" {
+		if path == wireImportPath {
 			if imp.Name != nil {
 				return imp.Name.Name
 			}
@@ -43,14 +47,19 @@ func (p *Parser) FindWireImport(file *ast.File) string {
 }
 
 // ExtractImports extracts all imports from a file as a map from package name/alias to import path.
-func (p *Parser) ExtractImports(file *ast.File) map[string]string {
+// The names of unaliased imports are taken from info, as a package name may differ from
+// the last element of its import path (e.g. "example.com/foo/v2" or "example.com/go-foo").
+func (p *Parser) ExtractImports(file *ast.File, info *types.Info) map[string]string {
 	imports := make(map[string]string)
 	for _, imp := range file.Imports {
 		path := strings.Trim(imp.Path.Value, "\"")
 		var name string
-		if imp.Name != nil {
+		switch {
+		case imp.Name != nil:
 			name = imp.Name.Name
-		} else {
+		case info != nil && info.Implicits[imp] != nil:
+			name = info.Implicits[imp].Name()
+		default:
 			// Use the last element of the path as the default package name
 			name = lastPathElement(path)
 		}
@@ -67,6 +76,8 @@ func (p *Parser) ExtractPatterns(file *ast.File, info *types.Info, wireAlias str
 	var patterns []WirePattern
 	var warnings []Warning
 
+	sets := collectLocalSets(file, info, wireAlias)
+
 	// Visit all declarations
 	for _, decl := range file.Decls {
 		switch d := decl.(type) {
@@ -93,10 +104,16 @@ func (p *Parser) ExtractPatterns(file *ast.File, info *types.Info, wireAlias str
 						varName = valueSpec.Names[i].Name
 					}
 
-					pattern, warn := p.parseCallExpr(call, info, wireAlias, filePath, varName)
+					pattern, warn := p.parseCallExpr(call, info, wireAlias, filePath, varName, sets)
 					if warn != nil {
 						warnings = append(warnings, *warn)
 					}
+					if pattern == nil {
+						// var Set = ProviderSet() takes over the set returned by the function
+						if setCall := sets.resolve(call, info); setCall != nil {
+							pattern = p.parseNewSet(setCall, info, wireAlias, filePath, varName, sets)
+						}
+					}
 					if pattern != nil {
 						patterns = append(patterns, pattern)
 					}
@@ -111,29 +128,13 @@ func (p *Parser) ExtractPatterns(file *ast.File, info *types.Info, wireAlias str
 
 			// Look for wire.Build calls in the function body
 			for _, stmt := range d.Body.List {
-				exprStmt, ok := stmt.(*ast.ExprStmt)
-				if !ok {
-					continue
-				}
-
-				call, ok := exprStmt.X.(*ast.CallExpr)
-				if !ok {
-					continue
-				}
-
-				// Check if it's a wire.Build call
-				sel, ok := call.Fun.(*ast.SelectorExpr)
-				if !ok {
-					continue
-				}
-
-				ident, ok := sel.X.(*ast.Ident)
-				if !ok || ident.Name != wireAlias || sel.Sel.Name != "Build" {
+				call := wireBuildCall(stmt, wireAlias)
+				if call == nil {
 					continue
 				}
 
 				// Parse wire.Build
-				buildPattern := p.parseBuild(call, d, info, wireAlias, filePath)
+				buildPattern := p.parseBuild(call, d, info, wireAlias, filePath, sets)
 				if buildPattern != nil {
 					patterns = append(patterns, buildPattern)
 				}
@@ -141,11 +142,14 @@ func (p *Parser) ExtractPatterns(file *ast.File, info *types.Info, wireAlias str
 		}
 	}
 
+	resolveStructForms(patterns)
+	warnings = append(warnings, sets.warnings...)
+
 	return patterns, warnings
 }
 
 // parseCallExpr parses a call expression and returns a wire pattern if applicable.
-func (p *Parser) parseCallExpr(call *ast.CallExpr, info *types.Info, wireAlias string, filePath string, varName string) (WirePattern, *Warning) {
+func (p *Parser) parseCallExpr(call *ast.CallExpr, info *types.Info, wireAlias string, filePath string, varName string, sets *localSets) (WirePattern, *Warning) {
 	sel, ok := call.Fun.(*ast.SelectorExpr)
 	if !ok {
 		return nil, nil
@@ -158,7 +162,7 @@ func (p *Parser) parseCallExpr(call *ast.CallExpr, info *types.Info, wireAlias s
 
 	switch sel.Sel.Name {
 	case "NewSet":
-		return p.parseNewSet(call, info, wireAlias, filePath, varName), nil
+		return p.parseNewSet(call, info, wireAlias, filePath, varName, sets), nil
 	case "Bind":
 		return p.parseBind(call, info, filePath), nil
 	case "Value":
@@ -178,7 +182,7 @@ func (p *Parser) parseCallExpr(call *ast.CallExpr, info *types.Info, wireAlias s
 }
 
 // parseNewSet parses wire.NewSet(...) pattern.
-func (p *Parser) parseNewSet(call *ast.CallExpr, info *types.Info, wireAlias string, filePath string, varName string) *WireNewSet {
+func (p *Parser) parseNewSet(call *ast.CallExpr, info *types.Info, wireAlias string, filePath string, varName string, sets *localSets) *WireNewSet {
 	set := &WireNewSet{
 		baseWirePattern: baseWirePattern{
 			Pos:  call.Pos(),
@@ -188,7 +192,7 @@ func (p *Parser) parseNewSet(call *ast.CallExpr, info *types.Info, wireAlias str
 	}
 
 	for _, arg := range call.Args {
-		elem := p.parseSetElement(arg, info, wireAlias, filePath)
+		elem := p.parseSetElement(arg, info, wireAlias, filePath, sets)
 		if elem != nil {
 			set.Elements = append(set.Elements, elem)
 		}
@@ -198,15 +202,26 @@ func (p *Parser) parseNewSet(call *ast.CallExpr, info *types.Info, wireAlias str
 }
 
 // parseSetElement parses an element within wire.NewSet.
-func (p *Parser) parseSetElement(expr ast.Expr, info *types.Info, wireAlias string, filePath string) WirePattern {
+func (p *Parser) parseSetElement(expr ast.Expr, info *types.Info, wireAlias string, filePath string, sets *localSets) WirePattern {
 	switch e := expr.(type) {
 	case *ast.CallExpr:
 		// Nested wire call (Bind, Value, etc.)
-		pattern, _ := p.parseCallExpr(e, info, wireAlias, filePath, "")
-		return pattern
+		pattern, _ := p.parseCallExpr(e, info, wireAlias, filePath, "", sets)
+		if pattern != nil {
+			return pattern
+		}
+		if !isWireCall(e, wireAlias) {
+			// Set returned by a function of the file, inlined into the enclosing set
+			return p.parseLocalSet(e, info, wireAlias, filePath, sets)
+		}
+		return nil
 	case *ast.Ident:
 		// Could be a provider function or set reference
 		if obj := info.ObjectOf(e); obj != nil {
+			if v, ok := obj.(*types.Var); ok && v.Pkg() != nil && v.Parent() != v.Pkg().Scope() {
+				// Set assigned to a function-local variable, inlined into the enclosing set
+				return p.parseLocalSet(e, info, wireAlias, filePath, sets)
+			}
 			if fn, ok := obj.(*types.Func); ok {
 				return &WireProviderFunc{
 					baseWirePattern: baseWirePattern{
@@ -266,6 +281,176 @@ func (p *Parser) parseSetElement(expr ast.Expr, info *types.Info, wireAlias stri
 	return nil
 }
 
+// parseLocalSet parses a set that is not a package-level variable, such as a function-local
+// set variable or a call of a function returning a set, into an inline wire.NewSet.
+// A set that cannot be resolved statically is reported as a warning and skipped.
+func (p *Parser) parseLocalSet(expr ast.Expr, info *types.Info, wireAlias string, filePath string, sets *localSets) WirePattern {
+	setCall := sets.resolve(expr, info)
+	if setCall == nil {
+		sets.warnings = append(sets.warnings, Warning{
+			Code: WarnUnsupportedPattern,
+			Message: fmt.Sprintf("Cannot statically resolve provider set %s in %s, migrate it manually",
+				types.ExprString(expr), filePath),
+			Pos: expr.Pos(),
+		})
+		return nil
+	}
+
+	obj := sets.object(expr, info)
+	sets.resolving[obj] = true
+	defer delete(sets.resolving, obj)
+
+	return p.parseNewSet(setCall, info, wireAlias, filePath, "", sets)
+}
+
+// localSets holds the wire.NewSet calls of a file that define sets other than package-level variables:
+// sets assigned once to a function-local variable, and sets returned by functions without parameters
+// whose body is a single return statement.
+type localSets struct {
+	defs      map[types.Object]*ast.CallExpr
+	resolving map[types.Object]bool // Sets being parsed, to stop at self references
+	warnings  []Warning
+}
+
+// collectLocalSets collects the function-local and function-returned sets of file.
+func collectLocalSets(file *ast.File, info *types.Info, wireAlias string) *localSets {
+	sets := &localSets{
+		defs:      make(map[types.Object]*ast.CallExpr),
+		resolving: make(map[types.Object]bool),
+	}
+	assigned := make(map[types.Object]int)
+
+	addVar := func(name *ast.Ident, value ast.Expr) {
+		obj := info.ObjectOf(name)
+		if obj == nil {
+			return
+		}
+		assigned[obj]++
+		if call, ok := ast.Unparen(value).(*ast.CallExpr); ok && isWireCall(call, wireAlias) && wireCallName(call) == "NewSet" {
+			sets.defs[obj] = call
+		}
+	}
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+
+		// func ProviderSet() wire.ProviderSet { return wire.NewSet(...) }
+		if funcDecl.Recv == nil && funcDecl.Type.Params.NumFields() == 0 && len(funcDecl.Body.List) == 1 {
+			if ret, ok := funcDecl.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+				if call, ok := ast.Unparen(ret.Results[0]).(*ast.CallExpr); ok && isWireCall(call, wireAlias) && wireCallName(call) == "NewSet" {
+					if obj := info.Defs[funcDecl.Name]; obj != nil {
+						sets.defs[obj] = call
+					}
+				}
+			}
+		}
+
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			switch stmt := n.(type) {
+			case *ast.AssignStmt:
+				if len(stmt.Lhs) != len(stmt.Rhs) {
+					return true
+				}
+				for i, lhs := range stmt.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						addVar(ident, stmt.Rhs[i])
+					}
+				}
+			case *ast.ValueSpec:
+				for i, name := range stmt.Names {
+					if i < len(stmt.Values) {
+						addVar(name, stmt.Values[i])
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	// A variable assigned more than once cannot be resolved statically
+	for obj, count := range assigned {
+		if count > 1 {
+			delete(sets.defs, obj)
+		}
+	}
+
+	return sets
+}
+
+// object returns the variable or function that expr refers to as a set.
+func (s *localSets) object(expr ast.Expr, info *types.Info) types.Object {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return info.ObjectOf(e)
+	case *ast.CallExpr:
+		if len(e.Args) != 0 {
+			return nil
+		}
+		if ident, ok := ast.Unparen(e.Fun).(*ast.Ident); ok {
+			return info.ObjectOf(ident)
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the wire.NewSet call defining the set expr refers to, or nil if it is unknown.
+func (s *localSets) resolve(expr ast.Expr, info *types.Info) *ast.CallExpr {
+	obj := s.object(expr, info)
+	if obj == nil || s.resolving[obj] {
+		return nil
+	}
+
+	return s.defs[obj]
+}
+
+// wireBuildCall returns the wire.Build call of a statement in an injector stub, which is either
+// wire.Build(...) followed by a return statement or panic(wire.Build(...)), or nil.
+func wireBuildCall(stmt ast.Stmt, wireAlias string) *ast.CallExpr {
+	exprStmt, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil
+	}
+
+	call, ok := ast.Unparen(exprStmt.X).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+
+	// panic(wire.Build(...)) avoids the dummy return values of the stub
+	if ident, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && ident.Name == "panic" && len(call.Args) == 1 {
+		call, ok = ast.Unparen(call.Args[0]).(*ast.CallExpr)
+		if !ok {
+			return nil
+		}
+	}
+
+	if !isWireCall(call, wireAlias) || wireCallName(call) != "Build" {
+		return nil
+	}
+
+	return call
+}
+
+// isWireCall reports whether call is a call of a function of the wire package.
+func isWireCall(call *ast.CallExpr, wireAlias string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == wireAlias
+}
+
+// wireCallName returns the name of the wire function called by call, which must satisfy isWireCall.
+func wireCallName(call *ast.CallExpr) string {
+	return call.Fun.(*ast.SelectorExpr).Sel.Name
+}
+
 // parseBind parses wire.Bind(new(Interface), new(Impl)) pattern.
 // extractStringFields extracts string literals from a slice of expressions.
 func extractStringFields(args []ast.Expr) []string {
@@ -354,16 +539,13 @@ func (p *Parser) parseStruct(call *ast.CallExpr, info *types.Info, filePath stri
 		return nil
 	}
 
-	isPointer := false
-	if _, ok := structType.(*types.Pointer); ok {
-		isPointer = true
-	}
-
 	fields := extractStringFields(call.Args[1:])
 	if len(fields) == 0 {
 		fields = []string{"*"}
 	}
 
+	// wire.Struct provides both T and *T; resolveStructForms narrows this
+	// down once the consumers in the file are known.
 	return &WireStruct{
 		baseWirePattern: baseWirePattern{
 			Pos:  call.Pos(),
@@ -371,8 +553,81 @@ func (p *Parser) parseStruct(call *ast.CallExpr, info *types.Info, filePath stri
 		},
 		StructType: structType,
 		Fields:     fields,
-		IsPointer:  isPointer,
+		IsPointer:  true,
+	}
+}
+
+// resolveStructForms decides whether each wire.Struct in patterns provides T, *T or both.
+// The form is chosen from the types required by the other patterns in the file;
+// *T is kept when neither form is required here.
+func resolveStructForms(patterns []WirePattern) {
+	required := make(map[string]bool)
+	require := func(t types.Type) {
+		required[types.TypeString(t, nil)] = true
+	}
+
+	var collect func([]WirePattern)
+	collect = func(patterns []WirePattern) {
+		for _, pattern := range patterns {
+			switch wp := pattern.(type) {
+			case *WireNewSet:
+				collect(wp.Elements)
+			case *WireBuild:
+				if len(wp.ReturnTypes) > 0 {
+					require(wp.ReturnTypes[0])
+				}
+				collect(wp.Elements)
+			case *WireProviderFunc:
+				if wp.Func == nil {
+					continue
+				}
+				params := wp.Func.Type().(*types.Signature).Params()
+				for param := range params.Variables() {
+					require(param.Type())
+				}
+			case *WireStruct:
+				st, ok := unwrapPointer(wp.StructType).Underlying().(*types.Struct)
+				if !ok {
+					continue
+				}
+				for field := range st.Fields() {
+					if wp.Fields[0] == "*" || contains(wp.Fields, field.Name()) {
+						require(field.Type())
+					}
+				}
+			case *WireFieldsOf:
+				// The generated accessor takes a pointer to the innermost struct type.
+				structType := wp.StructType
+				for {
+					ptr, ok := structType.(*types.Pointer)
+					if !ok {
+						break
+					}
+					structType = ptr.Elem()
+				}
+				require(types.NewPointer(structType))
+			}
+		}
+	}
+	collect(patterns)
+
+	var apply func([]WirePattern)
+	apply = func(patterns []WirePattern) {
+		for _, pattern := range patterns {
+			switch wp := pattern.(type) {
+			case *WireNewSet:
+				apply(wp.Elements)
+			case *WireBuild:
+				apply(wp.Elements)
+			case *WireStruct:
+				valueRequired := required[types.TypeString(unwrapPointer(wp.StructType), nil)]
+				pointerRequired := required[types.TypeString(wp.StructType, nil)]
+				wp.IsValue = valueRequired
+				wp.IsPointer = pointerRequired || !valueRequired
+			}
+		}
 	}
+	apply(patterns)
 }
 
 // parseFieldsOf parses wire.FieldsOf(new(Type), fields...) pattern.
@@ -399,7 +654,7 @@ func (p *Parser) parseFieldsOf(call *ast.CallExpr, info *types.Info, filePath st
 }
 
 // parseBuild parses wire.Build(...) pattern in an injector function.
-func (p *Parser) parseBuild(call *ast.CallExpr, funcDecl *ast.FuncDecl, info *types.Info, wireAlias string, filePath string) *WireBuild {
+func (p *Parser) parseBuild(call *ast.CallExpr, funcDecl *ast.FuncDecl, info *types.Info, wireAlias string, filePath string, sets *localSets) *WireBuild {
 	build := &WireBuild{
 		baseWirePattern: baseWirePattern{
 			Pos:  call.Pos(),
@@ -411,7 +666,7 @@ func (p *Parser) parseBuild(call *ast.CallExpr, funcDecl *ast.FuncDecl, info *ty
 
 	// Parse elements passed to wire.Build (same as wire.NewSet elements)
 	for _, arg := range call.Args {
-		elem := p.parseSetElement(arg, info, wireAlias, filePath)
+		elem := p.parseSetElement(arg, info, wireAlias, filePath, sets)
 		if elem != nil {
 			build.Elements = append(build.Elements, elem)
 		}
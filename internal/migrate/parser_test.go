@@ -5,6 +5,7 @@ import (
 	"go/parser"
 	"go/token"
 	"go/types"
+	"path/filepath"
 	"testing"
 
 	"golang.org/x/tools/go/packages"
@@ -100,6 +101,49 @@ type Foo struct{}
 			wantPatterns: 1,
 			wantWarnings: 0,
 		},
+		{
+			name: "Build with return stub",
+			src: `package test
+import "github.com/google/wire"
+func InitializeFoo() (*Foo, error) {
+	wire.Build(NewFoo)
+	return nil, nil
+}
+func NewFoo() *Foo { return &Foo{} }
+type Foo struct{}
+`,
+			wireAlias:    "wire",
+			wantPatterns: 1,
+			wantWarnings: 0,
+		},
+		{
+			name: "Build with panic stub",
+			src: `package test
+import "github.com/google/wire"
+func InitializeFoo() *Foo {
+	panic(wire.Build(NewFoo))
+}
+func NewFoo() *Foo { return &Foo{} }
+type Foo struct{}
+`,
+			wireAlias:    "wire",
+			wantPatterns: 1,
+			wantWarnings: 0,
+		},
+		{
+			name: "panic without Build",
+			src: `package test
+import "github.com/google/wire"
+var _ = wire.NewSet
+func InitializeFoo() *Foo {
+	panic("not implemented")
+}
+type Foo struct{}
+`,
+			wireAlias:    "wire",
+			wantPatterns: 0,
+			wantWarnings: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -225,7 +269,7 @@ import "github.com/traPtitech/traQ/repository/gorm2/v1"
 				t.Fatalf("failed to parse: %v", err)
 			}
 
-			got := p.ExtractImports(file)
+			got := p.ExtractImports(file, nil)
 			if len(got) != len(tt.want) {
 				t.Errorf("ExtractImports() got %d imports, want %d", len(got), len(tt.want))
 			}
@@ -240,6 +284,45 @@ import "github.com/traPtitech/traQ/repository/gorm2/v1"
 	}
 }
 
+func TestExtractImports_PackageName(t *testing.T) {
+	p := NewParser()
+
+	src := `package test
+import (
+	"example.com/foo/v2"
+	bar "example.com/go-bar"
+)
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	// Unaliased imports are named by the imported package, not by the last path element
+	info := &types.Info{
+		Implicits: map[ast.Node]types.Object{
+			file.Imports[0]: types.NewPkgName(token.NoPos, nil, "foo", types.NewPackage("example.com/foo/v2", "foo")),
+		},
+	}
+
+	got := p.ExtractImports(file, info)
+	want := map[string]string{
+		"foo": "example.com/foo/v2",
+		"bar": "example.com/go-bar",
+	}
+	if len(got) != len(want) {
+		t.Errorf("ExtractImports() got %d imports, want %d", len(got), len(want))
+	}
+	for name, path := range want {
+		if gotPath, exists := got[name]; !exists {
+			t.Errorf("ExtractImports() missing import %q", name)
+		} else if gotPath != path {
+			t.Errorf("ExtractImports() got path %q for %q, want %q", gotPath, name, path)
+		}
+	}
+}
+
 func TestExtractTypeFromNew(t *testing.T) {
 	p := NewParser()
 
@@ -293,3 +376,103 @@ func TestExtractTypeFromNew(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractPatterns_LocalSets(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantElements int
+		wantWarnings int
+	}{
+		{
+			name: "function-local set",
+			body: `	set := wire.NewSet(NewFoo)
+	wire.Build(set)`,
+			wantElements: 1,
+		},
+		{
+			name:         "set returned by a function",
+			body:         `	wire.Build(FooSet())`,
+			wantElements: 1,
+		},
+		{
+			name: "reassigned set variable",
+			body: `	set := wire.NewSet(NewFoo)
+	set = wire.NewSet(NewFoo)
+	wire.Build(set)`,
+			wantWarnings: 1,
+		},
+		{
+			name:         "set returned by a function with parameters",
+			body:         `	wire.Build(FooSetFor(true))`,
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package test
+
+import "github.com/google/wire"
+
+type Foo struct{}
+
+func NewFoo() *Foo { return &Foo{} }
+
+func FooSet() wire.ProviderSet {
+	return wire.NewSet(NewFoo)
+}
+
+func FooSetFor(enabled bool) wire.ProviderSet {
+	return wire.NewSet(NewFoo)
+}
+
+func InitializeFoo() *Foo {
+` + tt.body + `
+	return nil
+}
+`
+			// The overlay file lives in this module so that the wire import resolves
+			path, err := filepath.Abs(filepath.Join("testdata", "local_sets_overlay.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := &packages.Config{
+				Mode:    packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles,
+				Overlay: map[string][]byte{path: []byte(src)},
+			}
+			pkgs, err := packages.Load(cfg, path)
+			if err != nil {
+				t.Fatalf("failed to load: %v", err)
+			}
+			if len(pkgs) != 1 || len(pkgs[0].Errors) > 0 || len(pkgs[0].Syntax) != 1 {
+				t.Fatalf("failed to load package: %v", pkgs)
+			}
+
+			p := NewParser()
+			patterns, warnings := p.ExtractPatterns(pkgs[0].Syntax[0], pkgs[0].TypesInfo, "wire", path)
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("ExtractPatterns() got %d warnings, want %d: %v", len(warnings), tt.wantWarnings, warnings)
+			}
+
+			var build *WireBuild
+			for _, pattern := range patterns {
+				if b, ok := pattern.(*WireBuild); ok {
+					build = b
+				}
+			}
+			if build == nil {
+				t.Fatal("ExtractPatterns() found no wire.Build")
+			}
+			if len(build.Elements) != tt.wantElements {
+				t.Fatalf("wire.Build got %d elements, want %d", len(build.Elements), tt.wantElements)
+			}
+			if tt.wantElements > 0 {
+				set, ok := build.Elements[0].(*WireNewSet)
+				if !ok || len(set.Elements) != 1 {
+					t.Errorf("Expected the set to be inlined as wire.NewSet(NewFoo), got %#v", build.Elements[0])
+				}
+			}
+		})
+	}
+}
@@ -108,11 +108,13 @@ type WireInterfaceValue struct {
 func (*WireInterfaceValue) wirePattern() {}
 
 // WireStruct represents wire.Struct(new(Type), fields...) pattern.
+// IsPointer and IsValue report whether *Type and Type should be provided.
 type WireStruct struct {
 	baseWirePattern
 	StructType types.Type
 	Fields     []string
 	IsPointer  bool
+	IsValue    bool
 }
 
 func (*WireStruct) wirePattern() {}
@@ -223,6 +225,15 @@ type Warning struct {
 	Code    WarningCode
 }
 
+// UnsupportedConstruct represents a part of a wire construct that the migration could not
+// translate and left out of the output, to be migrated manually.
+type UnsupportedConstruct struct {
+	Construct string         // What was left out, e.g. an unexported field set by wire.Struct
+	Fix       string         // Suggested manual fix
+	Position  token.Position // Resolved by the Migrator from Pos
+	Pos       token.Pos
+}
+
 // ParseError represents an error during single-file parsing/analysis.
 type ParseError struct {
 	File    string
@@ -0,0 +1 @@
+// ERROR: multiple constructors found for type "PostgresRepo": DefaultPostgresRepo, MakePostgresRepo; define "NewPostgresRepo" to choose one
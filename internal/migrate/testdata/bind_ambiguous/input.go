@@ -0,0 +1,26 @@
+package bind_ambiguous
+
+import "github.com/google/wire"
+
+type Repository interface {
+	Get() string
+}
+
+type PostgresRepo struct{}
+
+func (p *PostgresRepo) Get() string {
+	return "postgres"
+}
+
+func MakePostgresRepo() *PostgresRepo {
+	return &PostgresRepo{}
+}
+
+func DefaultPostgresRepo() *PostgresRepo {
+	return &PostgresRepo{}
+}
+
+var RepoSet = wire.NewSet(
+	MakePostgresRepo,
+	wire.Bind(new(Repository), new(*PostgresRepo)),
+)
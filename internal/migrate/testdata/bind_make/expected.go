@@ -0,0 +1,11 @@
+//go:generate go tool kessoku $GOFILE
+
+package bind_make
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+var RepoSet = kessoku.Set(
+	kessoku.Bind[Repository](kessoku.Provide(MakePostgresRepo)),
+)
@@ -0,0 +1,22 @@
+package bind_make
+
+import "github.com/google/wire"
+
+type Repository interface {
+	Get() string
+}
+
+type PostgresRepo struct{}
+
+func (p *PostgresRepo) Get() string {
+	return "postgres"
+}
+
+func MakePostgresRepo() (*PostgresRepo, error) {
+	return &PostgresRepo{}, nil
+}
+
+var RepoSet = wire.NewSet(
+	MakePostgresRepo,
+	wire.Bind(new(Repository), new(*PostgresRepo)),
+)
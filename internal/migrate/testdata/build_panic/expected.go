@@ -0,0 +1,18 @@
+//go:generate go tool kessoku $GOFILE
+
+package build_panic
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewDB),
+	kessoku.Provide(NewApp),
+)
+var _ = kessoku.Inject[*Worker](
+	"InitializeWorker",
+	kessoku.Provide(NewDB),
+	kessoku.Provide(NewWorker),
+)
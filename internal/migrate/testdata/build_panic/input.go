@@ -0,0 +1,36 @@
+//go:build wireinject
+
+package build_panic
+
+import "github.com/google/wire"
+
+type App struct {
+	DB *DB
+}
+
+type DB struct{}
+
+type Worker struct {
+	DB *DB
+}
+
+func NewDB() (*DB, error) {
+	return &DB{}, nil
+}
+
+func NewApp(db *DB) *App {
+	return &App{DB: db}
+}
+
+func NewWorker(db *DB) *Worker {
+	return &Worker{DB: db}
+}
+
+func InitializeApp() (*App, error) {
+	panic(wire.Build(NewDB, NewApp))
+}
+
+func InitializeWorker() (*Worker, error) {
+	wire.Build(NewDB, NewWorker)
+	return nil, nil
+}
@@ -0,0 +1,13 @@
+//go:generate go tool kessoku $GOFILE
+
+package main
+
+import (
+	"github.com/mazrean/kessoku"
+	infra "github.com/mazrean/kessoku/internal/migrate/testdata/cross_pkg_pkgname/infra/v2"
+)
+
+var InfraSet = kessoku.Set(
+	kessoku.Value(infra.DefaultConfig),
+	kessoku.Provide(infra.NewDatabase),
+)
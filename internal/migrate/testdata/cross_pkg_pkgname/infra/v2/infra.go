@@ -0,0 +1,15 @@
+package infra
+
+type Config struct {
+	DSN string
+}
+
+var DefaultConfig = Config{DSN: "postgres://localhost:5432/app"}
+
+type Database struct {
+	config Config
+}
+
+func NewDatabase(config Config) *Database {
+	return &Database{config: config}
+}
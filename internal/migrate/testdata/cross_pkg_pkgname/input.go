@@ -0,0 +1,13 @@
+//go:build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+	"github.com/mazrean/kessoku/internal/migrate/testdata/cross_pkg_pkgname/infra/v2"
+)
+
+var InfraSet = wire.NewSet(
+	wire.Value(infra.DefaultConfig),
+	infra.NewDatabase,
+)
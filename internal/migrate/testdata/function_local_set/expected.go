@@ -0,0 +1,19 @@
+//go:generate go tool kessoku $GOFILE
+
+package function_local_set
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+var AppSet = kessoku.Set(
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+)
+var _ = kessoku.Inject[*App](
+	"InitializeApp",
+	kessoku.Provide(NewConfig),
+	kessoku.Provide(NewDatabase),
+	kessoku.Provide(NewCache),
+	kessoku.Provide(NewApp),
+)
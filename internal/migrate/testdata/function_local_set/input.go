@@ -0,0 +1,38 @@
+package function_local_set
+
+import (
+	"github.com/google/wire"
+)
+
+type Config struct{}
+type Database struct{}
+type Cache struct{}
+
+type App struct {
+	db    *Database
+	cache *Cache
+}
+
+func NewConfig() *Config { return &Config{} }
+
+func NewDatabase(config *Config) *Database { return &Database{} }
+
+func NewCache(config *Config) *Cache { return &Cache{} }
+
+func NewApp(db *Database, cache *Cache) *App {
+	return &App{db: db, cache: cache}
+}
+
+// Set returned by a function
+func InfraSet() wire.ProviderSet {
+	return wire.NewSet(NewConfig, NewDatabase)
+}
+
+var AppSet = InfraSet()
+
+func InitializeApp() (*App, error) {
+	// Set defined inside the injector function
+	cacheSet := wire.NewSet(NewCache)
+	wire.Build(InfraSet(), cacheSet, NewApp)
+	return nil, nil
+}
@@ -0,0 +1,15 @@
+package app
+
+import (
+	"github.com/mazrean/kessoku/internal/migrate/testdata/project/infra"
+	"github.com/mazrean/kessoku/internal/migrate/testdata/project/model"
+)
+
+type Service struct {
+	db   *infra.Database
+	user model.User
+}
+
+func NewService(db *infra.Database) *Service {
+	return &Service{db: db}
+}
@@ -0,0 +1,13 @@
+//go:build wireinject
+
+package app
+
+import (
+	"github.com/google/wire"
+	"github.com/mazrean/kessoku/internal/migrate/testdata/project/infra"
+)
+
+func InitializeService() (*Service, error) {
+	wire.Build(infra.InfraSet, NewService)
+	return nil, nil
+}
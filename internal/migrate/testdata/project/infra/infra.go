@@ -0,0 +1,17 @@
+package infra
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost/app"}
+}
+
+type Database struct {
+	DSN string
+}
+
+func NewDatabase(config *Config) (*Database, error) {
+	return &Database{DSN: config.DSN}, nil
+}
@@ -0,0 +1,8 @@
+package infra
+
+import "github.com/google/wire"
+
+var InfraSet = wire.NewSet(
+	ConfigSet,
+	NewDatabase,
+)
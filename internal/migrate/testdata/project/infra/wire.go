@@ -0,0 +1,5 @@
+package infra
+
+import "github.com/google/wire"
+
+var ConfigSet = wire.NewSet(NewConfig)
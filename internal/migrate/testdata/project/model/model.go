@@ -0,0 +1,6 @@
+package model
+
+// User is shared by the packages of the project but has no wire configuration.
+type User struct {
+	Name string
+}
@@ -0,0 +1,14 @@
+//go:generate go tool kessoku $GOFILE
+
+package struct_external_unexported
+
+import (
+	"github.com/mazrean/kessoku"
+	"github.com/mazrean/kessoku/internal/migrate/testdata/struct_external_unexported/external"
+)
+
+var OptionsSet = kessoku.Set(
+	kessoku.Provide(func(name string) *external.Options {
+		return &external.Options{Name: name}
+	}),
+)
@@ -0,0 +1,6 @@
+package external
+
+type Options struct {
+	Name   string
+	secret string
+}
@@ -0,0 +1,10 @@
+//go:build wireinject
+
+package struct_external_unexported
+
+import (
+	"github.com/google/wire"
+	"github.com/mazrean/kessoku/internal/migrate/testdata/struct_external_unexported/external"
+)
+
+var OptionsSet = wire.NewSet(wire.Struct(new(external.Options), "*"))
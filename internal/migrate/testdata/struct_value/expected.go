@@ -0,0 +1,14 @@
+//go:generate go tool kessoku $GOFILE
+
+package struct_value
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+var ServerSet = kessoku.Set(
+	kessoku.Provide(func(host string, port int) Config {
+		return Config{Host: host, Port: port}
+	}),
+	kessoku.Provide(NewServer),
+)
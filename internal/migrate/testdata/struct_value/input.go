@@ -0,0 +1,20 @@
+package struct_value
+
+import (
+	"github.com/google/wire"
+)
+
+type Config struct {
+	Host string
+	Port int
+}
+
+type Server struct {
+	config Config
+}
+
+func NewServer(config Config) *Server {
+	return &Server{config: config}
+}
+
+var ServerSet = wire.NewSet(wire.Struct(new(Config), "*"), NewServer)
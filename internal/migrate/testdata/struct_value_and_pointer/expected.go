@@ -0,0 +1,18 @@
+//go:generate go tool kessoku $GOFILE
+
+package struct_value_and_pointer
+
+import (
+	"github.com/mazrean/kessoku"
+)
+
+var AppSet = kessoku.Set(
+	kessoku.Provide(func(host string, port int) *Config {
+		return &Config{Host: host, Port: port}
+	}),
+	kessoku.Provide(func(host string, port int) Config {
+		return Config{Host: host, Port: port}
+	}),
+	kessoku.Provide(NewServer),
+	kessoku.Provide(NewClient),
+)
@@ -0,0 +1,28 @@
+package struct_value_and_pointer
+
+import (
+	"github.com/google/wire"
+)
+
+type Config struct {
+	Host string
+	Port int
+}
+
+type Server struct {
+	config Config
+}
+
+func NewServer(config Config) *Server {
+	return &Server{config: config}
+}
+
+type Client struct {
+	config *Config
+}
+
+func NewClient(config *Config) *Client {
+	return &Client{config: config}
+}
+
+var AppSet = wire.NewSet(wire.Struct(new(Config), "*"), NewServer, NewClient)
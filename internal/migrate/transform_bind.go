@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/types"
+	"strings"
 )
 
 // transformBind transforms wire.Bind to kessoku.Bind.
@@ -31,27 +32,16 @@ func (t *Transformer) transformBind(wb *WireBind, pkg *types.Package) (*KessokuB
 	typeName := named.Obj().Name()
 	implPkg := named.Obj().Pkg()
 
-	// Look up constructor in package scope
-	constructorName := "New" + typeName
-
-	var constructor *types.Func
-	if implPkg != nil {
-		obj := implPkg.Scope().Lookup(constructorName)
-		if obj != nil {
-			if fn, ok := obj.(*types.Func); ok {
-				constructor = fn
-			}
-		}
-	}
-
-	if constructor == nil {
+	constructor, err := findConstructor(implPkg, pkg, typeName, unwrapPointer(wb.Implementation))
+	if err != nil {
 		return nil, &ParseError{
 			Kind:    ParseErrorMissingConstructor,
 			File:    wb.File,
 			Pos:     wb.Pos,
-			Message: fmt.Sprintf("no constructor %q found for type %q", constructorName, typeName),
+			Message: err.Error(),
 		}
 	}
+	constructorName := constructor.Name()
 
 	// Build the constructor expression
 	var funcExpr ast.Expr
@@ -79,3 +69,60 @@ func (t *Transformer) transformBind(wb *WireBind, pkg *types.Package) (*KessokuB
 		SourcePos: wb.Pos,
 	}, nil
 }
+
+// findConstructor looks up the constructor of the implementation type implType named typeName in implPkg.
+// New<Type> is preferred. Otherwise the package is scanned for a unique function returning implType,
+// optionally followed by an error, so that names like Make<Type> or Default<Type> are found too.
+func findConstructor(implPkg, pkg *types.Package, typeName string, implType types.Type) (*types.Func, error) {
+	if implPkg == nil {
+		return nil, fmt.Errorf("no constructor found for type %q", typeName)
+	}
+
+	scope := implPkg.Scope()
+	if fn, ok := scope.Lookup("New" + typeName).(*types.Func); ok {
+		return fn, nil
+	}
+
+	var candidates []*types.Func
+	for _, name := range scope.Names() {
+		fn, ok := scope.Lookup(name).(*types.Func)
+		if !ok || (implPkg != pkg && !fn.Exported()) {
+			continue
+		}
+
+		sig, ok := fn.Type().(*types.Signature)
+		if !ok || sig.TypeParams().Len() > 0 || !isConstructorResults(sig.Results(), implType) {
+			continue
+		}
+
+		candidates = append(candidates, fn)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("no constructor %q or other function returning %s found for type %q", "New"+typeName, implType, typeName)
+	case 1:
+		return candidates[0], nil
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, fn := range candidates {
+			names = append(names, fn.Name())
+		}
+		return nil, fmt.Errorf("multiple constructors found for type %q: %s; define %q to choose one", typeName, strings.Join(names, ", "), "New"+typeName)
+	}
+}
+
+// isConstructorResults reports whether results are implType, optionally followed by an error.
+func isConstructorResults(results *types.Tuple, implType types.Type) bool {
+	switch results.Len() {
+	case 1:
+	case 2:
+		if !isErrorType(results.At(1).Type()) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	return types.Identical(results.At(0).Type(), implType)
+}
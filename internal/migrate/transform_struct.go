@@ -1,6 +1,7 @@
 package migrate
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
@@ -14,12 +15,16 @@ type fieldInfo struct {
 }
 
 // transformStruct transforms wire.Struct to kessoku.Provide with function literal.
-func (t *Transformer) transformStruct(ws *WireStruct, pkg *types.Package) *KessokuProvide {
+// One provider is returned for each of the pointer and value forms the struct provides.
+func (t *Transformer) transformStruct(ws *WireStruct, pkg *types.Package) []KessokuPattern {
 	structType := unwrapPointer(ws.StructType)
 	underlying := structType.Underlying()
 	st, ok := underlying.(*types.Struct)
 	if !ok {
-		return &KessokuProvide{SourcePos: ws.Pos}
+		t.addUnsupported(ws.Pos,
+			fmt.Sprintf("wire.Struct of %s, which is not a struct", typeName(structType, pkg)),
+			"provide a constructor function of the type instead")
+		return []KessokuPattern{&KessokuProvide{SourcePos: ws.Pos}}
 	}
 
 	// Check if struct is from external package
@@ -34,8 +39,11 @@ func (t *Transformer) transformStruct(ws *WireStruct, pkg *types.Package) *Kesso
 	var fieldInfos []fieldInfo
 	for field := range st.Fields() {
 		if ws.Fields[0] == "*" || contains(ws.Fields, field.Name()) {
-			// Skip unexported fields from external packages
+			// Unexported fields of external packages cannot be set from the migrated package
 			if isExternalPkg && !field.Exported() {
+				t.addUnsupported(ws.Pos,
+					fmt.Sprintf("wire.Struct field %s of %s, which is unexported", field.Name(), typeName(structType, pkg)),
+					fmt.Sprintf("add a constructor setting %s to package %s and provide it instead of the struct", field.Name(), field.Pkg().Path()))
 				continue
 			}
 			fieldInfos = append(fieldInfos, fieldInfo{
@@ -46,13 +54,22 @@ func (t *Transformer) transformStruct(ws *WireStruct, pkg *types.Package) *Kesso
 		}
 	}
 
-	// Build function literal
-	funcLit := t.buildStructConstructor(structType, fieldInfos, ws.IsPointer)
-
-	return &KessokuProvide{
-		FuncExpr:  funcLit,
-		SourcePos: ws.Pos,
+	// Build function literals
+	var result []KessokuPattern
+	if ws.IsPointer {
+		result = append(result, &KessokuProvide{
+			FuncExpr:  t.buildStructConstructor(structType, fieldInfos, true),
+			SourcePos: ws.Pos,
+		})
 	}
+	if ws.IsValue {
+		result = append(result, &KessokuProvide{
+			FuncExpr:  t.buildStructConstructor(structType, fieldInfos, false),
+			SourcePos: ws.Pos,
+		})
+	}
+
+	return result
 }
 
 // transformFieldsOf transforms wire.FieldsOf to kessoku.Provide with accessor function.
@@ -72,6 +89,9 @@ func (t *Transformer) transformFieldsOf(wf *WireFieldsOf, pkg *types.Package) *K
 	underlying := structType.Underlying()
 	st, ok := underlying.(*types.Struct)
 	if !ok {
+		t.addUnsupported(wf.Pos,
+			fmt.Sprintf("wire.FieldsOf of %s, which is not a struct", typeName(structType, pkg)),
+			"provide a function returning the values instead")
 		return &KessokuProvide{SourcePos: wf.Pos}
 	}
 
@@ -88,8 +108,11 @@ func (t *Transformer) transformFieldsOf(wf *WireFieldsOf, pkg *types.Package) *K
 	for _, fieldName := range wf.Fields {
 		for field := range st.Fields() {
 			if field.Name() == fieldName {
-				// Skip unexported fields from external packages
+				// Unexported fields of external packages cannot be read from the migrated package
 				if isExternalPkg && !field.Exported() {
+					t.addUnsupported(wf.Pos,
+						fmt.Sprintf("wire.FieldsOf field %s of %s, which is unexported", field.Name(), typeName(structType, pkg)),
+						fmt.Sprintf("add an exported method returning %s to package %s and provide it", field.Name(), field.Pkg().Path()))
 					break
 				}
 				fieldInfos = append(fieldInfos, fieldInfo{
@@ -15,7 +15,8 @@ const maxInjectorReturns = 2
 
 // Transformer converts wire patterns to kessoku patterns.
 type Transformer struct {
-	tc *TypeConverter
+	tc          *TypeConverter
+	unsupported []UnsupportedConstruct
 }
 
 // NewTransformer creates a new Transformer instance.
@@ -25,8 +26,10 @@ func NewTransformer() *Transformer {
 
 // Transform transforms a list of wire patterns to kessoku patterns.
 // If tc is non-nil, it will be used for proper package-qualified type expressions.
+// The constructs it could not fully translate are available from Unsupported afterwards.
 func (t *Transformer) Transform(patterns []WirePattern, pkg *types.Package, tc *TypeConverter) ([]KessokuPattern, error) {
 	t.tc = tc
+	t.unsupported = nil
 	var result []KessokuPattern
 
 	for _, p := range patterns {
@@ -48,7 +51,7 @@ func (t *Transformer) Transform(patterns []WirePattern, pkg *types.Package, tc *
 		case *WireInterfaceValue:
 			result = append(result, t.transformInterfaceValue(wp))
 		case *WireStruct:
-			result = append(result, t.transformStruct(wp, pkg))
+			result = append(result, t.transformStruct(wp, pkg)...)
 		case *WireFieldsOf:
 			result = append(result, t.transformFieldsOf(wp, pkg))
 		case *WireProviderFunc:
@@ -67,6 +70,30 @@ func (t *Transformer) Transform(patterns []WirePattern, pkg *types.Package, tc *
 	return result, nil
 }
 
+// Unsupported returns the constructs that the last Transform left out of its patterns.
+func (t *Transformer) Unsupported() []UnsupportedConstruct {
+	return t.unsupported
+}
+
+// addUnsupported records a construct at pos that is left out of the output.
+func (t *Transformer) addUnsupported(pos token.Pos, construct, fix string) {
+	t.unsupported = append(t.unsupported, UnsupportedConstruct{
+		Construct: construct,
+		Fix:       fix,
+		Pos:       pos,
+	})
+}
+
+// typeName returns the name of t as written in pkg, qualifying other packages by their names.
+func typeName(t types.Type, pkg *types.Package) string {
+	return types.TypeString(t, func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	})
+}
+
 // typeExpr converts a types.Type to ast.Expr using TypeConverter if available,
 // otherwise falls back to the standalone typeToExpr function.
 func (t *Transformer) typeExpr(typ types.Type) ast.Expr {